@@ -14,14 +14,32 @@ type Client interface {
 	GetContainer(logger lager.Logger, guid string) (Container, error)
 	RunContainer(lager.Logger, *RunRequest) error
 	StopContainer(logger lager.Logger, guid string) error
+	UpdateContainerLimits(logger lager.Logger, guid string, resource Resource) error
+	PauseContainer(logger lager.Logger, guid string) error
+	ResumeContainer(logger lager.Logger, guid string) error
 	DeleteContainer(logger lager.Logger, guid string) error
 	ListContainers(lager.Logger) ([]Container, error)
 	GetBulkMetrics(lager.Logger) (map[string]Metrics, error)
 	RemainingResources(lager.Logger) (ExecutorResources, error)
 	TotalResources(lager.Logger) (ExecutorResources, error)
 	GetFiles(logger lager.Logger, guid string, path string) (io.ReadCloser, error)
+	PutFiles(logger lager.Logger, guid string, destPath string, tarStream io.Reader, user string) error
+	// ExportContainerData writes the run request of a container and an archive
+	// of its data path (/home/vcap unless configured otherwise) to dest. This
+	// is not a checkpoint of the container: nothing else of its rootfs is
+	// archived, and neither are its processes.
+	ExportContainerData(logger lager.Logger, guid string, dest io.Writer) error
+	// ImportContainerData recreates an exported container under guid, with the
+	// exported data in its data path, and runs it. The container is destroyed
+	// if it cannot be imported.
+	ImportContainerData(logger lager.Logger, guid string, src io.Reader) error
 	VolumeDrivers(logger lager.Logger) ([]string, error)
 	SubscribeToEvents(lager.Logger) (EventSource, error)
+	SubscribeToEventsWithFilter(logger lager.Logger, filter EventFilter) (EventSource, error)
+	SubscribeFrom(logger lager.Logger, seq uint64, filter EventFilter) (EventSource, error)
+	RunProcess(logger lager.Logger, guid string, spec ProcessSpec, processIO ProcessIO) (Process, error)
+	SubscribeToLogs(logger lager.Logger, guid string) (LogSource, error)
+	GetRecentLogs(logger lager.Logger, guid string) ([]LogLine, error)
 	Healthy(lager.Logger) bool
 	SetHealthy(lager.Logger, bool)
 	Cleanup(lager.Logger)
@@ -34,10 +52,32 @@ type EventSource interface {
 	Close() error
 }
 
+//go:generate counterfeiter -o fakes/fake_log_source.go . LogSource
+
+type LogSource interface {
+	Next() (LogLine, error)
+	Close() error
+}
+
+//go:generate counterfeiter -o fakes/fake_process.go . Process
+
+// Process is an ad-hoc process run in a container with RunProcess.
+type Process interface {
+	ID() string
+	Wait() (int, error)
+	Resize(WindowSize) error
+	Signal(ProcessSignal) error
+}
+
 type AllocationRequest struct {
 	Guid string
 	Resource
 	Tags
+
+	// CPUWeight is the weight the container will run with, whose cpu shares
+	// are reserved along with its memory and disk. Its run cannot ask for a
+	// larger weight, and runs with this one when it does not set its own.
+	CPUWeight uint `json:"cpu_weight,omitempty"`
 }
 
 func NewAllocationRequest(guid string, resource *Resource, tags Tags) AllocationRequest {
@@ -55,9 +95,38 @@ func (a *AllocationRequest) Validate() error {
 	return nil
 }
 
+type AllocationFailureReason string
+
+const (
+	AllocationFailureReasonUnknown                     AllocationFailureReason = ""
+	AllocationFailureReasonInvalidRequest              AllocationFailureReason = "InvalidRequest"
+	AllocationFailureReasonGuidNotAvailable            AllocationFailureReason = "GuidNotAvailable"
+	AllocationFailureReasonInsufficientResources       AllocationFailureReason = "InsufficientResources"
+	AllocationFailureReasonTagQuotaExceeded            AllocationFailureReason = "TagQuotaExceeded"
+	AllocationFailureReasonTagConcurrencyLimitExceeded AllocationFailureReason = "TagConcurrencyLimitExceeded"
+)
+
+func AllocationFailureReasonForError(err error) AllocationFailureReason {
+	switch err {
+	case ErrGuidNotSpecified:
+		return AllocationFailureReasonInvalidRequest
+	case ErrContainerGuidNotAvailable:
+		return AllocationFailureReasonGuidNotAvailable
+	case ErrInsufficientResourcesAvailable:
+		return AllocationFailureReasonInsufficientResources
+	case ErrTagQuotaExceeded:
+		return AllocationFailureReasonTagQuotaExceeded
+	case ErrTagConcurrencyLimitExceeded:
+		return AllocationFailureReasonTagConcurrencyLimitExceeded
+	default:
+		return AllocationFailureReasonUnknown
+	}
+}
+
 type AllocationFailure struct {
 	AllocationRequest
 	ErrorMsg string
+	Reason   AllocationFailureReason
 }
 
 func (fail *AllocationFailure) Error() string {
@@ -71,6 +140,14 @@ func NewAllocationFailure(req *AllocationRequest, msg string) AllocationFailure
 	}
 }
 
+func NewAllocationFailureFromError(req *AllocationRequest, err error) AllocationFailure {
+	return AllocationFailure{
+		AllocationRequest: *req,
+		ErrorMsg:          err.Error(),
+		Reason:            AllocationFailureReasonForError(err),
+	}
+}
+
 type RunRequest struct {
 	Guid string
 	RunInfo
@@ -84,3 +161,39 @@ func NewRunRequest(guid string, runInfo *RunInfo, tags Tags) RunRequest {
 		Tags:    tags,
 	}
 }
+
+// ProcessSpec describes an ad-hoc process to run in a running container. The
+// process gets the container's environment, including its instance identity
+// credentials, and the same networking variables as the container's actions.
+type ProcessSpec struct {
+	Path string                `json:"path"`
+	Args []string              `json:"args,omitempty"`
+	Dir  string                `json:"dir,omitempty"`
+	User string                `json:"user,omitempty"`
+	Env  []EnvironmentVariable `json:"env,omitempty"`
+
+	// TTY allocates a terminal for the process when set.
+	TTY *TTYSpec `json:"tty,omitempty"`
+}
+
+type TTYSpec struct {
+	WindowSize *WindowSize `json:"window_size,omitempty"`
+}
+
+type WindowSize struct {
+	Columns uint16 `json:"columns"`
+	Rows    uint16 `json:"rows"`
+}
+
+type ProcessIO struct {
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+type ProcessSignal string
+
+const (
+	ProcessSignalTerminate ProcessSignal = "terminate"
+	ProcessSignalKill      ProcessSignal = "kill"
+)
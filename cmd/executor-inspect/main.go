@@ -0,0 +1,179 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"code.cloudfoundry.org/executor"
+	ehttp "code.cloudfoundry.org/executor/http"
+	"code.cloudfoundry.org/executor/inspector"
+	"code.cloudfoundry.org/lager"
+)
+
+const usage = `usage: executor-inspect [-network tcp|unix] [-cert <path> -key <path> -ca-cert <path>] -address <address> <command> [arguments]
+
+commands:
+  list                                list the containers with their state, usage, ports and tags
+  show [-env] <guid>                  show a container and its run info, with the
+                                      env var values redacted unless -env is set
+  events [-type t]... [-tag k:v]... [guid]
+                                      print lifecycle events as they happen
+  logs <guid>                         print the logs of a container as they happen
+  recent-logs <guid>                  print the last logs of a container, even after it crashed
+  files <guid> <path>                 write a tar of the files at path to stdout
+  put <guid> <path> [user]            extract a tar read from stdin at path in a running container
+  resources                           show the remaining and total resources of the cell
+`
+
+var (
+	network = flag.String("network", "unix", "network of the executor API (tcp or unix)")
+	address = flag.String("address", "", "address of the executor API")
+
+	certPath   = flag.String("cert", "", "client certificate for an executor API served over mutual TLS")
+	keyPath    = flag.String("key", "", "client key for an executor API served over mutual TLS")
+	caCertPath = flag.String("ca-cert", "", "CA of an executor API served over mutual TLS")
+)
+
+type stringList []string
+
+func (l *stringList) String() string     { return strings.Join(*l, ",") }
+func (l *stringList) Set(v string) error { *l = append(*l, v); return nil }
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprint(os.Stderr, usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if *address == "" || flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	logger := lager.NewLogger("executor-inspect")
+	logger.RegisterSink(lager.NewWriterSink(os.Stderr, lager.ERROR))
+
+	client, err := newClient()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "executor-inspect: %s\n", err)
+		os.Exit(1)
+	}
+
+	i := inspector.New(logger, client, os.Stdout)
+
+	err = run(i, flag.Arg(0), flag.Args()[1:])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "executor-inspect: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+func newClient() (executor.Client, error) {
+	if *certPath == "" && *keyPath == "" && *caCertPath == "" {
+		return ehttp.NewClient(*network, *address), nil
+	}
+
+	if *network != "tcp" {
+		return nil, fmt.Errorf("mutual TLS is only used over tcp")
+	}
+
+	cert, err := tls.LoadX509KeyPair(*certPath, *keyPath)
+	if err != nil {
+		return nil, err
+	}
+
+	caCert, err := ioutil.ReadFile(*caCertPath)
+	if err != nil {
+		return nil, err
+	}
+	caCertPool := x509.NewCertPool()
+	if !caCertPool.AppendCertsFromPEM(caCert) {
+		return nil, fmt.Errorf("no certificates in %s", *caCertPath)
+	}
+
+	return ehttp.NewTLSClient(*address, &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		RootCAs:      caCertPool,
+		MinVersion:   tls.VersionTLS12,
+	}), nil
+}
+
+func run(i *inspector.Inspector, command string, args []string) error {
+	switch command {
+	case "list":
+		return i.ListContainers()
+
+	case "show":
+		flags := flag.NewFlagSet("show", flag.ExitOnError)
+		showEnv := flags.Bool("env", false, "show the values of the env vars, except those with a secret name")
+		flags.Parse(args)
+		if flags.NArg() != 1 {
+			return fmt.Errorf("usage: show [-env] <guid>")
+		}
+		return i.ShowContainer(flags.Arg(0), *showEnv)
+
+	case "events":
+		var types, tags stringList
+		flags := flag.NewFlagSet("events", flag.ExitOnError)
+		flags.Var(&types, "type", "only print events of this type (repeatable)")
+		flags.Var(&tags, "tag", "only print events of containers with this key:value tag (repeatable)")
+		flags.Parse(args)
+
+		filter := executor.EventFilter{}
+		for _, t := range types {
+			filter.Types = append(filter.Types, executor.EventType(t))
+		}
+		for _, tag := range tags {
+			kv := strings.SplitN(tag, ":", 2)
+			if len(kv) != 2 {
+				return fmt.Errorf("invalid tag %q: expected key:value", tag)
+			}
+			if filter.Tags == nil {
+				filter.Tags = executor.Tags{}
+			}
+			filter.Tags[kv[0]] = kv[1]
+		}
+
+		return i.TailEvents(flags.Arg(0), filter)
+
+	case "logs":
+		if len(args) != 1 {
+			return fmt.Errorf("usage: logs <guid>")
+		}
+		return i.TailLogs(args[0])
+
+	case "recent-logs":
+		if len(args) != 1 {
+			return fmt.Errorf("usage: recent-logs <guid>")
+		}
+		return i.RecentLogs(args[0])
+
+	case "files":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: files <guid> <path>")
+		}
+		return i.GetFiles(args[0], args[1], os.Stdout)
+
+	case "put":
+		if len(args) != 2 && len(args) != 3 {
+			return fmt.Errorf("usage: put <guid> <path> [user]")
+		}
+		user := ""
+		if len(args) == 3 {
+			user = args[2]
+		}
+		return i.PutFiles(args[0], args[1], user, os.Stdin)
+
+	case "resources":
+		return i.Resources()
+
+	default:
+		return fmt.Errorf("unknown command %q", command)
+	}
+}
@@ -0,0 +1,111 @@
+package containerstore
+
+import "code.cloudfoundry.org/executor"
+
+//go:generate counterfeiter -o containerstorefakes/fake_admission_policy.go . AdmissionPolicy
+
+// AdmissionPolicy decides whether a reservation may be added to the store
+// given the containers it already holds. Rejections are returned as executor
+// errors so that they surface as typed allocation failure reasons.
+type AdmissionPolicy interface {
+	Admit(candidate executor.Container, existing []executor.Container) error
+}
+
+type compositeAdmissionPolicy []AdmissionPolicy
+
+func NewCompositeAdmissionPolicy(policies ...AdmissionPolicy) AdmissionPolicy {
+	return compositeAdmissionPolicy(policies)
+}
+
+func (policies compositeAdmissionPolicy) Admit(candidate executor.Container, existing []executor.Container) error {
+	for _, policy := range policies {
+		err := policy.Admit(candidate, existing)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type quotaByTagPolicy struct {
+	tag    string
+	quotas map[string]executor.Resource
+}
+
+// NewQuotaByTagPolicy limits the total memory and disk reserved by containers
+// sharing a value of the given tag, e.g. per tenant. Tag values without an
+// entry in quotas are unrestricted, as are zero fields of a quota.
+func NewQuotaByTagPolicy(tag string, quotas map[string]executor.Resource) AdmissionPolicy {
+	return &quotaByTagPolicy{
+		tag:    tag,
+		quotas: quotas,
+	}
+}
+
+func (p *quotaByTagPolicy) Admit(candidate executor.Container, existing []executor.Container) error {
+	value, ok := candidate.Tags[p.tag]
+	if !ok {
+		return nil
+	}
+
+	quota, ok := p.quotas[value]
+	if !ok {
+		return nil
+	}
+
+	used := candidate.Resource
+	for _, container := range existing {
+		if v, ok := container.Tags[p.tag]; !ok || v != value {
+			continue
+		}
+		used.MemoryMB += container.MemoryMB
+		used.DiskMB += container.DiskMB
+	}
+
+	if quota.MemoryMB > 0 && used.MemoryMB > quota.MemoryMB {
+		return executor.ErrTagQuotaExceeded
+	}
+	if quota.DiskMB > 0 && used.DiskMB > quota.DiskMB {
+		return executor.ErrTagQuotaExceeded
+	}
+
+	return nil
+}
+
+type maxConcurrentPerTagPolicy struct {
+	tag string
+	max int
+}
+
+// NewMaxConcurrentPerTagPolicy limits how many containers that have not yet
+// completed may share a value of the given tag, e.g. reservations per app
+// guid.
+func NewMaxConcurrentPerTagPolicy(tag string, max int) AdmissionPolicy {
+	return &maxConcurrentPerTagPolicy{
+		tag: tag,
+		max: max,
+	}
+}
+
+func (p *maxConcurrentPerTagPolicy) Admit(candidate executor.Container, existing []executor.Container) error {
+	value, ok := candidate.Tags[p.tag]
+	if !ok {
+		return nil
+	}
+
+	count := 1
+	for _, container := range existing {
+		if container.State == executor.StateCompleted {
+			continue
+		}
+		if v, ok := container.Tags[p.tag]; ok && v == value {
+			count++
+		}
+	}
+
+	if count > p.max {
+		return executor.ErrTagConcurrencyLimitExceeded
+	}
+
+	return nil
+}
@@ -0,0 +1,134 @@
+package containerstore_test
+
+import (
+	"errors"
+
+	"code.cloudfoundry.org/executor"
+	"code.cloudfoundry.org/executor/depot/containerstore"
+	"code.cloudfoundry.org/executor/depot/containerstore/containerstorefakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AdmissionPolicy", func() {
+	newContainer := func(guid string, state executor.State, memoryMB, diskMB int, tags executor.Tags) executor.Container {
+		return executor.Container{
+			Guid:     guid,
+			State:    state,
+			Resource: executor.Resource{MemoryMB: memoryMB, DiskMB: diskMB},
+			Tags:     tags,
+		}
+	}
+
+	Describe("QuotaByTagPolicy", func() {
+		var (
+			policy   containerstore.AdmissionPolicy
+			existing []executor.Container
+		)
+
+		BeforeEach(func() {
+			policy = containerstore.NewQuotaByTagPolicy("tenant", map[string]executor.Resource{
+				"tenant-a": {MemoryMB: 1024, DiskMB: 2048},
+				"tenant-b": {MemoryMB: 512},
+			})
+
+			existing = []executor.Container{
+				newContainer("a-1", executor.StateRunning, 512, 1024, executor.Tags{"tenant": "tenant-a"}),
+				newContainer("b-1", executor.StateRunning, 256, 4096, executor.Tags{"tenant": "tenant-b"}),
+				newContainer("untagged", executor.StateRunning, 4096, 4096, nil),
+			}
+		})
+
+		It("admits containers that fit within the quota of their tag value", func() {
+			candidate := newContainer("a-2", executor.StateReserved, 512, 1024, executor.Tags{"tenant": "tenant-a"})
+			Expect(policy.Admit(candidate, existing)).To(Succeed())
+		})
+
+		It("rejects containers that would exceed the memory quota", func() {
+			candidate := newContainer("a-2", executor.StateReserved, 513, 0, executor.Tags{"tenant": "tenant-a"})
+			Expect(policy.Admit(candidate, existing)).To(Equal(executor.ErrTagQuotaExceeded))
+		})
+
+		It("rejects containers that would exceed the disk quota", func() {
+			candidate := newContainer("a-2", executor.StateReserved, 0, 1025, executor.Tags{"tenant": "tenant-a"})
+			Expect(policy.Admit(candidate, existing)).To(Equal(executor.ErrTagQuotaExceeded))
+		})
+
+		It("does not limit resources that have no quota", func() {
+			candidate := newContainer("b-2", executor.StateReserved, 256, 100000, executor.Tags{"tenant": "tenant-b"})
+			Expect(policy.Admit(candidate, existing)).To(Succeed())
+		})
+
+		It("admits containers whose tag value has no quota", func() {
+			candidate := newContainer("c-1", executor.StateReserved, 100000, 100000, executor.Tags{"tenant": "tenant-c"})
+			Expect(policy.Admit(candidate, existing)).To(Succeed())
+		})
+
+		It("admits containers without the tag", func() {
+			candidate := newContainer("none", executor.StateReserved, 100000, 100000, nil)
+			Expect(policy.Admit(candidate, existing)).To(Succeed())
+		})
+	})
+
+	Describe("MaxConcurrentPerTagPolicy", func() {
+		var (
+			policy   containerstore.AdmissionPolicy
+			existing []executor.Container
+		)
+
+		BeforeEach(func() {
+			policy = containerstore.NewMaxConcurrentPerTagPolicy("app-guid", 2)
+
+			existing = []executor.Container{
+				newContainer("1", executor.StateRunning, 0, 0, executor.Tags{"app-guid": "app-1"}),
+				newContainer("2", executor.StateCompleted, 0, 0, executor.Tags{"app-guid": "app-1"}),
+				newContainer("3", executor.StateReserved, 0, 0, executor.Tags{"app-guid": "app-2"}),
+				newContainer("4", executor.StateReserved, 0, 0, executor.Tags{"app-guid": "app-2"}),
+			}
+		})
+
+		It("admits containers below the limit, ignoring completed containers", func() {
+			candidate := newContainer("5", executor.StateReserved, 0, 0, executor.Tags{"app-guid": "app-1"})
+			Expect(policy.Admit(candidate, existing)).To(Succeed())
+		})
+
+		It("rejects containers that would exceed the limit", func() {
+			candidate := newContainer("5", executor.StateReserved, 0, 0, executor.Tags{"app-guid": "app-2"})
+			Expect(policy.Admit(candidate, existing)).To(Equal(executor.ErrTagConcurrencyLimitExceeded))
+		})
+
+		It("admits containers without the tag", func() {
+			candidate := newContainer("5", executor.StateReserved, 0, 0, nil)
+			Expect(policy.Admit(candidate, existing)).To(Succeed())
+		})
+	})
+
+	Describe("CompositeAdmissionPolicy", func() {
+		var (
+			first, second *containerstorefakes.FakeAdmissionPolicy
+			policy        containerstore.AdmissionPolicy
+		)
+
+		BeforeEach(func() {
+			first = &containerstorefakes.FakeAdmissionPolicy{}
+			second = &containerstorefakes.FakeAdmissionPolicy{}
+			policy = containerstore.NewCompositeAdmissionPolicy(first, second)
+		})
+
+		It("admits containers that every policy admits", func() {
+			Expect(policy.Admit(executor.Container{}, nil)).To(Succeed())
+			Expect(first.AdmitCallCount()).To(Equal(1))
+			Expect(second.AdmitCallCount()).To(Equal(1))
+		})
+
+		It("returns the first rejection", func() {
+			first.AdmitReturns(errors.New("rejected"))
+			Expect(policy.Admit(executor.Container{}, nil)).To(MatchError("rejected"))
+			Expect(second.AdmitCallCount()).To(Equal(0))
+		})
+
+		It("admits everything when empty", func() {
+			Expect(containerstore.NewCompositeAdmissionPolicy().Admit(executor.Container{}, nil)).To(Succeed())
+		})
+	})
+})
@@ -9,6 +9,7 @@ import (
 	loggingclient "code.cloudfoundry.org/diego-logging-client"
 	"code.cloudfoundry.org/executor"
 	"code.cloudfoundry.org/executor/depot/event"
+	"code.cloudfoundry.org/executor/depot/log_streamer"
 	"code.cloudfoundry.org/executor/depot/transformer"
 	"code.cloudfoundry.org/executor/initializer/configuration"
 	"code.cloudfoundry.org/garden"
@@ -33,6 +34,10 @@ type ContainerStore interface {
 	Create(logger lager.Logger, guid string) (executor.Container, error)
 	Run(logger lager.Logger, guid string) error
 	Stop(logger lager.Logger, guid string) error
+	UpdateLimits(logger lager.Logger, guid string, resource executor.Resource) error
+	Pause(logger lager.Logger, guid string) error
+	Resume(logger lager.Logger, guid string) error
+	RunProcess(logger lager.Logger, guid string, spec executor.ProcessSpec, processIO executor.ProcessIO) (executor.Process, error)
 
 	// Getters
 	Get(logger lager.Logger, guid string) (executor.Container, error)
@@ -40,10 +45,22 @@ type ContainerStore interface {
 	Metrics(logger lager.Logger) (map[string]executor.ContainerMetrics, error)
 	RemainingResources(logger lager.Logger) executor.ExecutorResources
 	GetFiles(logger lager.Logger, guid, sourcePath string) (io.ReadCloser, error)
+	SubscribeToLogs(logger lager.Logger, guid string) (executor.LogSource, error)
+	GetRecentLogs(logger lager.Logger, guid string) ([]executor.LogLine, error)
+	PutFiles(logger lager.Logger, guid, destPath string, tarStream io.Reader, user string) error
+
+	// Exporting
+	ExportData(logger lager.Logger, guid string, dest io.Writer) error
+	ImportData(logger lager.Logger, guid string, src io.Reader) error
 
 	// Cleanup
 	NewRegistryPruner(logger lager.Logger) ifrit.Runner
 	NewContainerReaper(logger lager.Logger) ifrit.Runner
+	NewStateJournalWriter(logger lager.Logger) ifrit.Runner
+
+	// Restore rebuilds the store from the state file and reattaches to any
+	// containers that were running before the executor restarted
+	Restore(logger lager.Logger) error
 
 	// shutdown the dependency manager
 	Cleanup(logger lager.Logger)
@@ -58,7 +75,41 @@ type ContainerConfig struct {
 	ReservedExpirationTime             time.Duration
 	ReapInterval                       time.Duration
 	MaxLogLinesPerSecond               int
+	MaxLogBytesPerSecond               int64
 	LogRateLimitExceededReportInterval time.Duration
+	GracefulShutdownInterval           time.Duration
+
+	// MaxLogLinesPerSecondCeiling and MaxLogBytesPerSecondCeiling cap the log
+	// rate limits of every container, including the ones overriding the
+	// cell's limits in their LogConfig; zero does not cap them.
+	MaxLogLinesPerSecondCeiling int
+	MaxLogBytesPerSecondCeiling int64
+
+	StateFilePath     string
+	DataPath          string
+	FreezerCgroupRoot string
+	MemoryCgroupRoot  string
+
+	// OOMPollInterval is how often running containers are checked for OOM
+	// kills; zero uses DefaultOOMPollInterval and a negative interval only
+	// checks them when they stop.
+	OOMPollInterval time.Duration
+
+	// DownloadRateLimiter is shared with the download steps and bounds the
+	// number of concurrent PutFiles; nil does not limit them.
+	DownloadRateLimiter chan struct{}
+
+	// LogTap receives the log lines of every container; nil disables
+	// SubscribeToLogs.
+	LogTap log_streamer.Tap
+
+	// RecentLogs keeps the last log lines of every container until it is
+	// deleted; nil disables GetRecentLogs.
+	RecentLogs log_streamer.RecentLogs
+
+	// LogSink receives the log lines of every container; nil sends them to
+	// Loggregator through the metron client.
+	LogSink log_streamer.LogSink
 }
 
 type containerStore struct {
@@ -69,6 +120,9 @@ type containerStore struct {
 	credManager       CredManager
 	transformer       transformer.Transformer
 	containers        *nodeMap
+	stateJournal      stateJournal
+	freezer           freezer
+	memoryLimiter     memoryLimiter
 	eventEmitter      event.Hub
 	clock             clock.Clock
 	metronClient      loggingclient.IngressClient
@@ -107,6 +161,7 @@ func New(
 	cellID string,
 	enableUnproxiedPortMappings bool,
 	advertisePreferenceForInstanceAddress bool,
+	admissionPolicy AdmissionPolicy,
 ) ContainerStore {
 	return &containerStore{
 		containerConfig:               containerConfig,
@@ -114,7 +169,10 @@ func New(
 		dependencyManager:             dependencyManager,
 		volumeManager:                 volumeManager,
 		credManager:                   credManager,
-		containers:                    newNodeMap(totalCapacity),
+		containers:                    newNodeMap(totalCapacity, admissionPolicy),
+		stateJournal:                  newStateJournal(containerConfig.StateFilePath),
+		freezer:                       newFreezer(containerConfig.FreezerCgroupRoot),
+		memoryLimiter:                 newMemoryLimiter(containerConfig.MemoryCgroupRoot),
 		eventEmitter:                  eventEmitter,
 		transformer:                   transformer,
 		clock:                         clock,
@@ -141,38 +199,73 @@ func (cs *containerStore) Reserve(logger lager.Logger, req *executor.AllocationR
 	logger.Debug("starting")
 	defer logger.Debug("complete")
 
-	container := executor.NewReservedContainerFromAllocationRequest(req, cs.clock.Now().UnixNano())
-
-	err := cs.containers.Add(
-		newStoreNode(&cs.containerConfig,
-			cs.useDeclarativeHealthCheck,
-			cs.declarativeHealthcheckPath,
-			container,
-			cs.gardenClient,
-			cs.clock,
-			cs.dependencyManager,
-			cs.volumeManager,
-			cs.credManager,
-			cs.eventEmitter,
-			cs.transformer,
-			cs.trustedSystemCertificatesPath,
-			cs.metronClient,
-			cs.proxyConfigHandler,
-			cs.rootFSSizer,
-			cs.cellID,
-			cs.enableUnproxiedPortMappings,
-			cs.advertisePreferenceForInstanceAddress,
-		))
-
+	now := cs.clock.Now()
+	container := executor.NewReservedContainerFromAllocationRequest(req, now.UnixNano())
+	container.CPUShares = int(cpuShares(cs.containerConfig.MaxCPUShares, req.CPUWeight))
+
+	node := cs.newStoreNode(container)
+	err := cs.containers.Add(node)
+	if err == executor.ErrInsufficientResourcesAvailable && container.Priority > 0 {
+		victims := cs.containers.Preempt(logger, container)
+		if victims != nil {
+			cs.stopPreempted(logger, container, victims, now)
+			err = cs.containers.Add(node)
+		}
+	}
 	if err != nil {
 		logger.Error("failed-to-reserve", err)
 		return executor.Container{}, err
 	}
 
-	cs.eventEmitter.Emit(executor.NewContainerReservedEvent(container))
+	node.persist(logger)
+	node.tapLogs()
+
+	cs.eventEmitter.Emit(stampEvent(executor.NewContainerReservedEvent(container), now, ""))
 	return container, nil
 }
 
+// stopPreempted stops the containers preempted to make room for container in
+// the background. They complete as preempted and are left for their owner to
+// delete, like any other stopped container.
+func (cs *containerStore) stopPreempted(logger lager.Logger, container executor.Container, victims []*storeNode, now time.Time) {
+	for _, victim := range victims {
+		victimInfo := victim.Info()
+		logger.Info("preempting-container", lager.Data{
+			"preempted-guid":     victimInfo.Guid,
+			"preempted-priority": victimInfo.Priority,
+			"priority":           container.Priority,
+		})
+		cs.eventEmitter.Emit(stampEvent(executor.NewContainerPreemptedEvent(victimInfo), now, ""))
+
+		go victim.Stop(logger)
+	}
+}
+
+func (cs *containerStore) newStoreNode(container executor.Container) *storeNode {
+	return newStoreNode(&cs.containerConfig,
+		cs.useDeclarativeHealthCheck,
+		cs.declarativeHealthcheckPath,
+		container,
+		cs.gardenClient,
+		cs.clock,
+		cs.dependencyManager,
+		cs.volumeManager,
+		cs.credManager,
+		cs.eventEmitter,
+		cs.transformer,
+		cs.trustedSystemCertificatesPath,
+		cs.metronClient,
+		cs.proxyConfigHandler,
+		cs.rootFSSizer,
+		cs.cellID,
+		cs.enableUnproxiedPortMappings,
+		cs.advertisePreferenceForInstanceAddress,
+		cs.stateJournal,
+		cs.freezer,
+		cs.memoryLimiter,
+	)
+}
+
 func (cs *containerStore) Initialize(logger lager.Logger, req *executor.RunRequest) error {
 	logger = logger.Session("containerstore-initialize", lager.Data{"guid": req.Guid})
 	logger.Debug("starting")
@@ -251,6 +344,51 @@ func (cs *containerStore) Stop(logger lager.Logger, guid string) error {
 	return nil
 }
 
+func (cs *containerStore) UpdateLimits(logger lager.Logger, guid string, resource executor.Resource) error {
+	logger = logger.Session("containerstore-update-limits", lager.Data{"guid": guid})
+
+	logger.Info("starting")
+	defer logger.Info("complete")
+
+	node, err := cs.containers.Get(guid)
+	if err != nil {
+		logger.Error("failed-to-get-container", err)
+		return err
+	}
+
+	return node.UpdateLimits(logger, cs.containers, resource)
+}
+
+func (cs *containerStore) Pause(logger lager.Logger, guid string) error {
+	logger = logger.Session("containerstore-pause", lager.Data{"guid": guid})
+
+	logger.Info("starting")
+	defer logger.Info("complete")
+
+	node, err := cs.containers.Get(guid)
+	if err != nil {
+		logger.Error("failed-to-get-container", err)
+		return err
+	}
+
+	return node.Pause(logger)
+}
+
+func (cs *containerStore) Resume(logger lager.Logger, guid string) error {
+	logger = logger.Session("containerstore-resume", lager.Data{"guid": guid})
+
+	logger.Info("starting")
+	defer logger.Info("complete")
+
+	node, err := cs.containers.Get(guid)
+	if err != nil {
+		logger.Error("failed-to-get-container", err)
+		return err
+	}
+
+	return node.Resume(logger)
+}
+
 func (cs *containerStore) Destroy(logger lager.Logger, guid string) error {
 	logger = logger.Session("containerstore.destroy", lager.Data{"Guid": guid})
 
@@ -269,6 +407,7 @@ func (cs *containerStore) Destroy(logger lager.Logger, guid string) error {
 	}
 
 	cs.containers.Remove(guid)
+	node.forget(logger)
 
 	return err
 }
@@ -310,7 +449,7 @@ func (cs *containerStore) Metrics(logger lager.Logger) (map[string]executor.Cont
 
 	for i := range nodes {
 		nodeInfo := nodes[i].Info()
-		if nodeInfo.State == executor.StateRunning || nodeInfo.State == executor.StateCreated {
+		if nodeInfo.State == executor.StateRunning || nodeInfo.State == executor.StateCreated || nodeInfo.State == executor.StatePaused {
 			containerGuids = append(containerGuids, nodeInfo.Guid)
 			nodeInfoMap[nodeInfo.Guid] = nodeInfo
 		}
@@ -366,6 +505,185 @@ func (cs *containerStore) GetFiles(logger lager.Logger, guid, sourcePath string)
 	return node.GetFiles(logger, sourcePath)
 }
 
+func (cs *containerStore) RunProcess(logger lager.Logger, guid string, spec executor.ProcessSpec, processIO executor.ProcessIO) (executor.Process, error) {
+	logger = logger.Session("containerstore-run-process", lager.Data{"guid": guid})
+
+	logger.Info("starting")
+	defer logger.Info("complete")
+
+	node, err := cs.containers.Get(guid)
+	if err != nil {
+		logger.Error("failed-to-get-container", err)
+		return nil, err
+	}
+
+	return node.RunProcess(logger, spec, processIO)
+}
+
+// SubscribeToLogs streams the log lines of the container, starting with the
+// backlog the log tap kept.
+func (cs *containerStore) SubscribeToLogs(logger lager.Logger, guid string) (executor.LogSource, error) {
+	logger = logger.Session("containerstore-subscribe-to-logs", lager.Data{"guid": guid})
+
+	if cs.containerConfig.LogTap == nil {
+		return nil, executor.ErrLogTapNotEnabled
+	}
+
+	// the tap knows the container for as long as the store does, so the
+	// subscription cannot outlive it
+	source, err := cs.containerConfig.LogTap.Subscribe(guid)
+	if err != nil {
+		logger.Error("failed-to-subscribe", err)
+		return nil, err
+	}
+
+	return source, nil
+}
+
+func (cs *containerStore) GetRecentLogs(logger lager.Logger, guid string) ([]executor.LogLine, error) {
+	logger = logger.Session("containerstore-get-recent-logs", lager.Data{"guid": guid})
+
+	if cs.containerConfig.RecentLogs == nil {
+		return nil, executor.ErrRecentLogsNotEnabled
+	}
+
+	_, err := cs.containers.Get(guid)
+	if err != nil {
+		logger.Error("failed-to-get-container", err)
+		return nil, err
+	}
+
+	return cs.containerConfig.RecentLogs.Get(guid), nil
+}
+
+func (cs *containerStore) PutFiles(logger lager.Logger, guid, destPath string, tarStream io.Reader, user string) error {
+	logger = logger.Session("containerstore-putfiles", lager.Data{"guid": guid})
+
+	logger.Info("starting")
+	defer logger.Info("complete")
+
+	node, err := cs.containers.Get(guid)
+	if err != nil {
+		logger.Error("failed-to-get-container", err)
+		return err
+	}
+
+	return node.PutFiles(logger, destPath, tarStream, user)
+}
+
+func (cs *containerStore) ExportData(logger lager.Logger, guid string, dest io.Writer) error {
+	logger = logger.Session("containerstore-export-data", lager.Data{"guid": guid})
+
+	logger.Info("starting")
+	defer logger.Info("complete")
+
+	node, err := cs.containers.Get(guid)
+	if err != nil {
+		logger.Error("failed-to-get-container", err)
+		return err
+	}
+
+	return node.ExportData(logger, dest)
+}
+
+// ImportData recreates an exported container under the given guid. It goes
+// through the same reserve, initialize and create steps as a new container
+// and streams the exported data into its data path before running it. The
+// container is destroyed if any of them fails, so that a failed import leaves
+// nothing behind.
+func (cs *containerStore) ImportData(logger lager.Logger, guid string, src io.Reader) error {
+	logger = logger.Session("containerstore-import-data", lager.Data{"guid": guid})
+
+	logger.Info("starting")
+	defer logger.Info("complete")
+
+	header, data, err := readExportHeader(src)
+	if err != nil {
+		logger.Error("failed-to-read-export", err)
+		return err
+	}
+
+	logger.Info("importing-data", lager.Data{"exported-guid": header.Container.Guid})
+
+	allocationRequest := executor.NewAllocationRequest(guid, &header.Container.Resource, header.Container.Tags)
+	allocationRequest.CPUWeight = header.RunInfo.CPUWeight
+	_, err = cs.Reserve(logger, &allocationRequest)
+	if err != nil {
+		return err
+	}
+
+	err = cs.importReservedData(logger, guid, header, data)
+	if err != nil {
+		destroyErr := cs.Destroy(logger, guid)
+		if destroyErr != nil {
+			logger.Error("failed-to-destroy-container", destroyErr)
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (cs *containerStore) importReservedData(logger lager.Logger, guid string, header exportHeader, data io.Reader) error {
+	runRequest := executor.NewRunRequest(guid, &header.RunInfo, header.Container.Tags)
+	err := cs.Initialize(logger, &runRequest)
+	if err != nil {
+		return err
+	}
+
+	_, err = cs.Create(logger, guid)
+	if err != nil {
+		return err
+	}
+
+	node, err := cs.containers.Get(guid)
+	if err != nil {
+		logger.Error("failed-to-get-container", err)
+		return err
+	}
+
+	err = node.ImportData(logger, data)
+	if err != nil {
+		return err
+	}
+
+	return cs.Run(logger, guid)
+}
+
+func (cs *containerStore) Restore(logger lager.Logger) error {
+	logger = logger.Session("containerstore-restore")
+
+	logger.Info("starting")
+	defer logger.Info("complete")
+
+	states, err := cs.stateJournal.Load()
+	if err != nil {
+		logger.Error("failed-to-load-state", err)
+		return err
+	}
+
+	for _, state := range states {
+		node := cs.newStoreNode(state.Container)
+		node.runInfo = state.RunInfo
+		node.bindMounts = state.BindMounts
+		node.bindMountCacheKeys = state.BindMountCacheKeys
+		node.processIDs = state.ProcessIDs
+		node.preempted = state.Preempted
+
+		err := cs.containers.AddRestored(node)
+		if err != nil {
+			logger.Error("failed-to-restore-container", err, lager.Data{"guid": state.Container.Guid})
+			cs.stateJournal.Remove(state.Container.Guid)
+			continue
+		}
+
+		node.tapLogs()
+		node.Restore(logger)
+	}
+
+	return nil
+}
+
 func (cs *containerStore) NewRegistryPruner(logger lager.Logger) ifrit.Runner {
 	return newRegistryPruner(logger, &cs.containerConfig, cs.clock, cs.containers)
 }
@@ -373,3 +691,10 @@ func (cs *containerStore) NewRegistryPruner(logger lager.Logger) ifrit.Runner {
 func (cs *containerStore) NewContainerReaper(logger lager.Logger) ifrit.Runner {
 	return newContainerReaper(logger, &cs.containerConfig, cs.clock, cs.containers, cs.gardenClient)
 }
+
+// NewStateJournalWriter persists the state journal in the background. The
+// journal is written one last time when the runner is signalled, so it must
+// be stopped after every other user of the store.
+func (cs *containerStore) NewStateJournalWriter(logger lager.Logger) ifrit.Runner {
+	return cs.stateJournal.Writer(logger)
+}
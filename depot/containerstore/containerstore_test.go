@@ -27,6 +27,7 @@ import (
 	"code.cloudfoundry.org/garden/gardenfakes"
 	"code.cloudfoundry.org/garden/server"
 	loggregator "code.cloudfoundry.org/go-loggregator"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
 	"code.cloudfoundry.org/lager"
 	"code.cloudfoundry.org/volman"
 	"code.cloudfoundry.org/volman/volmanfakes"
@@ -65,6 +66,7 @@ var _ = Describe("Container Store", func() {
 		credManager       *containerstorefakes.FakeCredManager
 		proxyManager      *containerstorefakes.FakeProxyManager
 		volumeManager     *volmanfakes.FakeManager
+		admissionPolicy   *containerstorefakes.FakeAdmissionPolicy
 
 		clock            *fakeclock.FakeClock
 		eventEmitter     *eventfakes.FakeHub
@@ -102,6 +104,7 @@ var _ = Describe("Container Store", func() {
 		credManager = &containerstorefakes.FakeCredManager{}
 		proxyManager = &containerstorefakes.FakeProxyManager{}
 		volumeManager = &volmanfakes.FakeManager{}
+		admissionPolicy = &containerstorefakes.FakeAdmissionPolicy{}
 		clock = fakeclock.NewFakeClock(time.Now())
 		eventEmitter = &eventfakes.FakeHub{}
 		fakeRootFSSizer = new(configurationfakes.FakeRootFSSizer)
@@ -115,7 +118,7 @@ var _ = Describe("Container Store", func() {
 		iNodeLimit = 64
 		maxCPUShares = 100
 		ownerName = "test-owner"
-		totalCapacity = executor.NewExecutorResources(1024*10, 1024*10, 10)
+		totalCapacity = executor.NewExecutorResources(1024*10, 1024*10, 10, 1024*10, 1024*10)
 		advertisePreferenceForInstanceAddress = false
 
 		containerGuid = "container-guid"
@@ -151,6 +154,7 @@ var _ = Describe("Container Store", func() {
 			cellID,
 			true,
 			advertisePreferenceForInstanceAddress,
+			admissionPolicy,
 		)
 
 		fakeMetronClient.SendDurationStub = func(name string, value time.Duration, opts ...loggregator.EmitGaugeOption) error {
@@ -213,7 +217,8 @@ var _ = Describe("Container Store", func() {
 
 			event := eventEmitter.EmitArgsForCall(0)
 			Expect(event).To(Equal(executor.ContainerReservedEvent{
-				RawContainer: container,
+				RawContainer:  container,
+				EventMetadata: executor.EventMetadata{Timestamp: clock.Now().UnixNano()},
 			}))
 		})
 
@@ -227,6 +232,74 @@ var _ = Describe("Container Store", func() {
 			Expect(remainingCapacity.Containers).To(Equal(totalCapacity.Containers - 1))
 		})
 
+		Context("when the allocation has a cpu weight", func() {
+			BeforeEach(func() {
+				req.CPUWeight = 2
+			})
+
+			It("charges the cpu shares of its weight", func() {
+				container, err := containerStore.Reserve(logger, req)
+				Expect(err).NotTo(HaveOccurred())
+
+				expectedCPUShares := int(float64(maxCPUShares) * 2 / 100.0)
+				Expect(container.CPUShares).To(Equal(expectedCPUShares))
+				Expect(container.CPUWeight).To(Equal(uint(2)))
+				Expect(containerStore.RemainingResources(logger).CPUShares).To(Equal(totalCapacity.CPUShares - expectedCPUShares))
+			})
+
+			Context("when the cpu shares of its weight are not available", func() {
+				BeforeEach(func() {
+					req.CPUWeight = uint(totalCapacity.CPUShares + 1)
+				})
+
+				It("returns ErrInsufficientResourcesAvailable", func() {
+					_, err := containerStore.Reserve(logger, req)
+					Expect(err).To(Equal(executor.ErrInsufficientResourcesAvailable))
+					Expect(containerStore.RemainingResources(logger)).To(Equal(totalCapacity))
+				})
+			})
+
+			Context("when the cell does not limit cpu shares", func() {
+				BeforeEach(func() {
+					totalCapacity.CPUShares = 0
+					containerStore = containerstore.New(
+						containerConfig,
+						&totalCapacity,
+						gardenClient,
+						dependencyManager,
+						volumeManager,
+						credManager,
+						clock,
+						eventEmitter,
+						megatron,
+						"/var/vcap/data/cf-system-trusted-certs",
+						fakeMetronClient,
+						fakeRootFSSizer,
+						false,
+						"/var/vcap/packages/healthcheck",
+						proxyManager,
+						cellID,
+						true,
+						advertisePreferenceForInstanceAddress,
+						admissionPolicy,
+					)
+				})
+
+				It("reserves the container without charging its cpu shares", func() {
+					container, err := containerStore.Reserve(logger, req)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(container.CPUShares).To(Equal(int(float64(maxCPUShares) * 2 / 100.0)))
+
+					remainingCapacity := containerStore.RemainingResources(logger)
+					Expect(remainingCapacity.CPUShares).To(Equal(0))
+					Expect(remainingCapacity.Containers).To(Equal(totalCapacity.Containers - 1))
+
+					Expect(containerStore.Destroy(logger, req.Guid)).To(Succeed())
+					Expect(containerStore.RemainingResources(logger)).To(Equal(totalCapacity))
+				})
+			})
+		})
+
 		Context("when the container guid is already reserved", func() {
 			BeforeEach(func() {
 				_, err := containerStore.Reserve(logger, req)
@@ -249,6 +322,207 @@ var _ = Describe("Container Store", func() {
 				Expect(err).To(Equal(executor.ErrInsufficientResourcesAvailable))
 			})
 		})
+
+		Context("when a higher priority request does not fit", func() {
+			reserve := func(guid string, memoryMB, priority int) {
+				_, err := containerStore.Reserve(logger, &executor.AllocationRequest{
+					Guid:     guid,
+					Resource: executor.Resource{MemoryMB: memoryMB, DiskMB: 1024, Priority: priority},
+				})
+				Expect(err).NotTo(HaveOccurred())
+				clock.Increment(time.Second)
+			}
+
+			BeforeEach(func() {
+				reserve("lowest", 4096, 0)
+				reserve("low-old", 2048, 1)
+				reserve("low-new", 2048, 1)
+				reserve("high", 2048, 3)
+
+				req.Resource = executor.Resource{MemoryMB: 6144, DiskMB: 1024, Priority: 2}
+			})
+
+			It("preempts the lowest priority containers, newest first", func() {
+				_, err := containerStore.Reserve(logger, req)
+				Expect(err).NotTo(HaveOccurred())
+
+				for _, guid := range []string{"low-old", "high"} {
+					container, err := containerStore.Get(logger, guid)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(container.State).To(Equal(executor.StateReserved))
+				}
+			})
+
+			It("stops the preempted containers and leaves them for their owner to delete", func() {
+				_, err := containerStore.Reserve(logger, req)
+				Expect(err).NotTo(HaveOccurred())
+
+				for _, guid := range []string{"lowest", "low-new"} {
+					Eventually(containerState(guid)).Should(Equal(executor.StateCompleted))
+				}
+				Consistently(gardenClient.DestroyCallCount).Should(Equal(0))
+			})
+
+			It("completes the preempted containers as retryable failures", func() {
+				_, err := containerStore.Reserve(logger, req)
+				Expect(err).NotTo(HaveOccurred())
+
+				completed := func() map[string]executor.ContainerRunResult {
+					results := map[string]executor.ContainerRunResult{}
+					for i := 0; i < eventEmitter.EmitCallCount(); i++ {
+						if event, ok := eventEmitter.EmitArgsForCall(i).(executor.ContainerCompleteEvent); ok {
+							results[event.Container().Guid] = event.Container().RunResult
+						}
+					}
+					return results
+				}
+
+				preemptedResult := executor.ContainerRunResult{
+					Failed:        true,
+					FailureCode:   executor.FailureCodePreempted,
+					FailureReason: containerstore.ContainerPreemptedMessage,
+					Retryable:     true,
+					Stopped:       true,
+				}
+				Eventually(completed).Should(Equal(map[string]executor.ContainerRunResult{
+					"lowest":  preemptedResult,
+					"low-new": preemptedResult,
+				}))
+			})
+
+			It("emits a preempted event for each preempted container", func() {
+				_, err := containerStore.Reserve(logger, req)
+				Expect(err).NotTo(HaveOccurred())
+
+				preemptedGuids := []string{}
+				for i := 0; i < eventEmitter.EmitCallCount(); i++ {
+					if event, ok := eventEmitter.EmitArgsForCall(i).(executor.ContainerPreemptedEvent); ok {
+						preemptedGuids = append(preemptedGuids, event.Container().Guid)
+					}
+				}
+				Expect(preemptedGuids).To(ConsistOf("lowest", "low-new"))
+			})
+
+			It("hands the preempted resources over once", func() {
+				_, err := containerStore.Reserve(logger, req)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(containerStore.RemainingResources(logger).MemoryMB).To(Equal(0))
+
+				for _, guid := range []string{"lowest", "low-new"} {
+					Eventually(containerState(guid)).Should(Equal(executor.StateCompleted))
+					Expect(containerStore.Destroy(logger, guid)).To(Succeed())
+				}
+				Expect(containerStore.RemainingResources(logger).MemoryMB).To(Equal(0))
+			})
+
+			Context("when stopping a preempted container is slow", func() {
+				var exiting chan struct{}
+
+				BeforeEach(func() {
+					exiting = make(chan struct{})
+					gardenClient.CreateReturns(gardenContainer, nil)
+					megatron.StepsRunnerReturns(ifrit.RunFunc(func(signals <-chan os.Signal, ready chan<- struct{}) error {
+						close(ready)
+						<-signals
+						<-exiting
+						return nil
+					}), nil)
+
+					err := containerStore.Initialize(logger, &executor.RunRequest{Guid: "lowest"})
+					Expect(err).NotTo(HaveOccurred())
+					_, err = containerStore.Create(logger, "lowest")
+					Expect(err).NotTo(HaveOccurred())
+					Expect(containerStore.Run(logger, "lowest")).To(Succeed())
+					Eventually(containerState("lowest")).Should(Equal(executor.StateRunning))
+				})
+
+				It("reserves without waiting for it to stop", func() {
+					_, err := containerStore.Reserve(logger, req)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(containerStore.RemainingResources(logger).MemoryMB).To(Equal(0))
+
+					Consistently(containerState("lowest")).Should(Equal(executor.StateRunning))
+
+					close(exiting)
+					Eventually(containerState("lowest")).Should(Equal(executor.StateCompleted))
+				})
+			})
+
+			Context("when preempting every lower priority container is not enough", func() {
+				BeforeEach(func() {
+					req.Resource.MemoryMB = 8192 + 1
+				})
+
+				It("fails without preempting anything", func() {
+					_, err := containerStore.Reserve(logger, req)
+					Expect(err).To(Equal(executor.ErrInsufficientResourcesAvailable))
+
+					Consistently(func() []executor.State {
+						states := []executor.State{}
+						for _, guid := range []string{"lowest", "low-old", "low-new", "high"} {
+							container, err := containerStore.Get(logger, guid)
+							Expect(err).NotTo(HaveOccurred())
+							states = append(states, container.State)
+						}
+						return states
+					}).Should(Equal([]executor.State{
+						executor.StateReserved,
+						executor.StateReserved,
+						executor.StateReserved,
+						executor.StateReserved,
+					}))
+				})
+			})
+
+			Context("when the request has no priority", func() {
+				BeforeEach(func() {
+					req.Resource.Priority = 0
+				})
+
+				It("fails without preempting anything", func() {
+					_, err := containerStore.Reserve(logger, req)
+					Expect(err).To(Equal(executor.ErrInsufficientResourcesAvailable))
+					Expect(containerStore.RemainingResources(logger).MemoryMB).To(Equal(0))
+				})
+			})
+		})
+
+		It("consults the admission policy with the containers already in the store", func() {
+			existingReq := &executor.AllocationRequest{
+				Guid: "existing-guid",
+				Tags: executor.Tags{"Foo": "baz"},
+			}
+			existing, err := containerStore.Reserve(logger, existingReq)
+			Expect(err).NotTo(HaveOccurred())
+
+			container, err := containerStore.Reserve(logger, req)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(admissionPolicy.AdmitCallCount()).To(Equal(2))
+			candidate, held := admissionPolicy.AdmitArgsForCall(1)
+			Expect(candidate).To(Equal(container))
+			Expect(held).To(ConsistOf(existing))
+		})
+
+		Context("when the admission policy rejects the container", func() {
+			BeforeEach(func() {
+				admissionPolicy.AdmitReturns(executor.ErrTagQuotaExceeded)
+			})
+
+			It("returns the rejection", func() {
+				_, err := containerStore.Reserve(logger, req)
+				Expect(err).To(Equal(executor.ErrTagQuotaExceeded))
+			})
+
+			It("does not track the container or consume resources", func() {
+				_, err := containerStore.Reserve(logger, req)
+				Expect(err).To(HaveOccurred())
+
+				_, err = containerStore.Get(logger, containerGuid)
+				Expect(err).To(Equal(executor.ErrContainerNotFound))
+				Expect(containerStore.RemainingResources(logger)).To(Equal(totalCapacity))
+			})
+		})
 	})
 
 	Describe("Initialize", func() {
@@ -286,8 +560,9 @@ var _ = Describe("Container Store", func() {
 		Context("when the conatiner is reserved", func() {
 			BeforeEach(func() {
 				allocationReq := &executor.AllocationRequest{
-					Guid: containerGuid,
-					Tags: executor.Tags{},
+					Guid:      containerGuid,
+					Tags:      executor.Tags{},
+					CPUWeight: 2,
 				}
 
 				_, err := containerStore.Reserve(logger, allocationReq)
@@ -304,6 +579,92 @@ var _ = Describe("Container Store", func() {
 				Expect(container.RunInfo).To(Equal(runInfo))
 				Expect(container.Tags).To(Equal(runTags))
 			})
+
+			It("emits an initializing event", func() {
+				err := containerStore.Initialize(logger, req)
+				Expect(err).NotTo(HaveOccurred())
+
+				container, err := containerStore.Get(logger, req.Guid)
+				Expect(err).NotTo(HaveOccurred())
+
+				Eventually(eventEmitter.EmitCallCount).Should(Equal(2))
+				Expect(eventEmitter.EmitArgsForCall(1)).To(Equal(executor.ContainerInitializingEvent{
+					RawContainer: container,
+					EventMetadata: executor.EventMetadata{
+						Timestamp:     clock.Now().UnixNano(),
+						PreviousState: executor.StateReserved,
+					},
+				}))
+			})
+
+			It("keeps the cpu shares reserved for its weight", func() {
+				expectedCPUShares := int(float64(maxCPUShares) * float64(runInfo.CPUWeight) / 100.0)
+				Expect(containerStore.RemainingResources(logger).CPUShares).To(Equal(totalCapacity.CPUShares - expectedCPUShares))
+
+				err := containerStore.Initialize(logger, req)
+				Expect(err).NotTo(HaveOccurred())
+
+				container, err := containerStore.Get(logger, req.Guid)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(container.CPUShares).To(Equal(expectedCPUShares))
+				Expect(containerStore.RemainingResources(logger).CPUShares).To(Equal(totalCapacity.CPUShares - expectedCPUShares))
+			})
+
+			Context("when the run does not set a cpu weight", func() {
+				BeforeEach(func() {
+					req.RunInfo.CPUWeight = 0
+				})
+
+				It("runs with the weight it was reserved with", func() {
+					err := containerStore.Initialize(logger, req)
+					Expect(err).NotTo(HaveOccurred())
+
+					container, err := containerStore.Get(logger, req.Guid)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(container.CPUWeight).To(Equal(uint(2)))
+				})
+			})
+
+			Context("when the run asks for a larger cpu weight than it was reserved with", func() {
+				BeforeEach(func() {
+					req.RunInfo.CPUWeight = 3
+				})
+
+				It("returns ErrCPUWeightExceedsReservation and leaves the container reserved", func() {
+					err := containerStore.Initialize(logger, req)
+					Expect(err).To(Equal(executor.ErrCPUWeightExceedsReservation))
+
+					container, err := containerStore.Get(logger, req.Guid)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(container.State).To(Equal(executor.StateReserved))
+				})
+			})
+
+			Context("when the multiline start pattern of the log config does not compile", func() {
+				BeforeEach(func() {
+					req.RunInfo.LogConfig.Multiline = &executor.MultilineConfig{StartPattern: "(unclosed"}
+				})
+
+				It("returns ErrLogConfigInvalid and leaves the container reserved", func() {
+					err := containerStore.Initialize(logger, req)
+					Expect(err).To(Equal(executor.ErrLogConfigInvalid))
+
+					container, err := containerStore.Get(logger, req.Guid)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(container.State).To(Equal(executor.StateReserved))
+				})
+			})
+
+			Context("when a redaction pattern of the log config does not compile", func() {
+				BeforeEach(func() {
+					req.RunInfo.LogConfig.Redaction = &executor.LogRedactionConfig{Patterns: []string{"[a-"}}
+				})
+
+				It("returns ErrLogConfigInvalid", func() {
+					err := containerStore.Initialize(logger, req)
+					Expect(err).To(Equal(executor.ErrLogConfigInvalid))
+				})
+			})
 		})
 
 		Context("when the container exists but is not reserved", func() {
@@ -346,7 +707,8 @@ var _ = Describe("Container Store", func() {
 				Tags: executor.Tags{
 					"Foo": "Bar",
 				},
-				Resource: resource,
+				Resource:  resource,
+				CPUWeight: 50,
 			}
 		})
 
@@ -422,6 +784,20 @@ var _ = Describe("Container Store", func() {
 				Expect(container.State).To(Equal(executor.StateCreated))
 			})
 
+			It("emits a created event", func() {
+				container, err := containerStore.Create(logger, containerGuid)
+				Expect(err).NotTo(HaveOccurred())
+
+				Eventually(eventEmitter.EmitCallCount).Should(Equal(3))
+				Expect(eventEmitter.EmitArgsForCall(2)).To(Equal(executor.ContainerCreatedEvent{
+					RawContainer: container,
+					EventMetadata: executor.EventMetadata{
+						Timestamp:     clock.Now().UnixNano(),
+						PreviousState: executor.StateInitializing,
+					},
+				}))
+			})
+
 			It("creates the container in garden with correct image parameters", func() {
 				_, err := containerStore.Create(logger, containerGuid)
 				Expect(err).NotTo(HaveOccurred())
@@ -474,6 +850,7 @@ var _ = Describe("Container Store", func() {
 						cellID,
 						true,
 						advertisePreferenceForInstanceAddress,
+						containerstore.NewCompositeAdmissionPolicy(),
 					)
 				})
 
@@ -692,6 +1069,7 @@ var _ = Describe("Container Store", func() {
 						cellID,
 						true,
 						advertisePreferenceForInstanceAddress,
+						containerstore.NewCompositeAdmissionPolicy(),
 					)
 				})
 
@@ -751,6 +1129,7 @@ var _ = Describe("Container Store", func() {
 						Expect(container.State).To(Equal(executor.StateCompleted))
 						Expect(container.RunResult.Failed).To(BeTrue())
 						Expect(container.RunResult.FailureReason).To(Equal(containerstore.CredDirFailed))
+						Expect(container.RunResult.FailureCode).To(Equal(executor.FailureCodeSetupFailed))
 						Expect(container.RunResult.Retryable).To(BeTrue())
 					})
 				})
@@ -840,6 +1219,7 @@ var _ = Describe("Container Store", func() {
 							Expect(container.State).To(Equal(executor.StateCompleted))
 							Expect(container.RunResult.Failed).To(BeTrue())
 							Expect(container.RunResult.FailureReason).To(Equal(containerstore.VolmanMountFailed))
+							Expect(container.RunResult.FailureCode).To(Equal(executor.FailureCodeVolumeMountFailed))
 							Expect(container.RunResult.Retryable).To(BeTrue())
 						})
 					})
@@ -919,6 +1299,7 @@ var _ = Describe("Container Store", func() {
 					Expect(container.State).To(Equal(executor.StateCompleted))
 					Expect(container.RunResult.Failed).To(BeTrue())
 					Expect(container.RunResult.FailureReason).To(Equal(containerstore.DownloadCachedDependenciesFailed))
+					Expect(container.RunResult.FailureCode).To(Equal(executor.FailureCodeDownloadFailed))
 					Expect(container.RunResult.Retryable).To(BeTrue())
 				})
 			})
@@ -1174,6 +1555,7 @@ var _ = Describe("Container Store", func() {
 						cellID,
 						true,
 						advertisePreferenceForInstanceAddress,
+						containerstore.NewCompositeAdmissionPolicy(),
 					)
 
 					portMapping := []executor.PortMapping{
@@ -1257,6 +1639,7 @@ var _ = Describe("Container Store", func() {
 							cellID,
 							false,
 							advertisePreferenceForInstanceAddress,
+							containerstore.NewCompositeAdmissionPolicy(),
 						)
 					})
 
@@ -1366,6 +1749,7 @@ var _ = Describe("Container Store", func() {
 					Expect(container.RunResult.Failed).To(BeTrue())
 					Expect(container.RunResult.FailureReason).To(ContainSubstring(containerstore.ContainerCreationFailedMessage))
 					Expect(container.RunResult.FailureReason).To(ContainSubstring("boom!"))
+					Expect(container.RunResult.FailureCode).To(Equal(executor.FailureCodeContainerCreationFailed))
 					Expect(container.RunResult.Retryable).To(BeTrue())
 				})
 
@@ -1596,6 +1980,7 @@ var _ = Describe("Container Store", func() {
 					// make sure the error message is at the end so that
 					// FailureReasonSanitizer can properly map the error messages
 					Expect(container.RunResult.FailureReason).To(MatchRegexp("cred-manager-runner exited: BOOOM$"))
+					Expect(container.RunResult.FailureCode).To(Equal(executor.FailureCodeSetupFailed))
 					Expect(container.RunResult.Retryable).To(BeFalse())
 				})
 
@@ -1616,7 +2001,7 @@ var _ = Describe("Container Store", func() {
 							events = append(events, string(event.EventType()))
 						}
 						return events
-					}).Should(ConsistOf("container_reserved", "container_complete"))
+					}).Should(ConsistOf("container_reserved", "container_initializing", "container_created", "container_complete"))
 				})
 			})
 
@@ -1721,9 +2106,35 @@ var _ = Describe("Container Store", func() {
 						container, err = containerStore.Get(logger, containerGuid)
 						Expect(err).NotTo(HaveOccurred())
 
-						Eventually(eventEmitter.EmitCallCount).Should(Equal(2))
-						event := eventEmitter.EmitArgsForCall(1)
-						Expect(event).To(Equal(executor.ContainerRunningEvent{RawContainer: container}))
+						Eventually(eventEmitter.EmitCallCount).Should(Equal(4))
+						event := eventEmitter.EmitArgsForCall(3)
+						Expect(event).To(Equal(executor.ContainerRunningEvent{
+							RawContainer: container,
+							EventMetadata: executor.EventMetadata{
+								Timestamp:     clock.Now().UnixNano(),
+								PreviousState: executor.StateCreated,
+							},
+						}))
+					})
+
+					It("emits healthy and unhealthy events as the health checks report them", func() {
+						err := containerStore.Run(logger, containerGuid)
+						Expect(err).NotTo(HaveOccurred())
+						Eventually(readyChan).Should(Receive())
+
+						_, _, _, _, cfg := megatron.StepsRunnerArgsForCall(0)
+						Expect(cfg.HealthObserver).NotTo(BeNil())
+
+						cfg.HealthObserver(true)
+						cfg.HealthObserver(false)
+
+						Eventually(func() []executor.EventType {
+							types := []executor.EventType{}
+							for i := 0; i < eventEmitter.EmitCallCount(); i++ {
+								types = append(types, eventEmitter.EmitArgsForCall(i).EventType())
+							}
+							return types
+						}).Should(ContainElements(executor.EventTypeContainerHealthy, executor.EventTypeContainerUnhealthy))
 					})
 				})
 
@@ -1810,7 +2221,7 @@ var _ = Describe("Container Store", func() {
 							close(completeChan)
 							Eventually(containerState(containerGuid)).Should(Equal(executor.StateCompleted))
 
-							Expect(eventEmitter.EmitCallCount()).To(Equal(3))
+							Expect(eventEmitter.EmitCallCount()).To(Equal(5))
 
 							container, err := containerStore.Get(logger, containerGuid)
 							Expect(err).NotTo(HaveOccurred())
@@ -1821,6 +2232,10 @@ var _ = Describe("Container Store", func() {
 							}
 							Expect(emittedEvents).To(ContainElement(executor.ContainerCompleteEvent{
 								RawContainer: container,
+								EventMetadata: executor.EventMetadata{
+									Timestamp:     clock.Now().UnixNano(),
+									PreviousState: executor.StateRunning,
+								},
 							}))
 						})
 
@@ -1875,10 +2290,33 @@ var _ = Describe("Container Store", func() {
 							// make sure the error message is at the end so that
 							// FailureReasonSanitizer can properly map the error messages
 							Expect(container.RunResult.FailureReason).To(MatchRegexp("BOOOOM!!!!$"))
+							Expect(container.RunResult.FailureCode).To(Equal(executor.FailureCodeUnknown))
 							Expect(container.RunResult.Stopped).To(Equal(false))
 							Expect(container.RunResult.Retryable).To(BeFalse())
 						})
 
+						Context("when the error carries a failure code", func() {
+							BeforeEach(func() {
+								var testRunner ifrit.RunFunc = func(signals <-chan os.Signal, ready chan<- struct{}) error {
+									close(ready)
+									return steps.NewEmittableErrorWithCode(executor.FailureCodeOOM, nil, "Exited with status 137 (out of memory)")
+								}
+								megatron.StepsRunnerReturns(testRunner, nil)
+							})
+
+							It("sets the failure code alongside the failure reason", func() {
+								err := containerStore.Run(logger, containerGuid)
+								Expect(err).NotTo(HaveOccurred())
+
+								Eventually(containerState(containerGuid)).Should(Equal(executor.StateCompleted))
+
+								container, err := containerStore.Get(logger, containerGuid)
+								Expect(err).NotTo(HaveOccurred())
+								Expect(container.RunResult.FailureCode).To(Equal(executor.FailureCodeOOM))
+								Expect(container.RunResult.FailureReason).To(Equal("Exited with status 137 (out of memory)"))
+							})
+						})
+
 						It("increments the ContainerCompletedCount metric", func() {
 							err := containerStore.Run(logger, containerGuid)
 							Expect(err).NotTo(HaveOccurred())
@@ -1889,42 +2327,202 @@ var _ = Describe("Container Store", func() {
 							Expect(fakeMetronClient.IncrementCounterArgsForCall(0)).To(Equal(containerstore.ContainerCompletedCount))
 						})
 
-						Context("when run fails with ErrExceededGracefulShutdownInterval", func() {
+						Context("when a process in the container was OOM killed", func() {
 							BeforeEach(func() {
-								var testRunner ifrit.RunFunc = func(signals <-chan os.Signal, ready chan<- struct{}) error {
-									close(ready)
-									return new(steps.ExceededGracefulShutdownIntervalError)
-								}
-								megatron.StepsRunnerReturns(testRunner, nil)
+								gardenContainer.InfoReturns(garden.ContainerInfo{Events: []string{"happy land", "Out of memory"}}, nil)
 							})
 
-							It("increments the ContainerExitedOnTimeoutCount and ContainerCompletedCount metric", func() {
+							It("records the OOM kill on the container and reports it as the cause", func() {
 								err := containerStore.Run(logger, containerGuid)
 								Expect(err).NotTo(HaveOccurred())
 
 								Eventually(containerState(containerGuid)).Should(Equal(executor.StateCompleted))
-								Expect(fakeMetronClient.IncrementCounterCallCount()).To(Equal(2))
 
-								Expect(fakeMetronClient.IncrementCounterArgsForCall(0)).To(Equal(containerstore.ContainerExitedOnTimeoutCount))
-								Expect(fakeMetronClient.IncrementCounterArgsForCall(1)).To(Equal(containerstore.ContainerCompletedCount))
+								container, err := containerStore.Get(logger, containerGuid)
+								Expect(err).NotTo(HaveOccurred())
+								Expect(container.OOMKillCount).To(Equal(1))
+								Expect(container.LastOOMKillAt).To(Equal(clock.Now().UnixNano()))
+								Expect(container.RunResult.FailureCode).To(Equal(executor.FailureCodeOOM))
+								Expect(container.RunResult.FailureReason).To(MatchRegexp("BOOOOM!!!!$"))
 							})
-						})
 
-						Context("when run fails with ErrExitTimeout", func() {
-							BeforeEach(func() {
-								var testRunner ifrit.RunFunc = func(signals <-chan os.Signal, ready chan<- struct{}) error {
-									close(ready)
-									return new(steps.ExitTimeoutError)
-								}
-								megatron.StepsRunnerReturns(testRunner, nil)
+							It("emits a container OOM event", func() {
+								err := containerStore.Run(logger, containerGuid)
+								Expect(err).NotTo(HaveOccurred())
+
+								Eventually(func() []executor.EventType {
+									types := []executor.EventType{}
+									for i := 0; i < eventEmitter.EmitCallCount(); i++ {
+										types = append(types, eventEmitter.EmitArgsForCall(i).EventType())
+									}
+									return types
+								}).Should(ContainElement(executor.EventTypeContainerOOM))
 							})
 
-							It("increments the ContainerExitedOnTimeoutCount and ContainerCompletedCount metric", func() {
+							It("counts the kills with a single counter envelope", func() {
+								gardenContainer.InfoReturns(garden.ContainerInfo{Events: []string{"Out of memory", "happy land", "out of memory"}}, nil)
+
 								err := containerStore.Run(logger, containerGuid)
 								Expect(err).NotTo(HaveOccurred())
 
 								Eventually(containerState(containerGuid)).Should(Equal(executor.StateCompleted))
-								Expect(fakeMetronClient.IncrementCounterCallCount()).To(Equal(2))
+
+								Expect(fakeMetronClient.IncrementCounterWithDeltaCallCount()).To(Equal(1))
+								name, delta := fakeMetronClient.IncrementCounterWithDeltaArgsForCall(0)
+								Expect(name).To(Equal(containerstore.OOMKilledMetric))
+								Expect(delta).To(BeEquivalentTo(2))
+							})
+
+							Context("when the metron client sends tagged counters", func() {
+								var taggedClient *taggedCounterClient
+
+								BeforeEach(func() {
+									runReq.MetricsConfig = executor.MetricsConfig{Guid: "metric-guid", Tags: map[string]string{"app": "the-app"}}
+									gardenContainer.InfoReturns(garden.ContainerInfo{Events: []string{"Out of memory", "out of memory"}}, nil)
+
+									taggedClient = &taggedCounterClient{FakeIngressClient: fakeMetronClient}
+									containerStore = containerstore.New(
+										containerConfig,
+										&totalCapacity,
+										gardenClient,
+										dependencyManager,
+										volumeManager,
+										credManager,
+										clock,
+										eventEmitter,
+										megatron,
+										"/var/vcap/data/cf-system-trusted-certs",
+										taggedClient,
+										fakeRootFSSizer,
+										false,
+										"/var/vcap/packages/healthcheck",
+										proxyManager,
+										cellID,
+										true,
+										advertisePreferenceForInstanceAddress,
+										containerstore.NewCompositeAdmissionPolicy(),
+									)
+								})
+
+								It("tags the counter with the container's metrics tags", func() {
+									err := containerStore.Run(logger, containerGuid)
+									Expect(err).NotTo(HaveOccurred())
+
+									Eventually(containerState(containerGuid)).Should(Equal(executor.StateCompleted))
+
+									counters := taggedClient.Counters()
+									Expect(counters).To(HaveLen(1))
+									Expect(counters[0].GetCounter().GetName()).To(Equal(containerstore.OOMKilledMetric))
+									Expect(counters[0].GetCounter().GetDelta()).To(BeEquivalentTo(2))
+									Expect(counters[0].GetTags()).To(HaveKeyWithValue("app", "the-app"))
+									Expect(fakeMetronClient.IncrementCounterWithDeltaCallCount()).To(Equal(0))
+								})
+							})
+
+							Context("while the container is still running", func() {
+								BeforeEach(func() {
+									containerConfig.OOMPollInterval = time.Second
+									containerStore = containerstore.New(
+										containerConfig,
+										&totalCapacity,
+										gardenClient,
+										dependencyManager,
+										volumeManager,
+										credManager,
+										clock,
+										eventEmitter,
+										megatron,
+										"/var/vcap/data/cf-system-trusted-certs",
+										fakeMetronClient,
+										fakeRootFSSizer,
+										false,
+										"/var/vcap/packages/healthcheck",
+										proxyManager,
+										cellID,
+										true,
+										advertisePreferenceForInstanceAddress,
+										containerstore.NewCompositeAdmissionPolicy(),
+									)
+
+									var testRunner ifrit.RunFunc = func(signals <-chan os.Signal, ready chan<- struct{}) error {
+										close(ready)
+										<-signals
+										return nil
+									}
+									megatron.StepsRunnerReturns(testRunner, nil)
+								})
+
+								AfterEach(func() {
+									containerStore.Destroy(logger, containerGuid)
+								})
+
+								It("records the OOM kill without waiting for the container to stop", func() {
+									err := containerStore.Run(logger, containerGuid)
+									Expect(err).NotTo(HaveOccurred())
+
+									Eventually(containerState(containerGuid)).Should(Equal(executor.StateRunning))
+									clock.WaitForWatcherAndIncrement(time.Second)
+
+									Eventually(func() int {
+										container, err := containerStore.Get(logger, containerGuid)
+										Expect(err).NotTo(HaveOccurred())
+										return container.OOMKillCount
+									}).Should(Equal(1))
+									Expect(containerState(containerGuid)()).To(Equal(executor.StateRunning))
+								})
+
+								It("does not record the same OOM kill twice", func() {
+									err := containerStore.Run(logger, containerGuid)
+									Expect(err).NotTo(HaveOccurred())
+
+									Eventually(containerState(containerGuid)).Should(Equal(executor.StateRunning))
+									clock.WaitForWatcherAndIncrement(time.Second)
+									Eventually(eventEmitter.EmitCallCount).Should(Equal(5))
+
+									infoCalls := gardenContainer.InfoCallCount()
+									clock.WaitForWatcherAndIncrement(time.Second)
+									Eventually(gardenContainer.InfoCallCount).Should(BeNumerically(">", infoCalls))
+									Consistently(eventEmitter.EmitCallCount).Should(Equal(5))
+								})
+							})
+						})
+
+						Context("when run fails with ErrExceededGracefulShutdownInterval", func() {
+							BeforeEach(func() {
+								var testRunner ifrit.RunFunc = func(signals <-chan os.Signal, ready chan<- struct{}) error {
+									close(ready)
+									return new(steps.ExceededGracefulShutdownIntervalError)
+								}
+								megatron.StepsRunnerReturns(testRunner, nil)
+							})
+
+							It("increments the ContainerExitedOnTimeoutCount and ContainerCompletedCount metric", func() {
+								err := containerStore.Run(logger, containerGuid)
+								Expect(err).NotTo(HaveOccurred())
+
+								Eventually(containerState(containerGuid)).Should(Equal(executor.StateCompleted))
+								Expect(fakeMetronClient.IncrementCounterCallCount()).To(Equal(2))
+
+								Expect(fakeMetronClient.IncrementCounterArgsForCall(0)).To(Equal(containerstore.ContainerExitedOnTimeoutCount))
+								Expect(fakeMetronClient.IncrementCounterArgsForCall(1)).To(Equal(containerstore.ContainerCompletedCount))
+							})
+						})
+
+						Context("when run fails with ErrExitTimeout", func() {
+							BeforeEach(func() {
+								var testRunner ifrit.RunFunc = func(signals <-chan os.Signal, ready chan<- struct{}) error {
+									close(ready)
+									return new(steps.ExitTimeoutError)
+								}
+								megatron.StepsRunnerReturns(testRunner, nil)
+							})
+
+							It("increments the ContainerExitedOnTimeoutCount and ContainerCompletedCount metric", func() {
+								err := containerStore.Run(logger, containerGuid)
+								Expect(err).NotTo(HaveOccurred())
+
+								Eventually(containerState(containerGuid)).Should(Equal(executor.StateCompleted))
+								Expect(fakeMetronClient.IncrementCounterCallCount()).To(Equal(2))
 
 								Expect(fakeMetronClient.IncrementCounterArgsForCall(0)).To(Equal(containerstore.ContainerExitedOnTimeoutCount))
 								Expect(fakeMetronClient.IncrementCounterArgsForCall(1)).To(Equal(containerstore.ContainerCompletedCount))
@@ -2146,6 +2744,30 @@ var _ = Describe("Container Store", func() {
 				Expect(container.RunResult.Retryable).To(BeFalse())
 			})
 
+			It("emits a stopped event once", func() {
+				err := containerStore.Stop(logger, containerGuid)
+				Expect(err).NotTo(HaveOccurred())
+				err = containerStore.Stop(logger, containerGuid)
+				Expect(err).NotTo(HaveOccurred())
+
+				stoppedEvents := func() []executor.Event {
+					events := []executor.Event{}
+					for i := 0; i < eventEmitter.EmitCallCount(); i++ {
+						if ev, ok := eventEmitter.EmitArgsForCall(i).(executor.ContainerStoppedEvent); ok {
+							events = append(events, ev)
+						}
+					}
+					return events
+				}
+				Eventually(stoppedEvents).Should(HaveLen(1))
+				Consistently(stoppedEvents).Should(HaveLen(1))
+
+				ev := stoppedEvents()[0].(executor.ContainerStoppedEvent)
+				Expect(ev.Container().Guid).To(Equal(containerGuid))
+				Expect(ev.Container().RunResult.Stopped).To(BeTrue())
+				Expect(ev.Metadata().Timestamp).To(Equal(clock.Now().UnixNano()))
+			})
+
 			It("logs that the container is stopping", func() {
 				err := containerStore.Stop(logger, containerGuid)
 				Expect(err).NotTo(HaveOccurred())
@@ -2180,552 +2802,1087 @@ var _ = Describe("Container Store", func() {
 		})
 	})
 
-	Describe("Destroy", func() {
-		var resource executor.Resource
-		var expectedMounts containerstore.BindMounts
-		var runReq *executor.RunRequest
+	Describe("UpdateLimits", func() {
+		var (
+			cgroupRoot string
+			resource   executor.Resource
+		)
+
+		readCgroupFile := func(name string) string {
+			data, err := ioutil.ReadFile(filepath.Join(cgroupRoot, containerGuid, name))
+			Expect(err).NotTo(HaveOccurred())
+			return string(data)
+		}
 
 		BeforeEach(func() {
-			runInfo := executor.RunInfo{
-				LogConfig: executor.LogConfig{
-					Guid:       containerGuid,
-					Index:      1,
-					SourceName: "test-source",
-				},
-			}
-			runReq = &executor.RunRequest{Guid: containerGuid, RunInfo: runInfo}
+			var err error
+			cgroupRoot, err = ioutil.TempDir("", "memory-cgroup")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(os.Mkdir(filepath.Join(cgroupRoot, containerGuid), 0755)).To(Succeed())
+
+			containerConfig.MemoryCgroupRoot = cgroupRoot
 			gardenClient.CreateReturns(gardenContainer, nil)
-			resource = executor.NewResource(1024, 2048, 1024)
-			expectedMounts = containerstore.BindMounts{
-				CacheKeys: []containerstore.BindMountCacheKey{
-					{CacheKey: "cache-key", Dir: "foo"},
-				},
-			}
-			dependencyManager.DownloadCachedDependenciesReturns(expectedMounts, nil)
+
+			resource = executor.Resource{MemoryMB: 1024, DiskMB: 1024, MaxPids: 100}
 		})
 
 		JustBeforeEach(func() {
-			_, err := containerStore.Reserve(logger, &executor.AllocationRequest{Guid: containerGuid, Resource: resource})
-			Expect(err).NotTo(HaveOccurred())
-
-			err = containerStore.Initialize(logger, runReq)
-			Expect(err).NotTo(HaveOccurred())
+			containerStore = containerstore.New(
+				containerConfig,
+				&totalCapacity,
+				gardenClient,
+				dependencyManager,
+				volumeManager,
+				credManager,
+				clock,
+				eventEmitter,
+				megatron,
+				"/var/vcap/data/cf-system-trusted-certs",
+				fakeMetronClient,
+				fakeRootFSSizer,
+				false,
+				"/var/vcap/packages/healthcheck",
+				proxyManager,
+				cellID,
+				true,
+				advertisePreferenceForInstanceAddress,
+				admissionPolicy,
+			)
 
-			_, err = containerStore.Create(logger, containerGuid)
+			req := executor.NewAllocationRequest(containerGuid, &resource, nil)
+			_, err := containerStore.Reserve(logger, &req)
 			Expect(err).NotTo(HaveOccurred())
 		})
 
-		It("cleans up the credentials dir", func() {
-			err := containerStore.Destroy(logger, containerGuid)
-			Expect(err).NotTo(HaveOccurred())
-			Expect(credManager.RemoveCredDirCallCount()).To(Equal(1))
+		AfterEach(func() {
+			os.RemoveAll(cgroupRoot)
 		})
 
-		Context("when there are volumes mounted", func() {
-			BeforeEach(func() {
-				someConfig := map[string]interface{}{"some-config": "interface"}
-				runReq.RunInfo.VolumeMounts = []executor.VolumeMount{
-					executor.VolumeMount{ContainerPath: "cpath1", Driver: "some-driver", VolumeId: "some-volume", Config: someConfig},
-					executor.VolumeMount{ContainerPath: "cpath2", Driver: "some-other-driver", VolumeId: "some-other-volume", Config: someConfig},
-				}
-				count := 0
-				volumeManager.MountStub = // first call mounts at a different point than second call
-					func(lager.Logger, string, string, string, map[string]interface{}) (volman.MountResponse, error) {
-						defer func() { count = count + 1 }()
-						if count == 0 {
-							return volman.MountResponse{Path: "hpath1"}, nil
-						}
-						return volman.MountResponse{Path: "hpath2"}, nil
-					}
-			})
-
-			It("removes mounted volumes on the host machine", func() {
-				err := containerStore.Destroy(logger, containerGuid)
+		Context("when the container is reserved", func() {
+			It("adjusts the reservation", func() {
+				err := containerStore.UpdateLimits(logger, containerGuid, executor.Resource{MemoryMB: 2048, DiskMB: 512})
 				Expect(err).NotTo(HaveOccurred())
-				Expect(volumeManager.UnmountCallCount()).To(Equal(2))
 
-				_, driverId, volumeId, containerId := volumeManager.UnmountArgsForCall(0)
-				Expect(driverId).To(Equal(runReq.RunInfo.VolumeMounts[0].Driver))
-				Expect(volumeId).To(Equal(runReq.RunInfo.VolumeMounts[0].VolumeId))
-				Expect(containerId).To(Equal(containerGuid))
+				container, err := containerStore.Get(logger, containerGuid)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(container.Resource).To(Equal(executor.Resource{MemoryMB: 2048, DiskMB: 512, MaxPids: 100}))
 
-				_, driverId, volumeId, containerId = volumeManager.UnmountArgsForCall(1)
-				Expect(driverId).To(Equal(runReq.RunInfo.VolumeMounts[1].Driver))
-				Expect(volumeId).To(Equal(runReq.RunInfo.VolumeMounts[1].VolumeId))
-				Expect(containerId).To(Equal(containerGuid))
+				remaining := containerStore.RemainingResources(logger)
+				Expect(remaining.MemoryMB).To(Equal(totalCapacity.MemoryMB - 2048))
+				Expect(remaining.DiskMB).To(Equal(totalCapacity.DiskMB - 512))
+				Expect(remaining.MaxPids).To(Equal(totalCapacity.MaxPids - 100))
 			})
 
-			Context("when we fail to release cache dependencies", func() {
-				BeforeEach(func() {
-					dependencyManager.ReleaseCachedDependenciesReturns(errors.New("oh noes!"))
-				})
-				It("still attempts to unmount our volumes", func() {
-					err := containerStore.Destroy(logger, containerGuid)
-					Expect(err).To(HaveOccurred())
-					Expect(volumeManager.UnmountCallCount()).To(Equal(2))
-
-					_, driverId, volumeId, containerId := volumeManager.UnmountArgsForCall(0)
-					Expect(driverId).To(Equal(runReq.RunInfo.VolumeMounts[0].Driver))
-					Expect(volumeId).To(Equal(runReq.RunInfo.VolumeMounts[0].VolumeId))
-					Expect(containerId).To(Equal(containerGuid))
+			It("emits a limits updated event", func() {
+				err := containerStore.UpdateLimits(logger, containerGuid, executor.Resource{MemoryMB: 2048, DiskMB: 512})
+				Expect(err).NotTo(HaveOccurred())
 
-					_, driverId, volumeId, containerId = volumeManager.UnmountArgsForCall(1)
-					Expect(driverId).To(Equal(runReq.RunInfo.VolumeMounts[1].Driver))
-					Expect(volumeId).To(Equal(runReq.RunInfo.VolumeMounts[1].VolumeId))
-					Expect(containerId).To(Equal(containerGuid))
-				})
+				Eventually(eventEmitter.EmitCallCount).Should(Equal(2))
+				event, ok := eventEmitter.EmitArgsForCall(1).(executor.ContainerLimitsUpdatedEvent)
+				Expect(ok).To(BeTrue())
+				Expect(event.Container().MemoryMB).To(Equal(2048))
 			})
-			Context("when an volume unmount fails", func() {
-				BeforeEach(func() {
-					volumeManager.UnmountReturns(errors.New("oh noes!"))
-				})
 
-				It("still attempts to unmount the remaining volumes", func() {
-					err := containerStore.Destroy(logger, containerGuid)
+			Context("when the new limits do not fit in the remaining resources", func() {
+				It("returns ErrInsufficientResourcesAvailable and keeps the reservation", func() {
+					before := containerStore.RemainingResources(logger)
+
+					err := containerStore.UpdateLimits(logger, containerGuid, executor.Resource{MemoryMB: totalCapacity.MemoryMB + 1, DiskMB: 1024})
+					Expect(err).To(Equal(executor.ErrInsufficientResourcesAvailable))
+
+					container, err := containerStore.Get(logger, containerGuid)
 					Expect(err).NotTo(HaveOccurred())
-					Expect(volumeManager.UnmountCallCount()).To(Equal(2))
+					Expect(container.Resource).To(Equal(resource))
+					Expect(containerStore.RemainingResources(logger)).To(Equal(before))
 				})
 			})
-			Context("when garden fails to destroy the container", func() {
-				BeforeEach(func() {
-					gardenClient.DestroyReturns(errors.New("destroy failed"))
-				})
-				It("should still unmount the volumes", func() {
-					err := containerStore.Destroy(logger, containerGuid)
-					Expect(err).To(MatchError("destroy failed"))
-					Expect(volumeManager.UnmountCallCount()).To(Equal(2))
+
+			Context("when the new limits are negative", func() {
+				It("returns ErrLimitsInvalid", func() {
+					err := containerStore.UpdateLimits(logger, containerGuid, executor.Resource{MemoryMB: -1, DiskMB: 1024})
+					Expect(err).To(Equal(executor.ErrLimitsInvalid))
 				})
 			})
 		})
 
-		It("removes downloader cache references", func() {
-			err := containerStore.Destroy(logger, containerGuid)
-			Expect(err).NotTo(HaveOccurred())
-			Expect(dependencyManager.ReleaseCachedDependenciesCallCount()).To(Equal(1))
-			_, keys := dependencyManager.ReleaseCachedDependenciesArgsForCall(0)
-			Expect(keys).To(Equal(expectedMounts.CacheKeys))
-		})
+		Context("when the container has been created", func() {
+			JustBeforeEach(func() {
+				err := containerStore.Initialize(logger, &executor.RunRequest{Guid: containerGuid})
+				Expect(err).NotTo(HaveOccurred())
 
-		It("destroys the container", func() {
-			err := containerStore.Destroy(logger, containerGuid)
-			Expect(err).NotTo(HaveOccurred())
+				_, err = containerStore.Create(logger, containerGuid)
+				Expect(err).NotTo(HaveOccurred())
+			})
 
-			Expect(gardenClient.DestroyCallCount()).To(Equal(1))
-			Expect(gardenClient.DestroyArgsForCall(0)).To(Equal(containerGuid))
+			Context("with cgroup v1", func() {
+				BeforeEach(func() {
+					for _, name := range []string{"memory.limit_in_bytes", "memory.memsw.limit_in_bytes"} {
+						err := ioutil.WriteFile(filepath.Join(cgroupRoot, containerGuid, name), []byte("1073741824"), 0644)
+						Expect(err).NotTo(HaveOccurred())
+					}
+				})
 
-			_, err = containerStore.Get(logger, containerGuid)
-			Expect(err).To(Equal(executor.ErrContainerNotFound))
-		})
+				It("applies the new memory limit to the container", func() {
+					err := containerStore.UpdateLimits(logger, containerGuid, executor.Resource{MemoryMB: 2048, DiskMB: 1024})
+					Expect(err).NotTo(HaveOccurred())
 
-		It("emits a metric after destroying the container", func() {
-			err := containerStore.Destroy(logger, containerGuid)
-			Expect(err).NotTo(HaveOccurred())
+					Expect(readCgroupFile("memory.limit_in_bytes")).To(Equal("2147483648"))
+					Expect(readCgroupFile("memory.memsw.limit_in_bytes")).To(Equal("2147483648"))
 
-			Eventually(getMetrics).Should(HaveKey(containerstore.GardenContainerDestructionSucceededDuration))
-		})
+					container, err := containerStore.Get(logger, containerGuid)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(container.MemoryMB).To(Equal(2048))
+					Expect(container.MemoryLimit).To(Equal(uint64(2048 * 1024 * 1024)))
+				})
+			})
 
-		It("frees the containers resources", func() {
-			err := containerStore.Destroy(logger, containerGuid)
-			Expect(err).NotTo(HaveOccurred())
+			Context("with cgroup v2", func() {
+				BeforeEach(func() {
+					err := ioutil.WriteFile(filepath.Join(cgroupRoot, containerGuid, "memory.max"), []byte("1073741824"), 0644)
+					Expect(err).NotTo(HaveOccurred())
+				})
 
-			remainingResources := containerStore.RemainingResources(logger)
-			Expect(remainingResources).To(Equal(totalCapacity))
-		})
+				It("applies the new memory limit to the container", func() {
+					err := containerStore.UpdateLimits(logger, containerGuid, executor.Resource{MemoryMB: 512, DiskMB: 1024})
+					Expect(err).NotTo(HaveOccurred())
+					Expect(readCgroupFile("memory.max")).To(Equal("536870912"))
+				})
 
-		Context("when destroying the garden container fails", func() {
-			var destroyErr error
-			BeforeEach(func() {
-				destroyErr = errors.New("failed to destroy garden container")
+				It("removes the limit when the memory is zero", func() {
+					err := containerStore.UpdateLimits(logger, containerGuid, executor.Resource{MemoryMB: 0, DiskMB: 1024})
+					Expect(err).NotTo(HaveOccurred())
+					Expect(readCgroupFile("memory.max")).To(Equal("max"))
+				})
 			})
 
-			JustBeforeEach(func() {
-				gardenClient.DestroyReturns(destroyErr)
+			It("does not allow the disk limit to change", func() {
+				err := containerStore.UpdateLimits(logger, containerGuid, executor.Resource{MemoryMB: 1024, DiskMB: 2048})
+				Expect(err).To(Equal(executor.ErrDiskLimitNotUpdatable))
 			})
 
-			Context("because the garden container does not exist", func() {
+			Context("when updating limits is not configured", func() {
 				BeforeEach(func() {
-					destroyErr = garden.ContainerNotFoundError{}
+					containerConfig.MemoryCgroupRoot = ""
 				})
 
-				It("does not return an error", func() {
-					err := containerStore.Destroy(logger, containerGuid)
+				It("returns ErrLimitsUpdateNotSupported and keeps the reservation", func() {
+					before := containerStore.RemainingResources(logger)
+
+					err := containerStore.UpdateLimits(logger, containerGuid, executor.Resource{MemoryMB: 2048, DiskMB: 1024})
+					Expect(err).To(Equal(executor.ErrLimitsUpdateNotSupported))
+
+					container, err := containerStore.Get(logger, containerGuid)
 					Expect(err).NotTo(HaveOccurred())
+					Expect(container.MemoryMB).To(Equal(1024))
+					Expect(containerStore.RemainingResources(logger)).To(Equal(before))
 				})
 			})
+		})
 
-			Context("because the garden container is already being destroyed", func() {
-				BeforeEach(func() {
-					destroyErr = server.ErrConcurrentDestroy
-				})
-
-				It("does not return an error", func() {
-					err := containerStore.Destroy(logger, containerGuid)
-					Expect(err).NotTo(HaveOccurred())
-				})
-
-				It("logs the container is destroyed", func() {
-					err := containerStore.Destroy(logger, containerGuid)
-					Expect(err).NotTo(HaveOccurred())
-					Expect(fakeMetronClient.SendAppLogCallCount()).To(Equal(4))
-					msg, sourceType, tags := fakeMetronClient.SendAppLogArgsForCall(2)
-					Expect(tags["source_id"]).To(Equal(containerGuid))
-					Expect(sourceType).To(Equal("test-source"))
-					Expect(msg).To(Equal(fmt.Sprintf("Cell %s destroying container for instance %s", cellID, containerGuid)))
-					Expect(tags["instance_id"]).To(Equal("1"))
-
-					msg, sourceType, tags = fakeMetronClient.SendAppLogArgsForCall(3)
-					Expect(tags["source_id"]).To(Equal(containerGuid))
-					Expect(sourceType).To(Equal("test-source"))
-					Expect(msg).To(Equal(fmt.Sprintf("Cell %s successfully destroyed container for instance %s", cellID, containerGuid)))
-					Expect(tags["instance_id"]).To(Equal("1"))
-				})
+		Context("when the container has completed", func() {
+			JustBeforeEach(func() {
+				Expect(containerStore.Stop(logger, containerGuid)).To(Succeed())
 			})
 
-			Context("for unknown reason", func() {
-				It("returns an error", func() {
-					err := containerStore.Destroy(logger, containerGuid)
-					Expect(err).To(Equal(destroyErr))
-				})
+			It("returns ErrInvalidTransition", func() {
+				err := containerStore.UpdateLimits(logger, containerGuid, executor.Resource{MemoryMB: 2048, DiskMB: 1024})
+				Expect(err).To(Equal(executor.ErrInvalidTransition))
+			})
+		})
 
-				It("emits a metric after failing to destroy the container", func() {
-					err := containerStore.Destroy(logger, containerGuid)
-					Expect(err).To(Equal(destroyErr))
-					Eventually(getMetrics).Should(HaveKey(containerstore.GardenContainerDestructionFailedDuration))
-				})
+		Context("when the container does not exist", func() {
+			It("returns ErrContainerNotFound", func() {
+				err := containerStore.UpdateLimits(logger, "missing", executor.Resource{})
+				Expect(err).To(Equal(executor.ErrContainerNotFound))
+			})
+		})
+	})
 
-				It("does remove the container from the container store", func() {
-					err := containerStore.Destroy(logger, containerGuid)
-					Expect(err).To(Equal(destroyErr))
+	Describe("Pause and Resume", func() {
+		var (
+			cgroupRoot  string
+			freezerFile string
+		)
 
-					Expect(gardenClient.DestroyCallCount()).To(Equal(1))
-					Expect(gardenClient.DestroyArgsForCall(0)).To(Equal(containerGuid))
+		emittedEvents := func() []executor.Event {
+			events := []executor.Event{}
+			for i := 0; i < eventEmitter.EmitCallCount(); i++ {
+				events = append(events, eventEmitter.EmitArgsForCall(i))
+			}
+			return events
+		}
 
-					_, err = containerStore.Get(logger, containerGuid)
-					Expect(err).To(Equal(executor.ErrContainerNotFound))
-				})
+		freezerState := func() string {
+			data, err := ioutil.ReadFile(freezerFile)
+			Expect(err).NotTo(HaveOccurred())
+			return string(data)
+		}
 
-				It("frees the containers resources", func() {
-					err := containerStore.Destroy(logger, containerGuid)
-					Expect(err).To(Equal(destroyErr))
+		BeforeEach(func() {
+			var err error
+			cgroupRoot, err = ioutil.TempDir("", "freezer")
+			Expect(err).NotTo(HaveOccurred())
 
-					remainingResources := containerStore.RemainingResources(logger)
-					Expect(remainingResources).To(Equal(totalCapacity))
-				})
-			})
-		})
+			Expect(os.Mkdir(filepath.Join(cgroupRoot, containerGuid), 0755)).To(Succeed())
+			freezerFile = filepath.Join(cgroupRoot, containerGuid, "freezer.state")
+			Expect(ioutil.WriteFile(freezerFile, []byte("THAWED"), 0644)).To(Succeed())
 
-		Context("when the container does not exist", func() {
-			It("returns a ErrContainerNotFound", func() {
-				err := containerStore.Destroy(logger, "")
-				Expect(err).To(Equal(executor.ErrContainerNotFound))
-			})
+			containerConfig.FreezerCgroupRoot = cgroupRoot
+			gardenClient.CreateReturns(gardenContainer, nil)
+			megatron.StepsRunnerReturns(ifrit.RunFunc(func(signals <-chan os.Signal, ready chan<- struct{}) error {
+				close(ready)
+				<-signals
+				return nil
+			}), nil)
 		})
 
-		Context("when there is a stopped process associated with the container", func() {
-			var (
-				finishRun                  chan struct{}
-				credManagerRunnerSignalled chan struct{}
-				destroyed                  chan struct{}
+		JustBeforeEach(func() {
+			containerStore = containerstore.New(
+				containerConfig,
+				&totalCapacity,
+				gardenClient,
+				dependencyManager,
+				volumeManager,
+				credManager,
+				clock,
+				eventEmitter,
+				megatron,
+				"/var/vcap/data/cf-system-trusted-certs",
+				fakeMetronClient,
+				fakeRootFSSizer,
+				false,
+				"/var/vcap/packages/healthcheck",
+				proxyManager,
+				cellID,
+				true,
+				advertisePreferenceForInstanceAddress,
+				admissionPolicy,
 			)
 
-			BeforeEach(func() {
-				credManagerRunnerSignalled = make(chan struct{})
-				finishRun = make(chan struct{})
-				finishRunIfrit := finishRun
-				var testRunner ifrit.RunFunc = func(signals <-chan os.Signal, ready chan<- struct{}) error {
-					close(ready)
-					<-signals
-					<-finishRunIfrit
-					return nil
-				}
+			_, err := containerStore.Reserve(logger, &executor.AllocationRequest{Guid: containerGuid})
+			Expect(err).NotTo(HaveOccurred())
 
-				signalled := credManagerRunnerSignalled
-				megatron.StepsRunnerReturns(testRunner, nil)
-				credManager.RunnerReturns(ifrit.RunFunc(func(signals <-chan os.Signal, ready chan<- struct{}) error {
-					close(ready)
-					<-signals
-					close(signalled)
-					return nil
-				}))
-			})
+			err = containerStore.Initialize(logger, &executor.RunRequest{Guid: containerGuid})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = containerStore.Create(logger, containerGuid)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(cgroupRoot)
+		})
 
+		Context("when the container is running", func() {
 			JustBeforeEach(func() {
-				err := containerStore.Run(logger, containerGuid)
-				Expect(err).NotTo(HaveOccurred())
+				Expect(containerStore.Run(logger, containerGuid)).To(Succeed())
 				Eventually(containerState(containerGuid)).Should(Equal(executor.StateRunning))
-				err = containerStore.Stop(logger, containerGuid)
-				Expect(err).NotTo(HaveOccurred())
-				destroyed = make(chan struct{})
-				go func(ch chan struct{}) {
-					containerStore.Destroy(logger, containerGuid)
-					close(ch)
-				}(destroyed)
 			})
 
-			It("cancels the process", func() {
-				Consistently(destroyed).ShouldNot(Receive())
-				close(finishRun)
-				Eventually(destroyed).Should(BeClosed())
+			It("passes a monitor gate to the steps", func() {
+				_, _, _, _, cfg := megatron.StepsRunnerArgsForCall(0)
+				Expect(cfg.MonitorGate).NotTo(BeNil())
 			})
 
-			It("logs that the container is stopping once", func() {
-				close(finishRun)
-				Eventually(destroyed).Should(BeClosed())
+			It("freezes the container and emits a paused event", func() {
+				Expect(containerStore.Pause(logger, containerGuid)).To(Succeed())
 
-				Expect(fakeMetronClient.SendAppLogCallCount()).To(Equal(5))
-				msg, sourceType, tags := fakeMetronClient.SendAppLogArgsForCall(2)
-				Expect(tags["source_id"]).To(Equal(containerGuid))
-				Expect(sourceType).To(Equal("test-source"))
-				Expect(msg).To(Equal(fmt.Sprintf("Cell %s stopping instance %s", cellID, containerGuid)))
-				Expect(tags["instance_id"]).To(Equal("1"))
+				Expect(freezerState()).To(Equal("FROZEN"))
+				Expect(containerState(containerGuid)()).To(Equal(executor.StatePaused))
+
+				Eventually(emittedEvents).Should(ContainElement(WithTransform(func(e executor.Event) executor.State {
+					if paused, ok := e.(executor.ContainerPausedEvent); ok {
+						return paused.Container().State
+					}
+					return executor.StateInvalid
+				}, Equal(executor.StatePaused))))
 			})
-		})
 
-		Context("when there is a running process associated with the container", func() {
-			var (
-				finishRun                  chan struct{}
-				credManagerRunnerSignalled chan struct{}
-				destroyed                  chan struct{}
-			)
+			It("thaws the container on resume and emits a running event", func() {
+				Expect(containerStore.Pause(logger, containerGuid)).To(Succeed())
+				Expect(containerStore.Resume(logger, containerGuid)).To(Succeed())
 
-			BeforeEach(func() {
-				credManagerRunnerSignalled = make(chan struct{})
-				finishRun = make(chan struct{})
-				finishRunIfrit := finishRun
-				var testRunner ifrit.RunFunc = func(signals <-chan os.Signal, ready chan<- struct{}) error {
-					close(ready)
-					<-signals
-					<-finishRunIfrit
-					return nil
-				}
+				Expect(freezerState()).To(Equal("THAWED"))
+				Expect(containerState(containerGuid)()).To(Equal(executor.StateRunning))
 
-				signalled := credManagerRunnerSignalled
-				megatron.StepsRunnerReturns(testRunner, nil)
-				credManager.RunnerReturns(ifrit.RunFunc(func(signals <-chan os.Signal, ready chan<- struct{}) error {
-					close(ready)
-					<-signals
-					close(signalled)
-					return nil
-				}))
+				Eventually(func() int {
+					count := 0
+					for _, e := range emittedEvents() {
+						if _, ok := e.(executor.ContainerRunningEvent); ok {
+							count++
+						}
+					}
+					return count
+				}).Should(Equal(2))
 			})
 
-			JustBeforeEach(func() {
-				err := containerStore.Run(logger, containerGuid)
-				Expect(err).NotTo(HaveOccurred())
-				Eventually(containerState(containerGuid)).Should(Equal(executor.StateRunning))
-				destroyed = make(chan struct{})
-				go func(ch chan struct{}) {
-					containerStore.Destroy(logger, containerGuid)
-					close(ch)
-				}(destroyed)
+			It("cannot be resumed unless it is paused", func() {
+				Expect(containerStore.Resume(logger, containerGuid)).To(Equal(executor.ErrInvalidTransition))
 			})
 
-			It("cancels the process", func() {
-				Consistently(destroyed).ShouldNot(Receive())
-				close(finishRun)
-				Eventually(destroyed).Should(BeClosed())
-			})
+			It("thaws the container before stopping it", func() {
+				Expect(containerStore.Pause(logger, containerGuid)).To(Succeed())
+				Expect(containerStore.Stop(logger, containerGuid)).To(Succeed())
 
-			It("signals the cred manager runner", func() {
-				close(finishRun)
-				Eventually(credManagerRunnerSignalled).Should(BeClosed())
+				Expect(freezerState()).To(Equal("THAWED"))
+				Eventually(containerState(containerGuid)).Should(Equal(executor.StateCompleted))
 			})
 
-			It("logs that the container is stopping", func() {
-				close(finishRun)
-				Eventually(destroyed).Should(BeClosed())
-				Expect(fakeMetronClient.SendAppLogCallCount()).To(Equal(5))
-				msg, sourceType, tags := fakeMetronClient.SendAppLogArgsForCall(2)
-				Expect(tags["source_id"]).To(Equal(containerGuid))
-				Expect(sourceType).To(Equal("test-source"))
-				Expect(msg).To(Equal(fmt.Sprintf("Cell %s stopping instance %s", cellID, containerGuid)))
-				Expect(tags["instance_id"]).To(Equal("1"))
-			})
+			Context("when the freezer uses cgroup v2", func() {
+				BeforeEach(func() {
+					freezerFile = filepath.Join(cgroupRoot, containerGuid, "cgroup.freeze")
+					Expect(ioutil.WriteFile(freezerFile, []byte("0"), 0644)).To(Succeed())
+				})
 
-			Context("when the container credManager config process is running in the container", func() {
-				var (
-					credManagerRunnerSignalled chan struct{}
-				)
+				It("writes to cgroup.freeze", func() {
+					Expect(containerStore.Pause(logger, containerGuid)).To(Succeed())
+					Expect(freezerState()).To(Equal("1"))
+
+					Expect(containerStore.Resume(logger, containerGuid)).To(Succeed())
+					Expect(freezerState()).To(Equal("0"))
+				})
+			})
 
+			Context("when the container cgroup does not exist", func() {
 				BeforeEach(func() {
-					credManagerRunnerSignalled = make(chan struct{})
+					containerConfig.FreezerCgroupRoot = filepath.Join(cgroupRoot, "missing")
+				})
 
-					containerStore = containerstore.New(
-						containerConfig,
-						&totalCapacity,
-						gardenClient,
-						dependencyManager,
-						volumeManager,
-						credManager,
-						clock,
-						eventEmitter,
-						megatron,
-						"/var/vcap/data/cf-system-trusted-certs",
-						fakeMetronClient,
-						fakeRootFSSizer,
-						false,
-						"/var/vcap/packages/healthcheck",
-						proxyManager,
-						cellID,
-						true,
-						advertisePreferenceForInstanceAddress,
-					)
+				It("returns the error and keeps the container running", func() {
+					Expect(containerStore.Pause(logger, containerGuid)).NotTo(Succeed())
+					Expect(containerState(containerGuid)()).To(Equal(executor.StateRunning))
+				})
+			})
 
-					signalled := credManagerRunnerSignalled
-					runner := &fake_runner.FakeRunner{}
-					runner.RunStub = ifrit.RunFunc(func(signals <-chan os.Signal, ready chan<- struct{}) error {
-						close(ready)
-						<-signals
-						close(signalled)
-						return nil
-					})
-					credManager.RunnerReturns(runner)
+			Context("when pausing is not configured", func() {
+				BeforeEach(func() {
+					containerConfig.FreezerCgroupRoot = ""
 				})
 
-				It("signals the credManager config runner", func() {
-					close(finishRun)
-					Eventually(credManagerRunnerSignalled).Should(BeClosed())
+				It("returns ErrPauseNotSupported", func() {
+					Expect(containerStore.Pause(logger, containerGuid)).To(Equal(executor.ErrPauseNotSupported))
+					Expect(containerState(containerGuid)()).To(Equal(executor.StateRunning))
 				})
 			})
 		})
-	})
-
-	Describe("Get", func() {
-		BeforeEach(func() {
-			_, err := containerStore.Reserve(logger, &executor.AllocationRequest{Guid: containerGuid})
-			Expect(err).NotTo(HaveOccurred())
-		})
-
-		It("returns the specified container", func() {
-			container, err := containerStore.Get(logger, containerGuid)
-			Expect(err).NotTo(HaveOccurred())
 
-			Expect(container.Guid).To(Equal(containerGuid))
+		Context("when the container is not running", func() {
+			It("returns ErrInvalidTransition", func() {
+				Expect(containerStore.Pause(logger, containerGuid)).To(Equal(executor.ErrInvalidTransition))
+				Expect(freezerState()).To(Equal("THAWED"))
+			})
 		})
 
 		Context("when the container does not exist", func() {
-			It("returns an ErrContainerNotFound", func() {
-				_, err := containerStore.Get(logger, "")
-				Expect(err).To(Equal(executor.ErrContainerNotFound))
+			It("returns ErrContainerNotFound", func() {
+				Expect(containerStore.Pause(logger, "missing")).To(Equal(executor.ErrContainerNotFound))
+				Expect(containerStore.Resume(logger, "missing")).To(Equal(executor.ErrContainerNotFound))
 			})
 		})
 	})
 
-	Describe("List", func() {
-		var container1, container2 executor.Container
+	Describe("RunProcess", func() {
+		var (
+			gardenProcess *gardenfakes.FakeProcess
+			processExited chan struct{}
+			spec          executor.ProcessSpec
+		)
+
+		execLogs := func() []string {
+			logs := []string{}
+			for i := 0; i < fakeMetronClient.SendAppLogCallCount(); i++ {
+				msg, sourceType, _ := fakeMetronClient.SendAppLogArgsForCall(i)
+				if sourceType == containerstore.ExecLogSource {
+					logs = append(logs, msg)
+				}
+			}
+			for i := 0; i < fakeMetronClient.SendAppErrorLogCallCount(); i++ {
+				msg, sourceType, _ := fakeMetronClient.SendAppErrorLogArgsForCall(i)
+				if sourceType == containerstore.ExecLogSource {
+					logs = append(logs, msg)
+				}
+			}
+			return logs
+		}
 
 		BeforeEach(func() {
-			_, err := containerStore.Reserve(logger, &executor.AllocationRequest{
-				Guid: containerGuid,
-			})
+			processExited = make(chan struct{})
+			gardenProcess = &gardenfakes.FakeProcess{}
+			gardenProcess.IDReturns("some-process")
+			gardenProcess.WaitStub = func() (int, error) {
+				<-processExited
+				return 3, nil
+			}
+			gardenContainer.RunReturns(gardenProcess, nil)
+			gardenContainer.InfoReturns(garden.ContainerInfo{ExternalIP: "1.2.3.4", ContainerIP: "10.0.0.1"}, nil)
+			gardenClient.CreateReturns(gardenContainer, nil)
+
+			megatron.StepsRunnerReturns(ifrit.RunFunc(func(signals <-chan os.Signal, ready chan<- struct{}) error {
+				close(ready)
+				<-signals
+				return nil
+			}), nil)
+
+			spec = executor.ProcessSpec{
+				Path: "/bin/bash",
+				Args: []string{"-c", "echo hello"},
+				Dir:  "/home/vcap",
+				User: "vcap",
+				Env:  []executor.EnvironmentVariable{{Name: "TERM", Value: "xterm"}},
+				TTY:  &executor.TTYSpec{WindowSize: &executor.WindowSize{Columns: 80, Rows: 24}},
+			}
+		})
+
+		JustBeforeEach(func() {
+			_, err := containerStore.Reserve(logger, &executor.AllocationRequest{Guid: containerGuid})
 			Expect(err).NotTo(HaveOccurred())
 
 			err = containerStore.Initialize(logger, &executor.RunRequest{
 				Guid: containerGuid,
+				RunInfo: executor.RunInfo{
+					LogConfig: executor.LogConfig{Guid: containerGuid, Index: 1, SourceName: "test-source"},
+					Ports:     []executor.PortMapping{{ContainerPort: 8080}},
+				},
 			})
 			Expect(err).NotTo(HaveOccurred())
 
-			_, err = containerStore.Reserve(logger, &executor.AllocationRequest{
-				Guid: containerGuid + "2",
+			_, err = containerStore.Create(logger, containerGuid)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			close(processExited)
+		})
+
+		Context("when the container is running", func() {
+			JustBeforeEach(func() {
+				Expect(containerStore.Run(logger, containerGuid)).To(Succeed())
+				Eventually(containerState(containerGuid)).Should(Equal(executor.StateRunning))
+			})
+
+			It("runs the process in the garden container with the networking environment", func() {
+				stdout := &bytes.Buffer{}
+				process, err := containerStore.RunProcess(logger, containerGuid, spec, executor.ProcessIO{Stdout: stdout})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(process.ID()).To(Equal("some-process"))
+
+				Expect(gardenContainer.RunCallCount()).To(Equal(1))
+				gardenSpec, gardenIO := gardenContainer.RunArgsForCall(0)
+				Expect(gardenSpec.Path).To(Equal("/bin/bash"))
+				Expect(gardenSpec.Args).To(Equal([]string{"-c", "echo hello"}))
+				Expect(gardenSpec.Dir).To(Equal("/home/vcap"))
+				Expect(gardenSpec.User).To(Equal("vcap"))
+				Expect(gardenSpec.Env).To(ContainElements("TERM=xterm", "CF_INSTANCE_IP=1.2.3.4", "CF_INSTANCE_INTERNAL_IP=10.0.0.1"))
+				Expect(gardenSpec.TTY).To(Equal(&garden.TTYSpec{WindowSize: &garden.WindowSize{Columns: 80, Rows: 24}}))
+				Expect(gardenIO.Stdout).To(Equal(stdout))
+			})
+
+			It("writes audit lines to the container logs", func() {
+				_, err := containerStore.RunProcess(logger, containerGuid, spec, executor.ProcessIO{})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(execLogs()).To(ConsistOf(
+					fmt.Sprintf(`Cell %s running process as user "vcap" in instance %s: /bin/bash -c echo hello`, cellID, containerGuid),
+				))
+
+				processExited <- struct{}{}
+				Eventually(execLogs).Should(ContainElement(
+					fmt.Sprintf("Cell %s process some-process in instance %s exited with status 3", cellID, containerGuid),
+				))
+			})
+
+			It("resizes and signals the process", func() {
+				process, err := containerStore.RunProcess(logger, containerGuid, spec, executor.ProcessIO{})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(process.Resize(executor.WindowSize{Columns: 120, Rows: 40})).To(Succeed())
+				Expect(gardenProcess.SetTTYArgsForCall(0)).To(Equal(garden.TTYSpec{WindowSize: &garden.WindowSize{Columns: 120, Rows: 40}}))
+
+				Expect(process.Signal(executor.ProcessSignalTerminate)).To(Succeed())
+				Expect(gardenProcess.SignalArgsForCall(0)).To(Equal(garden.SignalTerminate))
+
+				Expect(process.Signal(executor.ProcessSignal("hup"))).To(Equal(executor.ErrInvalidProcessSignal))
+				Expect(gardenProcess.SignalCallCount()).To(Equal(1))
+			})
+
+			Context("when garden fails to run the process", func() {
+				BeforeEach(func() {
+					gardenContainer.RunReturns(nil, errors.New("boom"))
+				})
+
+				It("returns the error and logs the failure", func() {
+					_, err := containerStore.RunProcess(logger, containerGuid, spec, executor.ProcessIO{})
+					Expect(err).To(MatchError("boom"))
+					Expect(execLogs()).To(ContainElement(
+						fmt.Sprintf("Cell %s failed to run process in instance %s: boom", cellID, containerGuid),
+					))
+				})
+			})
+		})
+
+		Context("when the container is not running", func() {
+			It("returns ErrContainerNotRunning", func() {
+				_, err := containerStore.RunProcess(logger, containerGuid, spec, executor.ProcessIO{})
+				Expect(err).To(Equal(executor.ErrContainerNotRunning))
+				Expect(gardenContainer.RunCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when the container does not exist", func() {
+			It("returns ErrContainerNotFound", func() {
+				_, err := containerStore.RunProcess(logger, "missing", spec, executor.ProcessIO{})
+				Expect(err).To(Equal(executor.ErrContainerNotFound))
 			})
+		})
+	})
+
+	Describe("Destroy", func() {
+		var resource executor.Resource
+		var expectedMounts containerstore.BindMounts
+		var runReq *executor.RunRequest
+
+		BeforeEach(func() {
+			runInfo := executor.RunInfo{
+				LogConfig: executor.LogConfig{
+					Guid:       containerGuid,
+					Index:      1,
+					SourceName: "test-source",
+				},
+			}
+			runReq = &executor.RunRequest{Guid: containerGuid, RunInfo: runInfo}
+			gardenClient.CreateReturns(gardenContainer, nil)
+			resource = executor.NewResource(1024, 2048, 1024)
+			expectedMounts = containerstore.BindMounts{
+				CacheKeys: []containerstore.BindMountCacheKey{
+					{CacheKey: "cache-key", Dir: "foo"},
+				},
+			}
+			dependencyManager.DownloadCachedDependenciesReturns(expectedMounts, nil)
+		})
+
+		JustBeforeEach(func() {
+			_, err := containerStore.Reserve(logger, &executor.AllocationRequest{Guid: containerGuid, Resource: resource})
 			Expect(err).NotTo(HaveOccurred())
 
-			container1, err = containerStore.Get(logger, containerGuid)
+			err = containerStore.Initialize(logger, runReq)
 			Expect(err).NotTo(HaveOccurred())
 
-			container2, err = containerStore.Get(logger, containerGuid+"2")
+			_, err = containerStore.Create(logger, containerGuid)
 			Expect(err).NotTo(HaveOccurred())
 		})
 
-		It("returns the list of known containers", func() {
-			containers := containerStore.List(logger)
-			Expect(containers).To(HaveLen(2))
-			Expect(containers).To(ContainElement(container1))
-			Expect(containers).To(ContainElement(container2))
+		It("cleans up the credentials dir", func() {
+			err := containerStore.Destroy(logger, containerGuid)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(credManager.RemoveCredDirCallCount()).To(Equal(1))
 		})
-	})
 
-	reserveContainer := func(guid string) {
-		resource := executor.Resource{
-			MemoryMB: 10,
-			DiskMB:   10,
-		}
-		tags := executor.Tags{}
-		_, err := containerStore.Reserve(logger, &executor.AllocationRequest{Guid: guid, Tags: tags, Resource: resource})
-		Expect(err).NotTo(HaveOccurred())
-	}
+		Context("when there are volumes mounted", func() {
+			BeforeEach(func() {
+				someConfig := map[string]interface{}{"some-config": "interface"}
+				runReq.RunInfo.VolumeMounts = []executor.VolumeMount{
+					executor.VolumeMount{ContainerPath: "cpath1", Driver: "some-driver", VolumeId: "some-volume", Config: someConfig},
+					executor.VolumeMount{ContainerPath: "cpath2", Driver: "some-other-driver", VolumeId: "some-other-volume", Config: someConfig},
+				}
+				count := 0
+				volumeManager.MountStub = // first call mounts at a different point than second call
+					func(lager.Logger, string, string, string, map[string]interface{}) (volman.MountResponse, error) {
+						defer func() { count = count + 1 }()
+						if count == 0 {
+							return volman.MountResponse{Path: "hpath1"}, nil
+						}
+						return volman.MountResponse{Path: "hpath2"}, nil
+					}
+			})
 
-	initializeContainer := func(guid string, rootFSPath string) {
-		runInfo := executor.RunInfo{
-			RootFSPath:         rootFSPath,
-			CPUWeight:          2,
-			StartTimeoutMs:     50000,
-			Privileged:         true,
-			CachedDependencies: []executor.CachedDependency{},
-			LogConfig: executor.LogConfig{
-				Guid:       "log-guid",
-				Index:      1,
-				SourceName: "test-source",
-			},
-			MetricsConfig: executor.MetricsConfig{
-				Guid:  "metric-guid",
-				Index: 1,
-			},
-			Env:                           []executor.EnvironmentVariable{},
-			TrustedSystemCertificatesPath: "",
-			Network: &executor.Network{
-				Properties: map[string]string{},
-			},
-		}
+			It("removes mounted volumes on the host machine", func() {
+				err := containerStore.Destroy(logger, containerGuid)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(volumeManager.UnmountCallCount()).To(Equal(2))
 
-		req := &executor.RunRequest{
-			Guid:    guid,
-			RunInfo: runInfo,
-			Tags:    executor.Tags{},
-		}
+				_, driverId, volumeId, containerId := volumeManager.UnmountArgsForCall(0)
+				Expect(driverId).To(Equal(runReq.RunInfo.VolumeMounts[0].Driver))
+				Expect(volumeId).To(Equal(runReq.RunInfo.VolumeMounts[0].VolumeId))
+				Expect(containerId).To(Equal(containerGuid))
 
-		err := containerStore.Initialize(logger, req)
-		Expect(err).ToNot(HaveOccurred())
-	}
+				_, driverId, volumeId, containerId = volumeManager.UnmountArgsForCall(1)
+				Expect(driverId).To(Equal(runReq.RunInfo.VolumeMounts[1].Driver))
+				Expect(volumeId).To(Equal(runReq.RunInfo.VolumeMounts[1].VolumeId))
+				Expect(containerId).To(Equal(containerGuid))
+			})
 
-	Describe("Metrics", func() {
-		var (
-			containerGuid1, containerGuid2, containerGuid3, containerGuid4 string
-			containerGuid5, containerGuid6                                 string
-		)
+			Context("when we fail to release cache dependencies", func() {
+				BeforeEach(func() {
+					dependencyManager.ReleaseCachedDependenciesReturns(errors.New("oh noes!"))
+				})
+				It("still attempts to unmount our volumes", func() {
+					err := containerStore.Destroy(logger, containerGuid)
+					Expect(err).To(HaveOccurred())
+					Expect(volumeManager.UnmountCallCount()).To(Equal(2))
 
-		BeforeEach(func() {
-			fakeRootFSSizer.RootFSSizeFromPathStub = func(path string) uint64 {
-				switch path {
-				case "/foo/bar":
-					return 1000
-				default:
-					return 0
-				}
-			}
+					_, driverId, volumeId, containerId := volumeManager.UnmountArgsForCall(0)
+					Expect(driverId).To(Equal(runReq.RunInfo.VolumeMounts[0].Driver))
+					Expect(volumeId).To(Equal(runReq.RunInfo.VolumeMounts[0].VolumeId))
+					Expect(containerId).To(Equal(containerGuid))
 
-			containerGuid1 = "container-guid-1"
-			containerGuid2 = "container-guid-2"
-			containerGuid3 = "container-guid-3"
-			containerGuid4 = "container-guid-4"
-			containerGuid5 = "container-guid-5"
-			containerGuid6 = "container-guid-6"
+					_, driverId, volumeId, containerId = volumeManager.UnmountArgsForCall(1)
+					Expect(driverId).To(Equal(runReq.RunInfo.VolumeMounts[1].Driver))
+					Expect(volumeId).To(Equal(runReq.RunInfo.VolumeMounts[1].VolumeId))
+					Expect(containerId).To(Equal(containerGuid))
+				})
+			})
+			Context("when an volume unmount fails", func() {
+				BeforeEach(func() {
+					volumeManager.UnmountReturns(errors.New("oh noes!"))
+				})
 
-			reserveContainer(containerGuid1)
-			reserveContainer(containerGuid2)
-			reserveContainer(containerGuid3)
-			reserveContainer(containerGuid4)
-			reserveContainer(containerGuid5)
-			reserveContainer(containerGuid6)
+				It("still attempts to unmount the remaining volumes", func() {
+					err := containerStore.Destroy(logger, containerGuid)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(volumeManager.UnmountCallCount()).To(Equal(2))
+				})
+			})
+			Context("when garden fails to destroy the container", func() {
+				BeforeEach(func() {
+					gardenClient.DestroyReturns(errors.New("destroy failed"))
+				})
+				It("should still unmount the volumes", func() {
+					err := containerStore.Destroy(logger, containerGuid)
+					Expect(err).To(MatchError("destroy failed"))
+					Expect(volumeManager.UnmountCallCount()).To(Equal(2))
+				})
+			})
+		})
 
-			initializeContainer(containerGuid1, "/foo/bar")
-			initializeContainer(containerGuid2, "docker://repo")
-			initializeContainer(containerGuid3, "/foo/bar")
-			initializeContainer(containerGuid4, "/foo/bar")
+		It("removes downloader cache references", func() {
+			err := containerStore.Destroy(logger, containerGuid)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(dependencyManager.ReleaseCachedDependenciesCallCount()).To(Equal(1))
+			_, keys := dependencyManager.ReleaseCachedDependenciesArgsForCall(0)
+			Expect(keys).To(Equal(expectedMounts.CacheKeys))
+		})
 
-			gardenContainer.InfoReturns(garden.ContainerInfo{ExternalIP: "6.6.6.6"}, nil)
-			gardenClient.CreateReturns(gardenContainer, nil)
-			_, err := containerStore.Create(logger, containerGuid1)
+		It("destroys the container", func() {
+			err := containerStore.Destroy(logger, containerGuid)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(gardenClient.DestroyCallCount()).To(Equal(1))
+			Expect(gardenClient.DestroyArgsForCall(0)).To(Equal(containerGuid))
+
+			_, err = containerStore.Get(logger, containerGuid)
+			Expect(err).To(Equal(executor.ErrContainerNotFound))
+		})
+
+		It("emits a metric after destroying the container", func() {
+			err := containerStore.Destroy(logger, containerGuid)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(getMetrics).Should(HaveKey(containerstore.GardenContainerDestructionSucceededDuration))
+		})
+
+		It("frees the containers resources", func() {
+			err := containerStore.Destroy(logger, containerGuid)
+			Expect(err).NotTo(HaveOccurred())
+
+			remainingResources := containerStore.RemainingResources(logger)
+			Expect(remainingResources).To(Equal(totalCapacity))
+		})
+
+		Context("when destroying the garden container fails", func() {
+			var destroyErr error
+			BeforeEach(func() {
+				destroyErr = errors.New("failed to destroy garden container")
+			})
+
+			JustBeforeEach(func() {
+				gardenClient.DestroyReturns(destroyErr)
+			})
+
+			Context("because the garden container does not exist", func() {
+				BeforeEach(func() {
+					destroyErr = garden.ContainerNotFoundError{}
+				})
+
+				It("does not return an error", func() {
+					err := containerStore.Destroy(logger, containerGuid)
+					Expect(err).NotTo(HaveOccurred())
+				})
+			})
+
+			Context("because the garden container is already being destroyed", func() {
+				BeforeEach(func() {
+					destroyErr = server.ErrConcurrentDestroy
+				})
+
+				It("does not return an error", func() {
+					err := containerStore.Destroy(logger, containerGuid)
+					Expect(err).NotTo(HaveOccurred())
+				})
+
+				It("logs the container is destroyed", func() {
+					err := containerStore.Destroy(logger, containerGuid)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(fakeMetronClient.SendAppLogCallCount()).To(Equal(4))
+					msg, sourceType, tags := fakeMetronClient.SendAppLogArgsForCall(2)
+					Expect(tags["source_id"]).To(Equal(containerGuid))
+					Expect(sourceType).To(Equal("test-source"))
+					Expect(msg).To(Equal(fmt.Sprintf("Cell %s destroying container for instance %s", cellID, containerGuid)))
+					Expect(tags["instance_id"]).To(Equal("1"))
+
+					msg, sourceType, tags = fakeMetronClient.SendAppLogArgsForCall(3)
+					Expect(tags["source_id"]).To(Equal(containerGuid))
+					Expect(sourceType).To(Equal("test-source"))
+					Expect(msg).To(Equal(fmt.Sprintf("Cell %s successfully destroyed container for instance %s", cellID, containerGuid)))
+					Expect(tags["instance_id"]).To(Equal("1"))
+				})
+			})
+
+			Context("for unknown reason", func() {
+				It("returns an error", func() {
+					err := containerStore.Destroy(logger, containerGuid)
+					Expect(err).To(Equal(destroyErr))
+				})
+
+				It("emits a metric after failing to destroy the container", func() {
+					err := containerStore.Destroy(logger, containerGuid)
+					Expect(err).To(Equal(destroyErr))
+					Eventually(getMetrics).Should(HaveKey(containerstore.GardenContainerDestructionFailedDuration))
+				})
+
+				It("does remove the container from the container store", func() {
+					err := containerStore.Destroy(logger, containerGuid)
+					Expect(err).To(Equal(destroyErr))
+
+					Expect(gardenClient.DestroyCallCount()).To(Equal(1))
+					Expect(gardenClient.DestroyArgsForCall(0)).To(Equal(containerGuid))
+
+					_, err = containerStore.Get(logger, containerGuid)
+					Expect(err).To(Equal(executor.ErrContainerNotFound))
+				})
+
+				It("frees the containers resources", func() {
+					err := containerStore.Destroy(logger, containerGuid)
+					Expect(err).To(Equal(destroyErr))
+
+					remainingResources := containerStore.RemainingResources(logger)
+					Expect(remainingResources).To(Equal(totalCapacity))
+				})
+			})
+		})
+
+		Context("when the container does not exist", func() {
+			It("returns a ErrContainerNotFound", func() {
+				err := containerStore.Destroy(logger, "")
+				Expect(err).To(Equal(executor.ErrContainerNotFound))
+			})
+		})
+
+		Context("when there is a stopped process associated with the container", func() {
+			var (
+				finishRun                  chan struct{}
+				credManagerRunnerSignalled chan struct{}
+				destroyed                  chan struct{}
+			)
+
+			BeforeEach(func() {
+				credManagerRunnerSignalled = make(chan struct{})
+				finishRun = make(chan struct{})
+				finishRunIfrit := finishRun
+				var testRunner ifrit.RunFunc = func(signals <-chan os.Signal, ready chan<- struct{}) error {
+					close(ready)
+					<-signals
+					<-finishRunIfrit
+					return nil
+				}
+
+				signalled := credManagerRunnerSignalled
+				megatron.StepsRunnerReturns(testRunner, nil)
+				credManager.RunnerReturns(ifrit.RunFunc(func(signals <-chan os.Signal, ready chan<- struct{}) error {
+					close(ready)
+					<-signals
+					close(signalled)
+					return nil
+				}))
+			})
+
+			JustBeforeEach(func() {
+				err := containerStore.Run(logger, containerGuid)
+				Expect(err).NotTo(HaveOccurred())
+				Eventually(containerState(containerGuid)).Should(Equal(executor.StateRunning))
+				err = containerStore.Stop(logger, containerGuid)
+				Expect(err).NotTo(HaveOccurred())
+				destroyed = make(chan struct{})
+				go func(ch chan struct{}) {
+					containerStore.Destroy(logger, containerGuid)
+					close(ch)
+				}(destroyed)
+			})
+
+			It("cancels the process", func() {
+				Consistently(destroyed).ShouldNot(Receive())
+				close(finishRun)
+				Eventually(destroyed).Should(BeClosed())
+			})
+
+			It("logs that the container is stopping once", func() {
+				close(finishRun)
+				Eventually(destroyed).Should(BeClosed())
+
+				Expect(fakeMetronClient.SendAppLogCallCount()).To(Equal(5))
+				msg, sourceType, tags := fakeMetronClient.SendAppLogArgsForCall(2)
+				Expect(tags["source_id"]).To(Equal(containerGuid))
+				Expect(sourceType).To(Equal("test-source"))
+				Expect(msg).To(Equal(fmt.Sprintf("Cell %s stopping instance %s", cellID, containerGuid)))
+				Expect(tags["instance_id"]).To(Equal("1"))
+			})
+		})
+
+		Context("when there is a running process associated with the container", func() {
+			var (
+				finishRun                  chan struct{}
+				credManagerRunnerSignalled chan struct{}
+				destroyed                  chan struct{}
+			)
+
+			BeforeEach(func() {
+				credManagerRunnerSignalled = make(chan struct{})
+				finishRun = make(chan struct{})
+				finishRunIfrit := finishRun
+				var testRunner ifrit.RunFunc = func(signals <-chan os.Signal, ready chan<- struct{}) error {
+					close(ready)
+					<-signals
+					<-finishRunIfrit
+					return nil
+				}
+
+				signalled := credManagerRunnerSignalled
+				megatron.StepsRunnerReturns(testRunner, nil)
+				credManager.RunnerReturns(ifrit.RunFunc(func(signals <-chan os.Signal, ready chan<- struct{}) error {
+					close(ready)
+					<-signals
+					close(signalled)
+					return nil
+				}))
+			})
+
+			JustBeforeEach(func() {
+				err := containerStore.Run(logger, containerGuid)
+				Expect(err).NotTo(HaveOccurred())
+				Eventually(containerState(containerGuid)).Should(Equal(executor.StateRunning))
+				destroyed = make(chan struct{})
+				go func(ch chan struct{}) {
+					containerStore.Destroy(logger, containerGuid)
+					close(ch)
+				}(destroyed)
+			})
+
+			It("cancels the process", func() {
+				Consistently(destroyed).ShouldNot(Receive())
+				close(finishRun)
+				Eventually(destroyed).Should(BeClosed())
+			})
+
+			It("signals the cred manager runner", func() {
+				close(finishRun)
+				Eventually(credManagerRunnerSignalled).Should(BeClosed())
+			})
+
+			It("logs that the container is stopping", func() {
+				close(finishRun)
+				Eventually(destroyed).Should(BeClosed())
+				Expect(fakeMetronClient.SendAppLogCallCount()).To(Equal(5))
+				msg, sourceType, tags := fakeMetronClient.SendAppLogArgsForCall(2)
+				Expect(tags["source_id"]).To(Equal(containerGuid))
+				Expect(sourceType).To(Equal("test-source"))
+				Expect(msg).To(Equal(fmt.Sprintf("Cell %s stopping instance %s", cellID, containerGuid)))
+				Expect(tags["instance_id"]).To(Equal("1"))
+			})
+
+			Context("when the container credManager config process is running in the container", func() {
+				var (
+					credManagerRunnerSignalled chan struct{}
+				)
+
+				BeforeEach(func() {
+					credManagerRunnerSignalled = make(chan struct{})
+
+					containerStore = containerstore.New(
+						containerConfig,
+						&totalCapacity,
+						gardenClient,
+						dependencyManager,
+						volumeManager,
+						credManager,
+						clock,
+						eventEmitter,
+						megatron,
+						"/var/vcap/data/cf-system-trusted-certs",
+						fakeMetronClient,
+						fakeRootFSSizer,
+						false,
+						"/var/vcap/packages/healthcheck",
+						proxyManager,
+						cellID,
+						true,
+						advertisePreferenceForInstanceAddress,
+						containerstore.NewCompositeAdmissionPolicy(),
+					)
+
+					signalled := credManagerRunnerSignalled
+					runner := &fake_runner.FakeRunner{}
+					runner.RunStub = ifrit.RunFunc(func(signals <-chan os.Signal, ready chan<- struct{}) error {
+						close(ready)
+						<-signals
+						close(signalled)
+						return nil
+					})
+					credManager.RunnerReturns(runner)
+				})
+
+				It("signals the credManager config runner", func() {
+					close(finishRun)
+					Eventually(credManagerRunnerSignalled).Should(BeClosed())
+				})
+			})
+		})
+	})
+
+	Describe("Get", func() {
+		BeforeEach(func() {
+			_, err := containerStore.Reserve(logger, &executor.AllocationRequest{Guid: containerGuid})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("returns the specified container", func() {
+			container, err := containerStore.Get(logger, containerGuid)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(container.Guid).To(Equal(containerGuid))
+		})
+
+		Context("when the container does not exist", func() {
+			It("returns an ErrContainerNotFound", func() {
+				_, err := containerStore.Get(logger, "")
+				Expect(err).To(Equal(executor.ErrContainerNotFound))
+			})
+		})
+	})
+
+	Describe("List", func() {
+		var container1, container2 executor.Container
+
+		BeforeEach(func() {
+			_, err := containerStore.Reserve(logger, &executor.AllocationRequest{
+				Guid: containerGuid,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			err = containerStore.Initialize(logger, &executor.RunRequest{
+				Guid: containerGuid,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = containerStore.Reserve(logger, &executor.AllocationRequest{
+				Guid: containerGuid + "2",
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			container1, err = containerStore.Get(logger, containerGuid)
+			Expect(err).NotTo(HaveOccurred())
+
+			container2, err = containerStore.Get(logger, containerGuid+"2")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("returns the list of known containers", func() {
+			containers := containerStore.List(logger)
+			Expect(containers).To(HaveLen(2))
+			Expect(containers).To(ContainElement(container1))
+			Expect(containers).To(ContainElement(container2))
+		})
+	})
+
+	reserveContainer := func(guid string) {
+		resource := executor.Resource{
+			MemoryMB: 10,
+			DiskMB:   10,
+		}
+		tags := executor.Tags{}
+		_, err := containerStore.Reserve(logger, &executor.AllocationRequest{Guid: guid, Tags: tags, Resource: resource, CPUWeight: 2})
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	initializeContainer := func(guid string, rootFSPath string) {
+		runInfo := executor.RunInfo{
+			RootFSPath:         rootFSPath,
+			CPUWeight:          2,
+			StartTimeoutMs:     50000,
+			Privileged:         true,
+			CachedDependencies: []executor.CachedDependency{},
+			LogConfig: executor.LogConfig{
+				Guid:       "log-guid",
+				Index:      1,
+				SourceName: "test-source",
+			},
+			MetricsConfig: executor.MetricsConfig{
+				Guid:  "metric-guid",
+				Index: 1,
+			},
+			Env:                           []executor.EnvironmentVariable{},
+			TrustedSystemCertificatesPath: "",
+			Network: &executor.Network{
+				Properties: map[string]string{},
+			},
+		}
+
+		req := &executor.RunRequest{
+			Guid:    guid,
+			RunInfo: runInfo,
+			Tags:    executor.Tags{},
+		}
+
+		err := containerStore.Initialize(logger, req)
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	Describe("Metrics", func() {
+		var (
+			containerGuid1, containerGuid2, containerGuid3, containerGuid4 string
+			containerGuid5, containerGuid6                                 string
+		)
+
+		BeforeEach(func() {
+			fakeRootFSSizer.RootFSSizeFromPathStub = func(path string) uint64 {
+				switch path {
+				case "/foo/bar":
+					return 1000
+				default:
+					return 0
+				}
+			}
+
+			containerGuid1 = "container-guid-1"
+			containerGuid2 = "container-guid-2"
+			containerGuid3 = "container-guid-3"
+			containerGuid4 = "container-guid-4"
+			containerGuid5 = "container-guid-5"
+			containerGuid6 = "container-guid-6"
+
+			reserveContainer(containerGuid1)
+			reserveContainer(containerGuid2)
+			reserveContainer(containerGuid3)
+			reserveContainer(containerGuid4)
+			reserveContainer(containerGuid5)
+			reserveContainer(containerGuid6)
+
+			initializeContainer(containerGuid1, "/foo/bar")
+			initializeContainer(containerGuid2, "docker://repo")
+			initializeContainer(containerGuid3, "/foo/bar")
+			initializeContainer(containerGuid4, "/foo/bar")
+
+			gardenContainer.InfoReturns(garden.ContainerInfo{ExternalIP: "6.6.6.6"}, nil)
+			gardenClient.CreateReturns(gardenContainer, nil)
+			_, err := containerStore.Create(logger, containerGuid1)
 			Expect(err).NotTo(HaveOccurred())
 			_, err = containerStore.Create(logger, containerGuid2)
 			Expect(err).ToNot(HaveOccurred())
@@ -2734,150 +3891,952 @@ var _ = Describe("Container Store", func() {
 			_, err = containerStore.Create(logger, containerGuid4)
 			Expect(err).ToNot(HaveOccurred())
 
-			bulkMetrics := map[string]garden.ContainerMetricsEntry{
-				containerGuid1: garden.ContainerMetricsEntry{
-					Metrics: garden.Metrics{
-						MemoryStat: garden.ContainerMemoryStat{
-							TotalUsageTowardLimit: 1024,
-						},
-						DiskStat: garden.ContainerDiskStat{
-							TotalBytesUsed: uint64(1000 + 2048),
-						},
-						CPUStat: garden.ContainerCPUStat{
-							Usage: 5000000000,
-						},
-						Age:            1000000000,
-						CPUEntitlement: 100,
-					},
+			bulkMetrics := map[string]garden.ContainerMetricsEntry{
+				containerGuid1: garden.ContainerMetricsEntry{
+					Metrics: garden.Metrics{
+						MemoryStat: garden.ContainerMemoryStat{
+							TotalUsageTowardLimit: 1024,
+						},
+						DiskStat: garden.ContainerDiskStat{
+							TotalBytesUsed: uint64(1000 + 2048),
+						},
+						CPUStat: garden.ContainerCPUStat{
+							Usage: 5000000000,
+						},
+						Age:            1000000000,
+						CPUEntitlement: 100,
+					},
+				},
+				containerGuid2: garden.ContainerMetricsEntry{
+					Metrics: garden.Metrics{
+						MemoryStat: garden.ContainerMemoryStat{
+							TotalUsageTowardLimit: 512,
+						},
+						DiskStat: garden.ContainerDiskStat{
+							TotalBytesUsed: 256,
+						},
+						CPUStat: garden.ContainerCPUStat{
+							Usage: 1000000,
+						},
+						Age:            2000000000,
+						CPUEntitlement: 200,
+					},
+				},
+				containerGuid4: garden.ContainerMetricsEntry{
+					Err: &garden.Error{Err: errors.New("no-metrics-here")},
+					Metrics: garden.Metrics{
+						MemoryStat: garden.ContainerMemoryStat{
+							TotalUsageTowardLimit: 512,
+						},
+						DiskStat: garden.ContainerDiskStat{
+							TotalBytesUsed: uint64(1000 + 128),
+						},
+						CPUStat: garden.ContainerCPUStat{
+							Usage: 1000000,
+						},
+						Age:            4000000000,
+						CPUEntitlement: 300,
+					},
+				},
+				"BOGUS-GUID": garden.ContainerMetricsEntry{},
+			}
+			gardenClient.BulkMetricsReturns(bulkMetrics, nil)
+		})
+
+		It("returns metrics for all known containers in the running and created state", func() {
+			metrics, err := containerStore.Metrics(logger)
+			Expect(err).NotTo(HaveOccurred())
+			containerSpec1 := gardenClient.CreateArgsForCall(0)
+			containerSpec2 := gardenClient.CreateArgsForCall(1)
+
+			Expect(gardenClient.BulkMetricsCallCount()).To(Equal(1))
+			Expect(gardenClient.BulkMetricsArgsForCall(0)).To(ConsistOf(
+				containerGuid1, containerGuid2, containerGuid3, containerGuid4,
+			))
+
+			Expect(metrics).To(HaveLen(2))
+
+			container1Metrics, ok := metrics[containerGuid1]
+			Expect(ok).To(BeTrue())
+			Expect(container1Metrics.MemoryUsageInBytes).To(BeEquivalentTo(1024))
+			Expect(container1Metrics.DiskUsageInBytes).To(BeEquivalentTo(2048))
+			Expect(container1Metrics.MemoryLimitInBytes).To(BeEquivalentTo(containerSpec1.Limits.Memory.LimitInBytes))
+			Expect(container1Metrics.DiskLimitInBytes).To(BeEquivalentTo(10 * 1024 * 1024))
+			Expect(container1Metrics.TimeSpentInCPU).To(Equal(5 * time.Second))
+			Expect(container1Metrics.ContainerAgeInNanoseconds).To(Equal(uint64(1000000000)))
+			Expect(container1Metrics.AbsoluteCPUEntitlementInNanoseconds).To(Equal(uint64(100)))
+
+			container2Metrics, ok := metrics[containerGuid2]
+			Expect(ok).To(BeTrue())
+			Expect(container2Metrics.MemoryUsageInBytes).To(BeEquivalentTo(512))
+			Expect(container2Metrics.DiskUsageInBytes).To(BeEquivalentTo(256))
+			Expect(container2Metrics.MemoryLimitInBytes).To(BeEquivalentTo(containerSpec2.Limits.Memory.LimitInBytes))
+			Expect(container2Metrics.DiskLimitInBytes).To(BeEquivalentTo(10 * 1024 * 1024))
+			Expect(container2Metrics.TimeSpentInCPU).To(Equal(1 * time.Millisecond))
+			Expect(container2Metrics.ContainerAgeInNanoseconds).To(Equal(uint64(2000000000)))
+			Expect(container2Metrics.AbsoluteCPUEntitlementInNanoseconds).To(Equal(uint64(200)))
+		})
+
+		Context("when fetching bulk metrics fails", func() {
+			BeforeEach(func() {
+				gardenClient.BulkMetricsReturns(nil, errors.New("failed-bulk-metrics"))
+			})
+
+			It("returns an error", func() {
+				_, err := containerStore.Metrics(logger)
+				Expect(err).To(Equal(errors.New("failed-bulk-metrics")))
+			})
+		})
+	})
+
+	Describe("GetFiles", func() {
+		BeforeEach(func() {
+			gardenClient.CreateReturns(gardenContainer, nil)
+			gardenContainer.StreamOutReturns(ioutil.NopCloser(bytes.NewReader([]byte("this is the stream"))), nil)
+		})
+
+		JustBeforeEach(func() {
+			_, err := containerStore.Reserve(logger, &executor.AllocationRequest{Guid: containerGuid})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		Context("when the container has a corresponding garden container", func() {
+			JustBeforeEach(func() {
+				err := containerStore.Initialize(logger, &executor.RunRequest{Guid: containerGuid})
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = containerStore.Create(logger, containerGuid)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("calls streamout on the garden client", func() {
+				stream, err := containerStore.GetFiles(logger, containerGuid, "/path/to/file")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(gardenContainer.StreamOutCallCount()).To(Equal(1))
+				streamOutSpec := gardenContainer.StreamOutArgsForCall(0)
+				Expect(streamOutSpec.Path).To(Equal("/path/to/file"))
+				Expect(streamOutSpec.User).To(Equal("root"))
+
+				output := make([]byte, len("this is the stream"))
+				_, err = stream.Read(output)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(output).To(Equal([]byte("this is the stream")))
+			})
+		})
+
+		Context("when the container does not have a corresponding garden container", func() {
+			It("returns an error", func() {
+				_, err := containerStore.GetFiles(logger, containerGuid, "/path")
+				Expect(err).To(Equal(executor.ErrContainerNotFound))
+			})
+		})
+
+		Context("when the container does not exist", func() {
+			It("returns ErrContainerNotFound", func() {
+				_, err := containerStore.GetFiles(logger, "", "/stuff")
+				Expect(err).To(Equal(executor.ErrContainerNotFound))
+			})
+		})
+	})
+
+	Describe("PutFiles", func() {
+		var tarStream *bytes.Buffer
+
+		BeforeEach(func() {
+			tarStream = bytes.NewBufferString("some-tar")
+			gardenClient.CreateReturns(gardenContainer, nil)
+			megatron.StepsRunnerReturns(ifrit.RunFunc(func(signals <-chan os.Signal, ready chan<- struct{}) error {
+				close(ready)
+				<-signals
+				return nil
+			}), nil)
+		})
+
+		JustBeforeEach(func() {
+			_, err := containerStore.Reserve(logger, &executor.AllocationRequest{Guid: containerGuid})
+			Expect(err).NotTo(HaveOccurred())
+
+			err = containerStore.Initialize(logger, &executor.RunRequest{Guid: containerGuid})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = containerStore.Create(logger, containerGuid)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		Context("when the container is running", func() {
+			JustBeforeEach(func() {
+				Expect(containerStore.Run(logger, containerGuid)).To(Succeed())
+				Eventually(containerState(containerGuid)).Should(Equal(executor.StateRunning))
+			})
+
+			It("streams the tar into the garden container", func() {
+				Expect(containerStore.PutFiles(logger, containerGuid, "/some/path", tarStream, "vcap")).To(Succeed())
+
+				Expect(gardenContainer.StreamInCallCount()).To(Equal(1))
+				spec := gardenContainer.StreamInArgsForCall(0)
+				Expect(spec.Path).To(Equal("/some/path"))
+				Expect(spec.User).To(Equal("vcap"))
+				Expect(spec.TarStream).To(Equal(tarStream))
+			})
+
+			It("streams as root when no user is given", func() {
+				Expect(containerStore.PutFiles(logger, containerGuid, "/some/path", tarStream, "")).To(Succeed())
+				Expect(gardenContainer.StreamInArgsForCall(0).User).To(Equal("root"))
+			})
+
+			It("returns the error when streaming fails", func() {
+				gardenContainer.StreamInReturns(errors.New("boom"))
+				Expect(containerStore.PutFiles(logger, containerGuid, "/some/path", tarStream, "")).To(MatchError("boom"))
+			})
+
+			Context("when the download limiter is full", func() {
+				var limiter chan struct{}
+
+				BeforeEach(func() {
+					limiter = make(chan struct{}, 1)
+					limiter <- struct{}{}
+					containerConfig.DownloadRateLimiter = limiter
+
+					containerStore = containerstore.New(
+						containerConfig,
+						&totalCapacity,
+						gardenClient,
+						dependencyManager,
+						volumeManager,
+						credManager,
+						clock,
+						eventEmitter,
+						megatron,
+						"/var/vcap/data/cf-system-trusted-certs",
+						fakeMetronClient,
+						fakeRootFSSizer,
+						false,
+						"/var/vcap/packages/healthcheck",
+						proxyManager,
+						cellID,
+						true,
+						advertisePreferenceForInstanceAddress,
+						admissionPolicy,
+					)
+				})
+
+				It("waits for a slot before streaming", func() {
+					errCh := make(chan error, 1)
+					go func() {
+						errCh <- containerStore.PutFiles(logger, containerGuid, "/some/path", tarStream, "")
+					}()
+
+					Consistently(gardenContainer.StreamInCallCount).Should(Equal(0))
+
+					<-limiter
+					Eventually(errCh).Should(Receive(BeNil()))
+					Expect(gardenContainer.StreamInCallCount()).To(Equal(1))
+					Expect(limiter).To(BeEmpty())
+				})
+			})
+		})
+
+		Context("when the container is not running", func() {
+			It("returns ErrContainerNotRunning", func() {
+				Expect(containerStore.PutFiles(logger, containerGuid, "/some/path", tarStream, "")).To(Equal(executor.ErrContainerNotRunning))
+				Expect(gardenContainer.StreamInCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when the container does not exist", func() {
+			It("returns ErrContainerNotFound", func() {
+				Expect(containerStore.PutFiles(logger, "missing", "/some/path", tarStream, "")).To(Equal(executor.ErrContainerNotFound))
+			})
+		})
+	})
+
+	Describe("SubscribeToLogs", func() {
+		newContainerStore := func() containerstore.ContainerStore {
+			return containerstore.New(
+				containerConfig,
+				&totalCapacity,
+				gardenClient,
+				dependencyManager,
+				volumeManager,
+				credManager,
+				clock,
+				eventEmitter,
+				megatron,
+				"/var/vcap/data/cf-system-trusted-certs",
+				fakeMetronClient,
+				fakeRootFSSizer,
+				false,
+				"/var/vcap/packages/healthcheck",
+				proxyManager,
+				cellID,
+				true,
+				advertisePreferenceForInstanceAddress,
+				admissionPolicy,
+			)
+		}
+
+		BeforeEach(func() {
+			gardenClient.CreateReturns(gardenContainer, nil)
+		})
+
+		JustBeforeEach(func() {
+			_, err := containerStore.Reserve(logger, &executor.AllocationRequest{Guid: containerGuid})
+			Expect(err).NotTo(HaveOccurred())
+
+			err = containerStore.Initialize(logger, &executor.RunRequest{
+				Guid: containerGuid,
+				RunInfo: executor.RunInfo{
+					LogConfig: executor.LogConfig{Guid: "log-guid", SourceName: "test-source"},
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		Context("when the log tap is enabled", func() {
+			BeforeEach(func() {
+				containerConfig.LogTap = log_streamer.NewTap(10)
+				containerStore = newContainerStore()
+			})
+
+			It("streams the lines logged for the container, starting with the backlog", func() {
+				_, err := containerStore.Create(logger, containerGuid)
+				Expect(err).NotTo(HaveOccurred())
+
+				source, err := containerStore.SubscribeToLogs(logger, containerGuid)
+				Expect(err).NotTo(HaveOccurred())
+				defer source.Close()
+
+				line, err := source.Next()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(line.SourceName).To(Equal("test-source"))
+				Expect(line.Stream).To(Equal(executor.LogStreamStdout))
+				Expect(line.Message).To(Equal(fmt.Sprintf("Cell %s creating container for instance %s", cellID, containerGuid)))
+
+				line, err = source.Next()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(line.Message).To(Equal(fmt.Sprintf("Cell %s successfully created container for instance %s", cellID, containerGuid)))
+			})
+
+			It("closes the subscriptions when the container is destroyed", func() {
+				source, err := containerStore.SubscribeToLogs(logger, containerGuid)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(containerStore.Destroy(logger, containerGuid)).To(Succeed())
+
+				Eventually(func() error {
+					_, err := source.Next()
+					return err
+				}).Should(Equal(log_streamer.ErrReadFromClosedLogSource))
+			})
+
+			It("returns ErrContainerNotFound for unknown containers", func() {
+				_, err := containerStore.SubscribeToLogs(logger, "missing")
+				Expect(err).To(Equal(executor.ErrContainerNotFound))
+			})
+
+			It("returns ErrContainerNotFound once the container is destroyed", func() {
+				Expect(containerStore.Destroy(logger, containerGuid)).To(Succeed())
+
+				_, err := containerStore.SubscribeToLogs(logger, containerGuid)
+				Expect(err).To(Equal(executor.ErrContainerNotFound))
+			})
+		})
+
+		Context("when the log tap is not enabled", func() {
+			It("returns ErrLogTapNotEnabled", func() {
+				_, err := containerStore.SubscribeToLogs(logger, containerGuid)
+				Expect(err).To(Equal(executor.ErrLogTapNotEnabled))
+			})
+		})
+	})
+
+	Describe("recent logs", func() {
+		BeforeEach(func() {
+			gardenClient.CreateReturns(gardenContainer, nil)
+			megatron.StepsRunnerStub = func(_ lager.Logger, _ executor.Container, _ garden.Container, logStreamer log_streamer.LogStreamer, _ transformer.Config) (ifrit.Runner, error) {
+				return ifrit.RunFunc(func(signals <-chan os.Signal, ready chan<- struct{}) error {
+					close(ready)
+					fmt.Fprintln(logStreamer.WithSource("APP/PROC/WEB/0").Stderr(), "config file missing")
+					return errors.New("BOOOOM!!!!")
+				}), nil
+			}
+		})
+
+		JustBeforeEach(func() {
+			containerStore = containerstore.New(
+				containerConfig,
+				&totalCapacity,
+				gardenClient,
+				dependencyManager,
+				volumeManager,
+				credManager,
+				clock,
+				eventEmitter,
+				megatron,
+				"/var/vcap/data/cf-system-trusted-certs",
+				fakeMetronClient,
+				fakeRootFSSizer,
+				false,
+				"/var/vcap/packages/healthcheck",
+				proxyManager,
+				cellID,
+				true,
+				advertisePreferenceForInstanceAddress,
+				admissionPolicy,
+			)
+
+			_, err := containerStore.Reserve(logger, &executor.AllocationRequest{Guid: containerGuid})
+			Expect(err).NotTo(HaveOccurred())
+
+			err = containerStore.Initialize(logger, &executor.RunRequest{
+				Guid: containerGuid,
+				RunInfo: executor.RunInfo{
+					LogConfig: executor.LogConfig{Guid: "log-guid", SourceName: "test-source"},
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = containerStore.Create(logger, containerGuid)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(containerStore.Run(logger, containerGuid)).To(Succeed())
+			Eventually(containerState(containerGuid)).Should(Equal(executor.StateCompleted))
+		})
+
+		completeEvent := func() executor.ContainerCompleteEvent {
+			for i := 0; i < eventEmitter.EmitCallCount(); i++ {
+				if ev, ok := eventEmitter.EmitArgsForCall(i).(executor.ContainerCompleteEvent); ok {
+					return ev
+				}
+			}
+			return executor.ContainerCompleteEvent{}
+		}
+
+		Context("when recent logs are kept", func() {
+			BeforeEach(func() {
+				containerConfig.RecentLogs = log_streamer.NewRecentLogs(1024)
+			})
+
+			It("returns the last lines of every source until the container is deleted", func() {
+				lines, err := containerStore.GetRecentLogs(logger, containerGuid)
+				Expect(err).NotTo(HaveOccurred())
+
+				messages := []string{}
+				for _, line := range lines {
+					messages = append(messages, line.SourceName+": "+line.Message)
+				}
+				Expect(messages).To(Equal([]string{
+					fmt.Sprintf("test-source: Cell %s creating container for instance %s", cellID, containerGuid),
+					fmt.Sprintf("test-source: Cell %s successfully created container for instance %s", cellID, containerGuid),
+					"APP/PROC/WEB/0: config file missing",
+				}))
+
+				Expect(containerStore.Destroy(logger, containerGuid)).To(Succeed())
+				_, err = containerStore.GetRecentLogs(logger, containerGuid)
+				Expect(err).To(Equal(executor.ErrContainerNotFound))
+			})
+
+			It("attaches them to the complete event of the failed container", func() {
+				Eventually(completeEvent).Should(WithTransform(func(ev executor.ContainerCompleteEvent) []executor.LogLine {
+					return ev.RecentLogs
+				}, ContainElement(WithTransform(func(line executor.LogLine) string {
+					return line.Message
+				}, Equal("config file missing")))))
+			})
+		})
+
+		Context("when recent logs are not kept", func() {
+			It("returns ErrRecentLogsNotEnabled", func() {
+				_, err := containerStore.GetRecentLogs(logger, containerGuid)
+				Expect(err).To(Equal(executor.ErrRecentLogsNotEnabled))
+			})
+
+			It("does not attach logs to the complete event", func() {
+				Eventually(completeEvent).Should(WithTransform(func(ev executor.ContainerCompleteEvent) string {
+					return ev.Container().Guid
+				}, Equal(containerGuid)))
+				Expect(completeEvent().RecentLogs).To(BeEmpty())
+			})
+		})
+	})
+
+	Describe("log sink", func() {
+		var fakeSink *mfakes.FakeIngressClient
+
+		BeforeEach(func() {
+			gardenClient.CreateReturns(gardenContainer, nil)
+			fakeSink = &mfakes.FakeIngressClient{}
+			containerConfig.LogSink = fakeSink
+		})
+
+		JustBeforeEach(func() {
+			containerStore = containerstore.New(
+				containerConfig,
+				&totalCapacity,
+				gardenClient,
+				dependencyManager,
+				volumeManager,
+				credManager,
+				clock,
+				eventEmitter,
+				megatron,
+				"/var/vcap/data/cf-system-trusted-certs",
+				fakeMetronClient,
+				fakeRootFSSizer,
+				false,
+				"/var/vcap/packages/healthcheck",
+				proxyManager,
+				cellID,
+				true,
+				advertisePreferenceForInstanceAddress,
+				admissionPolicy,
+			)
+
+			_, err := containerStore.Reserve(logger, &executor.AllocationRequest{Guid: containerGuid})
+			Expect(err).NotTo(HaveOccurred())
+
+			err = containerStore.Initialize(logger, &executor.RunRequest{
+				Guid: containerGuid,
+				RunInfo: executor.RunInfo{
+					LogConfig: executor.LogConfig{Guid: "log-guid", SourceName: "test-source"},
 				},
-				containerGuid2: garden.ContainerMetricsEntry{
-					Metrics: garden.Metrics{
-						MemoryStat: garden.ContainerMemoryStat{
-							TotalUsageTowardLimit: 512,
-						},
-						DiskStat: garden.ContainerDiskStat{
-							TotalBytesUsed: 256,
-						},
-						CPUStat: garden.ContainerCPUStat{
-							Usage: 1000000,
-						},
-						Age:            2000000000,
-						CPUEntitlement: 200,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = containerStore.Create(logger, containerGuid)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("sends the container's logs to the sink instead of Loggregator", func() {
+			Expect(fakeSink.SendAppLogCallCount()).To(Equal(2))
+			message, sourceName, tags := fakeSink.SendAppLogArgsForCall(0)
+			Expect(message).To(Equal(fmt.Sprintf("Cell %s creating container for instance %s", cellID, containerGuid)))
+			Expect(sourceName).To(Equal("test-source"))
+			Expect(tags).To(HaveKeyWithValue("source_id", "log-guid"))
+
+			Expect(fakeMetronClient.SendAppLogCallCount()).To(Equal(0))
+		})
+
+		Context("when the sink holds on to the instances it received lines from", func() {
+			var forgotten chan map[string]string
+
+			BeforeEach(func() {
+				forgotten = make(chan map[string]string, 1)
+				containerConfig.LogSink = forgettingSink{FakeIngressClient: fakeSink, forgotten: forgotten}
+			})
+
+			It("has the sink forget the instance once the container is destroyed", func() {
+				Consistently(forgotten).ShouldNot(Receive())
+
+				Expect(containerStore.Destroy(logger, containerGuid)).To(Succeed())
+				Expect(forgotten).To(Receive(Equal(map[string]string{"source_id": "log-guid", "instance_id": "0"})))
+			})
+		})
+	})
+
+	Describe("log rate limits", func() {
+		var logConfig executor.LogConfig
+
+		BeforeEach(func() {
+			gardenClient.CreateReturns(gardenContainer, nil)
+			containerConfig.LogRateLimitExceededReportInterval = time.Minute
+			logConfig = executor.LogConfig{Guid: "log-guid", SourceName: "test-source", Tags: map[string]string{"app": "the-app"}}
+		})
+
+		JustBeforeEach(func() {
+			containerStore = containerstore.New(
+				containerConfig,
+				&totalCapacity,
+				gardenClient,
+				dependencyManager,
+				volumeManager,
+				credManager,
+				clock,
+				eventEmitter,
+				megatron,
+				"/var/vcap/data/cf-system-trusted-certs",
+				fakeMetronClient,
+				fakeRootFSSizer,
+				false,
+				"/var/vcap/packages/healthcheck",
+				proxyManager,
+				cellID,
+				true,
+				advertisePreferenceForInstanceAddress,
+				admissionPolicy,
+			)
+
+			_, err := containerStore.Reserve(logger, &executor.AllocationRequest{Guid: containerGuid})
+			Expect(err).NotTo(HaveOccurred())
+
+			err = containerStore.Initialize(logger, &executor.RunRequest{
+				Guid:    containerGuid,
+				RunInfo: executor.RunInfo{LogConfig: logConfig},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = containerStore.Create(logger, containerGuid)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		sentLogs := func() []string {
+			messages := []string{}
+			for i := 0; i < fakeMetronClient.SendAppLogCallCount(); i++ {
+				message, _, _ := fakeMetronClient.SendAppLogArgsForCall(i)
+				messages = append(messages, message)
+			}
+			return messages
+		}
+
+		Context("when the container overrides the cell's byte rate limit", func() {
+			BeforeEach(func() {
+				containerConfig.MaxLogBytesPerSecond = 10
+				logConfig.MaxLogBytesPerSecond = 1000
+			})
+
+			It("applies the container's limit", func() {
+				Expect(sentLogs()).To(Equal([]string{
+					fmt.Sprintf("Cell %s creating container for instance %s", cellID, containerGuid),
+					fmt.Sprintf("Cell %s successfully created container for instance %s", cellID, containerGuid),
+				}))
+			})
+
+			Context("when the override is above the operator's ceiling", func() {
+				BeforeEach(func() {
+					containerConfig.MaxLogBytesPerSecondCeiling = 10
+				})
+
+				It("caps it, dropping the lines and reporting them with the container's tags", func() {
+					Expect(sentLogs()).To(Equal([]string{
+						"app instance exceeded log rate limit (10 bytes/sec) set by platform operator",
+					}))
+
+					Expect(fakeMetronClient.SendMetricCallCount()).To(Equal(2))
+					name, value, _ := fakeMetronClient.SendMetricArgsForCall(0)
+					Expect(name).To(Equal(log_streamer.AppInstanceDroppedLogLinesTotal))
+					Expect(value).To(Equal(1))
+
+					_, _, tags := fakeMetronClient.SendAppLogArgsForCall(0)
+					Expect(tags).To(HaveKeyWithValue("app", "the-app"))
+				})
+
+				Context("when the drops are reported as they happen", func() {
+					BeforeEach(func() {
+						containerConfig.LogRateLimitExceededReportInterval = time.Nanosecond
+					})
+
+					It("keeps counting the dropped lines across the container's log streamers", func() {
+						Expect(containerStore.Destroy(logger, containerGuid)).To(Succeed())
+
+						droppedLines := []int{}
+						for i := 0; i < fakeMetronClient.SendMetricCallCount(); i++ {
+							name, value, _ := fakeMetronClient.SendMetricArgsForCall(i)
+							if name == log_streamer.AppInstanceDroppedLogLinesTotal {
+								droppedLines = append(droppedLines, value)
+							}
+						}
+						Expect(droppedLines).To(Equal([]int{1, 2, 3, 4}))
+					})
+				})
+			})
+		})
+	})
+
+	Describe("log redaction", func() {
+		BeforeEach(func() {
+			gardenClient.CreateReturns(gardenContainer, nil)
+			containerConfig.LogRateLimitExceededReportInterval = time.Minute
+		})
+
+		JustBeforeEach(func() {
+			containerStore = containerstore.New(
+				containerConfig,
+				&totalCapacity,
+				gardenClient,
+				dependencyManager,
+				volumeManager,
+				credManager,
+				clock,
+				eventEmitter,
+				megatron,
+				"/var/vcap/data/cf-system-trusted-certs",
+				fakeMetronClient,
+				fakeRootFSSizer,
+				false,
+				"/var/vcap/packages/healthcheck",
+				proxyManager,
+				cellID,
+				true,
+				advertisePreferenceForInstanceAddress,
+				admissionPolicy,
+			)
+
+			_, err := containerStore.Reserve(logger, &executor.AllocationRequest{Guid: containerGuid})
+			Expect(err).NotTo(HaveOccurred())
+
+			err = containerStore.Initialize(logger, &executor.RunRequest{
+				Guid: containerGuid,
+				RunInfo: executor.RunInfo{
+					Env: []executor.EnvironmentVariable{
+						{Name: "database_password", Value: containerGuid},
+						{Name: "THE_CELL", Value: cellID},
+						{Name: "NOT_SECRET", Value: "creating"},
 					},
-				},
-				containerGuid4: garden.ContainerMetricsEntry{
-					Err: &garden.Error{Err: errors.New("no-metrics-here")},
-					Metrics: garden.Metrics{
-						MemoryStat: garden.ContainerMemoryStat{
-							TotalUsageTowardLimit: 512,
-						},
-						DiskStat: garden.ContainerDiskStat{
-							TotalBytesUsed: uint64(1000 + 128),
-						},
-						CPUStat: garden.ContainerCPUStat{
-							Usage: 1000000,
+					LogConfig: executor.LogConfig{
+						Guid:       "log-guid",
+						SourceName: "test-source",
+						Redaction: &executor.LogRedactionConfig{
+							SecretEnvNames: []string{"THE_CELL"},
+							Patterns:       []string{`succ\w+`},
 						},
-						Age:            4000000000,
-						CPUEntitlement: 300,
 					},
 				},
-				"BOGUS-GUID": garden.ContainerMetricsEntry{},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = containerStore.Create(logger, containerGuid)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("masks the values of the secret env vars and the pattern matches", func() {
+			Expect(fakeMetronClient.SendAppLogCallCount()).To(Equal(2))
+			message, _, _ := fakeMetronClient.SendAppLogArgsForCall(0)
+			Expect(message).To(Equal("Cell [REDACTED] creating container for instance [REDACTED]"))
+			message, _, _ = fakeMetronClient.SendAppLogArgsForCall(1)
+			Expect(message).To(Equal("Cell [REDACTED] [REDACTED] created container for instance [REDACTED]"))
+		})
+
+		It("reports the redactions of the container, and their total once the container is created", func() {
+			Expect(fakeMetronClient.SendMetricCallCount()).To(Equal(2))
+			name, value, _ := fakeMetronClient.SendMetricArgsForCall(0)
+			Expect(name).To(Equal(log_streamer.AppInstanceRedactedLogSecretsTotal))
+			Expect(value).To(Equal(2))
+
+			name, value, _ = fakeMetronClient.SendMetricArgsForCall(1)
+			Expect(name).To(Equal(log_streamer.AppInstanceRedactedLogSecretsTotal))
+			Expect(value).To(Equal(5))
+		})
+	})
+
+	Describe("ExportData", func() {
+		var runInfo executor.RunInfo
+
+		BeforeEach(func() {
+			gardenClient.CreateReturns(gardenContainer, nil)
+			gardenContainer.StreamOutReturns(ioutil.NopCloser(bytes.NewReader([]byte("home-data"))), nil)
+
+			runInfo = executor.RunInfo{
+				RootFSPath: "/foo/bar",
+				Ports:      []executor.PortMapping{{ContainerPort: 8080}},
+				Env:        []executor.EnvironmentVariable{{Name: "FOO", Value: "bar"}},
 			}
-			gardenClient.BulkMetricsReturns(bulkMetrics, nil)
+
+			_, err := containerStore.Reserve(logger, &executor.AllocationRequest{
+				Guid:     containerGuid,
+				Resource: executor.Resource{MemoryMB: 512, DiskMB: 256},
+				Tags:     executor.Tags{"Foo": "bar"},
+			})
+			Expect(err).NotTo(HaveOccurred())
 		})
 
-		It("returns metrics for all known containers in the running and created state", func() {
-			metrics, err := containerStore.Metrics(logger)
-			Expect(err).NotTo(HaveOccurred())
-			containerSpec1 := gardenClient.CreateArgsForCall(0)
-			containerSpec2 := gardenClient.CreateArgsForCall(1)
+		Context("when the container has been created", func() {
+			BeforeEach(func() {
+				err := containerStore.Initialize(logger, &executor.RunRequest{Guid: containerGuid, RunInfo: runInfo})
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = containerStore.Create(logger, containerGuid)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("streams out the contents of the data path", func() {
+				dest := &bytes.Buffer{}
+				Expect(containerStore.ExportData(logger, containerGuid, dest)).To(Succeed())
+
+				Expect(gardenContainer.StreamOutCallCount()).To(Equal(1))
+				streamOutSpec := gardenContainer.StreamOutArgsForCall(0)
+				Expect(streamOutSpec.Path).To(Equal("/home/vcap/"))
+				Expect(streamOutSpec.User).To(Equal("root"))
+
+				Expect(dest.String()).To(HaveSuffix("\nhome-data"))
+			})
+
+			Context("when a data path is configured", func() {
+				BeforeEach(func() {
+					containerConfig.DataPath = "/data"
+					containerStore = containerstore.New(
+						containerConfig,
+						&totalCapacity,
+						gardenClient,
+						dependencyManager,
+						volumeManager,
+						credManager,
+						clock,
+						eventEmitter,
+						megatron,
+						"/var/vcap/data/cf-system-trusted-certs",
+						fakeMetronClient,
+						fakeRootFSSizer,
+						false,
+						"/var/vcap/packages/healthcheck",
+						proxyManager,
+						cellID,
+						true,
+						advertisePreferenceForInstanceAddress,
+						admissionPolicy,
+					)
+					_, err := containerStore.Reserve(logger, &executor.AllocationRequest{Guid: containerGuid})
+					Expect(err).NotTo(HaveOccurred())
+
+					err = containerStore.Initialize(logger, &executor.RunRequest{Guid: containerGuid})
+					Expect(err).NotTo(HaveOccurred())
+
+					_, err = containerStore.Create(logger, containerGuid)
+					Expect(err).NotTo(HaveOccurred())
+				})
+
+				It("streams out the configured path", func() {
+					Expect(containerStore.ExportData(logger, containerGuid, &bytes.Buffer{})).To(Succeed())
+
+					streamOutSpec := gardenContainer.StreamOutArgsForCall(0)
+					Expect(streamOutSpec.Path).To(Equal("/data/"))
+				})
+			})
+
+			Context("when streaming out fails", func() {
+				BeforeEach(func() {
+					gardenContainer.StreamOutReturns(nil, errors.New("boom"))
+				})
+
+				It("returns the error without writing an export", func() {
+					dest := &bytes.Buffer{}
+					Expect(containerStore.ExportData(logger, containerGuid, dest)).To(MatchError("boom"))
+					Expect(dest.Len()).To(BeZero())
+				})
+			})
+
+			Describe("ImportData", func() {
+				var (
+					export       *bytes.Buffer
+					importedGuid string
+					streamedIn   chan string
+				)
+
+				BeforeEach(func() {
+					importedGuid = "imported-guid"
+					streamedIn = make(chan string, 1)
+
+					export = &bytes.Buffer{}
+					Expect(containerStore.ExportData(logger, containerGuid, export)).To(Succeed())
+
+					gardenContainer.StreamInStub = func(spec garden.StreamInSpec) error {
+						data, err := ioutil.ReadAll(spec.TarStream)
+						Expect(err).NotTo(HaveOccurred())
+						streamedIn <- string(data)
+						return nil
+					}
+
+					megatron.StepsRunnerReturns(ifrit.RunFunc(func(signals <-chan os.Signal, ready chan<- struct{}) error {
+						close(ready)
+						<-signals
+						return nil
+					}), nil)
+				})
 
-			Expect(gardenClient.BulkMetricsCallCount()).To(Equal(1))
-			Expect(gardenClient.BulkMetricsArgsForCall(0)).To(ConsistOf(
-				containerGuid1, containerGuid2, containerGuid3, containerGuid4,
-			))
+				It("recreates the container under the new guid with the exported request", func() {
+					Expect(containerStore.ImportData(logger, importedGuid, export)).To(Succeed())
 
-			Expect(metrics).To(HaveLen(2))
+					Expect(gardenClient.CreateCallCount()).To(Equal(2))
+					containerSpec := gardenClient.CreateArgsForCall(1)
+					Expect(containerSpec.Handle).To(Equal(importedGuid))
+					Expect(containerSpec.Image.URI).To(Equal("/foo/bar"))
 
-			container1Metrics, ok := metrics[containerGuid1]
-			Expect(ok).To(BeTrue())
-			Expect(container1Metrics.MemoryUsageInBytes).To(BeEquivalentTo(1024))
-			Expect(container1Metrics.DiskUsageInBytes).To(BeEquivalentTo(2048))
-			Expect(container1Metrics.MemoryLimitInBytes).To(BeEquivalentTo(containerSpec1.Limits.Memory.LimitInBytes))
-			Expect(container1Metrics.DiskLimitInBytes).To(BeEquivalentTo(10 * 1024 * 1024))
-			Expect(container1Metrics.TimeSpentInCPU).To(Equal(5 * time.Second))
-			Expect(container1Metrics.ContainerAgeInNanoseconds).To(Equal(uint64(1000000000)))
-			Expect(container1Metrics.AbsoluteCPUEntitlementInNanoseconds).To(Equal(uint64(100)))
+					container, err := containerStore.Get(logger, importedGuid)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(container.Resource).To(Equal(executor.Resource{MemoryMB: 512, DiskMB: 256}))
+					Expect(container.Tags).To(Equal(executor.Tags{"Foo": "bar"}))
+					Expect(container.Env).To(Equal(runInfo.Env))
+				})
 
-			container2Metrics, ok := metrics[containerGuid2]
-			Expect(ok).To(BeTrue())
-			Expect(container2Metrics.MemoryUsageInBytes).To(BeEquivalentTo(512))
-			Expect(container2Metrics.DiskUsageInBytes).To(BeEquivalentTo(256))
-			Expect(container2Metrics.MemoryLimitInBytes).To(BeEquivalentTo(containerSpec2.Limits.Memory.LimitInBytes))
-			Expect(container2Metrics.DiskLimitInBytes).To(BeEquivalentTo(10 * 1024 * 1024))
-			Expect(container2Metrics.TimeSpentInCPU).To(Equal(1 * time.Millisecond))
-			Expect(container2Metrics.ContainerAgeInNanoseconds).To(Equal(uint64(2000000000)))
-			Expect(container2Metrics.AbsoluteCPUEntitlementInNanoseconds).To(Equal(uint64(200)))
-		})
+				It("streams the data into the data path before running the container", func() {
+					Expect(containerStore.ImportData(logger, importedGuid, export)).To(Succeed())
 
-		Context("when fetching bulk metrics fails", func() {
-			BeforeEach(func() {
-				gardenClient.BulkMetricsReturns(nil, errors.New("failed-bulk-metrics"))
-			})
+					Expect(gardenContainer.StreamInCallCount()).To(Equal(1))
+					streamInSpec := gardenContainer.StreamInArgsForCall(0)
+					Expect(streamInSpec.Path).To(Equal("/home/vcap"))
+					Expect(streamInSpec.User).To(Equal("root"))
+					Expect(streamedIn).To(Receive(Equal("home-data")))
 
-			It("returns an error", func() {
-				_, err := containerStore.Metrics(logger)
-				Expect(err).To(Equal(errors.New("failed-bulk-metrics")))
-			})
-		})
-	})
+					Expect(megatron.StepsRunnerCallCount()).To(Equal(1))
+					Eventually(containerState(importedGuid)).Should(Equal(executor.StateRunning))
+				})
 
-	Describe("GetFiles", func() {
-		BeforeEach(func() {
-			gardenClient.CreateReturns(gardenContainer, nil)
-			gardenContainer.StreamOutReturns(ioutil.NopCloser(bytes.NewReader([]byte("this is the stream"))), nil)
-		})
+				Context("when streaming in fails", func() {
+					BeforeEach(func() {
+						gardenContainer.StreamInStub = nil
+						gardenContainer.StreamInReturns(errors.New("boom"))
+					})
 
-		JustBeforeEach(func() {
-			_, err := containerStore.Reserve(logger, &executor.AllocationRequest{Guid: containerGuid})
-			Expect(err).NotTo(HaveOccurred())
-		})
+					It("destroys the container without running it", func() {
+						remaining := containerStore.RemainingResources(logger)
 
-		Context("when the container has a corresponding garden container", func() {
-			JustBeforeEach(func() {
-				err := containerStore.Initialize(logger, &executor.RunRequest{Guid: containerGuid})
-				Expect(err).NotTo(HaveOccurred())
+						Expect(containerStore.ImportData(logger, importedGuid, export)).To(MatchError("boom"))
 
-				_, err = containerStore.Create(logger, containerGuid)
-				Expect(err).NotTo(HaveOccurred())
-			})
+						_, err := containerStore.Get(logger, importedGuid)
+						Expect(err).To(Equal(executor.ErrContainerNotFound))
+						Expect(megatron.StepsRunnerCallCount()).To(Equal(0))
 
-			It("calls streamout on the garden client", func() {
-				stream, err := containerStore.GetFiles(logger, containerGuid, "/path/to/file")
-				Expect(err).NotTo(HaveOccurred())
+						Expect(gardenClient.DestroyCallCount()).To(Equal(1))
+						Expect(gardenClient.DestroyArgsForCall(0)).To(Equal(importedGuid))
+						Expect(containerStore.RemainingResources(logger)).To(Equal(remaining))
+					})
+				})
 
-				Expect(gardenContainer.StreamOutCallCount()).To(Equal(1))
-				streamOutSpec := gardenContainer.StreamOutArgsForCall(0)
-				Expect(streamOutSpec.Path).To(Equal("/path/to/file"))
-				Expect(streamOutSpec.User).To(Equal("root"))
+				Context("when creating the container fails", func() {
+					BeforeEach(func() {
+						gardenClient.CreateReturns(nil, errors.New("boom"))
+					})
 
-				output := make([]byte, len("this is the stream"))
-				_, err = stream.Read(output)
-				Expect(err).NotTo(HaveOccurred())
-				Expect(output).To(Equal([]byte("this is the stream")))
+					It("does not leave the reservation behind", func() {
+						remaining := containerStore.RemainingResources(logger)
+
+						Expect(containerStore.ImportData(logger, importedGuid, export)).NotTo(Succeed())
+
+						_, err := containerStore.Get(logger, importedGuid)
+						Expect(err).To(Equal(executor.ErrContainerNotFound))
+						Expect(containerStore.RemainingResources(logger)).To(Equal(remaining))
+					})
+				})
+
+				Context("when the export is invalid", func() {
+					It("returns ErrInvalidContainerExport without reserving a container", func() {
+						err := containerStore.ImportData(logger, importedGuid, bytes.NewBufferString("garbage"))
+						Expect(err).To(Equal(executor.ErrInvalidContainerExport))
+
+						_, err = containerStore.Get(logger, importedGuid)
+						Expect(err).To(Equal(executor.ErrContainerNotFound))
+					})
+				})
+
+				Context("when the guid is already in use", func() {
+					It("returns ErrContainerGuidNotAvailable", func() {
+						err := containerStore.ImportData(logger, containerGuid, export)
+						Expect(err).To(Equal(executor.ErrContainerGuidNotAvailable))
+					})
+				})
 			})
 		})
 
-		Context("when the container does not have a corresponding garden container", func() {
-			It("returns an error", func() {
-				_, err := containerStore.GetFiles(logger, containerGuid, "/path")
-				Expect(err).To(Equal(executor.ErrContainerNotFound))
+		Context("when the container has not been created", func() {
+			It("returns ErrContainerNotExportable", func() {
+				err := containerStore.ExportData(logger, containerGuid, &bytes.Buffer{})
+				Expect(err).To(Equal(executor.ErrContainerNotExportable))
 			})
 		})
 
 		Context("when the container does not exist", func() {
 			It("returns ErrContainerNotFound", func() {
-				_, err := containerStore.GetFiles(logger, "", "/stuff")
+				err := containerStore.ExportData(logger, "missing", &bytes.Buffer{})
 				Expect(err).To(Equal(executor.ErrContainerNotFound))
 			})
 		})
@@ -3012,7 +4971,7 @@ var _ = Describe("Container Store", func() {
 			err = containerStore.Stop(logger, containerGuid6)
 			Expect(err).NotTo(HaveOccurred())
 
-			Eventually(eventEmitter.EmitCallCount).Should(Equal(7))
+			Eventually(eventEmitter.EmitCallCount).Should(Equal(16))
 
 			extraGardenContainer = &gardenfakes.FakeContainer{}
 			extraGardenContainer.HandleReturns("foobar")
@@ -3058,8 +5017,12 @@ var _ = Describe("Container Store", func() {
 			events = append(events, eventEmitter.EmitArgsForCall(initialEmitCallCount))
 			events = append(events, eventEmitter.EmitArgsForCall(initialEmitCallCount+1))
 
-			Expect(events).To(ContainElement(executor.ContainerCompleteEvent{RawContainer: container4}))
-			Expect(events).To(ContainElement(executor.ContainerCompleteEvent{RawContainer: container5}))
+			reaped := executor.EventMetadata{
+				Timestamp:     clock.Now().UnixNano(),
+				PreviousState: executor.StateCreated,
+			}
+			Expect(events).To(ContainElement(executor.ContainerCompleteEvent{RawContainer: container4, EventMetadata: reaped}))
+			Expect(events).To(ContainElement(executor.ContainerCompleteEvent{RawContainer: container5, EventMetadata: reaped}))
 
 			Expect(gardenClient.ContainersCallCount()).To(Equal(2))
 
@@ -3147,4 +5110,335 @@ var _ = Describe("Container Store", func() {
 			})
 		})
 	})
+
+	Describe("Restore", func() {
+		var (
+			stateDir      string
+			journalWriter ifrit.Process
+			allocationReq *executor.AllocationRequest
+			runReq        *executor.RunRequest
+		)
+
+		newContainerStore := func() containerstore.ContainerStore {
+			return containerstore.New(
+				containerConfig,
+				&totalCapacity,
+				gardenClient,
+				dependencyManager,
+				volumeManager,
+				credManager,
+				clock,
+				eventEmitter,
+				megatron,
+				"/var/vcap/data/cf-system-trusted-certs",
+				fakeMetronClient,
+				fakeRootFSSizer,
+				false,
+				"/var/vcap/packages/healthcheck",
+				proxyManager,
+				cellID,
+				true,
+				advertisePreferenceForInstanceAddress,
+				containerstore.NewCompositeAdmissionPolicy(),
+			)
+		}
+
+		BeforeEach(func() {
+			var err error
+			stateDir, err = ioutil.TempDir("", "containerstore-state")
+			Expect(err).NotTo(HaveOccurred())
+
+			containerConfig.StateFilePath = filepath.Join(stateDir, "state.json")
+			containerStore = newContainerStore()
+			journalWriter = ginkgomon.Invoke(containerStore.NewStateJournalWriter(logger))
+
+			allocationReq = &executor.AllocationRequest{
+				Guid:     containerGuid,
+				Resource: executor.Resource{MemoryMB: 1024, DiskMB: 1024},
+				Tags:     executor.Tags{"Foo": "Bar"},
+			}
+
+			runReq = &executor.RunRequest{
+				Guid: containerGuid,
+				RunInfo: executor.RunInfo{
+					LogConfig: executor.LogConfig{
+						Guid: containerGuid,
+					},
+				},
+			}
+
+			gardenClient.CreateReturns(gardenContainer, nil)
+		})
+
+		AfterEach(func() {
+			ginkgomon.Interrupt(journalWriter)
+			os.RemoveAll(stateDir)
+		})
+
+		// restart stops the journal writer of the store, which writes the
+		// journal one last time, and returns a new store using the journal
+		restart := func() containerstore.ContainerStore {
+			ginkgomon.Interrupt(journalWriter)
+			return newContainerStore()
+		}
+
+		Context("when there is no state file", func() {
+			It("restores nothing", func() {
+				Expect(containerStore.Restore(logger)).To(Succeed())
+				Expect(containerStore.List(logger)).To(BeEmpty())
+			})
+		})
+
+		Context("when a container was being stopped to make room for another", func() {
+			BeforeEach(func() {
+				state := fmt.Sprintf(`{%q: {"container": {"guid": %q, "state": "running", "memory_mb": 1024}, "preempted": true}}`, containerGuid, containerGuid)
+				err := ioutil.WriteFile(containerConfig.StateFilePath, []byte(state), 0600)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("completes it as preempted without reattaching to it", func() {
+				Expect(containerStore.Restore(logger)).To(Succeed())
+
+				container, err := containerStore.Get(logger, containerGuid)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(container.State).To(Equal(executor.StateCompleted))
+				Expect(container.RunResult.FailureCode).To(Equal(executor.FailureCodePreempted))
+				Expect(container.RunResult.Retryable).To(BeTrue())
+				Expect(gardenClient.LookupCallCount()).To(Equal(0))
+			})
+
+			It("does not hold the resources it handed over", func() {
+				Expect(containerStore.Restore(logger)).To(Succeed())
+				Expect(containerStore.RemainingResources(logger)).To(Equal(totalCapacity))
+
+				Expect(containerStore.Destroy(logger, containerGuid)).To(Succeed())
+				Expect(containerStore.RemainingResources(logger)).To(Equal(totalCapacity))
+			})
+		})
+
+		Context("when the state file is corrupt", func() {
+			BeforeEach(func() {
+				err := ioutil.WriteFile(containerConfig.StateFilePath, []byte("{{"), 0600)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("returns an error", func() {
+				Expect(containerStore.Restore(logger)).NotTo(Succeed())
+			})
+		})
+
+		Context("when a container was reserved", func() {
+			BeforeEach(func() {
+				_, err := containerStore.Reserve(logger, allocationReq)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("journals it in the background", func() {
+				Eventually(containerConfig.StateFilePath).Should(BeAnExistingFile())
+
+				data, err := ioutil.ReadFile(containerConfig.StateFilePath)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(data)).To(ContainSubstring(containerGuid))
+			})
+
+			It("restores the reservation and its resources", func() {
+				restored := restart()
+				Expect(restored.Restore(logger)).To(Succeed())
+
+				container, err := restored.Get(logger, containerGuid)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(container.State).To(Equal(executor.StateReserved))
+				Expect(container.Resource).To(Equal(allocationReq.Resource))
+				Expect(container.Tags).To(Equal(allocationReq.Tags))
+
+				Expect(restored.RemainingResources(logger)).To(Equal(containerStore.RemainingResources(logger)))
+			})
+
+			Context("and then destroyed", func() {
+				BeforeEach(func() {
+					Expect(containerStore.Destroy(logger, containerGuid)).To(Succeed())
+				})
+
+				It("does not restore it", func() {
+					restored := restart()
+					Expect(restored.Restore(logger)).To(Succeed())
+
+					_, err := restored.Get(logger, containerGuid)
+					Expect(err).To(Equal(executor.ErrContainerNotFound))
+				})
+			})
+		})
+
+		Context("when a container was still being set up", func() {
+			BeforeEach(func() {
+				_, err := containerStore.Reserve(logger, allocationReq)
+				Expect(err).NotTo(HaveOccurred())
+
+				err = containerStore.Initialize(logger, runReq)
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = containerStore.Create(logger, containerGuid)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("completes it as a retryable failure", func() {
+				restored := restart()
+				Expect(restored.Restore(logger)).To(Succeed())
+
+				container, err := restored.Get(logger, containerGuid)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(container.State).To(Equal(executor.StateCompleted))
+				Expect(container.RunResult.Failed).To(BeTrue())
+				Expect(container.RunResult.Retryable).To(BeTrue())
+				Expect(container.RunResult.FailureReason).To(Equal(containerstore.ContainerRestoreFailedMessage))
+				Expect(container.RunResult.FailureCode).To(Equal(executor.FailureCodeRestoreFailed))
+			})
+		})
+
+		Context("when a container was running", func() {
+			var (
+				restored          containerstore.ContainerStore
+				restoredContainer *gardenfakes.FakeContainer
+				signalled         chan os.Signal
+			)
+
+			BeforeEach(func() {
+				signalled = make(chan os.Signal, 1)
+				megatron.StepsRunnerStub = func(_ lager.Logger, _ executor.Container, _ garden.Container, _ log_streamer.LogStreamer, cfg transformer.Config) (ifrit.Runner, error) {
+					if cfg.ProcessNamed != nil {
+						cfg.ProcessNamed(containerGuid + "-action-0")
+						cfg.ProcessNamed(containerGuid + "-envoy")
+					}
+					return ifrit.RunFunc(func(signals <-chan os.Signal, ready chan<- struct{}) error {
+						close(ready)
+						signalled <- <-signals
+						return nil
+					}), nil
+				}
+
+				_, err := containerStore.Reserve(logger, allocationReq)
+				Expect(err).NotTo(HaveOccurred())
+
+				err = containerStore.Initialize(logger, runReq)
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = containerStore.Create(logger, containerGuid)
+				Expect(err).NotTo(HaveOccurred())
+
+				err = containerStore.Run(logger, containerGuid)
+				Expect(err).NotTo(HaveOccurred())
+				Eventually(containerState(containerGuid)).Should(Equal(executor.StateRunning))
+
+				restoredContainer = &gardenfakes.FakeContainer{}
+				restoredContainer.InfoReturns(garden.ContainerInfo{ProcessIDs: []string{
+					containerGuid + "-action-0",
+					containerGuid + "-envoy",
+					"health-check-probe",
+				}}, nil)
+				gardenClient.LookupReturns(restoredContainer, nil)
+
+				restored = restart()
+			})
+
+			It("rebuilds the runner of the container, attaching to its processes", func() {
+				Expect(restored.Restore(logger)).To(Succeed())
+
+				Expect(gardenClient.LookupCallCount()).To(Equal(1))
+				Expect(gardenClient.LookupArgsForCall(0)).To(Equal(containerGuid))
+
+				container, err := restored.Get(logger, containerGuid)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(container.State).To(Equal(executor.StateRunning))
+
+				Expect(megatron.StepsRunnerCallCount()).To(Equal(2))
+				_, _, gardenContainer, _, cfg := megatron.StepsRunnerArgsForCall(1)
+				Expect(gardenContainer).To(Equal(restoredContainer))
+				Expect(cfg.Reattach).To(BeTrue())
+				Expect(cfg.MonitorGate).NotTo(BeNil())
+				Expect(cfg.HealthObserver).NotTo(BeNil())
+				Expect(restoredContainer.RunCallCount()).To(Equal(0))
+			})
+
+			It("stops the reattached runner when the container is stopped", func() {
+				Expect(restored.Restore(logger)).To(Succeed())
+
+				Expect(restored.Stop(logger, containerGuid)).To(Succeed())
+				Eventually(signalled).Should(Receive())
+			})
+
+			Context("when a process of the action is no longer running", func() {
+				BeforeEach(func() {
+					restoredContainer.InfoReturns(garden.ContainerInfo{ProcessIDs: []string{
+						containerGuid + "-envoy",
+						"health-check-probe",
+					}}, nil)
+				})
+
+				It("completes the container as a retryable failure", func() {
+					Expect(restored.Restore(logger)).To(Succeed())
+
+					container, err := restored.Get(logger, containerGuid)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(container.State).To(Equal(executor.StateCompleted))
+					Expect(container.RunResult.Retryable).To(BeTrue())
+					Expect(container.RunResult.FailureCode).To(Equal(executor.FailureCodeRestoreFailed))
+					Expect(megatron.StepsRunnerCallCount()).To(Equal(1))
+				})
+			})
+
+			Context("when the garden container no longer exists", func() {
+				BeforeEach(func() {
+					gardenClient.LookupReturns(nil, garden.ContainerNotFoundError{Handle: containerGuid})
+				})
+
+				It("completes the container", func() {
+					Expect(restored.Restore(logger)).To(Succeed())
+
+					container, err := restored.Get(logger, containerGuid)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(container.State).To(Equal(executor.StateCompleted))
+					Expect(container.RunResult.Failed).To(BeTrue())
+					Expect(container.RunResult.FailureReason).To(Equal(containerstore.ContainerMissingMessage))
+					Expect(container.RunResult.FailureCode).To(Equal(executor.FailureCodeContainerMissing))
+				})
+			})
+		})
+	})
 })
+
+type forgettingSink struct {
+	*mfakes.FakeIngressClient
+	forgotten chan map[string]string
+}
+
+func (s forgettingSink) ForgetInstance(tags map[string]string) {
+	s.forgotten <- tags
+}
+
+// taggedCounterClient records the counter envelopes sent through EmitCounter.
+type taggedCounterClient struct {
+	*mfakes.FakeIngressClient
+
+	lock     sync.Mutex
+	counters []*loggregator_v2.Envelope
+}
+
+func (c *taggedCounterClient) EmitCounter(name string, opts ...loggregator.EmitCounterOption) {
+	envelope := &loggregator_v2.Envelope{
+		Message: &loggregator_v2.Envelope_Counter{Counter: &loggregator_v2.Counter{Name: name}},
+		Tags:    map[string]string{},
+	}
+	for _, opt := range opts {
+		opt(envelope)
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.counters = append(c.counters, envelope)
+}
+
+func (c *taggedCounterClient) Counters() []*loggregator_v2.Envelope {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return append([]*loggregator_v2.Envelope{}, c.counters...)
+}
@@ -43,6 +43,19 @@ type FakeContainerStore struct {
 	destroyReturnsOnCall map[int]struct {
 		result1 error
 	}
+	ExportDataStub        func(lager.Logger, string, io.Writer) error
+	exportDataMutex       sync.RWMutex
+	exportDataArgsForCall []struct {
+		arg1 lager.Logger
+		arg2 string
+		arg3 io.Writer
+	}
+	exportDataReturns struct {
+		result1 error
+	}
+	exportDataReturnsOnCall map[int]struct {
+		result1 error
+	}
 	GetStub        func(lager.Logger, string) (executor.Container, error)
 	getMutex       sync.RWMutex
 	getArgsForCall []struct {
@@ -72,6 +85,33 @@ type FakeContainerStore struct {
 		result1 io.ReadCloser
 		result2 error
 	}
+	GetRecentLogsStub        func(lager.Logger, string) ([]executor.LogLine, error)
+	getRecentLogsMutex       sync.RWMutex
+	getRecentLogsArgsForCall []struct {
+		arg1 lager.Logger
+		arg2 string
+	}
+	getRecentLogsReturns struct {
+		result1 []executor.LogLine
+		result2 error
+	}
+	getRecentLogsReturnsOnCall map[int]struct {
+		result1 []executor.LogLine
+		result2 error
+	}
+	ImportDataStub        func(lager.Logger, string, io.Reader) error
+	importDataMutex       sync.RWMutex
+	importDataArgsForCall []struct {
+		arg1 lager.Logger
+		arg2 string
+		arg3 io.Reader
+	}
+	importDataReturns struct {
+		result1 error
+	}
+	importDataReturnsOnCall map[int]struct {
+		result1 error
+	}
 	InitializeStub        func(lager.Logger, *executor.RunRequest) error
 	initializeMutex       sync.RWMutex
 	initializeArgsForCall []struct {
@@ -130,6 +170,44 @@ type FakeContainerStore struct {
 	newRegistryPrunerReturnsOnCall map[int]struct {
 		result1 ifrit.Runner
 	}
+	NewStateJournalWriterStub        func(lager.Logger) ifrit.Runner
+	newStateJournalWriterMutex       sync.RWMutex
+	newStateJournalWriterArgsForCall []struct {
+		arg1 lager.Logger
+	}
+	newStateJournalWriterReturns struct {
+		result1 ifrit.Runner
+	}
+	newStateJournalWriterReturnsOnCall map[int]struct {
+		result1 ifrit.Runner
+	}
+	PauseStub        func(lager.Logger, string) error
+	pauseMutex       sync.RWMutex
+	pauseArgsForCall []struct {
+		arg1 lager.Logger
+		arg2 string
+	}
+	pauseReturns struct {
+		result1 error
+	}
+	pauseReturnsOnCall map[int]struct {
+		result1 error
+	}
+	PutFilesStub        func(lager.Logger, string, string, io.Reader, string) error
+	putFilesMutex       sync.RWMutex
+	putFilesArgsForCall []struct {
+		arg1 lager.Logger
+		arg2 string
+		arg3 string
+		arg4 io.Reader
+		arg5 string
+	}
+	putFilesReturns struct {
+		result1 error
+	}
+	putFilesReturnsOnCall map[int]struct {
+		result1 error
+	}
 	RemainingResourcesStub        func(lager.Logger) executor.ExecutorResources
 	remainingResourcesMutex       sync.RWMutex
 	remainingResourcesArgsForCall []struct {
@@ -155,6 +233,29 @@ type FakeContainerStore struct {
 		result1 executor.Container
 		result2 error
 	}
+	RestoreStub        func(lager.Logger) error
+	restoreMutex       sync.RWMutex
+	restoreArgsForCall []struct {
+		arg1 lager.Logger
+	}
+	restoreReturns struct {
+		result1 error
+	}
+	restoreReturnsOnCall map[int]struct {
+		result1 error
+	}
+	ResumeStub        func(lager.Logger, string) error
+	resumeMutex       sync.RWMutex
+	resumeArgsForCall []struct {
+		arg1 lager.Logger
+		arg2 string
+	}
+	resumeReturns struct {
+		result1 error
+	}
+	resumeReturnsOnCall map[int]struct {
+		result1 error
+	}
 	RunStub        func(lager.Logger, string) error
 	runMutex       sync.RWMutex
 	runArgsForCall []struct {
@@ -167,6 +268,22 @@ type FakeContainerStore struct {
 	runReturnsOnCall map[int]struct {
 		result1 error
 	}
+	RunProcessStub        func(lager.Logger, string, executor.ProcessSpec, executor.ProcessIO) (executor.Process, error)
+	runProcessMutex       sync.RWMutex
+	runProcessArgsForCall []struct {
+		arg1 lager.Logger
+		arg2 string
+		arg3 executor.ProcessSpec
+		arg4 executor.ProcessIO
+	}
+	runProcessReturns struct {
+		result1 executor.Process
+		result2 error
+	}
+	runProcessReturnsOnCall map[int]struct {
+		result1 executor.Process
+		result2 error
+	}
 	StopStub        func(lager.Logger, string) error
 	stopMutex       sync.RWMutex
 	stopArgsForCall []struct {
@@ -179,6 +296,33 @@ type FakeContainerStore struct {
 	stopReturnsOnCall map[int]struct {
 		result1 error
 	}
+	SubscribeToLogsStub        func(lager.Logger, string) (executor.LogSource, error)
+	subscribeToLogsMutex       sync.RWMutex
+	subscribeToLogsArgsForCall []struct {
+		arg1 lager.Logger
+		arg2 string
+	}
+	subscribeToLogsReturns struct {
+		result1 executor.LogSource
+		result2 error
+	}
+	subscribeToLogsReturnsOnCall map[int]struct {
+		result1 executor.LogSource
+		result2 error
+	}
+	UpdateLimitsStub        func(lager.Logger, string, executor.Resource) error
+	updateLimitsMutex       sync.RWMutex
+	updateLimitsArgsForCall []struct {
+		arg1 lager.Logger
+		arg2 string
+		arg3 executor.Resource
+	}
+	updateLimitsReturns struct {
+		result1 error
+	}
+	updateLimitsReturnsOnCall map[int]struct {
+		result1 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -342,6 +486,69 @@ func (fake *FakeContainerStore) DestroyReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *FakeContainerStore) ExportData(arg1 lager.Logger, arg2 string, arg3 io.Writer) error {
+	fake.exportDataMutex.Lock()
+	ret, specificReturn := fake.exportDataReturnsOnCall[len(fake.exportDataArgsForCall)]
+	fake.exportDataArgsForCall = append(fake.exportDataArgsForCall, struct {
+		arg1 lager.Logger
+		arg2 string
+		arg3 io.Writer
+	}{arg1, arg2, arg3})
+	stub := fake.ExportDataStub
+	fakeReturns := fake.exportDataReturns
+	fake.recordInvocation("ExportData", []interface{}{arg1, arg2, arg3})
+	fake.exportDataMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeContainerStore) ExportDataCallCount() int {
+	fake.exportDataMutex.RLock()
+	defer fake.exportDataMutex.RUnlock()
+	return len(fake.exportDataArgsForCall)
+}
+
+func (fake *FakeContainerStore) ExportDataCalls(stub func(lager.Logger, string, io.Writer) error) {
+	fake.exportDataMutex.Lock()
+	defer fake.exportDataMutex.Unlock()
+	fake.ExportDataStub = stub
+}
+
+func (fake *FakeContainerStore) ExportDataArgsForCall(i int) (lager.Logger, string, io.Writer) {
+	fake.exportDataMutex.RLock()
+	defer fake.exportDataMutex.RUnlock()
+	argsForCall := fake.exportDataArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeContainerStore) ExportDataReturns(result1 error) {
+	fake.exportDataMutex.Lock()
+	defer fake.exportDataMutex.Unlock()
+	fake.ExportDataStub = nil
+	fake.exportDataReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeContainerStore) ExportDataReturnsOnCall(i int, result1 error) {
+	fake.exportDataMutex.Lock()
+	defer fake.exportDataMutex.Unlock()
+	fake.ExportDataStub = nil
+	if fake.exportDataReturnsOnCall == nil {
+		fake.exportDataReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.exportDataReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeContainerStore) Get(arg1 lager.Logger, arg2 string) (executor.Container, error) {
 	fake.getMutex.Lock()
 	ret, specificReturn := fake.getReturnsOnCall[len(fake.getArgsForCall)]
@@ -473,6 +680,134 @@ func (fake *FakeContainerStore) GetFilesReturnsOnCall(i int, result1 io.ReadClos
 	}{result1, result2}
 }
 
+func (fake *FakeContainerStore) GetRecentLogs(arg1 lager.Logger, arg2 string) ([]executor.LogLine, error) {
+	fake.getRecentLogsMutex.Lock()
+	ret, specificReturn := fake.getRecentLogsReturnsOnCall[len(fake.getRecentLogsArgsForCall)]
+	fake.getRecentLogsArgsForCall = append(fake.getRecentLogsArgsForCall, struct {
+		arg1 lager.Logger
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.GetRecentLogsStub
+	fakeReturns := fake.getRecentLogsReturns
+	fake.recordInvocation("GetRecentLogs", []interface{}{arg1, arg2})
+	fake.getRecentLogsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeContainerStore) GetRecentLogsCallCount() int {
+	fake.getRecentLogsMutex.RLock()
+	defer fake.getRecentLogsMutex.RUnlock()
+	return len(fake.getRecentLogsArgsForCall)
+}
+
+func (fake *FakeContainerStore) GetRecentLogsCalls(stub func(lager.Logger, string) ([]executor.LogLine, error)) {
+	fake.getRecentLogsMutex.Lock()
+	defer fake.getRecentLogsMutex.Unlock()
+	fake.GetRecentLogsStub = stub
+}
+
+func (fake *FakeContainerStore) GetRecentLogsArgsForCall(i int) (lager.Logger, string) {
+	fake.getRecentLogsMutex.RLock()
+	defer fake.getRecentLogsMutex.RUnlock()
+	argsForCall := fake.getRecentLogsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeContainerStore) GetRecentLogsReturns(result1 []executor.LogLine, result2 error) {
+	fake.getRecentLogsMutex.Lock()
+	defer fake.getRecentLogsMutex.Unlock()
+	fake.GetRecentLogsStub = nil
+	fake.getRecentLogsReturns = struct {
+		result1 []executor.LogLine
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContainerStore) GetRecentLogsReturnsOnCall(i int, result1 []executor.LogLine, result2 error) {
+	fake.getRecentLogsMutex.Lock()
+	defer fake.getRecentLogsMutex.Unlock()
+	fake.GetRecentLogsStub = nil
+	if fake.getRecentLogsReturnsOnCall == nil {
+		fake.getRecentLogsReturnsOnCall = make(map[int]struct {
+			result1 []executor.LogLine
+			result2 error
+		})
+	}
+	fake.getRecentLogsReturnsOnCall[i] = struct {
+		result1 []executor.LogLine
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContainerStore) ImportData(arg1 lager.Logger, arg2 string, arg3 io.Reader) error {
+	fake.importDataMutex.Lock()
+	ret, specificReturn := fake.importDataReturnsOnCall[len(fake.importDataArgsForCall)]
+	fake.importDataArgsForCall = append(fake.importDataArgsForCall, struct {
+		arg1 lager.Logger
+		arg2 string
+		arg3 io.Reader
+	}{arg1, arg2, arg3})
+	stub := fake.ImportDataStub
+	fakeReturns := fake.importDataReturns
+	fake.recordInvocation("ImportData", []interface{}{arg1, arg2, arg3})
+	fake.importDataMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeContainerStore) ImportDataCallCount() int {
+	fake.importDataMutex.RLock()
+	defer fake.importDataMutex.RUnlock()
+	return len(fake.importDataArgsForCall)
+}
+
+func (fake *FakeContainerStore) ImportDataCalls(stub func(lager.Logger, string, io.Reader) error) {
+	fake.importDataMutex.Lock()
+	defer fake.importDataMutex.Unlock()
+	fake.ImportDataStub = stub
+}
+
+func (fake *FakeContainerStore) ImportDataArgsForCall(i int) (lager.Logger, string, io.Reader) {
+	fake.importDataMutex.RLock()
+	defer fake.importDataMutex.RUnlock()
+	argsForCall := fake.importDataArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeContainerStore) ImportDataReturns(result1 error) {
+	fake.importDataMutex.Lock()
+	defer fake.importDataMutex.Unlock()
+	fake.ImportDataStub = nil
+	fake.importDataReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeContainerStore) ImportDataReturnsOnCall(i int, result1 error) {
+	fake.importDataMutex.Lock()
+	defer fake.importDataMutex.Unlock()
+	fake.ImportDataStub = nil
+	if fake.importDataReturnsOnCall == nil {
+		fake.importDataReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.importDataReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeContainerStore) Initialize(arg1 lager.Logger, arg2 *executor.RunRequest) error {
 	fake.initializeMutex.Lock()
 	ret, specificReturn := fake.initializeReturnsOnCall[len(fake.initializeArgsForCall)]
@@ -782,6 +1117,194 @@ func (fake *FakeContainerStore) NewRegistryPrunerReturnsOnCall(i int, result1 if
 	}{result1}
 }
 
+func (fake *FakeContainerStore) NewStateJournalWriter(arg1 lager.Logger) ifrit.Runner {
+	fake.newStateJournalWriterMutex.Lock()
+	ret, specificReturn := fake.newStateJournalWriterReturnsOnCall[len(fake.newStateJournalWriterArgsForCall)]
+	fake.newStateJournalWriterArgsForCall = append(fake.newStateJournalWriterArgsForCall, struct {
+		arg1 lager.Logger
+	}{arg1})
+	stub := fake.NewStateJournalWriterStub
+	fakeReturns := fake.newStateJournalWriterReturns
+	fake.recordInvocation("NewStateJournalWriter", []interface{}{arg1})
+	fake.newStateJournalWriterMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeContainerStore) NewStateJournalWriterCallCount() int {
+	fake.newStateJournalWriterMutex.RLock()
+	defer fake.newStateJournalWriterMutex.RUnlock()
+	return len(fake.newStateJournalWriterArgsForCall)
+}
+
+func (fake *FakeContainerStore) NewStateJournalWriterCalls(stub func(lager.Logger) ifrit.Runner) {
+	fake.newStateJournalWriterMutex.Lock()
+	defer fake.newStateJournalWriterMutex.Unlock()
+	fake.NewStateJournalWriterStub = stub
+}
+
+func (fake *FakeContainerStore) NewStateJournalWriterArgsForCall(i int) lager.Logger {
+	fake.newStateJournalWriterMutex.RLock()
+	defer fake.newStateJournalWriterMutex.RUnlock()
+	argsForCall := fake.newStateJournalWriterArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeContainerStore) NewStateJournalWriterReturns(result1 ifrit.Runner) {
+	fake.newStateJournalWriterMutex.Lock()
+	defer fake.newStateJournalWriterMutex.Unlock()
+	fake.NewStateJournalWriterStub = nil
+	fake.newStateJournalWriterReturns = struct {
+		result1 ifrit.Runner
+	}{result1}
+}
+
+func (fake *FakeContainerStore) NewStateJournalWriterReturnsOnCall(i int, result1 ifrit.Runner) {
+	fake.newStateJournalWriterMutex.Lock()
+	defer fake.newStateJournalWriterMutex.Unlock()
+	fake.NewStateJournalWriterStub = nil
+	if fake.newStateJournalWriterReturnsOnCall == nil {
+		fake.newStateJournalWriterReturnsOnCall = make(map[int]struct {
+			result1 ifrit.Runner
+		})
+	}
+	fake.newStateJournalWriterReturnsOnCall[i] = struct {
+		result1 ifrit.Runner
+	}{result1}
+}
+
+func (fake *FakeContainerStore) Pause(arg1 lager.Logger, arg2 string) error {
+	fake.pauseMutex.Lock()
+	ret, specificReturn := fake.pauseReturnsOnCall[len(fake.pauseArgsForCall)]
+	fake.pauseArgsForCall = append(fake.pauseArgsForCall, struct {
+		arg1 lager.Logger
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.PauseStub
+	fakeReturns := fake.pauseReturns
+	fake.recordInvocation("Pause", []interface{}{arg1, arg2})
+	fake.pauseMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeContainerStore) PauseCallCount() int {
+	fake.pauseMutex.RLock()
+	defer fake.pauseMutex.RUnlock()
+	return len(fake.pauseArgsForCall)
+}
+
+func (fake *FakeContainerStore) PauseCalls(stub func(lager.Logger, string) error) {
+	fake.pauseMutex.Lock()
+	defer fake.pauseMutex.Unlock()
+	fake.PauseStub = stub
+}
+
+func (fake *FakeContainerStore) PauseArgsForCall(i int) (lager.Logger, string) {
+	fake.pauseMutex.RLock()
+	defer fake.pauseMutex.RUnlock()
+	argsForCall := fake.pauseArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeContainerStore) PauseReturns(result1 error) {
+	fake.pauseMutex.Lock()
+	defer fake.pauseMutex.Unlock()
+	fake.PauseStub = nil
+	fake.pauseReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeContainerStore) PauseReturnsOnCall(i int, result1 error) {
+	fake.pauseMutex.Lock()
+	defer fake.pauseMutex.Unlock()
+	fake.PauseStub = nil
+	if fake.pauseReturnsOnCall == nil {
+		fake.pauseReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.pauseReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeContainerStore) PutFiles(arg1 lager.Logger, arg2 string, arg3 string, arg4 io.Reader, arg5 string) error {
+	fake.putFilesMutex.Lock()
+	ret, specificReturn := fake.putFilesReturnsOnCall[len(fake.putFilesArgsForCall)]
+	fake.putFilesArgsForCall = append(fake.putFilesArgsForCall, struct {
+		arg1 lager.Logger
+		arg2 string
+		arg3 string
+		arg4 io.Reader
+		arg5 string
+	}{arg1, arg2, arg3, arg4, arg5})
+	stub := fake.PutFilesStub
+	fakeReturns := fake.putFilesReturns
+	fake.recordInvocation("PutFiles", []interface{}{arg1, arg2, arg3, arg4, arg5})
+	fake.putFilesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4, arg5)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeContainerStore) PutFilesCallCount() int {
+	fake.putFilesMutex.RLock()
+	defer fake.putFilesMutex.RUnlock()
+	return len(fake.putFilesArgsForCall)
+}
+
+func (fake *FakeContainerStore) PutFilesCalls(stub func(lager.Logger, string, string, io.Reader, string) error) {
+	fake.putFilesMutex.Lock()
+	defer fake.putFilesMutex.Unlock()
+	fake.PutFilesStub = stub
+}
+
+func (fake *FakeContainerStore) PutFilesArgsForCall(i int) (lager.Logger, string, string, io.Reader, string) {
+	fake.putFilesMutex.RLock()
+	defer fake.putFilesMutex.RUnlock()
+	argsForCall := fake.putFilesArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5
+}
+
+func (fake *FakeContainerStore) PutFilesReturns(result1 error) {
+	fake.putFilesMutex.Lock()
+	defer fake.putFilesMutex.Unlock()
+	fake.PutFilesStub = nil
+	fake.putFilesReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeContainerStore) PutFilesReturnsOnCall(i int, result1 error) {
+	fake.putFilesMutex.Lock()
+	defer fake.putFilesMutex.Unlock()
+	fake.PutFilesStub = nil
+	if fake.putFilesReturnsOnCall == nil {
+		fake.putFilesReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.putFilesReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeContainerStore) RemainingResources(arg1 lager.Logger) executor.ExecutorResources {
 	fake.remainingResourcesMutex.Lock()
 	ret, specificReturn := fake.remainingResourcesReturnsOnCall[len(fake.remainingResourcesArgsForCall)]
@@ -908,6 +1431,129 @@ func (fake *FakeContainerStore) ReserveReturnsOnCall(i int, result1 executor.Con
 	}{result1, result2}
 }
 
+func (fake *FakeContainerStore) Restore(arg1 lager.Logger) error {
+	fake.restoreMutex.Lock()
+	ret, specificReturn := fake.restoreReturnsOnCall[len(fake.restoreArgsForCall)]
+	fake.restoreArgsForCall = append(fake.restoreArgsForCall, struct {
+		arg1 lager.Logger
+	}{arg1})
+	stub := fake.RestoreStub
+	fakeReturns := fake.restoreReturns
+	fake.recordInvocation("Restore", []interface{}{arg1})
+	fake.restoreMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeContainerStore) RestoreCallCount() int {
+	fake.restoreMutex.RLock()
+	defer fake.restoreMutex.RUnlock()
+	return len(fake.restoreArgsForCall)
+}
+
+func (fake *FakeContainerStore) RestoreCalls(stub func(lager.Logger) error) {
+	fake.restoreMutex.Lock()
+	defer fake.restoreMutex.Unlock()
+	fake.RestoreStub = stub
+}
+
+func (fake *FakeContainerStore) RestoreArgsForCall(i int) lager.Logger {
+	fake.restoreMutex.RLock()
+	defer fake.restoreMutex.RUnlock()
+	argsForCall := fake.restoreArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeContainerStore) RestoreReturns(result1 error) {
+	fake.restoreMutex.Lock()
+	defer fake.restoreMutex.Unlock()
+	fake.RestoreStub = nil
+	fake.restoreReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeContainerStore) RestoreReturnsOnCall(i int, result1 error) {
+	fake.restoreMutex.Lock()
+	defer fake.restoreMutex.Unlock()
+	fake.RestoreStub = nil
+	if fake.restoreReturnsOnCall == nil {
+		fake.restoreReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.restoreReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeContainerStore) Resume(arg1 lager.Logger, arg2 string) error {
+	fake.resumeMutex.Lock()
+	ret, specificReturn := fake.resumeReturnsOnCall[len(fake.resumeArgsForCall)]
+	fake.resumeArgsForCall = append(fake.resumeArgsForCall, struct {
+		arg1 lager.Logger
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.ResumeStub
+	fakeReturns := fake.resumeReturns
+	fake.recordInvocation("Resume", []interface{}{arg1, arg2})
+	fake.resumeMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeContainerStore) ResumeCallCount() int {
+	fake.resumeMutex.RLock()
+	defer fake.resumeMutex.RUnlock()
+	return len(fake.resumeArgsForCall)
+}
+
+func (fake *FakeContainerStore) ResumeCalls(stub func(lager.Logger, string) error) {
+	fake.resumeMutex.Lock()
+	defer fake.resumeMutex.Unlock()
+	fake.ResumeStub = stub
+}
+
+func (fake *FakeContainerStore) ResumeArgsForCall(i int) (lager.Logger, string) {
+	fake.resumeMutex.RLock()
+	defer fake.resumeMutex.RUnlock()
+	argsForCall := fake.resumeArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeContainerStore) ResumeReturns(result1 error) {
+	fake.resumeMutex.Lock()
+	defer fake.resumeMutex.Unlock()
+	fake.ResumeStub = nil
+	fake.resumeReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeContainerStore) ResumeReturnsOnCall(i int, result1 error) {
+	fake.resumeMutex.Lock()
+	defer fake.resumeMutex.Unlock()
+	fake.ResumeStub = nil
+	if fake.resumeReturnsOnCall == nil {
+		fake.resumeReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.resumeReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeContainerStore) Run(arg1 lager.Logger, arg2 string) error {
 	fake.runMutex.Lock()
 	ret, specificReturn := fake.runReturnsOnCall[len(fake.runArgsForCall)]
@@ -970,6 +1616,73 @@ func (fake *FakeContainerStore) RunReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *FakeContainerStore) RunProcess(arg1 lager.Logger, arg2 string, arg3 executor.ProcessSpec, arg4 executor.ProcessIO) (executor.Process, error) {
+	fake.runProcessMutex.Lock()
+	ret, specificReturn := fake.runProcessReturnsOnCall[len(fake.runProcessArgsForCall)]
+	fake.runProcessArgsForCall = append(fake.runProcessArgsForCall, struct {
+		arg1 lager.Logger
+		arg2 string
+		arg3 executor.ProcessSpec
+		arg4 executor.ProcessIO
+	}{arg1, arg2, arg3, arg4})
+	stub := fake.RunProcessStub
+	fakeReturns := fake.runProcessReturns
+	fake.recordInvocation("RunProcess", []interface{}{arg1, arg2, arg3, arg4})
+	fake.runProcessMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeContainerStore) RunProcessCallCount() int {
+	fake.runProcessMutex.RLock()
+	defer fake.runProcessMutex.RUnlock()
+	return len(fake.runProcessArgsForCall)
+}
+
+func (fake *FakeContainerStore) RunProcessCalls(stub func(lager.Logger, string, executor.ProcessSpec, executor.ProcessIO) (executor.Process, error)) {
+	fake.runProcessMutex.Lock()
+	defer fake.runProcessMutex.Unlock()
+	fake.RunProcessStub = stub
+}
+
+func (fake *FakeContainerStore) RunProcessArgsForCall(i int) (lager.Logger, string, executor.ProcessSpec, executor.ProcessIO) {
+	fake.runProcessMutex.RLock()
+	defer fake.runProcessMutex.RUnlock()
+	argsForCall := fake.runProcessArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *FakeContainerStore) RunProcessReturns(result1 executor.Process, result2 error) {
+	fake.runProcessMutex.Lock()
+	defer fake.runProcessMutex.Unlock()
+	fake.RunProcessStub = nil
+	fake.runProcessReturns = struct {
+		result1 executor.Process
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContainerStore) RunProcessReturnsOnCall(i int, result1 executor.Process, result2 error) {
+	fake.runProcessMutex.Lock()
+	defer fake.runProcessMutex.Unlock()
+	fake.RunProcessStub = nil
+	if fake.runProcessReturnsOnCall == nil {
+		fake.runProcessReturnsOnCall = make(map[int]struct {
+			result1 executor.Process
+			result2 error
+		})
+	}
+	fake.runProcessReturnsOnCall[i] = struct {
+		result1 executor.Process
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeContainerStore) Stop(arg1 lager.Logger, arg2 string) error {
 	fake.stopMutex.Lock()
 	ret, specificReturn := fake.stopReturnsOnCall[len(fake.stopArgsForCall)]
@@ -1032,6 +1745,134 @@ func (fake *FakeContainerStore) StopReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *FakeContainerStore) SubscribeToLogs(arg1 lager.Logger, arg2 string) (executor.LogSource, error) {
+	fake.subscribeToLogsMutex.Lock()
+	ret, specificReturn := fake.subscribeToLogsReturnsOnCall[len(fake.subscribeToLogsArgsForCall)]
+	fake.subscribeToLogsArgsForCall = append(fake.subscribeToLogsArgsForCall, struct {
+		arg1 lager.Logger
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.SubscribeToLogsStub
+	fakeReturns := fake.subscribeToLogsReturns
+	fake.recordInvocation("SubscribeToLogs", []interface{}{arg1, arg2})
+	fake.subscribeToLogsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeContainerStore) SubscribeToLogsCallCount() int {
+	fake.subscribeToLogsMutex.RLock()
+	defer fake.subscribeToLogsMutex.RUnlock()
+	return len(fake.subscribeToLogsArgsForCall)
+}
+
+func (fake *FakeContainerStore) SubscribeToLogsCalls(stub func(lager.Logger, string) (executor.LogSource, error)) {
+	fake.subscribeToLogsMutex.Lock()
+	defer fake.subscribeToLogsMutex.Unlock()
+	fake.SubscribeToLogsStub = stub
+}
+
+func (fake *FakeContainerStore) SubscribeToLogsArgsForCall(i int) (lager.Logger, string) {
+	fake.subscribeToLogsMutex.RLock()
+	defer fake.subscribeToLogsMutex.RUnlock()
+	argsForCall := fake.subscribeToLogsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeContainerStore) SubscribeToLogsReturns(result1 executor.LogSource, result2 error) {
+	fake.subscribeToLogsMutex.Lock()
+	defer fake.subscribeToLogsMutex.Unlock()
+	fake.SubscribeToLogsStub = nil
+	fake.subscribeToLogsReturns = struct {
+		result1 executor.LogSource
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContainerStore) SubscribeToLogsReturnsOnCall(i int, result1 executor.LogSource, result2 error) {
+	fake.subscribeToLogsMutex.Lock()
+	defer fake.subscribeToLogsMutex.Unlock()
+	fake.SubscribeToLogsStub = nil
+	if fake.subscribeToLogsReturnsOnCall == nil {
+		fake.subscribeToLogsReturnsOnCall = make(map[int]struct {
+			result1 executor.LogSource
+			result2 error
+		})
+	}
+	fake.subscribeToLogsReturnsOnCall[i] = struct {
+		result1 executor.LogSource
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContainerStore) UpdateLimits(arg1 lager.Logger, arg2 string, arg3 executor.Resource) error {
+	fake.updateLimitsMutex.Lock()
+	ret, specificReturn := fake.updateLimitsReturnsOnCall[len(fake.updateLimitsArgsForCall)]
+	fake.updateLimitsArgsForCall = append(fake.updateLimitsArgsForCall, struct {
+		arg1 lager.Logger
+		arg2 string
+		arg3 executor.Resource
+	}{arg1, arg2, arg3})
+	stub := fake.UpdateLimitsStub
+	fakeReturns := fake.updateLimitsReturns
+	fake.recordInvocation("UpdateLimits", []interface{}{arg1, arg2, arg3})
+	fake.updateLimitsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeContainerStore) UpdateLimitsCallCount() int {
+	fake.updateLimitsMutex.RLock()
+	defer fake.updateLimitsMutex.RUnlock()
+	return len(fake.updateLimitsArgsForCall)
+}
+
+func (fake *FakeContainerStore) UpdateLimitsCalls(stub func(lager.Logger, string, executor.Resource) error) {
+	fake.updateLimitsMutex.Lock()
+	defer fake.updateLimitsMutex.Unlock()
+	fake.UpdateLimitsStub = stub
+}
+
+func (fake *FakeContainerStore) UpdateLimitsArgsForCall(i int) (lager.Logger, string, executor.Resource) {
+	fake.updateLimitsMutex.RLock()
+	defer fake.updateLimitsMutex.RUnlock()
+	argsForCall := fake.updateLimitsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeContainerStore) UpdateLimitsReturns(result1 error) {
+	fake.updateLimitsMutex.Lock()
+	defer fake.updateLimitsMutex.Unlock()
+	fake.UpdateLimitsStub = nil
+	fake.updateLimitsReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeContainerStore) UpdateLimitsReturnsOnCall(i int, result1 error) {
+	fake.updateLimitsMutex.Lock()
+	defer fake.updateLimitsMutex.Unlock()
+	fake.UpdateLimitsStub = nil
+	if fake.updateLimitsReturnsOnCall == nil {
+		fake.updateLimitsReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.updateLimitsReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeContainerStore) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
@@ -1041,10 +1882,16 @@ func (fake *FakeContainerStore) Invocations() map[string][][]interface{} {
 	defer fake.createMutex.RUnlock()
 	fake.destroyMutex.RLock()
 	defer fake.destroyMutex.RUnlock()
+	fake.exportDataMutex.RLock()
+	defer fake.exportDataMutex.RUnlock()
 	fake.getMutex.RLock()
 	defer fake.getMutex.RUnlock()
 	fake.getFilesMutex.RLock()
 	defer fake.getFilesMutex.RUnlock()
+	fake.getRecentLogsMutex.RLock()
+	defer fake.getRecentLogsMutex.RUnlock()
+	fake.importDataMutex.RLock()
+	defer fake.importDataMutex.RUnlock()
 	fake.initializeMutex.RLock()
 	defer fake.initializeMutex.RUnlock()
 	fake.listMutex.RLock()
@@ -1055,14 +1902,30 @@ func (fake *FakeContainerStore) Invocations() map[string][][]interface{} {
 	defer fake.newContainerReaperMutex.RUnlock()
 	fake.newRegistryPrunerMutex.RLock()
 	defer fake.newRegistryPrunerMutex.RUnlock()
+	fake.newStateJournalWriterMutex.RLock()
+	defer fake.newStateJournalWriterMutex.RUnlock()
+	fake.pauseMutex.RLock()
+	defer fake.pauseMutex.RUnlock()
+	fake.putFilesMutex.RLock()
+	defer fake.putFilesMutex.RUnlock()
 	fake.remainingResourcesMutex.RLock()
 	defer fake.remainingResourcesMutex.RUnlock()
 	fake.reserveMutex.RLock()
 	defer fake.reserveMutex.RUnlock()
+	fake.restoreMutex.RLock()
+	defer fake.restoreMutex.RUnlock()
+	fake.resumeMutex.RLock()
+	defer fake.resumeMutex.RUnlock()
 	fake.runMutex.RLock()
 	defer fake.runMutex.RUnlock()
+	fake.runProcessMutex.RLock()
+	defer fake.runProcessMutex.RUnlock()
 	fake.stopMutex.RLock()
 	defer fake.stopMutex.RUnlock()
+	fake.subscribeToLogsMutex.RLock()
+	defer fake.subscribeToLogsMutex.RUnlock()
+	fake.updateLimitsMutex.RLock()
+	defer fake.updateLimitsMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
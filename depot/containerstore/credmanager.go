@@ -2,10 +2,15 @@ package containerstore
 
 import (
 	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
 	"crypto/rsa"
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/pem"
+	"errors"
 	"io"
 	"math/big"
 	"net"
@@ -29,6 +34,28 @@ const (
 	CredCreationFailedCount       = "CredCreationFailedCount"
 )
 
+// KeyAlgorithm is the algorithm of the instance identity keys.
+type KeyAlgorithm string
+
+const (
+	KeyAlgorithmRSA2048   KeyAlgorithm = "rsa-2048"
+	KeyAlgorithmECDSAP256 KeyAlgorithm = "ecdsa-p256"
+	KeyAlgorithmEd25519   KeyAlgorithm = "ed25519"
+)
+
+var ErrUnsupportedKeyAlgorithm = errors.New("unsupported instance identity key algorithm")
+
+// Validate accepts the known algorithms and the empty one, which is
+// KeyAlgorithmRSA2048.
+func (a KeyAlgorithm) Validate() error {
+	switch a {
+	case "", KeyAlgorithmRSA2048, KeyAlgorithmECDSAP256, KeyAlgorithmEd25519:
+		return nil
+	default:
+		return ErrUnsupportedKeyAlgorithm
+	}
+}
+
 type Credential struct {
 	Cert string
 	Key  string
@@ -69,8 +96,9 @@ type credManager struct {
 	validityPeriod time.Duration
 	entropyReader  io.Reader
 	clock          clock.Clock
+	keyAlgorithm   KeyAlgorithm
 	CaCert         *x509.Certificate
-	privateKey     *rsa.PrivateKey
+	signer         Signer
 	handlers       []CredentialHandler
 }
 
@@ -99,8 +127,9 @@ func NewCredManager(
 	validityPeriod time.Duration,
 	entropyReader io.Reader,
 	clock clock.Clock,
+	keyAlgorithm KeyAlgorithm,
 	CaCert *x509.Certificate,
-	privateKey *rsa.PrivateKey,
+	signer Signer,
 	handlers ...CredentialHandler,
 ) CredManager {
 	return &credManager{
@@ -109,8 +138,9 @@ func NewCredManager(
 		validityPeriod: validityPeriod,
 		entropyReader:  entropyReader,
 		clock:          clock,
+		keyAlgorithm:   keyAlgorithm,
 		CaCert:         CaCert,
-		privateKey:     privateKey,
+		signer:         signer,
 		handlers:       handlers,
 	}
 }
@@ -242,13 +272,57 @@ const (
 	privateKeyPEMBlockType  = "RSA PRIVATE KEY"
 )
 
+const (
+	ecPrivateKeyPEMBlockType    = "EC PRIVATE KEY"
+	pkcs8PrivateKeyPEMBlockType = "PRIVATE KEY"
+)
+
+// generatePrivateKey returns the public key and the DER encoding of a new
+// private key, along with its PEM block type. RSA keys stay PKCS #1 encoded,
+// as they were before the other algorithms.
+func generatePrivateKey(entropyReader io.Reader, algorithm KeyAlgorithm) (crypto.PublicKey, []byte, string, error) {
+	switch algorithm {
+	case "", KeyAlgorithmRSA2048:
+		privateKey, err := rsa.GenerateKey(entropyReader, 2048)
+		if err != nil {
+			return nil, nil, "", err
+		}
+		return privateKey.Public(), x509.MarshalPKCS1PrivateKey(privateKey), privateKeyPEMBlockType, nil
+
+	case KeyAlgorithmECDSAP256:
+		privateKey, err := ecdsa.GenerateKey(elliptic.P256(), entropyReader)
+		if err != nil {
+			return nil, nil, "", err
+		}
+		privateKeyBytes, err := x509.MarshalECPrivateKey(privateKey)
+		if err != nil {
+			return nil, nil, "", err
+		}
+		return privateKey.Public(), privateKeyBytes, ecPrivateKeyPEMBlockType, nil
+
+	case KeyAlgorithmEd25519:
+		publicKey, privateKey, err := ed25519.GenerateKey(entropyReader)
+		if err != nil {
+			return nil, nil, "", err
+		}
+		privateKeyBytes, err := x509.MarshalPKCS8PrivateKey(privateKey)
+		if err != nil {
+			return nil, nil, "", err
+		}
+		return publicKey, privateKeyBytes, pkcs8PrivateKeyPEMBlockType, nil
+
+	default:
+		return nil, nil, "", ErrUnsupportedKeyAlgorithm
+	}
+}
+
 func (c *credManager) generateCreds(logger lager.Logger, container executor.Container, certGUID string) (Credential, error) {
 	logger = logger.Session("generating-credentials")
 	logger.Debug("starting")
 	defer logger.Debug("complete")
 
-	logger.Debug("generating-private-key")
-	privateKey, err := rsa.GenerateKey(c.entropyReader, 2048)
+	logger.Debug("generating-private-key", lager.Data{"algorithm": c.keyAlgorithm})
+	publicKey, privateKeyBytes, privateKeyBlockType, err := generatePrivateKey(c.entropyReader, c.keyAlgorithm)
 	if err != nil {
 		return Credential{}, err
 	}
@@ -280,16 +354,14 @@ func (c *credManager) generateCreds(logger lager.Logger, container executor.Cont
 	template.SerialNumber.SetBytes(guidBytes[:])
 
 	logger.Debug("generating-certificate")
-	certBytes, err := x509.CreateCertificate(c.entropyReader, template, c.CaCert, privateKey.Public(), c.privateKey)
+	certBytes, err := c.signer.Sign(template, publicKey)
 	if err != nil {
 		return Credential{}, err
 	}
 	logger.Debug("generated-certificate")
 
-	privateKeyBytes := x509.MarshalPKCS1PrivateKey(privateKey)
-
 	var keyBuf bytes.Buffer
-	err = pemEncode(privateKeyBytes, privateKeyPEMBlockType, &keyBuf)
+	err = pemEncode(privateKeyBytes, privateKeyBlockType, &keyBuf)
 	if err != nil {
 		return Credential{}, err
 	}
@@ -1,16 +1,23 @@
 package containerstore_test
 
 import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
+	"encoding/json"
 	"encoding/pem"
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"math/big"
 	"net"
+	"net/http"
 	"os"
+	"path/filepath"
 	"time"
 
 	"code.cloudfoundry.org/clock/fakeclock"
@@ -32,6 +39,8 @@ var _ = Describe("CredManager", func() {
 		validityPeriod   time.Duration
 		CaCert           *x509.Certificate
 		privateKey       *rsa.PrivateKey
+		keyAlgorithm     containerstore.KeyAlgorithm
+		signer           containerstore.Signer
 		reader           io.Reader
 		logger           lager.Logger
 		clock            *fakeclock.FakeClock
@@ -61,17 +70,24 @@ var _ = Describe("CredManager", func() {
 		clock = fakeclock.NewFakeClock(time.Now().UTC().Truncate(time.Second))
 
 		CaCert, privateKey = createIntermediateCert()
+		keyAlgorithm = containerstore.KeyAlgorithmRSA2048
+		signer = nil
 	})
 
 	JustBeforeEach(func() {
+		if signer == nil {
+			signer = containerstore.NewLocalSigner(reader, CaCert, privateKey)
+		}
+
 		credManager = containerstore.NewCredManager(
 			logger,
 			fakeMetronClient,
 			validityPeriod,
 			reader,
 			clock,
+			keyAlgorithm,
 			CaCert,
-			privateKey,
+			signer,
 			fakeCredHandler,
 		)
 	})
@@ -110,8 +126,9 @@ var _ = Describe("CredManager", func() {
 				validityPeriod,
 				reader,
 				clock,
+				keyAlgorithm,
 				CaCert,
-				privateKey,
+				containerstore.NewLocalSigner(reader, CaCert, privateKey),
 				fakeCredHandler1,
 				fakeCredHandler2,
 			)
@@ -166,8 +183,9 @@ var _ = Describe("CredManager", func() {
 				validityPeriod,
 				reader,
 				clock,
+				keyAlgorithm,
 				CaCert,
-				privateKey,
+				containerstore.NewLocalSigner(reader, CaCert, privateKey),
 				fakeCredHandler1,
 				fakeCredHandler2,
 			)
@@ -554,6 +572,153 @@ var _ = Describe("CredManager", func() {
 				})
 			})
 
+			Context("when the signer fails", func() {
+				var fakeSigner *containerstorefakes.FakeSigner
+
+				BeforeEach(func() {
+					fakeSigner = &containerstorefakes.FakeSigner{}
+					fakeSigner.SignReturns(nil, errors.New("no CA for you"))
+					signer = fakeSigner
+				})
+
+				It("returns the error, after asking to sign the container's certificate", func() {
+					Eventually(containerProcess.Wait()).Should(Receive(MatchError("no CA for you")))
+
+					Expect(fakeSigner.SignCallCount()).To(Equal(1))
+					template, publicKey := fakeSigner.SignArgsForCall(0)
+					Expect(template.Subject.CommonName).To(Equal(container.Guid))
+					Expect(publicKey).To(BeAssignableToTypeOf(&rsa.PublicKey{}))
+				})
+			})
+
+			Context("when the key algorithm is ECDSA P-256", func() {
+				BeforeEach(func() {
+					keyAlgorithm = containerstore.KeyAlgorithmECDSAP256
+				})
+
+				It("certifies a new EC key", func() {
+					Eventually(fakeCredHandler.UpdateCallCount).Should(Equal(1))
+					cred, _ := fakeCredHandler.UpdateArgsForCall(0)
+					cert, _ := parseCert(cred)
+					Expect(cert.CheckSignatureFrom(CaCert)).To(Succeed())
+
+					block, _ := pem.Decode([]byte(cred.Key))
+					Expect(block).NotTo(BeNil())
+					Expect(block.Type).To(Equal("EC PRIVATE KEY"))
+					key, err := x509.ParseECPrivateKey(block.Bytes)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(key.Curve).To(Equal(elliptic.P256()))
+					Expect(key.PublicKey.Equal(cert.PublicKey)).To(BeTrue())
+				})
+			})
+
+			Context("when the key algorithm is Ed25519", func() {
+				BeforeEach(func() {
+					keyAlgorithm = containerstore.KeyAlgorithmEd25519
+				})
+
+				It("certifies a new Ed25519 key", func() {
+					Eventually(fakeCredHandler.UpdateCallCount).Should(Equal(1))
+					cred, _ := fakeCredHandler.UpdateArgsForCall(0)
+					cert, _ := parseCert(cred)
+					Expect(cert.CheckSignatureFrom(CaCert)).To(Succeed())
+
+					block, _ := pem.Decode([]byte(cred.Key))
+					Expect(block).NotTo(BeNil())
+					Expect(block.Type).To(Equal("PRIVATE KEY"))
+					key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(key).To(BeAssignableToTypeOf(ed25519.PrivateKey{}))
+					Expect(key.(ed25519.PrivateKey).Public().(ed25519.PublicKey).Equal(cert.PublicKey)).To(BeTrue())
+				})
+			})
+
+			Context("when the certificates are signed over a unix socket", func() {
+				var (
+					socketDir string
+					listener  net.Listener
+					caKey     crypto.Signer
+				)
+
+				BeforeEach(func() {
+					var err error
+					socketDir, err = ioutil.TempDir("", "signer")
+					Expect(err).NotTo(HaveOccurred())
+
+					caKey = privateKey
+				})
+
+				JustBeforeEach(func() {
+					var err error
+					listener, err = net.Listen("unix", filepath.Join(socketDir, "signer.sock"))
+					Expect(err).NotTo(HaveOccurred())
+					go http.Serve(listener, newSignerHandler(CaCert, caKey))
+				})
+
+				AfterEach(func() {
+					listener.Close()
+					os.RemoveAll(socketDir)
+				})
+
+				Context("by the CA process", func() {
+					BeforeEach(func() {
+						signer = containerstore.NewSocketSigner(filepath.Join(socketDir, "signer.sock"), CaCert)
+					})
+
+					It("certifies the key with the CA", func() {
+						Eventually(fakeCredHandler.UpdateCallCount).Should(Equal(1))
+						cred, _ := fakeCredHandler.UpdateArgsForCall(0)
+						cert, _ := parseCert(cred)
+						Expect(cert.Subject.CommonName).To(Equal(container.Guid))
+						Expect(cert.ExtKeyUsage).To(ConsistOf(x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth))
+						Expect(cert.IsCA).To(BeFalse())
+						Expect(cert.CheckSignatureFrom(CaCert)).To(Succeed())
+					})
+
+					Context("when the CA key is an Ed25519 key", func() {
+						BeforeEach(func() {
+							CaCert, caKey = createEd25519IntermediateCert()
+							signer = containerstore.NewSocketSigner(filepath.Join(socketDir, "signer.sock"), CaCert)
+						})
+
+						It("certifies the key with the CA", func() {
+							Eventually(fakeCredHandler.UpdateCallCount).Should(Equal(1))
+							cred, _ := fakeCredHandler.UpdateArgsForCall(0)
+							cert, _ := parseCert(cred)
+							Expect(cert.CheckSignatureFrom(CaCert)).To(Succeed())
+						})
+					})
+				})
+
+				Context("when the CA process signs with another key", func() {
+					BeforeEach(func() {
+						_, caKey = createIntermediateCert()
+						signer = containerstore.NewSocketSigner(filepath.Join(socketDir, "signer.sock"), CaCert)
+					})
+
+					It("fails to create the credentials", func() {
+						var err error
+						Eventually(containerProcess.Wait()).Should(Receive(&err))
+						Expect(err).To(HaveOccurred())
+					})
+				})
+
+				Context("when the signer is not listening", func() {
+					BeforeEach(func() {
+						signer = containerstore.NewSocketSigner(filepath.Join(socketDir, "missing.sock"), CaCert)
+					})
+
+					It("fails to create the credentials", func() {
+						var err error
+						Eventually(containerProcess.Wait()).Should(Receive(&err))
+						Expect(err).To(HaveOccurred())
+
+						Expect(fakeMetronClient.IncrementCounterCallCount()).To(Equal(1))
+						Expect(fakeMetronClient.IncrementCounterArgsForCall(0)).To(Equal("CredCreationFailedCount"))
+					})
+				})
+			})
+
 			Context("when signalled", func() {
 				JustBeforeEach(func() {
 					Eventually(containerProcess.Ready()).Should(BeClosed())
@@ -603,6 +768,54 @@ func createIntermediateCert() (*x509.Certificate, *rsa.PrivateKey) {
 	return certs[0], privateKey
 }
 
+func createEd25519IntermediateCert() (*x509.Certificate, ed25519.PrivateKey) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	Expect(err).NotTo(HaveOccurred())
+
+	template := &x509.Certificate{
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		SerialNumber:          big.NewInt(1),
+		NotAfter:              time.Now().Add(36 * time.Hour),
+	}
+	certBytes, err := x509.CreateCertificate(rand.Reader, template, template, publicKey, privateKey)
+	Expect(err).NotTo(HaveOccurred())
+
+	cert, err := x509.ParseCertificate(certBytes)
+	Expect(err).NotTo(HaveOccurred())
+	return cert, privateKey
+}
+
+// newSignerHandler serves the SignRequests of the socket signer as the CA
+// process would, issuing the certificates with caKey.
+func newSignerHandler(caCert *x509.Certificate, caKey crypto.Signer) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/sign", func(w http.ResponseWriter, r *http.Request) {
+		var request containerstore.SignRequest
+		err := json.NewDecoder(r.Body).Decode(&request)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		template, publicKey, err := request.Template()
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		cert, err := x509.CreateCertificate(rand.Reader, template, caCert, publicKey, caKey)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(containerstore.SignResponse{Certificate: cert})
+	})
+	return mux
+}
+
 func parseCert(cred containerstore.Credential) (*x509.Certificate, []byte) {
 	var block *pem.Block
 	var rest []byte
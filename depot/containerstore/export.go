@@ -0,0 +1,62 @@
+package containerstore
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"strings"
+
+	"code.cloudfoundry.org/executor"
+)
+
+const DefaultDataPath = "/home/vcap"
+
+const exportVersion = 1
+
+// An export is a single JSON header line followed by the tar stream of the
+// container's data path, so that neither side has to buffer the data.
+type exportHeader struct {
+	Version   int                `json:"version"`
+	Container executor.Container `json:"container"`
+
+	// RunInfo is the run info as originally requested. The container's own
+	// copy has been extended during creation (ports, env) and cannot be used to
+	// recreate it.
+	RunInfo executor.RunInfo `json:"run_info"`
+}
+
+func writeExportHeader(dest io.Writer, header exportHeader) error {
+	header.Version = exportVersion
+	return json.NewEncoder(dest).Encode(header)
+}
+
+func readExportHeader(src io.Reader) (exportHeader, io.Reader, error) {
+	reader := bufio.NewReader(src)
+
+	line, err := reader.ReadBytes('\n')
+	if err != nil {
+		return exportHeader{}, nil, executor.ErrInvalidContainerExport
+	}
+
+	var header exportHeader
+	err = json.Unmarshal(line, &header)
+	if err != nil || header.Version != exportVersion || header.Container.Guid == "" {
+		return exportHeader{}, nil, executor.ErrInvalidContainerExport
+	}
+
+	return header, reader, nil
+}
+
+// dataStreamOutPath streams out the contents of the data path
+// rather than the directory itself, so that they can be streamed back into
+// the same path.
+func dataStreamOutPath(path string) string {
+	return strings.TrimSuffix(path, "/") + "/"
+}
+
+func (config *ContainerConfig) dataPath() string {
+	if config.DataPath == "" {
+		return DefaultDataPath
+	}
+	return config.DataPath
+}
@@ -0,0 +1,55 @@
+package containerstore
+
+import (
+	"os"
+	"path/filepath"
+
+	"code.cloudfoundry.org/executor"
+)
+
+// freezer suspends and resumes every process in a Garden container. Garden
+// does not expose the cgroup freezer, so the container's freezer cgroup is
+// written to directly.
+type freezer interface {
+	Freeze(guid string) error
+	Thaw(guid string) error
+}
+
+func newFreezer(cgroupRoot string) freezer {
+	if cgroupRoot == "" {
+		return unsupportedFreezer{}
+	}
+
+	return &cgroupFreezer{root: cgroupRoot}
+}
+
+type unsupportedFreezer struct{}
+
+func (unsupportedFreezer) Freeze(string) error { return executor.ErrPauseNotSupported }
+func (unsupportedFreezer) Thaw(string) error   { return executor.ErrPauseNotSupported }
+
+// cgroupFreezer expects the containers' cgroups to be named after their
+// handles under root, and supports both cgroup v1 (freezer.state) and v2
+// (cgroup.freeze).
+type cgroupFreezer struct {
+	root string
+}
+
+func (f *cgroupFreezer) Freeze(guid string) error {
+	return f.write(guid, "1", "FROZEN")
+}
+
+func (f *cgroupFreezer) Thaw(guid string) error {
+	return f.write(guid, "0", "THAWED")
+}
+
+func (f *cgroupFreezer) write(guid, v2Value, v1Value string) error {
+	cgroup := filepath.Join(f.root, guid)
+
+	v2File := filepath.Join(cgroup, "cgroup.freeze")
+	if _, err := os.Stat(v2File); err == nil {
+		return writeCgroupFile(v2File, v2Value)
+	}
+
+	return writeCgroupFile(filepath.Join(cgroup, "freezer.state"), v1Value)
+}
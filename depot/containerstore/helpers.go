@@ -16,18 +16,39 @@ import (
 
 var ErrIPRangeConversionFailed = errors.New("failed to convert destination to ip range")
 
-func logStreamerFromLogConfig(conf executor.LogConfig, metronClient loggingclient.IngressClient, maxLogLinesPerSecond int, logRateLimitExceededReportInterval time.Duration) log_streamer.LogStreamer {
+func logStreamerFromLogConfig(conf executor.LogConfig, sink log_streamer.LogSink, metronClient loggingclient.IngressClient, maxLogLinesPerSecond int, maxLogBytesPerSecond int64, logRateLimitExceededReportInterval time.Duration, droppedLogs *log_streamer.DroppedLogs, redactor *log_streamer.Redactor, tap log_streamer.LineTap) log_streamer.LogStreamer {
 	return log_streamer.New(
 		conf.Guid,
 		conf.SourceName,
 		conf.Index,
 		conf.Tags,
+		sink,
 		metronClient,
 		maxLogLinesPerSecond,
+		maxLogBytesPerSecond,
 		logRateLimitExceededReportInterval,
+		droppedLogs,
+		conf.Multiline,
+		conf.JSON,
+		redactor,
+		tap,
 	)
 }
 
+// logRateLimit returns the container's override of the cell's log rate limit,
+// if any, capped by the ceiling. Zero is no limit.
+func logRateLimit(override, cellLimit, ceiling int64) int64 {
+	limit := cellLimit
+	if override > 0 {
+		limit = override
+	}
+
+	if ceiling > 0 && (limit <= 0 || limit > ceiling) {
+		return ceiling
+	}
+	return limit
+}
+
 func newBindMount(src, dst string) garden.BindMount {
 	return garden.BindMount{
 		SrcPath: src,
@@ -0,0 +1,78 @@
+package containerstore
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"code.cloudfoundry.org/executor"
+)
+
+// memoryLimiter changes the memory limit of an existing Garden container.
+// Garden only sets limits on creation, so the container's memory cgroup is
+// written to directly.
+type memoryLimiter interface {
+	SetMemoryLimit(guid string, limitInBytes uint64) error
+}
+
+func newMemoryLimiter(cgroupRoot string) memoryLimiter {
+	if cgroupRoot == "" {
+		return unsupportedMemoryLimiter{}
+	}
+
+	return &cgroupMemoryLimiter{root: cgroupRoot}
+}
+
+type unsupportedMemoryLimiter struct{}
+
+func (unsupportedMemoryLimiter) SetMemoryLimit(string, uint64) error {
+	return executor.ErrLimitsUpdateNotSupported
+}
+
+// cgroupMemoryLimiter expects the containers' cgroups to be named after their
+// handles under root, and supports both cgroup v1 and v2. A limit of zero
+// removes the limit, as it does in a garden.ContainerSpec.
+type cgroupMemoryLimiter struct {
+	root string
+}
+
+func (l *cgroupMemoryLimiter) SetMemoryLimit(guid string, limitInBytes uint64) error {
+	cgroup := filepath.Join(l.root, guid)
+
+	v2File := filepath.Join(cgroup, "memory.max")
+	if _, err := os.Stat(v2File); err == nil {
+		value := "max"
+		if limitInBytes > 0 {
+			value = strconv.FormatUint(limitInBytes, 10)
+		}
+		return writeCgroupFile(v2File, value)
+	}
+
+	value := "-1"
+	if limitInBytes > 0 {
+		value = strconv.FormatUint(limitInBytes, 10)
+	}
+
+	limitFile := filepath.Join(cgroup, "memory.limit_in_bytes")
+	memswFile := filepath.Join(cgroup, "memory.memsw.limit_in_bytes")
+	if _, err := os.Stat(memswFile); err != nil {
+		return writeCgroupFile(limitFile, value)
+	}
+
+	// the memory+swap limit may never be lower than the memory limit, so it
+	// has to be raised first when the limit grows and lowered last when it
+	// shrinks
+	if err := writeCgroupFile(limitFile, value); err == nil {
+		return writeCgroupFile(memswFile, value)
+	}
+
+	if err := writeCgroupFile(memswFile, value); err != nil {
+		return err
+	}
+	return writeCgroupFile(limitFile, value)
+}
+
+func writeCgroupFile(path, value string) error {
+	return ioutil.WriteFile(path, []byte(value), 0644)
+}
@@ -1,6 +1,7 @@
 package containerstore
 
 import (
+	"sort"
 	"sync"
 	"time"
 
@@ -12,16 +13,36 @@ type nodeMap struct {
 	nodes map[string]*storeNode
 	lock  *sync.RWMutex
 
+	// preempted holds the guids of nodes stopped to make room for a higher
+	// priority reservation, whose resources were handed over to it
+	preempted map[string]struct{}
+
 	remainingResources *executor.ExecutorResources
+	admissionPolicy    AdmissionPolicy
+
+	// a cell without cpu shares does not limit them, so they are not charged
+	enforceCPUShares bool
 }
 
-func newNodeMap(totalCapacity *executor.ExecutorResources) *nodeMap {
+func newNodeMap(totalCapacity *executor.ExecutorResources, admissionPolicy AdmissionPolicy) *nodeMap {
 	capacity := totalCapacity.Copy()
 	return &nodeMap{
 		nodes:              make(map[string]*storeNode),
+		preempted:          make(map[string]struct{}),
 		lock:               &sync.RWMutex{},
 		remainingResources: &capacity,
+		admissionPolicy:    admissionPolicy,
+		enforceCPUShares:   capacity.CPUShares > 0,
+	}
+}
+
+// charged returns the part of resource that counts against the cell's
+// resources.
+func (n *nodeMap) charged(resource executor.Resource) *executor.Resource {
+	if !n.enforceCPUShares {
+		resource.CPUShares = 0
 	}
+	return &resource
 }
 
 func (n *nodeMap) Contains(guid string) bool {
@@ -37,6 +58,7 @@ func (n *nodeMap) RemainingResources() executor.ExecutorResources {
 	return n.remainingResources.Copy()
 }
 
+// Add adds a node if there are enough resources left for it.
 func (n *nodeMap) Add(node *storeNode) error {
 	n.lock.Lock()
 	defer n.lock.Unlock()
@@ -46,7 +68,103 @@ func (n *nodeMap) Add(node *storeNode) error {
 		return executor.ErrContainerGuidNotAvailable
 	}
 
-	ok := n.remainingResources.Subtract(&info.Resource)
+	existing := make([]executor.Container, 0, len(n.nodes))
+	for _, existingNode := range n.nodes {
+		existing = append(existing, existingNode.Info())
+	}
+
+	err := n.admissionPolicy.Admit(info, existing)
+	if err != nil {
+		return err
+	}
+
+	return n.add(node, info)
+}
+
+// Preempt marks the nodes to preempt to make room for the candidate, so that
+// no other reservation picks them, releases their resources and returns them.
+// They stay in the map until their owner deletes them; it is up to the caller
+// to stop them. It returns nil if there is no way to make room.
+func (n *nodeMap) Preempt(logger lager.Logger, candidate executor.Container) []*storeNode {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	victims := n.preemptionVictims(candidate)
+	for _, victim := range victims {
+		victimInfo := victim.preempt(logger)
+		n.preempted[victimInfo.Guid] = struct{}{}
+		n.remainingResources.Add(n.charged(victimInfo.Resource))
+	}
+
+	return victims
+}
+
+// preemptionVictims picks the nodes to preempt for the candidate: those with
+// the lowest priority first and, among them, the most recently allocated. It
+// returns nil if preempting every lower priority node would not be enough.
+func (n *nodeMap) preemptionVictims(candidate executor.Container) []*storeNode {
+	type entry struct {
+		node *storeNode
+		info executor.Container
+	}
+
+	var entries []entry
+	for guid, node := range n.nodes {
+		if _, ok := n.preempted[guid]; ok {
+			continue
+		}
+		info := node.Info()
+		if info.State == executor.StateCompleted || info.Priority >= candidate.Priority {
+			continue
+		}
+		entries = append(entries, entry{node: node, info: info})
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].info.Priority != entries[j].info.Priority {
+			return entries[i].info.Priority < entries[j].info.Priority
+		}
+		return entries[i].info.AllocatedAt > entries[j].info.AllocatedAt
+	})
+
+	remaining := n.remainingResources.Copy()
+	victims := []*storeNode{}
+	for _, e := range entries {
+		remaining.Add(n.charged(e.info.Resource))
+		victims = append(victims, e.node)
+
+		fits := remaining.Copy()
+		if fits.Subtract(n.charged(candidate.Resource)) {
+			return victims
+		}
+	}
+
+	return nil
+}
+
+// AddRestored adds a node rebuilt from the state journal. Admission policies
+// are not consulted since the container was already admitted before the
+// executor restarted. A preempted node no longer holds any resources.
+func (n *nodeMap) AddRestored(node *storeNode) error {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	info := node.Info()
+	if _, ok := n.nodes[info.Guid]; ok {
+		return executor.ErrContainerGuidNotAvailable
+	}
+
+	if node.isPreempted() {
+		n.nodes[info.Guid] = node
+		n.preempted[info.Guid] = struct{}{}
+		return nil
+	}
+
+	return n.add(node, info)
+}
+
+func (n *nodeMap) add(node *storeNode, info executor.Container) error {
+	ok := n.remainingResources.Subtract(n.charged(info.Resource))
 	if !ok {
 		return executor.ErrInsufficientResourcesAvailable
 	}
@@ -56,6 +174,38 @@ func (n *nodeMap) Add(node *storeNode) error {
 	return nil
 }
 
+// Resize swaps the reservation of a node for the given resource. apply is
+// called once the new reservation is known to fit and must update the node's
+// resource; the reservation is left untouched if it fails.
+func (n *nodeMap) Resize(guid string, resource executor.Resource, apply func() error) error {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	node, ok := n.nodes[guid]
+	if !ok {
+		return executor.ErrContainerNotFound
+	}
+
+	if _, ok := n.preempted[guid]; ok {
+		return executor.ErrInvalidTransition
+	}
+
+	info := node.Info()
+	remaining := n.remainingResources.Copy()
+	remaining.Add(n.charged(info.Resource))
+	if !remaining.Subtract(n.charged(resource)) {
+		return executor.ErrInsufficientResourcesAvailable
+	}
+
+	err := apply()
+	if err != nil {
+		return err
+	}
+
+	*n.remainingResources = remaining
+	return nil
+}
+
 func (n *nodeMap) Remove(guid string) {
 	n.lock.Lock()
 	defer n.lock.Unlock()
@@ -70,7 +220,12 @@ func (n *nodeMap) Remove(guid string) {
 
 func (n *nodeMap) remove(node *storeNode) {
 	info := node.Info()
-	n.remainingResources.Add(&info.Resource)
+	if _, ok := n.preempted[info.Guid]; ok {
+		// its resources were released when it was preempted
+		delete(n.preempted, info.Guid)
+	} else {
+		n.remainingResources.Add(n.charged(info.Resource))
+	}
 	delete(n.nodes, info.Guid)
 }
 
@@ -0,0 +1,133 @@
+package containerstore
+
+import (
+	"os"
+	"time"
+
+	"code.cloudfoundry.org/executor"
+	"code.cloudfoundry.org/garden"
+	loggregator "code.cloudfoundry.org/go-loggregator"
+	"code.cloudfoundry.org/lager"
+	"github.com/tedsuo/ifrit"
+)
+
+// OOMKilledMetric is a counter incremented for every OOM kill, tagged with the
+// container's metrics tags.
+const OOMKilledMetric = "OOMKilled"
+
+// CounterEmitter is implemented by the IngressClients able to send tagged
+// counter envelopes, as go-loggregator's IngressClient does; the IngressClient
+// interface only sends untagged counters. Without it, the OOM kills are
+// counted untagged.
+type CounterEmitter interface {
+	EmitCounter(name string, opts ...loggregator.EmitCounterOption)
+}
+
+const DefaultOOMPollInterval = 10 * time.Second
+
+// newOOMWatcher polls the events of the Garden container for OOM kills for as
+// long as the container runs, so that kills of sidecars and health checks, or
+// kills after the run step has returned, are noticed as well. Garden only
+// reports events as part of the container info, hence the polling. The events
+// are checked one last time when the container stops, even if polling is
+// disabled.
+func (n *storeNode) newOOMWatcher(logger lager.Logger, gardenContainer garden.Container) ifrit.Runner {
+	return ifrit.RunFunc(func(signals <-chan os.Signal, ready chan<- struct{}) error {
+		logger := logger.Session("oom-watcher")
+
+		var tick <-chan time.Time
+		if interval := n.config.oomPollInterval(); interval > 0 {
+			ticker := n.clock.NewTicker(interval)
+			defer ticker.Stop()
+			tick = ticker.C()
+		}
+
+		close(ready)
+
+		for {
+			select {
+			case <-tick:
+				n.checkOOMKills(logger, gardenContainer)
+			case <-signals:
+				// the container may well be stopping because one of its
+				// processes was just killed
+				n.checkOOMKills(logger, gardenContainer)
+				return nil
+			}
+		}
+	})
+}
+
+func (n *storeNode) checkOOMKills(logger lager.Logger, gardenContainer garden.Container) {
+	gardenInfo, err := gardenContainer.Info()
+	if err != nil {
+		logger.Error("failed-to-get-garden-container-info", err)
+		return
+	}
+
+	// Garden keeps every event of the container, so the kills that have not
+	// been recorded yet are those beyond the recorded count
+	kills := countOOMEvents(gardenInfo.Events)
+
+	n.infoLock.Lock()
+	recorded := n.info.OOMKillCount
+	if kills <= recorded {
+		n.infoLock.Unlock()
+		return
+	}
+	n.info.OOMKillCount = kills
+	n.info.LastOOMKillAt = n.clock.Now().UnixNano()
+	n.saveState(logger)
+	info := n.info.Copy()
+	n.infoLock.Unlock()
+
+	logger.Info("container-oom-killed", lager.Data{"oom-kill-count": kills})
+
+	delta := uint64(kills - recorded)
+	if emitter, ok := n.metronClient.(CounterEmitter); ok {
+		emitter.EmitCounter(OOMKilledMetric,
+			loggregator.WithDelta(delta),
+			loggregator.EmitCounterOption(loggregator.WithEnvelopeTags(info.MetricsConfig.Tags)),
+		)
+	} else {
+		err = n.metronClient.IncrementCounterWithDelta(OOMKilledMetric, delta)
+		if err != nil {
+			logger.Error("failed-to-increment-counter", err, lager.Data{"metric-name": OOMKilledMetric})
+		}
+	}
+
+	go n.eventEmitter.Emit(stampEvent(executor.NewContainerOOMEvent(info), n.clock.Now(), ""))
+}
+
+func (config *ContainerConfig) oomPollInterval() time.Duration {
+	if config.OOMPollInterval == 0 {
+		return DefaultOOMPollInterval
+	}
+	return config.OOMPollInterval
+}
+
+// Garden-RunC capitalizes the O in out of memory whereas Garden-linux does not
+func countOOMEvents(events []string) int {
+	count := 0
+	for _, event := range events {
+		if event == "out of memory" || event == "Out of memory" {
+			count++
+		}
+	}
+	return count
+}
+
+// oomExplains returns whether an OOM kill may be the cause of a failure with
+// the given code.
+func oomExplains(failureCode executor.FailureCode) bool {
+	switch failureCode {
+	case executor.FailureCodeUnknown,
+		executor.FailureCodeProcessExited,
+		executor.FailureCodeExitTimeout,
+		executor.FailureCodeHealthCheckFailed,
+		executor.FailureCodeHealthCheckTimeout:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,112 @@
+package containerstore
+
+import (
+	"fmt"
+	"strings"
+
+	"code.cloudfoundry.org/executor"
+	"code.cloudfoundry.org/executor/depot/steps"
+	"code.cloudfoundry.org/garden"
+	"code.cloudfoundry.org/lager"
+)
+
+// ExecLogSource is the log source of the audit lines written to the
+// container's logs for every process run with RunProcess.
+const ExecLogSource = "SSH-EXEC"
+
+// RunProcess runs an ad-hoc process in the Garden container. The Garden
+// container's environment already holds the instance identity credentials,
+// the networking variables are added just like for the container's actions.
+func (n *storeNode) RunProcess(logger lager.Logger, spec executor.ProcessSpec, processIO executor.ProcessIO) (executor.Process, error) {
+	logger = logger.Session("node-run-process", lager.Data{"path": spec.Path, "user": spec.User})
+
+	n.infoLock.Lock()
+	info := n.info.Copy()
+	gardenContainer := n.gardenContainer
+	n.infoLock.Unlock()
+
+	if gardenContainer == nil || info.State != executor.StateRunning {
+		logger.Error("container-not-running", executor.ErrContainerNotRunning, lager.Data{"state": info.State})
+		return nil, executor.ErrContainerNotRunning
+	}
+
+	env := convertEnvVars(spec.Env)
+	env = append(env, steps.NetworkingEnvVars(logger, info.ExternalIP, info.InternalIP, info.Ports)...)
+
+	var tty *garden.TTYSpec
+	if spec.TTY != nil {
+		tty = &garden.TTYSpec{WindowSize: gardenWindowSize(spec.TTY.WindowSize)}
+	}
+
+	audit := n.logStreamer(info).WithSource(ExecLogSource)
+	command := strings.Join(append([]string{spec.Path}, spec.Args...), " ")
+	fmt.Fprintf(audit.Stdout(), "Cell %s running process as user %q in instance %s: %s\n", n.cellID, spec.User, info.Guid, command)
+
+	process, err := gardenContainer.Run(garden.ProcessSpec{
+		Path: spec.Path,
+		Args: spec.Args,
+		Dir:  spec.Dir,
+		User: spec.User,
+		Env:  env,
+		TTY:  tty,
+	}, garden.ProcessIO{
+		Stdin:  processIO.Stdin,
+		Stdout: processIO.Stdout,
+		Stderr: processIO.Stderr,
+	})
+	if err != nil {
+		logger.Error("failed-to-run-process", err)
+		fmt.Fprintf(audit.Stderr(), "Cell %s failed to run process in instance %s: %s\n", n.cellID, info.Guid, err.Error())
+		audit.Stop()
+		return nil, err
+	}
+
+	logger.Info("running-process", lager.Data{"process": process.ID()})
+
+	go func() {
+		defer audit.Stop()
+
+		exitStatus, err := process.Wait()
+		if err != nil {
+			fmt.Fprintf(audit.Stderr(), "Cell %s lost track of process %s in instance %s: %s\n", n.cellID, process.ID(), info.Guid, err.Error())
+			return
+		}
+		fmt.Fprintf(audit.Stdout(), "Cell %s process %s in instance %s exited with status %d\n", n.cellID, process.ID(), info.Guid, exitStatus)
+	}()
+
+	return &gardenProcess{process: process}, nil
+}
+
+func gardenWindowSize(size *executor.WindowSize) *garden.WindowSize {
+	if size == nil {
+		return nil
+	}
+	return &garden.WindowSize{Columns: int(size.Columns), Rows: int(size.Rows)}
+}
+
+type gardenProcess struct {
+	process garden.Process
+}
+
+func (p *gardenProcess) ID() string {
+	return p.process.ID()
+}
+
+func (p *gardenProcess) Wait() (int, error) {
+	return p.process.Wait()
+}
+
+func (p *gardenProcess) Resize(size executor.WindowSize) error {
+	return p.process.SetTTY(garden.TTYSpec{WindowSize: gardenWindowSize(&size)})
+}
+
+func (p *gardenProcess) Signal(signal executor.ProcessSignal) error {
+	switch signal {
+	case executor.ProcessSignalTerminate:
+		return p.process.Signal(garden.SignalTerminate)
+	case executor.ProcessSignalKill:
+		return p.process.Signal(garden.SignalKill)
+	default:
+		return executor.ErrInvalidProcessSignal
+	}
+}
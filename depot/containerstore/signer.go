@@ -0,0 +1,185 @@
+package containerstore
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+)
+
+const SignerRequestTimeout = 10 * time.Second
+
+var (
+	ErrInvalidSignRequest          = errors.New("signer: invalid sign request")
+	ErrUnexpectedSignerCertificate = errors.New("signer: certificate is not the one requested")
+)
+
+//go:generate counterfeiter -o containerstorefakes/fake_signer.go . Signer
+
+// Signer signs the instance identity certificates with the key of the CA.
+type Signer interface {
+	// Sign returns the DER encoded certificate made from template for
+	// publicKey.
+	Sign(template *x509.Certificate, publicKey crypto.PublicKey) ([]byte, error)
+}
+
+type localSigner struct {
+	entropyReader io.Reader
+	caCert        *x509.Certificate
+	caKey         crypto.Signer
+}
+
+// NewLocalSigner returns a Signer using the CA key held by the cell.
+func NewLocalSigner(entropyReader io.Reader, caCert *x509.Certificate, caKey crypto.Signer) Signer {
+	return &localSigner{
+		entropyReader: entropyReader,
+		caCert:        caCert,
+		caKey:         caKey,
+	}
+}
+
+func (s *localSigner) Sign(template *x509.Certificate, publicKey crypto.PublicKey) ([]byte, error) {
+	return x509.CreateCertificate(s.entropyReader, template, s.caCert, publicKey, s.caKey)
+}
+
+// SignRequest is sent by the socket signer to have an instance identity
+// certificate issued by the CA process. It carries the fields the cell chooses
+// and the key to certify, never a digest or a certificate to sign as is: the
+// CA process builds the certificate itself, with the extensions of every
+// instance identity certificate, so it knows what it signs.
+type SignRequest struct {
+	// SerialNumber is big-endian.
+	SerialNumber       []byte    `json:"serial_number"`
+	CommonName         string    `json:"common_name"`
+	OrganizationalUnit []string  `json:"organizational_unit,omitempty"`
+	IPAddress          string    `json:"ip_address,omitempty"`
+	NotBefore          time.Time `json:"not_before"`
+	NotAfter           time.Time `json:"not_after"`
+
+	// PublicKey is PKIX, ASN.1 DER encoded.
+	PublicKey []byte `json:"public_key"`
+}
+
+// NewSignRequest returns the SignRequest for an instance identity certificate
+// template. The fields it does not carry are left to the CA process.
+func NewSignRequest(template *x509.Certificate, publicKey crypto.PublicKey) (SignRequest, error) {
+	publicKeyBytes, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return SignRequest{}, err
+	}
+
+	request := SignRequest{
+		SerialNumber:       template.SerialNumber.Bytes(),
+		CommonName:         template.Subject.CommonName,
+		OrganizationalUnit: template.Subject.OrganizationalUnit,
+		NotBefore:          template.NotBefore,
+		NotAfter:           template.NotAfter,
+		PublicKey:          publicKeyBytes,
+	}
+	if len(template.IPAddresses) > 0 {
+		request.IPAddress = template.IPAddresses[0].String()
+	}
+	return request, nil
+}
+
+// Template returns the certificate the CA process is asked to issue, and the
+// key it certifies. The CA process is still the one to decide whether to
+// issue it, for instance from the validity period.
+func (r SignRequest) Template() (*x509.Certificate, crypto.PublicKey, error) {
+	publicKey, err := x509.ParsePKIXPublicKey(r.PublicKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if r.IPAddress != "" && net.ParseIP(r.IPAddress) == nil {
+		return nil, nil, ErrInvalidSignRequest
+	}
+
+	template := createCertificateTemplate(r.IPAddress, r.CommonName, r.NotBefore, r.NotAfter, r.OrganizationalUnit)
+	template.SerialNumber.SetBytes(r.SerialNumber)
+	return template, publicKey, nil
+}
+
+type SignResponse struct {
+	// Certificate is ASN.1 DER encoded.
+	Certificate []byte `json:"certificate"`
+}
+
+type socketSigner struct {
+	client *http.Client
+	caCert *x509.Certificate
+}
+
+// NewSocketSigner returns a Signer using a CA key held by another process,
+// which serves the SignRequests on the unix socket at socketPath, so the CA
+// key never has to be on the cell. The certificates it issues are checked
+// against caCert and the key they were asked for.
+func NewSocketSigner(socketPath string, caCert *x509.Certificate) Signer {
+	client := &http.Client{
+		Timeout: SignerRequestTimeout,
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var dialer net.Dialer
+				return dialer.DialContext(ctx, "unix", socketPath)
+			},
+		},
+	}
+
+	return &socketSigner{
+		client: client,
+		caCert: caCert,
+	}
+}
+
+func (s *socketSigner) Sign(template *x509.Certificate, publicKey crypto.PublicKey) ([]byte, error) {
+	request, err := NewSignRequest(template, publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.client.Post("http://signer/sign", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("signer: %s", resp.Status)
+	}
+
+	var response SignResponse
+	err = json.NewDecoder(resp.Body).Decode(&response)
+	if err != nil {
+		return nil, err
+	}
+
+	cert, err := x509.ParseCertificate(response.Certificate)
+	if err != nil {
+		return nil, err
+	}
+	err = cert.CheckSignatureFrom(s.caCert)
+	if err != nil {
+		return nil, err
+	}
+	certPublicKey, err := x509.MarshalPKIXPublicKey(cert.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(certPublicKey, request.PublicKey) || cert.SerialNumber.Cmp(template.SerialNumber) != 0 {
+		return nil, ErrUnexpectedSignerCertificate
+	}
+
+	return response.Certificate, nil
+}
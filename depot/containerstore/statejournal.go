@@ -0,0 +1,188 @@
+package containerstore
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"code.cloudfoundry.org/executor"
+	"code.cloudfoundry.org/garden"
+	"code.cloudfoundry.org/lager"
+	"github.com/tedsuo/ifrit"
+)
+
+// nodeState is everything needed to rebuild a storeNode after the executor
+// restarts. Credential directories and proxy ports are not stored separately:
+// the former are derived from the container guid and the latter are part of
+// the container's port mappings. RunInfo is kept as requested so that the
+// container's data can still be exported after a restart. ProcessIDs are the
+// Garden processes of the action, the sidecars and the proxy, the only ones
+// reattached to. Preempted containers are completed instead of reattached to,
+// since they were being stopped and no longer hold any resources.
+type nodeState struct {
+	Container          executor.Container  `json:"container"`
+	RunInfo            executor.RunInfo    `json:"run_info"`
+	BindMounts         []garden.BindMount  `json:"bind_mounts,omitempty"`
+	BindMountCacheKeys []BindMountCacheKey `json:"bind_mount_cache_keys,omitempty"`
+	ProcessIDs         []string            `json:"process_ids,omitempty"`
+	Preempted          bool                `json:"preempted,omitempty"`
+}
+
+// stateJournal keeps the state of the nodes in memory; Save and Remove only
+// update it, so that they can be called with the locks of the store held.
+// The runner returned by Writer persists it in the background.
+type stateJournal interface {
+	Load() ([]nodeState, error)
+	Save(state nodeState)
+	Remove(guid string)
+	Writer(logger lager.Logger) ifrit.Runner
+}
+
+func newStateJournal(path string) stateJournal {
+	if path == "" {
+		return noopStateJournal{}
+	}
+
+	return &fileStateJournal{
+		path:    path,
+		states:  map[string]nodeState{},
+		changed: make(chan struct{}, 1),
+	}
+}
+
+type noopStateJournal struct{}
+
+func (noopStateJournal) Load() ([]nodeState, error) { return nil, nil }
+func (noopStateJournal) Save(nodeState)             {}
+func (noopStateJournal) Remove(string)              {}
+
+func (noopStateJournal) Writer(lager.Logger) ifrit.Runner {
+	return ifrit.RunFunc(func(signals <-chan os.Signal, ready chan<- struct{}) error {
+		close(ready)
+		<-signals
+		return nil
+	})
+}
+
+// fileStateJournal writes all the states to a single file. The changes made
+// while a write is in progress are coalesced into the next one.
+type fileStateJournal struct {
+	path    string
+	lock    sync.Mutex
+	states  map[string]nodeState
+	changed chan struct{}
+}
+
+func (j *fileStateJournal) Load() ([]nodeState, error) {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	data, err := ioutil.ReadFile(j.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	states := map[string]nodeState{}
+	err = json.Unmarshal(data, &states)
+	if err != nil {
+		return nil, err
+	}
+
+	j.states = states
+
+	result := make([]nodeState, 0, len(states))
+	for _, state := range states {
+		result = append(result, state)
+	}
+
+	return result, nil
+}
+
+func (j *fileStateJournal) Save(state nodeState) {
+	j.lock.Lock()
+	j.states[state.Container.Guid] = state
+	j.lock.Unlock()
+
+	j.notify()
+}
+
+func (j *fileStateJournal) Remove(guid string) {
+	j.lock.Lock()
+	_, ok := j.states[guid]
+	delete(j.states, guid)
+	j.lock.Unlock()
+
+	if ok {
+		j.notify()
+	}
+}
+
+func (j *fileStateJournal) notify() {
+	select {
+	case j.changed <- struct{}{}:
+	default:
+	}
+}
+
+// Writer writes the journal after every change until it is signalled, and
+// then once more if anything changed since its last write.
+func (j *fileStateJournal) Writer(logger lager.Logger) ifrit.Runner {
+	return ifrit.RunFunc(func(signals <-chan os.Signal, ready chan<- struct{}) error {
+		logger := logger.Session("state-journal-writer", lager.Data{"path": j.path})
+		close(ready)
+
+		for {
+			select {
+			case <-j.changed:
+				j.write(logger)
+			case <-signals:
+				select {
+				case <-j.changed:
+					j.write(logger)
+				default:
+				}
+				return nil
+			}
+		}
+	})
+}
+
+func (j *fileStateJournal) write(logger lager.Logger) {
+	j.lock.Lock()
+	data, err := json.Marshal(j.states)
+	j.lock.Unlock()
+	if err != nil {
+		logger.Error("failed-to-marshal-container-state", err)
+		return
+	}
+
+	tmpFile, err := ioutil.TempFile(filepath.Dir(j.path), filepath.Base(j.path))
+	if err != nil {
+		logger.Error("failed-to-create-container-state-file", err)
+		return
+	}
+	defer os.Remove(tmpFile.Name())
+
+	_, err = tmpFile.Write(data)
+	if err == nil {
+		err = tmpFile.Sync()
+	}
+	closeErr := tmpFile.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		logger.Error("failed-to-write-container-state-file", err)
+		return
+	}
+
+	err = os.Rename(tmpFile.Name(), j.path)
+	if err != nil {
+		logger.Error("failed-to-rename-container-state-file", err)
+	}
+}
@@ -33,6 +33,9 @@ const ContainerMissingMessage = "missing garden container"
 const VolmanMountFailed = "failed to mount volume"
 const BindMountCleanupFailed = "failed to cleanup bindmount artifacts"
 const CredDirFailed = "failed to create credentials directory"
+const ContainerRestoreFailedMessage = "failed to restore container after executor restart"
+const DataImportFailedMessage = "failed to import container data"
+const ContainerPreemptedMessage = "preempted by a higher priority container"
 
 const ContainerCompletedCount = "ContainerCompletedCount"
 const ContainerExitedOnTimeoutCount = "ContainerExitedOnTimeoutCount"
@@ -62,8 +65,23 @@ type storeNode struct {
 	// infoLock protects modifying info and swapping gardenContainer pointers
 	infoLock           *sync.Mutex
 	info               executor.Container
+	runInfo            executor.RunInfo
 	bindMountCacheKeys []BindMountCacheKey
+	processIDs         []string
 	gardenContainer    garden.Container
+	stateJournal       stateJournal
+	forgotten          bool
+	preempted          bool
+	monitorGate        *steps.MonitorGate
+
+	// redactorLock protects making the redactor, which is shared by all the
+	// container's LogStreamers
+	redactorLock *sync.Mutex
+	redactor     *log_streamer.Redactor
+
+	// droppedLogs totals the lines the log rate limits of all the container's
+	// LogStreamers dropped
+	droppedLogs *log_streamer.DroppedLogs
 
 	clock clock.Clock
 
@@ -73,6 +91,8 @@ type storeNode struct {
 	dependencyManager                     DependencyManager
 	volumeManager                         volman.Manager
 	credManager                           CredManager
+	freezer                               freezer
+	memoryLimiter                         memoryLimiter
 	instanceIdentityHandler               *InstanceIdentityHandler
 	eventEmitter                          event.Hub
 	transformer                           transformer.Transformer
@@ -112,12 +132,17 @@ func newStoreNode(
 	cellID string,
 	enableUnproxiedPortMappings bool,
 	advertisePreferenceForInstanceAddress bool,
+	stateJournal stateJournal,
+	freezer freezer,
+	memoryLimiter memoryLimiter,
 ) *storeNode {
 	return &storeNode{
 		config:                                config,
 		info:                                  container,
 		infoLock:                              &sync.Mutex{},
 		opLock:                                &sync.Mutex{},
+		redactorLock:                          &sync.Mutex{},
+		droppedLogs:                           log_streamer.NewDroppedLogs(config.LogRateLimitExceededReportInterval),
 		gardenClient:                          gardenClient,
 		clock:                                 clock,
 		dependencyManager:                     dependencyManager,
@@ -135,6 +160,9 @@ func newStoreNode(
 		cellID:                                cellID,
 		enableUnproxiedPortMappings:           enableUnproxiedPortMappings,
 		advertisePreferenceForInstanceAddress: advertisePreferenceForInstanceAddress,
+		stateJournal:                          stateJournal,
+		freezer:                               freezer,
+		memoryLimiter:                         memoryLimiter,
 	}
 }
 
@@ -149,6 +177,59 @@ func (n *storeNode) releaseOpLock(logger lager.Logger) {
 	logger.Debug("ops-lock-released")
 }
 
+// logStreamer returns a LogStreamer for the container's logs, which also feeds
+// the log tap and the recent logs when they are enabled.
+func (n *storeNode) logStreamer(info executor.Container) log_streamer.LogStreamer {
+	var sink log_streamer.LogSink = n.metronClient
+	if n.config.LogSink != nil {
+		sink = n.config.LogSink
+	}
+
+	// the taps of a forgotten container would keep its lines for good
+	n.infoLock.Lock()
+	forgotten := n.forgotten
+	n.infoLock.Unlock()
+
+	taps := log_streamer.LineTaps{}
+	if n.config.LogTap != nil && !forgotten {
+		taps = append(taps, n.config.LogTap.Container(info.Guid))
+	}
+	if n.config.RecentLogs != nil && !forgotten {
+		taps = append(taps, n.config.RecentLogs.Container(info.Guid))
+	}
+
+	var tap log_streamer.LineTap
+	if len(taps) > 0 {
+		tap = taps
+	}
+	maxLogLinesPerSecond := logRateLimit(int64(info.LogConfig.MaxLogLinesPerSecond), int64(n.config.MaxLogLinesPerSecond), int64(n.config.MaxLogLinesPerSecondCeiling))
+	maxLogBytesPerSecond := logRateLimit(info.LogConfig.MaxLogBytesPerSecond, n.config.MaxLogBytesPerSecond, n.config.MaxLogBytesPerSecondCeiling)
+
+	return logStreamerFromLogConfig(info.LogConfig, sink, n.metronClient, int(maxLogLinesPerSecond), maxLogBytesPerSecond, n.config.LogRateLimitExceededReportInterval, n.droppedLogs, n.logRedactor(info), tap)
+}
+
+// logRedactor returns the container's Redactor, made on first use, or nil
+// when its logs are not redacted.
+func (n *storeNode) logRedactor(info executor.Container) *log_streamer.Redactor {
+	conf := info.LogConfig.Redaction
+	if conf == nil {
+		return nil
+	}
+
+	n.redactorLock.Lock()
+	defer n.redactorLock.Unlock()
+
+	if n.redactor == nil {
+		// the patterns are validated by Initialize
+		redactor, err := log_streamer.NewRedactor(conf.SecretEnvValues(info.Env), conf.Patterns, n.config.LogRateLimitExceededReportInterval)
+		if err != nil {
+			return nil
+		}
+		n.redactor = redactor
+	}
+	return n.redactor
+}
+
 func (n *storeNode) Info() executor.Container {
 	n.infoLock.Lock()
 	defer n.infoLock.Unlock()
@@ -166,16 +247,159 @@ func (n *storeNode) GetFiles(logger lager.Logger, sourcePath string) (io.ReadClo
 	return gc.StreamOut(garden.StreamOutSpec{Path: sourcePath, User: "root"})
 }
 
+// PutFiles streams the tar into the Garden container at destPath. It takes a
+// slot of the download limiter first, so that large transfers queue behind
+// the downloads of containers being set up rather than starving them.
+func (n *storeNode) PutFiles(logger lager.Logger, destPath string, tarStream io.Reader, user string) error {
+	logger = logger.Session("node-put-files", lager.Data{"path": destPath, "user": user})
+
+	n.infoLock.Lock()
+	gc := n.gardenContainer
+	state := n.info.State
+	n.infoLock.Unlock()
+
+	if gc == nil || state != executor.StateRunning {
+		logger.Error("container-not-running", executor.ErrContainerNotRunning, lager.Data{"state": state})
+		return executor.ErrContainerNotRunning
+	}
+
+	if user == "" {
+		user = "root"
+	}
+
+	if limiter := n.config.DownloadRateLimiter; limiter != nil {
+		logger.Info("acquiring-limiter")
+		limiter <- struct{}{}
+		defer func() {
+			<-limiter
+		}()
+		logger.Info("acquired-limiter")
+	}
+
+	err := gc.StreamIn(garden.StreamInSpec{Path: destPath, User: user, TarStream: tarStream})
+	if err != nil {
+		logger.Error("failed-to-stream-in", err)
+		return err
+	}
+	return nil
+}
+
+// ExportData writes the container's metadata and the contents of its data
+// path to dest.
+func (n *storeNode) ExportData(logger lager.Logger, dest io.Writer) error {
+	logger = logger.Session("node-export-data")
+	n.acquireOpLock(logger)
+	defer n.releaseOpLock(logger)
+
+	n.infoLock.Lock()
+	gc := n.gardenContainer
+	info := n.info.Copy()
+	runInfo := n.runInfo
+	n.infoLock.Unlock()
+
+	if gc == nil || info.State == executor.StateCompleted {
+		logger.Error("container-not-exportable", executor.ErrContainerNotExportable, lager.Data{"state": info.State})
+		return executor.ErrContainerNotExportable
+	}
+
+	path := n.config.dataPath()
+	data, err := gc.StreamOut(garden.StreamOutSpec{Path: dataStreamOutPath(path), User: "root"})
+	if err != nil {
+		logger.Error("failed-to-stream-out", err, lager.Data{"path": path})
+		return err
+	}
+	defer data.Close()
+
+	err = writeExportHeader(dest, exportHeader{Container: info, RunInfo: runInfo})
+	if err != nil {
+		logger.Error("failed-to-write-export-header", err)
+		return err
+	}
+
+	_, err = io.Copy(dest, data)
+	if err != nil {
+		logger.Error("failed-to-write-export", err)
+		return err
+	}
+
+	return nil
+}
+
+// ImportData streams exported data back into the data path of the created
+// container. The container is completed as failed if this is not possible,
+// since running it without its data would defeat the purpose of the import.
+func (n *storeNode) ImportData(logger lager.Logger, data io.Reader) error {
+	logger = logger.Session("node-import-data")
+	n.acquireOpLock(logger)
+	defer n.releaseOpLock(logger)
+
+	n.infoLock.Lock()
+	gc := n.gardenContainer
+	state := n.info.State
+	n.infoLock.Unlock()
+
+	if gc == nil || state != executor.StateCreated {
+		logger.Error("failed-to-import-data", executor.ErrInvalidTransition, lager.Data{"state": state})
+		return executor.ErrInvalidTransition
+	}
+
+	path := n.config.dataPath()
+	err := gc.StreamIn(garden.StreamInSpec{Path: path, User: "root", TarStream: data})
+	if err != nil {
+		logger.Error("failed-to-stream-in", err, lager.Data{"path": path})
+		n.complete(logger, true, executor.FailureCodeRestoreFailed, DataImportFailedMessage, false)
+		return err
+	}
+
+	return nil
+}
+
 func (n *storeNode) Initialize(logger lager.Logger, req *executor.RunRequest) error {
 	logger = logger.Session("node-initialize")
+
+	if req.LogConfig.Multiline != nil {
+		err := req.LogConfig.Multiline.Validate()
+		if err != nil {
+			logger.Error("invalid-log-config", err)
+			return err
+		}
+	}
+
+	if req.LogConfig.Redaction != nil {
+		err := req.LogConfig.Redaction.Validate()
+		if err != nil {
+			logger.Error("invalid-log-config", err)
+			return err
+		}
+	}
+
 	n.infoLock.Lock()
-	defer n.infoLock.Unlock()
+	// the cpu shares of the container were charged for the weight it was
+	// reserved with, which a run without a weight of its own keeps
+	reservedCPUWeight := n.info.CPUWeight
+	if req.CPUWeight > reservedCPUWeight {
+		n.infoLock.Unlock()
+		logger.Error("failed-to-initialize", executor.ErrCPUWeightExceedsReservation, lager.Data{"cpu-weight": req.CPUWeight, "reserved-cpu-weight": reservedCPUWeight})
+		return executor.ErrCPUWeightExceedsReservation
+	}
+	if req.CPUWeight == 0 {
+		runReq := *req
+		runReq.CPUWeight = reservedCPUWeight
+		req = &runReq
+	}
 
 	err := n.info.TransistionToInitialize(req)
 	if err != nil {
+		n.infoLock.Unlock()
 		logger.Error("failed-to-initialize", err)
 		return err
 	}
+	n.runInfo = req.RunInfo
+	n.saveState(logger)
+	info := n.info.Copy()
+	n.infoLock.Unlock()
+
+	n.eventEmitter.Emit(stampEvent(executor.NewContainerInitializingEvent(info), n.clock.Now(), executor.StateReserved))
 	return nil
 }
 
@@ -194,11 +418,12 @@ func (n *storeNode) Create(logger lager.Logger) error {
 	}
 
 	createContainer := func() error {
-		logStreamer := logStreamerFromLogConfig(info.LogConfig, n.metronClient, n.config.MaxLogLinesPerSecond, n.config.LogRateLimitExceededReportInterval)
+		logStreamer := n.logStreamer(info)
+		defer logStreamer.Stop()
 
 		mounts, err := n.dependencyManager.DownloadCachedDependencies(logger, info.CachedDependencies, logStreamer)
 		if err != nil {
-			n.complete(logger, true, DownloadCachedDependenciesFailed, true)
+			n.complete(logger, true, executor.FailureCodeDownloadFailed, DownloadCachedDependenciesFailed, true)
 			return err
 		}
 
@@ -225,14 +450,14 @@ func (n *storeNode) Create(logger lager.Logger) error {
 				failMsg = VolmanMountFailed
 			}
 			logger.Error("failed-to-mount-volume", err)
-			n.complete(logger, true, failMsg, true)
+			n.complete(logger, true, executor.FailureCodeVolumeMountFailed, failMsg, true)
 			return err
 		}
 		n.bindMounts = append(n.bindMounts, volumeMounts...)
 
 		credMounts, envs, err := n.credManager.CreateCredDir(logger, n.info)
 		if err != nil {
-			n.complete(logger, true, CredDirFailed, true)
+			n.complete(logger, true, executor.FailureCodeSetupFailed, CredDirFailed, true)
 			return err
 		}
 		n.bindMounts = append(n.bindMounts, credMounts...)
@@ -251,7 +476,7 @@ func (n *storeNode) Create(logger lager.Logger) error {
 		gardenContainer, err := n.createGardenContainer(logger, &info)
 		if err != nil {
 			fmt.Fprintf(logStreamer.Stderr(), "Cell %s failed to create container for instance %s: %s\n", n.cellID, n.Info().Guid, err.Error())
-			n.complete(logger, true, fmt.Sprintf("%s: %s", ContainerCreationFailedMessage, err.Error()), true)
+			n.complete(logger, true, executor.FailureCodeContainerCreationFailed, fmt.Sprintf("%s: %s", ContainerCreationFailedMessage, err.Error()), true)
 			return err
 		}
 		fmt.Fprintf(logStreamer.Stdout(), "Cell %s successfully created container for instance %s\n", n.cellID, n.Info().Guid)
@@ -261,8 +486,15 @@ func (n *storeNode) Create(logger lager.Logger) error {
 		n.info = info
 		err = n.info.TransitionToCreate()
 		n.bindMountCacheKeys = mounts.CacheKeys
+		if err == nil {
+			n.saveState(logger)
+		}
+		info = n.info.Copy()
 		n.infoLock.Unlock()
 
+		if err == nil {
+			n.eventEmitter.Emit(stampEvent(executor.NewContainerCreatedEvent(info), n.clock.Now(), executor.StateInitializing))
+		}
 		return err
 	}
 
@@ -380,7 +612,7 @@ func (n *storeNode) createGardenContainer(logger lager.Logger, info *executor.Co
 				Max: uint64(info.MaxPids),
 			},
 			CPU: garden.CPULimits{
-				LimitInShares: uint64(float64(n.config.MaxCPUShares) * float64(info.CPUWeight) / 100.0),
+				LimitInShares: cpuShares(n.config.MaxCPUShares, info.CPUWeight),
 			},
 		},
 		Properties: n.gardenProperties(info),
@@ -477,32 +709,67 @@ func (n *storeNode) Run(logger lager.Logger) error {
 		return executor.ErrInvalidTransition
 	}
 
-	logStreamer := logStreamerFromLogConfig(n.info.LogConfig, n.metronClient, n.config.MaxLogLinesPerSecond, n.config.LogRateLimitExceededReportInterval)
+	logStreamer := n.logStreamer(n.info)
 
-	credManagerRunner := n.credManager.Runner(logger, n.info)
+	processIDs := []string{}
+	runner, err := n.stepsRunner(logger, n.info, n.gardenContainer, logStreamer, false, func(processID string) {
+		processIDs = append(processIDs, processID)
+	})
+	if err != nil {
+		return err
+	}
 
-	proxyTLSPorts := make([]uint16, len(n.info.Ports))
-	for i, p := range n.info.Ports {
+	n.infoLock.Lock()
+	n.processIDs = processIDs
+	n.saveState(logger)
+	n.infoLock.Unlock()
+
+	n.startRunner(logger, n.info, n.gardenContainer, logStreamer, runner)
+	return nil
+}
+
+// stepsRunner makes the runner of the container's action, sidecars and
+// health checks. When reattach is set, it attaches to the processes started
+// before a restart instead of starting them.
+func (n *storeNode) stepsRunner(logger lager.Logger, info executor.Container, gardenContainer garden.Container, logStreamer log_streamer.LogStreamer, reattach bool, processNamed func(string)) (ifrit.Runner, error) {
+	proxyTLSPorts := make([]uint16, len(info.Ports))
+	for i, p := range info.Ports {
 		proxyTLSPorts[i] = p.ContainerTLSProxyPort
 	}
+	n.monitorGate = steps.NewMonitorGate()
+	if info.State == executor.StatePaused {
+		n.monitorGate.Suspend()
+	}
 	cfg := transformer.Config{
 		BindMounts:        n.bindMounts,
 		ProxyTLSPorts:     proxyTLSPorts,
 		CreationStartTime: n.startTime,
 		MetronClient:      n.metronClient,
+		MonitorGate:       n.monitorGate,
+		HealthObserver:    n.observeHealth,
+		Reattach:          reattach,
+		ProcessNamed:      processNamed,
 	}
-	runner, err := n.transformer.StepsRunner(logger, n.info, n.gardenContainer, logStreamer, cfg)
-	if err != nil {
-		return err
-	}
+	return n.transformer.StepsRunner(logger, info, gardenContainer, logStreamer, cfg)
+}
 
+func (n *storeNode) startRunner(logger lager.Logger, info executor.Container, gardenContainer garden.Container, logStreamer log_streamer.LogStreamer, runner ifrit.Runner) {
 	group := grouper.NewQueueOrdered(os.Interrupt, grouper.Members{
-		{"cred-manager-runner", credManagerRunner},
+		{"oom-watcher", n.newOOMWatcher(logger, gardenContainer)},
+		{"cred-manager-runner", n.credManager.Runner(logger, info)},
 		{"runner", runner},
 	})
 	n.process = ifrit.Background(group)
 	go n.run(logger, logStreamer)
-	return nil
+}
+
+func (n *storeNode) observeHealth(healthy bool) {
+	info := n.Info()
+	var ev executor.Event = executor.NewContainerUnhealthyEvent(info)
+	if healthy {
+		ev = executor.NewContainerHealthyEvent(info)
+	}
+	n.eventEmitter.Emit(stampEvent(ev, n.clock.Now(), ""))
 }
 
 func (n *storeNode) completeWithError(logger lager.Logger, err error) {
@@ -516,7 +783,7 @@ func (n *storeNode) completeWithError(logger lager.Logger, err error) {
 				return
 			} else if err != nil {
 				if event.Member.Name != "runner" {
-					err = errors.New(event.Member.Name + " exited: " + err.Error())
+					err = steps.NewEmittableErrorWithCode(executor.FailureCodeSetupFailed, err, event.Member.Name+" exited: "+err.Error())
 				}
 				n.completeWithError(logger, err)
 			}
@@ -533,10 +800,14 @@ func (n *storeNode) completeWithError(logger lager.Logger, err error) {
 	}
 
 	if errorStr != "" {
-		n.complete(logger, true, errorStr, false)
+		failureCode := steps.FailureCodeForError(err)
+		if failureCode == executor.FailureCodeNone {
+			failureCode = executor.FailureCodeUnknown
+		}
+		n.complete(logger, true, failureCode, errorStr, false)
 		return
 	}
-	n.complete(logger, false, "", false)
+	n.complete(logger, false, executor.FailureCodeNone, "", false)
 }
 
 func (n *storeNode) run(logger lager.Logger, logStreamer log_streamer.LogStreamer) {
@@ -554,15 +825,164 @@ func (n *storeNode) run(logger lager.Logger, logStreamer log_streamer.LogStreame
 	logger.Debug("healthcheck-passed")
 
 	n.infoLock.Lock()
-	n.info.State = executor.StateRunning
+	// a container reattached after a restart may still be paused
+	previousState := n.info.State
+	paused := previousState == executor.StatePaused
+	if !paused {
+		n.info.State = executor.StateRunning
+		n.saveState(logger)
+	}
 	info := n.info.Copy()
 	n.infoLock.Unlock()
-	go n.eventEmitter.Emit(executor.NewContainerRunningEvent(info))
+	if !paused {
+		go n.eventEmitter.Emit(stampEvent(executor.NewContainerRunningEvent(info), n.clock.Now(), previousState))
+	}
 
 	err := <-n.process.Wait()
 	n.completeWithError(logger, err)
 }
 
+// cpuShares is the share of the cpu given to a container of the given weight,
+// which is also what its reservation counts against the cell's cpu shares.
+func cpuShares(maxCPUShares uint64, cpuWeight uint) uint64 {
+	return uint64(float64(maxCPUShares) * float64(cpuWeight) / 100.0)
+}
+
+// UpdateLimits changes the memory and disk reserved for the container and
+// applies the memory limit to its Garden container. Garden cannot change disk
+// quotas, so the disk can only be resized until the container is created.
+func (n *storeNode) UpdateLimits(logger lager.Logger, containers *nodeMap, resource executor.Resource) error {
+	logger = logger.Session("node-update-limits")
+	n.acquireOpLock(logger)
+	defer n.releaseOpLock(logger)
+
+	info := n.Info()
+	if info.State == executor.StateCompleted {
+		logger.Error("failed-to-update-limits", executor.ErrInvalidTransition)
+		return executor.ErrInvalidTransition
+	}
+
+	if resource.MemoryMB < 0 || resource.DiskMB < 0 {
+		logger.Error("invalid-limits", executor.ErrLimitsInvalid)
+		return executor.ErrLimitsInvalid
+	}
+
+	created := info.IsCreated()
+	if created && resource.DiskMB != info.DiskMB {
+		logger.Error("failed-to-update-disk-limit", executor.ErrDiskLimitNotUpdatable)
+		return executor.ErrDiskLimitNotUpdatable
+	}
+
+	updated := info.Resource
+	updated.MemoryMB = resource.MemoryMB
+	updated.DiskMB = resource.DiskMB
+	memoryLimit := uint64(updated.MemoryMB * 1024 * 1024)
+
+	err := containers.Resize(info.Guid, updated, func() error {
+		if created {
+			err := n.memoryLimiter.SetMemoryLimit(info.Guid, memoryLimit)
+			if err != nil {
+				logger.Error("failed-to-set-memory-limit", err)
+				return err
+			}
+		}
+
+		n.infoLock.Lock()
+		n.info.Resource = updated
+		if created {
+			n.info.MemoryLimit = memoryLimit
+		}
+		info = n.info.Copy()
+		n.saveState(logger)
+		n.infoLock.Unlock()
+		return nil
+	})
+	if err != nil {
+		logger.Error("failed-to-resize-reservation", err)
+		return err
+	}
+
+	logger.Info("updated-limits", lager.Data{"memory-mb": updated.MemoryMB, "disk-mb": updated.DiskMB})
+	go n.eventEmitter.Emit(stampEvent(executor.NewContainerLimitsUpdatedEvent(info), n.clock.Now(), ""))
+	return nil
+}
+
+// Pause freezes the container's processes and suspends its liveness checks
+// so that it is not considered crashed while paused.
+func (n *storeNode) Pause(logger lager.Logger) error {
+	logger = logger.Session("node-pause")
+	n.acquireOpLock(logger)
+	defer n.releaseOpLock(logger)
+
+	info := n.Info()
+	if !info.ValidateTransitionTo(executor.StatePaused) {
+		logger.Error("failed-to-pause", executor.ErrInvalidTransition, lager.Data{"state": info.State})
+		return executor.ErrInvalidTransition
+	}
+
+	n.monitorGate.Suspend()
+
+	err := n.freezer.Freeze(info.Guid)
+	if err != nil {
+		logger.Error("failed-to-freeze-container", err)
+		n.monitorGate.Resume()
+		return err
+	}
+
+	n.infoLock.Lock()
+	if n.info.State != executor.StateRunning {
+		// the container completed while it was being frozen
+		n.infoLock.Unlock()
+		n.thaw(logger)
+		return executor.ErrInvalidTransition
+	}
+	n.info.State = executor.StatePaused
+	info = n.info.Copy()
+	n.saveState(logger)
+	n.infoLock.Unlock()
+
+	logger.Info("paused")
+	go n.eventEmitter.Emit(stampEvent(executor.NewContainerPausedEvent(info), n.clock.Now(), executor.StateRunning))
+	return nil
+}
+
+func (n *storeNode) Resume(logger lager.Logger) error {
+	logger = logger.Session("node-resume")
+	n.acquireOpLock(logger)
+	defer n.releaseOpLock(logger)
+
+	info := n.Info()
+	if info.State != executor.StatePaused {
+		logger.Error("failed-to-resume", executor.ErrInvalidTransition, lager.Data{"state": info.State})
+		return executor.ErrInvalidTransition
+	}
+
+	err := n.freezer.Thaw(info.Guid)
+	if err != nil {
+		logger.Error("failed-to-thaw-container", err)
+		return err
+	}
+
+	n.infoLock.Lock()
+	n.info.State = executor.StateRunning
+	info = n.info.Copy()
+	n.saveState(logger)
+	n.infoLock.Unlock()
+
+	n.monitorGate.Resume()
+
+	logger.Info("resumed")
+	go n.eventEmitter.Emit(stampEvent(executor.NewContainerRunningEvent(info), n.clock.Now(), executor.StatePaused))
+	return nil
+}
+
+func (n *storeNode) thaw(logger lager.Logger) {
+	err := n.freezer.Thaw(n.Info().Guid)
+	if err != nil {
+		logger.Error("failed-to-thaw-container", err)
+	}
+}
+
 func (n *storeNode) Stop(logger lager.Logger) {
 	if !atomic.CompareAndSwapInt32(&n.stopping, 0, 1) {
 		return
@@ -579,18 +999,30 @@ func (n *storeNode) Stop(logger lager.Logger) {
 func (n *storeNode) stop(logger lager.Logger) {
 	n.infoLock.Lock()
 	stopped := n.info.RunResult.Stopped
+	paused := n.info.State == executor.StatePaused
+	completed := n.info.State == executor.StateCompleted
 	n.info.RunResult.Stopped = true
+	n.saveState(logger)
+	info := n.info.Copy()
 	n.infoLock.Unlock()
+	if !stopped && !completed {
+		n.eventEmitter.Emit(stampEvent(executor.NewContainerStoppedEvent(info), n.clock.Now(), ""))
+	}
+	if paused {
+		// frozen processes cannot react to signals
+		n.thaw(logger)
+	}
 	if n.process != nil {
 		if !stopped {
-			logStreamer := logStreamerFromLogConfig(n.info.LogConfig, n.metronClient, n.config.MaxLogLinesPerSecond, n.config.LogRateLimitExceededReportInterval)
+			logStreamer := n.logStreamer(n.info)
 			fmt.Fprintf(logStreamer.Stdout(), "Cell %s stopping instance %s\n", n.cellID, n.Info().Guid)
+			logStreamer.Stop()
 		}
 
 		n.process.Signal(os.Interrupt)
 		logger.Debug("signalled-process")
 	} else {
-		n.complete(logger, true, "stopped-before-running", false)
+		n.complete(logger, true, executor.FailureCodeStoppedBeforeRunning, "stopped-before-running", false)
 	}
 }
 
@@ -614,7 +1046,8 @@ func (n *storeNode) Destroy(logger lager.Logger) error {
 	info := n.info.Copy()
 	n.infoLock.Unlock()
 
-	logStreamer := logStreamerFromLogConfig(info.LogConfig, n.metronClient, n.config.MaxLogLinesPerSecond, n.config.LogRateLimitExceededReportInterval)
+	logStreamer := n.logStreamer(info)
+	defer logStreamer.Stop()
 
 	fmt.Fprintf(logStreamer.Stdout(), "Cell %s destroying container for instance %s\n", n.cellID, info.Guid)
 
@@ -684,8 +1117,9 @@ func (n *storeNode) Expire(logger lager.Logger, now time.Time) bool {
 
 	lifespan := now.Sub(time.Unix(0, n.info.AllocatedAt))
 	if lifespan >= n.config.ReservedExpirationTime {
-		n.info.TransitionToComplete(true, ContainerExpirationMessage, false)
-		go n.eventEmitter.Emit(executor.NewContainerCompleteEvent(n.info))
+		n.info.TransitionToComplete(true, executor.FailureCodeExpired, ContainerExpirationMessage, false)
+		n.saveState(logger)
+		go n.eventEmitter.Emit(stampEvent(n.completeEvent(), now, executor.StateReserved))
 		return true
 	}
 
@@ -702,20 +1136,192 @@ func (n *storeNode) Reap(logger lager.Logger) bool {
 		// ensure these directories are removed even if the container fails to destroy
 		n.removeCredsDir(logger, n.info.Copy())
 
-		n.info.TransitionToComplete(true, ContainerMissingMessage, false)
-		go n.eventEmitter.Emit(executor.NewContainerCompleteEvent(n.info))
+		previousState := n.info.State
+		n.info.TransitionToComplete(true, executor.FailureCodeContainerMissing, ContainerMissingMessage, false)
+		n.saveState(logger)
+		go n.eventEmitter.Emit(stampEvent(n.completeEvent(), n.clock.Now(), previousState))
 		return true
 	}
 
 	return false
 }
 
-func (n *storeNode) complete(logger lager.Logger, failed bool, failureReason string, retryable bool) {
-	logger.Debug("node-complete", lager.Data{"failed": failed, "reason": failureReason})
+// preempt marks the node as making room for a higher priority container, so
+// that it completes as a retryable failure however it ends up being stopped.
+func (n *storeNode) preempt(logger lager.Logger) executor.Container {
+	n.infoLock.Lock()
+	defer n.infoLock.Unlock()
+	n.preempted = true
+	n.saveState(logger)
+	return n.info.Copy()
+}
+
+func (n *storeNode) isPreempted() bool {
+	n.infoLock.Lock()
+	defer n.infoLock.Unlock()
+	return n.preempted
+}
+
+func (n *storeNode) complete(logger lager.Logger, failed bool, failureCode executor.FailureCode, failureReason string, retryable bool) {
+	logger.Debug("node-complete", lager.Data{"failed": failed, "code": failureCode, "reason": failureReason})
+	n.infoLock.Lock()
+	defer n.infoLock.Unlock()
+	if n.preempted {
+		failed, failureCode, failureReason, retryable = true, executor.FailureCodePreempted, ContainerPreemptedMessage, true
+	} else if failed && n.info.OOMKillCount > 0 && oomExplains(failureCode) {
+		failureCode = executor.FailureCodeOOM
+	}
+	previousState := n.info.State
+	n.info.TransitionToComplete(failed, failureCode, failureReason, retryable)
+	n.saveState(logger)
+	go n.eventEmitter.Emit(stampEvent(n.completeEvent(), n.clock.Now(), previousState))
+}
+
+// completeEvent attaches the recent logs of a failed container, so that the
+// reason it died is at hand. It must be called with the infoLock held.
+func (n *storeNode) completeEvent() executor.ContainerCompleteEvent {
+	ev := executor.NewContainerCompleteEvent(n.info)
+	if n.info.RunResult.Failed && n.config.RecentLogs != nil {
+		ev.RecentLogs = n.config.RecentLogs.Get(n.info.Guid)
+	}
+	return ev
+}
+
+// stampEvent records when ev happened and, for events that are state
+// transitions, the state the container left.
+func stampEvent(ev executor.Event, at time.Time, previousState executor.State) executor.Event {
+	metadata := ev.Metadata()
+	metadata.Timestamp = at.UnixNano()
+	metadata.PreviousState = previousState
+	return ev.WithMetadata(metadata)
+}
+
+// saveState journals the node so that it can be restored if the executor
+// restarts. infoLock must be held by the caller.
+func (n *storeNode) saveState(logger lager.Logger) {
+	if n.forgotten {
+		return
+	}
+
+	n.stateJournal.Save(nodeState{
+		Container:          n.info.Copy(),
+		RunInfo:            n.runInfo,
+		BindMounts:         n.bindMounts,
+		BindMountCacheKeys: n.bindMountCacheKeys,
+		ProcessIDs:         n.processIDs,
+		Preempted:          n.preempted,
+	})
+}
+
+func (n *storeNode) persist(logger lager.Logger) {
+	n.infoLock.Lock()
+	defer n.infoLock.Unlock()
+	n.saveState(logger)
+}
+
+// tapLogs makes the container known to the log tap, so that its logs can be
+// subscribed to before it logs anything and until it is forgotten.
+func (n *storeNode) tapLogs() {
 	n.infoLock.Lock()
 	defer n.infoLock.Unlock()
-	n.info.TransitionToComplete(failed, failureReason, retryable)
-	go n.eventEmitter.Emit(executor.NewContainerCompleteEvent(n.info))
+	if n.config.LogTap != nil && !n.forgotten {
+		n.config.LogTap.Container(n.info.Guid)
+	}
+}
+
+// forget removes the node from the journal and prevents any operation still
+// in flight from writing it back.
+func (n *storeNode) forget(logger lager.Logger) {
+	n.infoLock.Lock()
+	defer n.infoLock.Unlock()
+	n.forgotten = true
+	n.stateJournal.Remove(n.info.Guid)
+	if n.config.LogTap != nil {
+		n.config.LogTap.Forget(n.info.Guid)
+	}
+	if n.config.RecentLogs != nil {
+		n.config.RecentLogs.Forget(n.info.Guid)
+	}
+	if forgetter, ok := n.config.LogSink.(log_streamer.InstanceForgetter); ok {
+		conf := n.info.LogConfig
+		forgetter.ForgetInstance(log_streamer.InstanceTags(conf.Guid, conf.Index, conf.Tags))
+	}
+}
+
+// Restore resumes a node that was rebuilt from the journal. Running
+// containers are reattached to their Garden processes; containers that were
+// still being set up cannot be resumed and are completed as retryable
+// failures.
+func (n *storeNode) Restore(logger lager.Logger) {
+	logger = logger.Session("node-restore", lager.Data{"guid": n.Info().Guid})
+	n.acquireOpLock(logger)
+	defer n.releaseOpLock(logger)
+
+	info := n.Info()
+	if n.isPreempted() && info.State != executor.StateCompleted {
+		logger.Info("container-was-preempted")
+		n.complete(logger, true, executor.FailureCodePreempted, ContainerPreemptedMessage, true)
+		return
+	}
+
+	switch info.State {
+	case executor.StateInitializing, executor.StateCreated:
+		logger.Info("container-was-not-running")
+		n.complete(logger, true, executor.FailureCodeRestoreFailed, ContainerRestoreFailedMessage, true)
+	case executor.StateRunning, executor.StatePaused:
+		n.reattach(logger, info)
+	}
+}
+
+func (n *storeNode) reattach(logger lager.Logger, info executor.Container) {
+	gardenContainer, err := n.gardenClient.Lookup(info.Guid)
+	if err != nil {
+		logger.Error("failed-to-lookup-garden-container", err)
+		n.complete(logger, true, executor.FailureCodeContainerMissing, ContainerMissingMessage, false)
+		return
+	}
+
+	gardenInfo, err := gardenContainer.Info()
+	if err != nil {
+		logger.Error("failed-to-get-garden-container-info", err)
+		n.complete(logger, true, executor.FailureCodeRestoreFailed, ContainerRestoreFailedMessage, false)
+		return
+	}
+
+	// only the action, the sidecars and the proxy are reattached to; health
+	// checks and ad-hoc processes may also be running, and are left alone
+	running := map[string]bool{}
+	for _, processID := range gardenInfo.ProcessIDs {
+		running[processID] = true
+	}
+	for _, processID := range n.processIDs {
+		if !running[processID] {
+			logger.Info("process-not-running", lager.Data{"process-id": processID})
+			n.complete(logger, true, executor.FailureCodeRestoreFailed, ContainerRestoreFailedMessage, true)
+			return
+		}
+	}
+	if len(n.processIDs) == 0 {
+		logger.Info("no-processes-to-reattach")
+		n.complete(logger, true, executor.FailureCodeRestoreFailed, ContainerRestoreFailedMessage, true)
+		return
+	}
+
+	n.infoLock.Lock()
+	n.gardenContainer = gardenContainer
+	n.infoLock.Unlock()
+
+	logStreamer := n.logStreamer(info)
+
+	runner, err := n.stepsRunner(logger, info, gardenContainer, logStreamer, true, nil)
+	if err != nil {
+		logger.Error("failed-to-build-steps-runner", err)
+		n.complete(logger, true, executor.FailureCodeRestoreFailed, ContainerRestoreFailedMessage, true)
+		return
+	}
+
+	logger.Info("reattaching-processes", lager.Data{"process-ids": n.processIDs})
+	n.startRunner(logger, info, gardenContainer, logStreamer, runner)
 }
 
 func (n *storeNode) removeCredsDir(logger lager.Logger, info executor.Container) {
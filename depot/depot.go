@@ -72,14 +72,14 @@ func (c *client) AllocateContainers(logger lager.Logger, requests []executor.All
 		err := req.Validate()
 		if err != nil {
 			logger.Error("invalid-request", err)
-			failures = append(failures, executor.NewAllocationFailure(req, err.Error()))
+			failures = append(failures, executor.NewAllocationFailureFromError(req, err))
 			continue
 		}
 
 		_, err = c.containerStore.Reserve(logger, req)
 		if err != nil {
 			logger.Error("failed-to-allocate-container", err, lager.Data{"guid": req.Guid})
-			failures = append(failures, executor.NewAllocationFailure(req, err.Error()))
+			failures = append(failures, executor.NewAllocationFailureFromError(req, err))
 			continue
 		}
 	}
@@ -196,6 +196,38 @@ func (c *client) StopContainer(logger lager.Logger, guid string) error {
 	return c.containerStore.Stop(logger, guid)
 }
 
+func (c *client) UpdateContainerLimits(logger lager.Logger, guid string, resource executor.Resource) error {
+	logger = logger.Session("update-container-limits", lager.Data{"guid": guid})
+	logger.Info("starting")
+	defer logger.Info("complete")
+
+	return c.containerStore.UpdateLimits(logger, guid, resource)
+}
+
+func (c *client) PauseContainer(logger lager.Logger, guid string) error {
+	logger = logger.Session("pause-container", lager.Data{"guid": guid})
+	logger.Info("starting")
+	defer logger.Info("complete")
+
+	return c.containerStore.Pause(logger, guid)
+}
+
+func (c *client) ResumeContainer(logger lager.Logger, guid string) error {
+	logger = logger.Session("resume-container", lager.Data{"guid": guid})
+	logger.Info("starting")
+	defer logger.Info("complete")
+
+	return c.containerStore.Resume(logger, guid)
+}
+
+func (c *client) RunProcess(logger lager.Logger, guid string, spec executor.ProcessSpec, processIO executor.ProcessIO) (executor.Process, error) {
+	logger = logger.Session("run-process", lager.Data{"guid": guid})
+	logger.Info("starting")
+	defer logger.Info("complete")
+
+	return c.containerStore.RunProcess(logger, guid, spec, processIO)
+}
+
 func (c *client) DeleteContainer(logger lager.Logger, guid string) error {
 	logger = logger.Session("delete-container", lager.Data{"guid": guid})
 
@@ -232,6 +264,8 @@ func (c *client) TotalResources(logger lager.Logger) (executor.ExecutorResources
 		MemoryMB:   totalCapacity.MemoryMB,
 		DiskMB:     totalCapacity.DiskMB,
 		Containers: totalCapacity.Containers,
+		MaxPids:    totalCapacity.MaxPids,
+		CPUShares:  totalCapacity.CPUShares,
 	}, nil
 }
 
@@ -261,6 +295,57 @@ func (c *client) GetFiles(logger lager.Logger, guid, sourcePath string) (io.Read
 	return readCloser, err
 }
 
+func (c *client) SubscribeToLogs(logger lager.Logger, guid string) (executor.LogSource, error) {
+	logger = logger.Session("subscribe-to-logs", lager.Data{"guid": guid})
+	return c.containerStore.SubscribeToLogs(logger, guid)
+}
+
+func (c *client) GetRecentLogs(logger lager.Logger, guid string) ([]executor.LogLine, error) {
+	logger = logger.Session("get-recent-logs", lager.Data{"guid": guid})
+	return c.containerStore.GetRecentLogs(logger, guid)
+}
+
+func (c *client) PutFiles(logger lager.Logger, guid, destPath string, tarStream io.Reader, user string) error {
+	logger = logger.Session("put-files", lager.Data{"guid": guid, "path": destPath})
+	logger.Info("starting")
+	defer logger.Info("complete")
+
+	return c.containerStore.PutFiles(logger, guid, destPath, tarStream, user)
+}
+
+func (c *client) ExportContainerData(logger lager.Logger, guid string, dest io.Writer) error {
+	logger = logger.Session("export-container-data", lager.Data{"guid": guid})
+
+	logger.Info("starting")
+	defer logger.Info("complete")
+
+	err := c.containerStore.ExportData(logger, guid, dest)
+	if err != nil {
+		logger.Error("failed-to-export-container-data", err)
+	}
+
+	return err
+}
+
+func (c *client) ImportContainerData(logger lager.Logger, guid string, src io.Reader) error {
+	logger = logger.Session("import-container-data", lager.Data{"guid": guid})
+
+	logger.Info("starting")
+	defer logger.Info("complete")
+
+	errChannel := make(chan error, 1)
+	c.creationWorkPool.Submit(func() {
+		errChannel <- c.containerStore.ImportData(logger, guid, src)
+	})
+
+	err := <-errChannel
+	if err != nil {
+		logger.Error("failed-to-import-container-data", err)
+	}
+
+	return err
+}
+
 func (c *client) VolumeDrivers(logger lager.Logger) ([]string, error) {
 	logger = logger.Session("volume-drivers")
 
@@ -281,6 +366,22 @@ func (c *client) SubscribeToEvents(logger lager.Logger) (executor.EventSource, e
 	return c.eventHub.Subscribe()
 }
 
+func (c *client) SubscribeToEventsWithFilter(logger lager.Logger, filter executor.EventFilter) (executor.EventSource, error) {
+	return c.eventHub.SubscribeWithFilter(filter)
+}
+
+func (c *client) SubscribeFrom(logger lager.Logger, seq uint64, filter executor.EventFilter) (executor.EventSource, error) {
+	logger = logger.Session("subscribe-from", lager.Data{"sequence": seq})
+
+	source, err := c.eventHub.SubscribeFrom(seq, filter)
+	if err != nil {
+		logger.Error("failed-to-subscribe", err)
+		return nil, err
+	}
+
+	return source, nil
+}
+
 func (c *client) Healthy(logger lager.Logger) bool {
 	c.healthyLock.RLock()
 	defer c.healthyLock.RUnlock()
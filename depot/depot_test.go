@@ -47,6 +47,8 @@ var _ = Describe("Depot", func() {
 			MemoryMB:   1024,
 			DiskMB:     1024,
 			Containers: 3,
+			MaxPids:    4096,
+			CPUShares:  2048,
 		}
 
 		CreateWorkPoolSize = 5
@@ -142,8 +144,9 @@ var _ = Describe("Depot", func() {
 				failures := depotClient.AllocateContainers(logger, requests)
 
 				Expect(failures).To(HaveLen(1))
-				expectedFailure := executor.NewAllocationFailure(&requests[0], executor.ErrContainerGuidNotAvailable.Error())
+				expectedFailure := executor.NewAllocationFailureFromError(&requests[0], executor.ErrContainerGuidNotAvailable)
 				Expect(failures[0]).To(BeEquivalentTo(expectedFailure))
+				Expect(failures[0].Reason).To(Equal(executor.AllocationFailureReasonGuidNotAvailable))
 
 				Expect(containerStore.ReserveCallCount()).To(Equal(2))
 
@@ -154,6 +157,25 @@ var _ = Describe("Depot", func() {
 			})
 		})
 
+		Context("when an admission policy rejects a container", func() {
+			var requests []executor.AllocationRequest
+
+			BeforeEach(func() {
+				requests = []executor.AllocationRequest{
+					newAllocationRequest("guid-1"),
+				}
+
+				containerStore.ReserveReturns(executor.Container{}, executor.ErrTagQuotaExceeded)
+			})
+
+			It("reports the rejection reason", func() {
+				failures := depotClient.AllocateContainers(logger, requests)
+				Expect(failures).To(HaveLen(1))
+				Expect(failures[0].ErrorMsg).To(Equal(executor.ErrTagQuotaExceeded.Error()))
+				Expect(failures[0].Reason).To(Equal(executor.AllocationFailureReasonTagQuotaExceeded))
+			})
+		})
+
 		Context("when one of the containers has empty guid", func() {
 			var requests []executor.AllocationRequest
 
@@ -167,8 +189,9 @@ var _ = Describe("Depot", func() {
 			It("should not allocate container with empty guid", func() {
 				failures := depotClient.AllocateContainers(logger, requests)
 				Expect(failures).To(HaveLen(1))
-				expectedFailure := executor.NewAllocationFailure(&requests[1], executor.ErrGuidNotSpecified.Error())
+				expectedFailure := executor.NewAllocationFailureFromError(&requests[1], executor.ErrGuidNotSpecified)
 				Expect(failures[0]).To(BeEquivalentTo(expectedFailure))
+				Expect(failures[0].Reason).To(Equal(executor.AllocationFailureReasonInvalidRequest))
 
 				Expect(containerStore.ReserveCallCount()).To(Equal(1))
 
@@ -616,6 +639,69 @@ var _ = Describe("Depot", func() {
 		})
 	})
 
+	Describe("UpdateContainerLimits", func() {
+		It("updates the container's limits in the container store", func() {
+			resource := executor.NewResource(2048, 1024, 100)
+			Expect(depotClient.UpdateContainerLimits(logger, "guid-1", resource)).To(Succeed())
+
+			Expect(containerStore.UpdateLimitsCallCount()).To(Equal(1))
+			_, guid, updated := containerStore.UpdateLimitsArgsForCall(0)
+			Expect(guid).To(Equal("guid-1"))
+			Expect(updated).To(Equal(resource))
+		})
+
+		Context("when the container store fails to update the limits", func() {
+			BeforeEach(func() {
+				containerStore.UpdateLimitsReturns(executor.ErrInsufficientResourcesAvailable)
+			})
+
+			It("returns the error", func() {
+				err := depotClient.UpdateContainerLimits(logger, "guid-1", executor.Resource{})
+				Expect(err).To(Equal(executor.ErrInsufficientResourcesAvailable))
+			})
+		})
+	})
+
+	Describe("PauseContainer", func() {
+		It("pauses the container in the container store", func() {
+			Expect(depotClient.PauseContainer(logger, "guid-1")).To(Succeed())
+
+			Expect(containerStore.PauseCallCount()).To(Equal(1))
+			_, guid := containerStore.PauseArgsForCall(0)
+			Expect(guid).To(Equal("guid-1"))
+		})
+
+		Context("when the container store fails to pause the container", func() {
+			BeforeEach(func() {
+				containerStore.PauseReturns(executor.ErrPauseNotSupported)
+			})
+
+			It("returns the error", func() {
+				Expect(depotClient.PauseContainer(logger, "guid-1")).To(Equal(executor.ErrPauseNotSupported))
+			})
+		})
+	})
+
+	Describe("ResumeContainer", func() {
+		It("resumes the container in the container store", func() {
+			Expect(depotClient.ResumeContainer(logger, "guid-1")).To(Succeed())
+
+			Expect(containerStore.ResumeCallCount()).To(Equal(1))
+			_, guid := containerStore.ResumeArgsForCall(0)
+			Expect(guid).To(Equal("guid-1"))
+		})
+
+		Context("when the container store fails to resume the container", func() {
+			BeforeEach(func() {
+				containerStore.ResumeReturns(executor.ErrInvalidTransition)
+			})
+
+			It("returns the error", func() {
+				Expect(depotClient.ResumeContainer(logger, "guid-1")).To(Equal(executor.ErrInvalidTransition))
+			})
+		})
+	})
+
 	Describe("GetContainer", func() {
 		var container executor.Container
 
@@ -646,11 +732,59 @@ var _ = Describe("Depot", func() {
 		})
 	})
 
+	Describe("ExportContainerData", func() {
+		It("exports the container data from the container store", func() {
+			dest := gbytes.NewBuffer()
+			err := depotClient.ExportContainerData(logger, "guid-1", dest)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(containerStore.ExportDataCallCount()).To(Equal(1))
+			_, guid, writer := containerStore.ExportDataArgsForCall(0)
+			Expect(guid).To(Equal("guid-1"))
+			Expect(writer).To(Equal(dest))
+		})
+
+		Context("when the container store fails to export the container data", func() {
+			BeforeEach(func() {
+				containerStore.ExportDataReturns(executor.ErrContainerNotExportable)
+			})
+
+			It("returns the error", func() {
+				err := depotClient.ExportContainerData(logger, "guid-1", gbytes.NewBuffer())
+				Expect(err).To(Equal(executor.ErrContainerNotExportable))
+			})
+		})
+	})
+
+	Describe("ImportContainerData", func() {
+		It("imports the data into the container store", func() {
+			src := gbytes.BufferWithBytes([]byte("export"))
+			err := depotClient.ImportContainerData(logger, "guid-1", src)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(containerStore.ImportDataCallCount()).To(Equal(1))
+			_, guid, reader := containerStore.ImportDataArgsForCall(0)
+			Expect(guid).To(Equal("guid-1"))
+			Expect(reader).To(Equal(src))
+		})
+
+		Context("when the container store fails to import the data", func() {
+			BeforeEach(func() {
+				containerStore.ImportDataReturns(executor.ErrInvalidContainerExport)
+			})
+
+			It("returns the error", func() {
+				err := depotClient.ImportContainerData(logger, "guid-1", gbytes.NewBuffer())
+				Expect(err).To(Equal(executor.ErrInvalidContainerExport))
+			})
+		})
+	})
+
 	Describe("RemainingResources", func() {
 		var resources executor.ExecutorResources
 
 		BeforeEach(func() {
-			resources = executor.NewExecutorResources(1024, 1024, 3)
+			resources = executor.NewExecutorResources(1024, 1024, 3, 4096, 2048)
 			containerStore.RemainingResourcesReturns(resources)
 		})
 
@@ -669,6 +803,54 @@ var _ = Describe("Depot", func() {
 		})
 	})
 
+	Describe("SubscribeToEventsWithFilter", func() {
+		It("subscribes to the hub with the filter", func() {
+			source := new(fakes.FakeEventSource)
+			eventHub.SubscribeWithFilterReturns(source, nil)
+
+			filter := executor.EventFilter{Types: []executor.EventType{executor.EventTypeContainerComplete}}
+			actualSource, err := depotClient.SubscribeToEventsWithFilter(logger, filter)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(actualSource).To(Equal(source))
+
+			Expect(eventHub.SubscribeWithFilterCallCount()).To(Equal(1))
+			Expect(eventHub.SubscribeWithFilterArgsForCall(0)).To(Equal(filter))
+		})
+	})
+
+	Describe("SubscribeFrom", func() {
+		var filter executor.EventFilter
+
+		BeforeEach(func() {
+			filter = executor.EventFilter{Tags: executor.Tags{"domain": "cf-apps"}}
+		})
+
+		It("subscribes to the hub from the given sequence", func() {
+			source := new(fakes.FakeEventSource)
+			eventHub.SubscribeFromReturns(source, nil)
+
+			actualSource, err := depotClient.SubscribeFrom(logger, 42, filter)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(actualSource).To(Equal(source))
+
+			Expect(eventHub.SubscribeFromCallCount()).To(Equal(1))
+			seq, actualFilter := eventHub.SubscribeFromArgsForCall(0)
+			Expect(seq).To(Equal(uint64(42)))
+			Expect(actualFilter).To(Equal(filter))
+		})
+
+		Context("when the requested events have been evicted", func() {
+			BeforeEach(func() {
+				eventHub.SubscribeFromReturns(nil, executor.ErrEventsEvicted)
+			})
+
+			It("returns the error", func() {
+				_, err := depotClient.SubscribeFrom(logger, 42, filter)
+				Expect(err).To(Equal(executor.ErrEventsEvicted))
+			})
+		})
+	})
+
 	Describe("VolumeDrivers", func() {
 		Context("when getting volume drivers succeeds", func() {
 			BeforeEach(func() {
@@ -36,6 +36,33 @@ type FakeHub struct {
 		result1 executor.EventSource
 		result2 error
 	}
+	SubscribeFromStub        func(uint64, executor.EventFilter) (executor.EventSource, error)
+	subscribeFromMutex       sync.RWMutex
+	subscribeFromArgsForCall []struct {
+		arg1 uint64
+		arg2 executor.EventFilter
+	}
+	subscribeFromReturns struct {
+		result1 executor.EventSource
+		result2 error
+	}
+	subscribeFromReturnsOnCall map[int]struct {
+		result1 executor.EventSource
+		result2 error
+	}
+	SubscribeWithFilterStub        func(executor.EventFilter) (executor.EventSource, error)
+	subscribeWithFilterMutex       sync.RWMutex
+	subscribeWithFilterArgsForCall []struct {
+		arg1 executor.EventFilter
+	}
+	subscribeWithFilterReturns struct {
+		result1 executor.EventSource
+		result2 error
+	}
+	subscribeWithFilterReturnsOnCall map[int]struct {
+		result1 executor.EventSource
+		result2 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -181,6 +208,135 @@ func (fake *FakeHub) SubscribeReturnsOnCall(i int, result1 executor.EventSource,
 	}{result1, result2}
 }
 
+func (fake *FakeHub) SubscribeFrom(arg1 uint64, arg2 executor.EventFilter) (executor.EventSource, error) {
+	fake.subscribeFromMutex.Lock()
+	ret, specificReturn := fake.subscribeFromReturnsOnCall[len(fake.subscribeFromArgsForCall)]
+	fake.subscribeFromArgsForCall = append(fake.subscribeFromArgsForCall, struct {
+		arg1 uint64
+		arg2 executor.EventFilter
+	}{arg1, arg2})
+	stub := fake.SubscribeFromStub
+	fakeReturns := fake.subscribeFromReturns
+	fake.recordInvocation("SubscribeFrom", []interface{}{arg1, arg2})
+	fake.subscribeFromMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeHub) SubscribeFromCallCount() int {
+	fake.subscribeFromMutex.RLock()
+	defer fake.subscribeFromMutex.RUnlock()
+	return len(fake.subscribeFromArgsForCall)
+}
+
+func (fake *FakeHub) SubscribeFromCalls(stub func(uint64, executor.EventFilter) (executor.EventSource, error)) {
+	fake.subscribeFromMutex.Lock()
+	defer fake.subscribeFromMutex.Unlock()
+	fake.SubscribeFromStub = stub
+}
+
+func (fake *FakeHub) SubscribeFromArgsForCall(i int) (uint64, executor.EventFilter) {
+	fake.subscribeFromMutex.RLock()
+	defer fake.subscribeFromMutex.RUnlock()
+	argsForCall := fake.subscribeFromArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeHub) SubscribeFromReturns(result1 executor.EventSource, result2 error) {
+	fake.subscribeFromMutex.Lock()
+	defer fake.subscribeFromMutex.Unlock()
+	fake.SubscribeFromStub = nil
+	fake.subscribeFromReturns = struct {
+		result1 executor.EventSource
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeHub) SubscribeFromReturnsOnCall(i int, result1 executor.EventSource, result2 error) {
+	fake.subscribeFromMutex.Lock()
+	defer fake.subscribeFromMutex.Unlock()
+	fake.SubscribeFromStub = nil
+	if fake.subscribeFromReturnsOnCall == nil {
+		fake.subscribeFromReturnsOnCall = make(map[int]struct {
+			result1 executor.EventSource
+			result2 error
+		})
+	}
+	fake.subscribeFromReturnsOnCall[i] = struct {
+		result1 executor.EventSource
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeHub) SubscribeWithFilter(arg1 executor.EventFilter) (executor.EventSource, error) {
+	fake.subscribeWithFilterMutex.Lock()
+	ret, specificReturn := fake.subscribeWithFilterReturnsOnCall[len(fake.subscribeWithFilterArgsForCall)]
+	fake.subscribeWithFilterArgsForCall = append(fake.subscribeWithFilterArgsForCall, struct {
+		arg1 executor.EventFilter
+	}{arg1})
+	stub := fake.SubscribeWithFilterStub
+	fakeReturns := fake.subscribeWithFilterReturns
+	fake.recordInvocation("SubscribeWithFilter", []interface{}{arg1})
+	fake.subscribeWithFilterMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeHub) SubscribeWithFilterCallCount() int {
+	fake.subscribeWithFilterMutex.RLock()
+	defer fake.subscribeWithFilterMutex.RUnlock()
+	return len(fake.subscribeWithFilterArgsForCall)
+}
+
+func (fake *FakeHub) SubscribeWithFilterCalls(stub func(executor.EventFilter) (executor.EventSource, error)) {
+	fake.subscribeWithFilterMutex.Lock()
+	defer fake.subscribeWithFilterMutex.Unlock()
+	fake.SubscribeWithFilterStub = stub
+}
+
+func (fake *FakeHub) SubscribeWithFilterArgsForCall(i int) executor.EventFilter {
+	fake.subscribeWithFilterMutex.RLock()
+	defer fake.subscribeWithFilterMutex.RUnlock()
+	argsForCall := fake.subscribeWithFilterArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeHub) SubscribeWithFilterReturns(result1 executor.EventSource, result2 error) {
+	fake.subscribeWithFilterMutex.Lock()
+	defer fake.subscribeWithFilterMutex.Unlock()
+	fake.SubscribeWithFilterStub = nil
+	fake.subscribeWithFilterReturns = struct {
+		result1 executor.EventSource
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeHub) SubscribeWithFilterReturnsOnCall(i int, result1 executor.EventSource, result2 error) {
+	fake.subscribeWithFilterMutex.Lock()
+	defer fake.subscribeWithFilterMutex.Unlock()
+	fake.SubscribeWithFilterStub = nil
+	if fake.subscribeWithFilterReturnsOnCall == nil {
+		fake.subscribeWithFilterReturnsOnCall = make(map[int]struct {
+			result1 executor.EventSource
+			result2 error
+		})
+	}
+	fake.subscribeWithFilterReturnsOnCall[i] = struct {
+		result1 executor.EventSource
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeHub) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
@@ -190,6 +346,10 @@ func (fake *FakeHub) Invocations() map[string][][]interface{} {
 	defer fake.emitMutex.RUnlock()
 	fake.subscribeMutex.RLock()
 	defer fake.subscribeMutex.RUnlock()
+	fake.subscribeFromMutex.RLock()
+	defer fake.subscribeFromMutex.RUnlock()
+	fake.subscribeWithFilterMutex.RLock()
+	defer fake.subscribeWithFilterMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
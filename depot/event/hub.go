@@ -1,59 +1,213 @@
 package event
 
 import (
-	"code.cloudfoundry.org/eventhub"
+	"errors"
+	"sync"
+
 	"code.cloudfoundry.org/executor"
 )
 
 const SUBSCRIBER_BUFFER = 1024
 
+// REPLAY_BUFFER is the number of recent events kept for subscribers that
+// resume from a sequence number.
+const REPLAY_BUFFER = 1024
+
+var (
+	ErrReadFromClosedSource  = errors.New("read from closed source")
+	ErrSubscribedToClosedHub = errors.New("subscribed to closed hub")
+)
+
 //go:generate counterfeiter -o fakes/fake_hub.go . Hub
 type Hub interface {
 	Emit(executor.Event)
 	Subscribe() (executor.EventSource, error)
+
+	// SubscribeWithFilter only delivers the events matching filter, which
+	// spares the subscriber from being woken up for every other event.
+	SubscribeWithFilter(filter executor.EventFilter) (executor.EventSource, error)
+
+	// SubscribeFrom replays the events matching filter that were emitted
+	// after seq and then streams new ones. It returns
+	// executor.ErrEventsEvicted if some of those events are no longer kept, or
+	// if seq is ahead of the hub, as it is after the executor restarts; the
+	// subscriber has missed events either way.
+	SubscribeFrom(seq uint64, filter executor.EventFilter) (executor.EventSource, error)
 	Close() error
 }
 
 func NewHub() Hub {
 	return &hub{
-		rawHub: eventhub.NewNonBlocking(SUBSCRIBER_BUFFER),
+		subscribers: make(map[*source]struct{}),
+		recent:      make([]executor.Event, 0, REPLAY_BUFFER),
 	}
 }
 
+// hub fans events out to its subscribers without blocking: a subscriber whose
+// buffer is full misses the event.
 type hub struct {
-	rawHub eventhub.Hub
+	// lock serializes emitting events with subscribing, so that a resumed
+	// subscription neither misses nor repeats the events emitted while it is
+	// set up
+	lock        sync.Mutex
+	subscribers map[*source]struct{}
+	closed      bool
+
+	lastSeq uint64
+	recent  []executor.Event
+	oldest  int
 }
 
 func (hub *hub) Subscribe() (executor.EventSource, error) {
-	rawSource, err := hub.rawHub.Subscribe()
-	if err != nil {
-		return nil, err
+	return hub.SubscribeWithFilter(executor.EventFilter{})
+}
+
+func (hub *hub) SubscribeWithFilter(filter executor.EventFilter) (executor.EventSource, error) {
+	hub.lock.Lock()
+	defer hub.lock.Unlock()
+
+	return hub.subscribe(filter, nil)
+}
+
+func (hub *hub) SubscribeFrom(seq uint64, filter executor.EventFilter) (executor.EventSource, error) {
+	hub.lock.Lock()
+	defer hub.lock.Unlock()
+
+	if seq > hub.lastSeq {
+		return nil, executor.ErrEventsEvicted
+	}
+
+	missed := hub.lastSeq - seq
+	if missed > uint64(len(hub.recent)) {
+		return nil, executor.ErrEventsEvicted
+	}
+
+	replay := []executor.Event{}
+	for i := len(hub.recent) - int(missed); i < len(hub.recent); i++ {
+		ev := hub.recent[(hub.oldest+i)%len(hub.recent)]
+		if filter.Matches(ev) {
+			replay = append(replay, ev)
+		}
+	}
+
+	return hub.subscribe(filter, replay)
+}
+
+// subscribe must be called with the lock held.
+func (hub *hub) subscribe(filter executor.EventFilter, replay []executor.Event) (executor.EventSource, error) {
+	if hub.closed {
+		return nil, ErrSubscribedToClosedHub
+	}
+
+	sub := &source{
+		hub:    hub,
+		filter: filter,
+		replay: replay,
+		events: make(chan executor.Event, SUBSCRIBER_BUFFER),
+		done:   make(chan struct{}),
 	}
+	hub.subscribers[sub] = struct{}{}
 
-	return executorSource{rawSource}, nil
+	return sub, nil
 }
 
 func (hub *hub) Emit(ev executor.Event) {
-	hub.rawHub.Emit(ev)
+	hub.lock.Lock()
+	defer hub.lock.Unlock()
+
+	if hub.closed {
+		return
+	}
+
+	hub.lastSeq++
+	metadata := ev.Metadata()
+	metadata.Seq = hub.lastSeq
+	ev = ev.WithMetadata(metadata)
+
+	if len(hub.recent) < cap(hub.recent) {
+		hub.recent = append(hub.recent, ev)
+	} else {
+		hub.recent[hub.oldest] = ev
+		hub.oldest = (hub.oldest + 1) % len(hub.recent)
+	}
+
+	for sub := range hub.subscribers {
+		if !sub.filter.Matches(ev) {
+			continue
+		}
+
+		select {
+		case sub.events <- ev:
+		default:
+		}
+	}
 }
 
 func (hub *hub) Close() error {
-	return hub.rawHub.Close()
+	hub.lock.Lock()
+	defer hub.lock.Unlock()
+
+	if hub.closed {
+		return nil
+	}
+	hub.closed = true
+
+	for sub := range hub.subscribers {
+		sub.close()
+	}
+	hub.subscribers = nil
+
+	return nil
 }
 
-type executorSource struct {
-	rawSource eventhub.Source
+func (hub *hub) unsubscribe(sub *source) {
+	hub.lock.Lock()
+	defer hub.lock.Unlock()
+
+	delete(hub.subscribers, sub)
 }
 
-func (source executorSource) Next() (executor.Event, error) {
-	ev, err := source.rawSource.Next()
-	if err != nil {
-		return nil, err
+type source struct {
+	hub    *hub
+	filter executor.EventFilter
+
+	lock   sync.Mutex
+	replay []executor.Event
+
+	events    chan executor.Event
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func (source *source) Next() (executor.Event, error) {
+	source.lock.Lock()
+	if len(source.replay) > 0 {
+		ev := source.replay[0]
+		source.replay = source.replay[1:]
+		source.lock.Unlock()
+		return ev, nil
 	}
+	source.lock.Unlock()
+
+	select {
+	case ev := <-source.events:
+		return ev, nil
+	case <-source.done:
+		return nil, ErrReadFromClosedSource
+	}
+}
 
-	return ev.(executor.Event), nil
+func (source *source) Close() error {
+	source.hub.unsubscribe(source)
+	source.close()
+	return nil
 }
 
-func (source executorSource) Close() error {
-	return source.rawSource.Close()
+func (source *source) close() {
+	source.closeOnce.Do(func() {
+		source.lock.Lock()
+		source.replay = nil
+		source.lock.Unlock()
+		close(source.done)
+	})
 }
@@ -0,0 +1,198 @@
+package event_test
+
+import (
+	"code.cloudfoundry.org/executor"
+	"code.cloudfoundry.org/executor/depot/event"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Hub", func() {
+	var hub event.Hub
+
+	newEvent := func(guid string) executor.Event {
+		return executor.NewContainerRunningEvent(executor.Container{Guid: guid})
+	}
+
+	nextGuid := func(source executor.EventSource) string {
+		ev, err := source.Next()
+		Expect(err).NotTo(HaveOccurred())
+		return ev.(executor.LifecycleEvent).Container().Guid
+	}
+
+	BeforeEach(func() {
+		hub = event.NewHub()
+	})
+
+	AfterEach(func() {
+		hub.Close()
+	})
+
+	It("numbers events in the order they are emitted", func() {
+		source, err := hub.Subscribe()
+		Expect(err).NotTo(HaveOccurred())
+		defer source.Close()
+
+		hub.Emit(newEvent("a"))
+		hub.Emit(newEvent("b"))
+
+		ev, err := source.Next()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ev.Sequence()).To(Equal(uint64(1)))
+
+		ev, err = source.Next()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ev.Sequence()).To(Equal(uint64(2)))
+	})
+
+	It("keeps the rest of the event metadata", func() {
+		source, err := hub.Subscribe()
+		Expect(err).NotTo(HaveOccurred())
+		defer source.Close()
+
+		hub.Emit(newEvent("a").WithMetadata(executor.EventMetadata{
+			Timestamp:     1234,
+			PreviousState: executor.StateCreated,
+		}))
+
+		ev, err := source.Next()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ev.Metadata()).To(Equal(executor.EventMetadata{
+			Seq:           1,
+			Timestamp:     1234,
+			PreviousState: executor.StateCreated,
+		}))
+	})
+
+	Describe("SubscribeWithFilter", func() {
+		It("only delivers the matching events", func() {
+			source, err := hub.SubscribeWithFilter(executor.EventFilter{
+				Types: []executor.EventType{executor.EventTypeContainerComplete},
+				Tags:  executor.Tags{"app": "app-1"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			defer source.Close()
+
+			hub.Emit(executor.NewContainerRunningEvent(executor.Container{Guid: "running", Tags: executor.Tags{"app": "app-1"}}))
+			hub.Emit(executor.NewContainerCompleteEvent(executor.Container{Guid: "other-app", Tags: executor.Tags{"app": "app-2"}}))
+			hub.Emit(executor.NewContainerCompleteEvent(executor.Container{Guid: "complete", Tags: executor.Tags{"app": "app-1"}}))
+
+			Expect(nextGuid(source)).To(Equal("complete"))
+		})
+	})
+
+	Describe("Close", func() {
+		It("closes the subscriptions", func() {
+			source, err := hub.Subscribe()
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(hub.Close()).To(Succeed())
+
+			_, err = source.Next()
+			Expect(err).To(Equal(event.ErrReadFromClosedSource))
+		})
+
+		It("rejects new subscriptions", func() {
+			Expect(hub.Close()).To(Succeed())
+
+			_, err := hub.Subscribe()
+			Expect(err).To(Equal(event.ErrSubscribedToClosedHub))
+		})
+	})
+
+	It("stops delivering events to closed sources", func() {
+		source, err := hub.Subscribe()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(source.Close()).To(Succeed())
+
+		hub.Emit(newEvent("a"))
+
+		_, err = source.Next()
+		Expect(err).To(Equal(event.ErrReadFromClosedSource))
+	})
+
+	Describe("SubscribeFrom", func() {
+		BeforeEach(func() {
+			hub.Emit(newEvent("a"))
+			hub.Emit(newEvent("b"))
+			hub.Emit(newEvent("c"))
+		})
+
+		It("replays the events after the sequence and then streams new ones", func() {
+			source, err := hub.SubscribeFrom(1, executor.EventFilter{})
+			Expect(err).NotTo(HaveOccurred())
+			defer source.Close()
+
+			hub.Emit(newEvent("d"))
+
+			Expect(nextGuid(source)).To(Equal("b"))
+			Expect(nextGuid(source)).To(Equal("c"))
+			Expect(nextGuid(source)).To(Equal("d"))
+		})
+
+		It("replays every kept event from the beginning", func() {
+			source, err := hub.SubscribeFrom(0, executor.EventFilter{})
+			Expect(err).NotTo(HaveOccurred())
+			defer source.Close()
+
+			Expect(nextGuid(source)).To(Equal("a"))
+			Expect(nextGuid(source)).To(Equal("b"))
+			Expect(nextGuid(source)).To(Equal("c"))
+		})
+
+		It("only replays the events matching the filter", func() {
+			source, err := hub.SubscribeFrom(0, executor.EventFilter{Types: []executor.EventType{executor.EventTypeContainerComplete}})
+			Expect(err).NotTo(HaveOccurred())
+			defer source.Close()
+
+			hub.Emit(executor.NewContainerCompleteEvent(executor.Container{Guid: "d"}))
+
+			Expect(nextGuid(source)).To(Equal("d"))
+		})
+
+		It("only streams new events when the subscriber is up to date", func() {
+			source, err := hub.SubscribeFrom(3, executor.EventFilter{})
+			Expect(err).NotTo(HaveOccurred())
+			defer source.Close()
+
+			hub.Emit(newEvent("d"))
+
+			Expect(nextGuid(source)).To(Equal("d"))
+		})
+
+		It("fails when the sequence is ahead of the hub", func() {
+			_, err := hub.SubscribeFrom(4, executor.EventFilter{})
+			Expect(err).To(Equal(executor.ErrEventsEvicted))
+		})
+
+		Context("when more events were emitted than are kept", func() {
+			BeforeEach(func() {
+				for i := 0; i < event.REPLAY_BUFFER; i++ {
+					hub.Emit(newEvent("filler"))
+				}
+				hub.Emit(newEvent("last"))
+			})
+
+			It("fails when some of the missed events were evicted", func() {
+				_, err := hub.SubscribeFrom(3, executor.EventFilter{})
+				Expect(err).To(Equal(executor.ErrEventsEvicted))
+			})
+
+			It("replays the missed events when they are all kept", func() {
+				source, err := hub.SubscribeFrom(4, executor.EventFilter{})
+				Expect(err).NotTo(HaveOccurred())
+				defer source.Close()
+
+				ev, err := source.Next()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(ev.Sequence()).To(Equal(uint64(5)))
+
+				for i := 0; i < event.REPLAY_BUFFER-2; i++ {
+					_, err := source.Next()
+					Expect(err).NotTo(HaveOccurred())
+				}
+				Expect(nextGuid(source)).To(Equal("last"))
+			})
+		})
+	})
+})
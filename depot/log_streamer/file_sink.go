@@ -0,0 +1,183 @@
+package log_streamer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FILE_SINK_MAX_OPEN_FILES bounds the log files a file sink keeps open. The
+// file written to the longest ago is closed to open another one.
+const FILE_SINK_MAX_OPEN_FILES = 128
+
+// NewFileSink returns a LogSink appending the lines of every container
+// instance to <dir>/<source_id>/<instance_id>.log. A file growing past
+// maxFileSize is rotated to <instance_id>.log.1, and so on up to maxFiles
+// rotated files; the oldest is removed. A maxFileSize of zero never rotates.
+func NewFileSink(dir string, maxFileSize int64, maxFiles int) LogSink {
+	return &fileSink{
+		dir:         dir,
+		maxFileSize: maxFileSize,
+		maxFiles:    maxFiles,
+		files:       map[string]*logFile{},
+	}
+}
+
+type fileSink struct {
+	dir         string
+	maxFileSize int64
+	maxFiles    int
+
+	lock  sync.Mutex
+	files map[string]*logFile
+}
+
+type logFile struct {
+	file        *os.File
+	size        int64
+	lastWritten time.Time
+}
+
+func (s *fileSink) SendAppLog(message, sourceType string, tags map[string]string) error {
+	return s.write("OUT", message, sourceType, tags)
+}
+
+func (s *fileSink) SendAppErrorLog(message, sourceType string, tags map[string]string) error {
+	return s.write("ERR", message, sourceType, tags)
+}
+
+// ForgetInstance closes the file of the instance. A line sent afterwards
+// opens it again.
+func (s *fileSink) ForgetInstance(tags map[string]string) {
+	path := s.path(tags)
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if f, ok := s.files[path]; ok {
+		f.file.Close()
+		delete(s.files, path)
+	}
+}
+
+func (s *fileSink) path(tags map[string]string) string {
+	return filepath.Join(s.dir, filePathComponent(tags["source_id"]), filePathComponent(tags["instance_id"])+".log")
+}
+
+func (s *fileSink) write(stream, message, sourceType string, tags map[string]string) error {
+	now := time.Now()
+	line := fmt.Sprintf("%s [%s] %s %s\n", now.UTC().Format(time.RFC3339Nano), sourceType, stream, message)
+	path := s.path(tags)
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	f, err := s.open(path)
+	if err != nil {
+		return err
+	}
+
+	if s.maxFileSize > 0 && f.size > 0 && f.size+int64(len(line)) > s.maxFileSize {
+		err = s.rotate(path)
+		if err != nil {
+			return err
+		}
+
+		f, err = s.open(path)
+		if err != nil {
+			return err
+		}
+	}
+
+	n, err := f.file.WriteString(line)
+	f.size += int64(n)
+	f.lastWritten = now
+	return err
+}
+
+// open must be called with the lock held.
+func (s *fileSink) open(path string) (*logFile, error) {
+	if f, ok := s.files[path]; ok {
+		return f, nil
+	}
+
+	if len(s.files) >= FILE_SINK_MAX_OPEN_FILES {
+		s.closeLeastRecentlyWritten()
+	}
+
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	f := &logFile{file: file, size: info.Size()}
+	s.files[path] = f
+	return f, nil
+}
+
+// rotate must be called with the lock held.
+func (s *fileSink) rotate(path string) error {
+	if f, ok := s.files[path]; ok {
+		f.file.Close()
+		delete(s.files, path)
+	}
+
+	if s.maxFiles <= 0 {
+		return os.Remove(path)
+	}
+
+	for i := s.maxFiles - 1; i > 0; i-- {
+		err := os.Rename(fmt.Sprintf("%s.%d", path, i), fmt.Sprintf("%s.%d", path, i+1))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	return os.Rename(path, path+".1")
+}
+
+// closeLeastRecentlyWritten must be called with the lock held.
+func (s *fileSink) closeLeastRecentlyWritten() {
+	var oldestPath string
+	var oldest *logFile
+	for path, f := range s.files {
+		if oldest == nil || f.lastWritten.Before(oldest.lastWritten) {
+			oldestPath = path
+			oldest = f
+		}
+	}
+
+	if oldest != nil {
+		oldest.file.Close()
+		delete(s.files, oldestPath)
+	}
+}
+
+// filePathComponent keeps a tag from escaping the sink's directory.
+func filePathComponent(tag string) string {
+	tag = strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == os.PathSeparator || r < 32 {
+			return '_'
+		}
+		return r
+	}, tag)
+
+	if tag == "" || tag == "." || tag == ".." {
+		return "_"
+	}
+	return tag
+}
@@ -0,0 +1,102 @@
+package log_streamer
+
+import (
+	"encoding/json"
+	"strings"
+
+	"code.cloudfoundry.org/executor"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+)
+
+const DefaultJSONLevelField = "level"
+
+var DefaultJSONPromotedFields = []string{"level", "trace_id", "span_id"}
+
+// jsonErrorLevels are the levels, lowercased, of error or higher severity.
+var jsonErrorLevels = map[string]bool{
+	"error":     true,
+	"err":       true,
+	"severe":    true,
+	"fatal":     true,
+	"critical":  true,
+	"crit":      true,
+	"alert":     true,
+	"emergency": true,
+	"emerg":     true,
+	"panic":     true,
+}
+
+// jsonLineParser reads the lines that are JSON objects. Their promoted fields
+// become tags, and the ones with an error level or higher are sent as errors.
+type jsonLineParser struct {
+	promotedFields []string
+	levelField     string
+}
+
+// newJSONLineParser returns nil when config is nil.
+func newJSONLineParser(config *executor.JSONLogConfig) *jsonLineParser {
+	if config == nil {
+		return nil
+	}
+
+	parser := &jsonLineParser{
+		promotedFields: DefaultJSONPromotedFields,
+		levelField:     DefaultJSONLevelField,
+	}
+	if len(config.PromotedFields) > 0 {
+		parser.promotedFields = config.PromotedFields
+	}
+	if config.LevelField != "" {
+		parser.levelField = config.LevelField
+	}
+
+	return parser
+}
+
+// parse returns the tags and the type of message. Lines that are not JSON
+// objects keep them.
+func (p *jsonLineParser) parse(message string, tags map[string]string, messageType loggregator_v2.Log_Type) (map[string]string, loggregator_v2.Log_Type) {
+	trimmed := strings.TrimSpace(message)
+	if !strings.HasPrefix(trimmed, "{") || !strings.HasSuffix(trimmed, "}") {
+		return tags, messageType
+	}
+
+	fields := map[string]json.RawMessage{}
+	err := json.Unmarshal([]byte(trimmed), &fields)
+	if err != nil {
+		return tags, messageType
+	}
+
+	promoted := map[string]string{}
+	for k, v := range tags {
+		promoted[k] = v
+	}
+	for _, field := range p.promotedFields {
+		value, ok := fields[field]
+		if !ok {
+			continue
+		}
+		// the tags of the container are never overridden
+		if _, ok := tags[field]; ok {
+			continue
+		}
+		promoted[field] = jsonFieldString(value)
+	}
+
+	if level, ok := fields[p.levelField]; ok && jsonErrorLevels[strings.ToLower(jsonFieldString(level))] {
+		messageType = loggregator_v2.Log_ERR
+	}
+
+	return promoted, messageType
+}
+
+// jsonFieldString returns a string field unquoted, and any other field as
+// its JSON.
+func jsonFieldString(value json.RawMessage) string {
+	var s string
+	err := json.Unmarshal(value, &s)
+	if err == nil {
+		return s
+	}
+	return string(value)
+}
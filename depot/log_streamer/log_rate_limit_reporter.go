@@ -2,10 +2,13 @@ package log_streamer
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	loggingclient "code.cloudfoundry.org/diego-logging-client"
+	loggregator "code.cloudfoundry.org/go-loggregator"
 	"golang.org/x/time/rate"
 )
 
@@ -15,38 +18,120 @@ const (
 	LogRateLimitAllowDelta               = time.Millisecond
 )
 
+// the totals of the lines and bytes the byte rate limit dropped for an app
+// instance; they are sent as gauges, since only gauges carry the instance's
+// tags through the IngressClient
+const (
+	AppInstanceDroppedLogLinesTotal = "AppInstanceDroppedLogLinesTotal"
+	AppInstanceDroppedLogBytesTotal = "AppInstanceDroppedLogBytesTotal"
+)
+
+var ErrLogRateLimitExceeded = errors.New("log rate limit exceeded")
+
+// DroppedLogs totals the lines and bytes the byte rate limit dropped for an
+// app instance. It is shared by all the LogStreamers of the container, so that
+// the totals of its streams, and of the LogStreamers made one after the other,
+// add up rather than start over.
+type DroppedLogs struct {
+	reportLimiter *rate.Limiter
+
+	lock  sync.Mutex
+	lines uint64
+	bytes uint64
+}
+
+func NewDroppedLogs(reportInterval time.Duration) *DroppedLogs {
+	return &DroppedLogs{
+		reportLimiter: rate.NewLimiter(rate.Every(reportInterval), 1),
+	}
+}
+
+// Totals returns the lines and bytes dropped so far.
+func (d *DroppedLogs) Totals() (uint64, uint64) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	return d.lines, d.bytes
+}
+
+func (d *DroppedLogs) add(messageSize int) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.lines++
+	d.bytes += uint64(messageSize)
+}
+
+func (d *DroppedLogs) report(metronClient loggingclient.IngressClient, tags map[string]string) {
+	if !d.reportLimiter.Allow() {
+		return
+	}
+
+	lines, bytes := d.Totals()
+	gaugeOpts := []loggregator.EmitGaugeOption{
+		loggregator.WithGaugeSourceInfo(tags["source_id"], tags["instance_id"]),
+		loggregator.EmitGaugeOption(loggregator.WithEnvelopeTags(tags)),
+	}
+	metronClient.SendMetric(AppInstanceDroppedLogLinesTotal, int(lines), gaugeOpts...)
+	metronClient.SendMetric(AppInstanceDroppedLogBytesTotal, int(bytes), gaugeOpts...)
+}
+
 type logRateLimitReporter struct {
 	ctx          context.Context
+	sink         LogSink
 	metronClient loggingclient.IngressClient
 
 	maxLogLinesPerSecond        int
 	maxLogLinesPerSecondLimiter *rate.Limiter
+	maxLogBytesPerSecond        int64
+	maxLogBytesPerSecondLimiter *rate.Limiter
 	metricReportLimiter         *rate.Limiter
 	logReportLimiter            *rate.Limiter
+	droppedLogs                 *DroppedLogs
 }
 
 func newLogRateLimitReporter(
 	ctx context.Context,
+	sink LogSink,
 	metronClient loggingclient.IngressClient,
 	maxLogLinesPerSecond int,
+	maxLogBytesPerSecond int64,
 	logRateLimitExceededReportInterval time.Duration,
+	droppedLogs *DroppedLogs,
 ) *logRateLimitReporter {
 	reporter := &logRateLimitReporter{
 		ctx:                  ctx,
+		sink:                 sink,
 		metronClient:         metronClient,
 		maxLogLinesPerSecond: maxLogLinesPerSecond,
+		maxLogBytesPerSecond: maxLogBytesPerSecond,
 		metricReportLimiter:  rate.NewLimiter(rate.Every(logRateLimitExceededReportInterval), 1),
 		logReportLimiter:     rate.NewLimiter(rate.Every(LogRateLimitExceededLogInterval), 1),
+		droppedLogs:          droppedLogs,
 	}
 
 	if maxLogLinesPerSecond > 0 {
 		reporter.maxLogLinesPerSecondLimiter = rate.NewLimiter(rate.Every(time.Second/time.Duration(maxLogLinesPerSecond)), maxLogLinesPerSecond)
 	}
 
+	if maxLogBytesPerSecond > 0 {
+		reporter.maxLogBytesPerSecondLimiter = rate.NewLimiter(rate.Limit(maxLogBytesPerSecond), int(maxLogBytesPerSecond))
+	}
+
 	return reporter
 }
 
-func (r *logRateLimitReporter) Report(sourceName string, tags map[string]string) error {
+// Report applies the rate limits to a message of messageSize bytes. It delays
+// the message while it would exceed the line rate limit and drops it, with
+// ErrLogRateLimitExceeded, when it exceeds the byte rate limit.
+func (r *logRateLimitReporter) Report(sourceName string, tags map[string]string, messageSize int) error {
+	err := r.limitLines(sourceName, tags)
+	if err != nil {
+		return err
+	}
+
+	return r.limitBytes(sourceName, tags, messageSize)
+}
+
+func (r *logRateLimitReporter) limitLines(sourceName string, tags map[string]string) error {
 	if r.maxLogLinesPerSecond == 0 {
 		return nil
 	}
@@ -73,7 +158,33 @@ func (r *logRateLimitReporter) Report(sourceName string, tags map[string]string)
 		return r.ctx.Err()
 	}
 
-	now = time.Now()
+	return r.reportExceeded(fmt.Sprintf("app instance exceeded log rate limit (%d log-lines/sec) set by platform operator", r.maxLogLinesPerSecond), sourceName, tags)
+}
+
+// limitBytes drops the message when it would exceed the byte rate limit. The
+// limit is also the largest burst, so a message larger than the limit is
+// always dropped.
+func (r *logRateLimitReporter) limitBytes(sourceName string, tags map[string]string, messageSize int) error {
+	if r.maxLogBytesPerSecondLimiter == nil || messageSize == 0 {
+		return nil
+	}
+
+	if r.maxLogBytesPerSecondLimiter.AllowN(time.Now(), messageSize) {
+		return nil
+	}
+
+	r.droppedLogs.add(messageSize)
+	r.droppedLogs.report(r.metronClient, tags)
+
+	err := r.reportExceeded(fmt.Sprintf("app instance exceeded log rate limit (%d bytes/sec) set by platform operator", r.maxLogBytesPerSecond), sourceName, tags)
+	if err != nil {
+		return err
+	}
+	return ErrLogRateLimitExceeded
+}
+
+func (r *logRateLimitReporter) reportExceeded(msg string, sourceName string, tags map[string]string) error {
+	now := time.Now()
 	metricReporterReservation := r.metricReportLimiter.ReserveN(now, 1)
 	if !metricReporterReservation.OK() {
 		return fmt.Errorf("rate would exceed context deadline")
@@ -92,8 +203,7 @@ func (r *logRateLimitReporter) Report(sourceName string, tags map[string]string)
 	}
 	logReportDelay := logReporterReservation.DelayFrom(now)
 	if logReportDelay < LogRateLimitAllowDelta {
-		msg := fmt.Sprintf("app instance exceeded log rate limit (%d log-lines/sec) set by platform operator", r.maxLogLinesPerSecond)
-		r.metronClient.SendAppLog(string(msg), sourceName, tags)
+		r.sink.SendAppLog(msg, sourceName, tags)
 	} else {
 		logReporterReservation.CancelAt(now)
 	}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	loggingclient "code.cloudfoundry.org/diego-logging-client"
+	"code.cloudfoundry.org/executor"
 	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
 )
 
@@ -36,7 +37,18 @@ type logStreamer struct {
 	stderr     *streamDestination
 }
 
-func New(guid string, sourceName string, index int, originalTags map[string]string, metronClient loggingclient.IngressClient, maxLogLinesPerSecond int, logRateLimitExceededReportInterval time.Duration) LogStreamer {
+// New returns a LogStreamer sending the lines written to it to sink and, when
+// tap is not nil, to tap. The rate limiting metrics are sent to metronClient.
+// A zero maxLogLinesPerSecond or maxLogBytesPerSecond does not limit the rate.
+// The byte rate limit is also the largest burst: a message larger than
+// maxLogBytesPerSecond is always dropped. The dropped lines are totalled in
+// droppedLogs, which a nil droppedLogs keeps to this LogStreamer.
+// When multiline is not nil, the lines of a record are sent as one message.
+// When jsonLines is not nil, the lines that are JSON objects are parsed.
+// When redactor is not nil, it masks the secrets in the lines before they are
+// split into messages, and the total of its redactions is sent on Flush and
+// Stop as well as once per logRateLimitExceededReportInterval.
+func New(guid string, sourceName string, index int, originalTags map[string]string, sink LogSink, metronClient loggingclient.IngressClient, maxLogLinesPerSecond int, maxLogBytesPerSecond int64, logRateLimitExceededReportInterval time.Duration, droppedLogs *DroppedLogs, multiline *executor.MultilineConfig, jsonLines *executor.JSONLogConfig, redactor *Redactor, tap LineTap) LogStreamer {
 	if guid == "" {
 		return noopStreamer{}
 	}
@@ -45,20 +57,15 @@ func New(guid string, sourceName string, index int, originalTags map[string]stri
 		sourceName = DefaultLogSource
 	}
 
-	tags := map[string]string{}
-	for k, v := range originalTags {
-		tags[k] = v
-	}
+	tags := InstanceTags(guid, index, originalTags)
 
-	if _, ok := tags["source_id"]; !ok {
-		tags["source_id"] = guid
-	}
-	sourceIndex := strconv.Itoa(index)
-	if _, ok := tags["instance_id"]; !ok {
-		tags["instance_id"] = sourceIndex
+	if droppedLogs == nil {
+		droppedLogs = NewDroppedLogs(logRateLimitExceededReportInterval)
 	}
 
 	ctx, cancelFunc := context.WithCancel(context.Background())
+	grouper := newMultilineGrouper(multiline)
+	parser := newJSONLineParser(jsonLines)
 
 	return &logStreamer{
 		ctx:        ctx,
@@ -68,9 +75,16 @@ func New(guid string, sourceName string, index int, originalTags map[string]stri
 			sourceName,
 			tags,
 			loggregator_v2.Log_OUT,
+			sink,
 			metronClient,
 			maxLogLinesPerSecond,
+			maxLogBytesPerSecond,
 			logRateLimitExceededReportInterval,
+			droppedLogs,
+			grouper,
+			parser,
+			redactor,
+			tap,
 		),
 
 		stderr: newStreamDestination(
@@ -78,13 +92,39 @@ func New(guid string, sourceName string, index int, originalTags map[string]stri
 			sourceName,
 			tags,
 			loggregator_v2.Log_ERR,
+			sink,
 			metronClient,
 			maxLogLinesPerSecond,
+			maxLogBytesPerSecond,
 			logRateLimitExceededReportInterval,
+			droppedLogs,
+			grouper,
+			parser,
+			redactor,
+			tap,
 		),
 	}
 }
 
+// InstanceTags returns the tags a LogStreamer sends with the lines of the
+// instance: originalTags, with the source_id and instance_id defaulting to
+// guid and index.
+func InstanceTags(guid string, index int, originalTags map[string]string) map[string]string {
+	tags := map[string]string{}
+	for k, v := range originalTags {
+		tags[k] = v
+	}
+
+	if _, ok := tags["source_id"]; !ok {
+		tags["source_id"] = guid
+	}
+	if _, ok := tags["instance_id"]; !ok {
+		tags["instance_id"] = strconv.Itoa(index)
+	}
+
+	return tags
+}
+
 func (e *logStreamer) Stdout() io.Writer {
 	return e.stdout
 }
@@ -96,6 +136,8 @@ func (e *logStreamer) Stderr() io.Writer {
 func (e *logStreamer) Flush() {
 	e.stdout.lockAndFlush()
 	e.stderr.lockAndFlush()
+	// the streams share the redactor
+	e.stdout.reportRedactions()
 }
 
 func (e *logStreamer) WithSource(sourceName string) LogStreamer {
@@ -119,4 +161,8 @@ func (e *logStreamer) SourceName() string {
 
 func (e *logStreamer) Stop() {
 	e.cancelFunc()
+	e.stdout.stop()
+	e.stderr.stop()
+	// the streams share the redactor
+	e.stdout.reportRedactions()
 }
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	mfakes "code.cloudfoundry.org/diego-logging-client/testhelpers"
+	"code.cloudfoundry.org/executor"
 	"code.cloudfoundry.org/executor/depot/log_streamer"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -33,7 +34,7 @@ var _ = Describe("LogStreamer", func() {
 		maxLogLinesPerSecond = 9999
 		logRateLimitExceededReportInterval = 5 * time.Minute
 		fakeClient = &mfakes.FakeIngressClient{}
-		streamer = log_streamer.New(guid, sourceName, index, tags, fakeClient, maxLogLinesPerSecond, logRateLimitExceededReportInterval)
+		streamer = log_streamer.New(guid, sourceName, index, tags, fakeClient, fakeClient, maxLogLinesPerSecond, 0, logRateLimitExceededReportInterval, nil, nil, nil, nil, nil)
 	})
 
 	Context("when told to emit", func() {
@@ -68,7 +69,7 @@ var _ = Describe("LogStreamer", func() {
 			Context("rate limit is applied at a lower threshold", func() {
 				BeforeEach(func() {
 					maxLogLinesPerSecond = 1
-					streamer = log_streamer.New(guid, sourceName, index, tags, fakeClient, maxLogLinesPerSecond, logRateLimitExceededReportInterval)
+					streamer = log_streamer.New(guid, sourceName, index, tags, fakeClient, fakeClient, maxLogLinesPerSecond, 0, logRateLimitExceededReportInterval, nil, nil, nil, nil, nil)
 
 					for i := 0; i < maxLogLinesPerSecond*3; i++ {
 						go fmt.Fprintf(streamer.Stdout(), "this is log # %d\n", i)
@@ -123,7 +124,7 @@ var _ = Describe("LogStreamer", func() {
 				BeforeEach(func() {
 					maxLogLinesPerSecond = 1
 					logRateLimitExceededReportInterval = time.Second
-					streamer = log_streamer.New(guid, sourceName, index, tags, fakeClient, maxLogLinesPerSecond, logRateLimitExceededReportInterval)
+					streamer = log_streamer.New(guid, sourceName, index, tags, fakeClient, fakeClient, maxLogLinesPerSecond, 0, logRateLimitExceededReportInterval, nil, nil, nil, nil, nil)
 
 					for i := 0; i < 3; i++ {
 						go fmt.Fprintf(streamer.Stdout(), "this is log # %d \n", i)
@@ -137,10 +138,63 @@ var _ = Describe("LogStreamer", func() {
 				})
 			})
 
+			Context("when the byte rate limit is exceeded", func() {
+				BeforeEach(func() {
+					streamer = log_streamer.New(guid, sourceName, index, tags, fakeClient, fakeClient, maxLogLinesPerSecond, 10, logRateLimitExceededReportInterval, nil, nil, nil, nil, nil)
+
+					fmt.Fprintln(streamer.Stdout(), "12345")
+					fmt.Fprintln(streamer.Stdout(), "123456")
+					fmt.Fprintln(streamer.Stdout(), "this line is over the limit")
+				})
+
+				It("drops the lines and reports it once", func() {
+					messages := []string{}
+					for i := 0; i < fakeClient.SendAppLogCallCount(); i++ {
+						msg, _, _ := fakeClient.SendAppLogArgsForCall(i)
+						messages = append(messages, msg)
+					}
+					Expect(messages).To(Equal([]string{
+						"12345",
+						"app instance exceeded log rate limit (10 bytes/sec) set by platform operator",
+					}))
+					Expect(fakeClient.SendAppErrorLogCallCount()).To(Equal(0))
+
+					Expect(fakeClient.IncrementCounterCallCount()).To(Equal(1))
+					Expect(fakeClient.IncrementCounterArgsForCall(0)).To(Equal(log_streamer.AppInstanceExceededLogRateLimitCount))
+				})
+
+				It("reports the dropped lines and bytes of the instance", func() {
+					Expect(fakeClient.SendMetricCallCount()).To(Equal(2))
+
+					name, value, opts := fakeClient.SendMetricArgsForCall(0)
+					Expect(name).To(Equal(log_streamer.AppInstanceDroppedLogLinesTotal))
+					Expect(value).To(Equal(1))
+					Expect(opts).To(HaveLen(2))
+
+					name, value, _ = fakeClient.SendMetricArgsForCall(1)
+					Expect(name).To(Equal(log_streamer.AppInstanceDroppedLogBytesTotal))
+					Expect(value).To(Equal(6))
+				})
+
+				It("adds up the lines dropped on both streams and by the streamers sharing the totals", func() {
+					droppedLogs := log_streamer.NewDroppedLogs(logRateLimitExceededReportInterval)
+					first := log_streamer.New(guid, sourceName, index, tags, fakeClient, fakeClient, maxLogLinesPerSecond, 10, logRateLimitExceededReportInterval, droppedLogs, nil, nil, nil, nil)
+					fmt.Fprintln(first.Stdout(), "this line is over the limit")
+					fmt.Fprintln(first.Stderr(), "so is this one")
+
+					second := log_streamer.New(guid, sourceName, index, tags, fakeClient, fakeClient, maxLogLinesPerSecond, 10, logRateLimitExceededReportInterval, droppedLogs, nil, nil, nil, nil)
+					fmt.Fprintln(second.Stdout(), "and this one")
+
+					lines, bytes := droppedLogs.Totals()
+					Expect(lines).To(Equal(uint64(3)))
+					Expect(bytes).To(Equal(uint64(27 + 14 + 12)))
+				})
+			})
+
 			Context("rate limit is not applied", func() {
 				BeforeEach(func() {
 					maxLogLinesPerSecond = 0
-					streamer = log_streamer.New(guid, sourceName, index, tags, fakeClient, maxLogLinesPerSecond, logRateLimitExceededReportInterval)
+					streamer = log_streamer.New(guid, sourceName, index, tags, fakeClient, fakeClient, maxLogLinesPerSecond, 0, logRateLimitExceededReportInterval, nil, nil, nil, nil, nil)
 
 					for i := 0; i < 20; i++ {
 						go fmt.Fprintf(streamer.Stdout(), "this is log # %d \n", i)
@@ -155,7 +209,7 @@ var _ = Describe("LogStreamer", func() {
 			Context("rate limit is bigger than number of log lines", func() {
 				BeforeEach(func() {
 					maxLogLinesPerSecond = 6
-					streamer = log_streamer.New(guid, sourceName, index, tags, fakeClient, maxLogLinesPerSecond, logRateLimitExceededReportInterval)
+					streamer = log_streamer.New(guid, sourceName, index, tags, fakeClient, fakeClient, maxLogLinesPerSecond, 0, logRateLimitExceededReportInterval, nil, nil, nil, nil, nil)
 
 					for i := 0; i < 3; i++ {
 						go fmt.Fprintf(streamer.Stdout(), "this is log # %d \n", i)
@@ -208,7 +262,7 @@ var _ = Describe("LogStreamer", func() {
 
 				BeforeEach(func() {
 					maxLogLinesPerSecond = 1
-					streamer = log_streamer.New(guid, sourceName, index, tags, fakeClient, maxLogLinesPerSecond, logRateLimitExceededReportInterval)
+					streamer = log_streamer.New(guid, sourceName, index, tags, fakeClient, fakeClient, maxLogLinesPerSecond, 0, logRateLimitExceededReportInterval, nil, nil, nil, nil, nil)
 
 					newStreamer = streamer.WithSource("new-source-name")
 				})
@@ -280,7 +334,7 @@ var _ = Describe("LogStreamer", func() {
 					BeforeEach(func() {
 						maxLogLinesPerSecond = 1
 						logRateLimitExceededReportInterval = time.Second
-						streamer = log_streamer.New(guid, sourceName, index, tags, fakeClient, maxLogLinesPerSecond, logRateLimitExceededReportInterval)
+						streamer = log_streamer.New(guid, sourceName, index, tags, fakeClient, fakeClient, maxLogLinesPerSecond, 0, logRateLimitExceededReportInterval, nil, nil, nil, nil, nil)
 						newStreamer = streamer.WithSource("new-source-name")
 					})
 
@@ -509,9 +563,261 @@ var _ = Describe("LogStreamer", func() {
 		})
 	})
 
+	Context("when multiline grouping is configured", func() {
+		var multiline *executor.MultilineConfig
+
+		sentLogs := func() []string {
+			messages := []string{}
+			for i := 0; i < fakeClient.SendAppLogCallCount(); i++ {
+				msg, _, _ := fakeClient.SendAppLogArgsForCall(i)
+				messages = append(messages, msg)
+			}
+			return messages
+		}
+
+		BeforeEach(func() {
+			multiline = &executor.MultilineConfig{FlushTimeoutMs: 50}
+		})
+
+		JustBeforeEach(func() {
+			streamer = log_streamer.New(guid, sourceName, index, tags, fakeClient, fakeClient, maxLogLinesPerSecond, 0, logRateLimitExceededReportInterval, nil, multiline, nil, nil, nil)
+		})
+
+		It("groups the indented and 'Caused by:' lines with the line they follow", func() {
+			fmt.Fprint(streamer.Stdout(), "Exception in thread \"main\" java.lang.RuntimeException: boom\n\tat Main.run(Main.java:10)\n")
+			fmt.Fprint(streamer.Stdout(), "Caused by: java.io.IOException: disk\n\t... 3 more\nnext line\n")
+
+			Expect(sentLogs()).To(Equal([]string{
+				"Exception in thread \"main\" java.lang.RuntimeException: boom\n\tat Main.run(Main.java:10)\nCaused by: java.io.IOException: disk\n\t... 3 more",
+			}))
+			Eventually(sentLogs).Should(HaveLen(2))
+			Expect(sentLogs()[1]).To(Equal("next line"))
+		})
+
+		It("sends the last record once no line follows it within the flush timeout", func() {
+			fmt.Fprintln(streamer.Stdout(), "Traceback (most recent call last):")
+			fmt.Fprintln(streamer.Stdout(), `  File "app.py", line 1, in <module>`)
+			Consistently(sentLogs, 20*time.Millisecond).Should(BeEmpty())
+
+			Eventually(sentLogs).Should(Equal([]string{
+				"Traceback (most recent call last):\n  File \"app.py\", line 1, in <module>",
+			}))
+		})
+
+		It("sends the record on Flush, along with the line being written", func() {
+			fmt.Fprint(streamer.Stdout(), "first\n  second\n  thi")
+			fmt.Fprint(streamer.Stdout(), "rd")
+			streamer.Flush()
+
+			Expect(sentLogs()).To(Equal([]string{"first\n  second\n  third"}))
+		})
+
+		It("sends the record on Stop and does not flush it again afterwards", func() {
+			fmt.Fprint(streamer.Stdout(), "first\n  second\n")
+			streamer.Stop()
+
+			Expect(sentLogs()).To(Equal([]string{"first\n  second"}))
+			Consistently(sentLogs, 100*time.Millisecond).Should(HaveLen(1))
+		})
+
+		It("drops empty lines", func() {
+			fmt.Fprint(streamer.Stdout(), "first\r\n  second\r\n\r\n")
+			streamer.Flush()
+
+			Expect(sentLogs()).To(Equal([]string{"first\n  second"}))
+		})
+
+		It("splits records larger than MAX_MESSAGE_SIZE", func() {
+			fmt.Fprintln(streamer.Stdout(), "start")
+			fmt.Fprintln(streamer.Stdout(), " "+strings.Repeat("x", log_streamer.MAX_MESSAGE_SIZE))
+			streamer.Flush()
+
+			messages := sentLogs()
+			Expect(messages).To(HaveLen(2))
+			Expect(messages[0]).To(HaveLen(log_streamer.MAX_MESSAGE_SIZE))
+			Expect(messages[0] + messages[1]).To(Equal("start\n " + strings.Repeat("x", log_streamer.MAX_MESSAGE_SIZE)))
+		})
+
+		Context("with a start pattern", func() {
+			BeforeEach(func() {
+				multiline.StartPattern = `^\d{4}-\d\d-\d\d `
+			})
+
+			It("groups the lines not matching it with the record before them", func() {
+				fmt.Fprint(streamer.Stdout(), "2021-01-01 ERROR boom\nTraceback (most recent call last):\n  File \"app.py\"\nValueError: bad\n2021-01-01 INFO ok\n")
+				streamer.Flush()
+
+				Expect(sentLogs()).To(Equal([]string{
+					"2021-01-01 ERROR boom\nTraceback (most recent call last):\n  File \"app.py\"\nValueError: bad",
+					"2021-01-01 INFO ok",
+				}))
+			})
+		})
+	})
+
+	Context("when JSON parsing is configured", func() {
+		var jsonLines *executor.JSONLogConfig
+
+		BeforeEach(func() {
+			jsonLines = &executor.JSONLogConfig{}
+		})
+
+		JustBeforeEach(func() {
+			streamer = log_streamer.New(guid, sourceName, index, tags, fakeClient, fakeClient, maxLogLinesPerSecond, 0, logRateLimitExceededReportInterval, nil, nil, jsonLines, nil, nil)
+		})
+
+		It("promotes the level, trace_id and span_id fields to tags", func() {
+			fmt.Fprintln(streamer.Stdout(), `{"level":"info","trace_id":"abc","span_id":"def","msg":"hi","foo":"ignored"}`)
+
+			Expect(fakeClient.SendAppLogCallCount()).To(Equal(1))
+			message, _, sentTags := fakeClient.SendAppLogArgsForCall(0)
+			Expect(message).To(Equal(`{"level":"info","trace_id":"abc","span_id":"def","msg":"hi","foo":"ignored"}`))
+			Expect(sentTags).To(Equal(map[string]string{
+				"source_id":   guid,
+				"instance_id": "11",
+				"foo":         "bar",
+				"biz":         "baz",
+				"level":       "info",
+				"trace_id":    "abc",
+				"span_id":     "def",
+			}))
+		})
+
+		It("sends the lines with a level of error or higher as errors", func() {
+			fmt.Fprintln(streamer.Stdout(), `{"level":"ERROR","msg":"boom"}`)
+			fmt.Fprintln(streamer.Stdout(), `{"level":"fatal","msg":"gone"}`)
+			fmt.Fprintln(streamer.Stdout(), `{"level":"warn","msg":"hmm"}`)
+
+			Expect(fakeClient.SendAppErrorLogCallCount()).To(Equal(2))
+			message, _, sentTags := fakeClient.SendAppErrorLogArgsForCall(0)
+			Expect(message).To(Equal(`{"level":"ERROR","msg":"boom"}`))
+			Expect(sentTags["level"]).To(Equal("ERROR"))
+
+			Expect(fakeClient.SendAppLogCallCount()).To(Equal(1))
+		})
+
+		It("sends the lines that are not JSON objects unchanged", func() {
+			fmt.Fprintln(streamer.Stdout(), `{"level":"error", not json`)
+			fmt.Fprintln(streamer.Stdout(), `["level","error"]`)
+
+			Expect(fakeClient.SendAppLogCallCount()).To(Equal(2))
+			message, _, sentTags := fakeClient.SendAppLogArgsForCall(0)
+			Expect(message).To(Equal(`{"level":"error", not json`))
+			Expect(sentTags).NotTo(HaveKey("level"))
+		})
+
+		Context("with the fields to promote", func() {
+			BeforeEach(func() {
+				jsonLines.PromotedFields = []string{"severity", "code", "foo"}
+				jsonLines.LevelField = "severity"
+			})
+
+			It("promotes them without overriding the container's tags", func() {
+				fmt.Fprintln(streamer.Stdout(), `{"severity":"critical","code":503,"foo":"other","level":"info"}`)
+
+				Expect(fakeClient.SendAppErrorLogCallCount()).To(Equal(1))
+				_, _, sentTags := fakeClient.SendAppErrorLogArgsForCall(0)
+				Expect(sentTags["severity"]).To(Equal("critical"))
+				Expect(sentTags["code"]).To(Equal("503"))
+				Expect(sentTags["foo"]).To(Equal("bar"))
+				Expect(sentTags).NotTo(HaveKey("level"))
+			})
+		})
+	})
+
+	Context("when a redactor is given", func() {
+		var redactor *log_streamer.Redactor
+
+		BeforeEach(func() {
+			var err error
+			redactor, err = log_streamer.NewRedactor([]string{"hunter22", "hunter2222", "abc"}, []string{`Bearer [A-Za-z0-9.]+`}, logRateLimitExceededReportInterval)
+			Expect(err).NotTo(HaveOccurred())
+
+			streamer = log_streamer.New(guid, sourceName, index, tags, fakeClient, fakeClient, maxLogLinesPerSecond, 0, logRateLimitExceededReportInterval, nil, nil, nil, redactor, nil)
+		})
+
+		It("masks the secrets and the pattern matches before sending the line", func() {
+			fmt.Fprintln(streamer.Stdout(), "password hunter2222 then hunter22 with Bearer eyJ.abc")
+			fmt.Fprintln(streamer.Stderr(), "nothing secret, abc is too short")
+
+			Expect(fakeClient.SendAppLogCallCount()).To(Equal(1))
+			message, _, _ := fakeClient.SendAppLogArgsForCall(0)
+			Expect(message).To(Equal("password [REDACTED] then [REDACTED] with [REDACTED]"))
+
+			message, _, _ = fakeClient.SendAppErrorLogArgsForCall(0)
+			Expect(message).To(Equal("nothing secret, abc is too short"))
+		})
+
+		It("counts the redactions of every stream and source, and reports the total", func() {
+			fmt.Fprintln(streamer.Stdout(), "hunter22 hunter22")
+			fmt.Fprintln(streamer.WithSource("OTHER").Stderr(), "hunter22")
+
+			Expect(redactor.RedactionCount()).To(BeEquivalentTo(3))
+
+			Expect(fakeClient.SendMetricCallCount()).To(Equal(1))
+			name, value, opts := fakeClient.SendMetricArgsForCall(0)
+			Expect(name).To(Equal(log_streamer.AppInstanceRedactedLogSecretsTotal))
+			Expect(value).To(Equal(2))
+			Expect(opts).To(HaveLen(2))
+
+			streamer.Stop()
+
+			Expect(fakeClient.SendMetricCallCount()).To(Equal(2))
+			name, value, _ = fakeClient.SendMetricArgsForCall(1)
+			Expect(name).To(Equal(log_streamer.AppInstanceRedactedLogSecretsTotal))
+			Expect(value).To(Equal(3))
+		})
+
+		It("sends the total on Flush only when it changed", func() {
+			fmt.Fprintln(streamer.Stdout(), "hunter22")
+			streamer.Flush()
+			Expect(fakeClient.SendMetricCallCount()).To(Equal(1))
+
+			fmt.Fprintln(streamer.Stdout(), "hunter22")
+			streamer.Flush()
+			streamer.Flush()
+
+			Expect(fakeClient.SendMetricCallCount()).To(Equal(2))
+			_, value, _ := fakeClient.SendMetricArgsForCall(1)
+			Expect(value).To(Equal(2))
+		})
+
+		It("masks the secrets of a line spanning several messages, keeping them within MAX_MESSAGE_SIZE", func() {
+			line := strings.Repeat("x", log_streamer.MAX_MESSAGE_SIZE-4) + "hunter22"
+			fmt.Fprintln(streamer.Stdout(), line)
+
+			Expect(fakeClient.SendAppLogCallCount()).To(Equal(2))
+			first, _, _ := fakeClient.SendAppLogArgsForCall(0)
+			second, _, _ := fakeClient.SendAppLogArgsForCall(1)
+			Expect(len(first)).To(BeNumerically("<=", log_streamer.MAX_MESSAGE_SIZE))
+			Expect(first + second).To(Equal(strings.Repeat("x", log_streamer.MAX_MESSAGE_SIZE-4) + "[REDACTED]"))
+		})
+
+		Context("when multiline grouping is configured", func() {
+			BeforeEach(func() {
+				streamer = log_streamer.New(guid, sourceName, index, tags, fakeClient, fakeClient, maxLogLinesPerSecond, 0, logRateLimitExceededReportInterval, nil, &executor.MultilineConfig{}, nil, redactor, nil)
+			})
+
+			It("masks the secrets of every line of a record", func() {
+				fmt.Fprint(streamer.Stdout(), "token hunter22\n  again hunt")
+				fmt.Fprint(streamer.Stdout(), "er22\n")
+				streamer.Flush()
+
+				Expect(fakeClient.SendAppLogCallCount()).To(Equal(1))
+				message, _, _ := fakeClient.SendAppLogArgsForCall(0)
+				Expect(message).To(Equal("token [REDACTED]\n  again [REDACTED]"))
+			})
+		})
+
+		It("fails to make a redactor with an invalid pattern", func() {
+			_, err := log_streamer.NewRedactor(nil, []string{"("}, time.Minute)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
 	Context("when there is no app guid", func() {
 		It("does nothing when told to emit or flush", func() {
-			streamer = log_streamer.New("", sourceName, index, tags, fakeClient, maxLogLinesPerSecond, logRateLimitExceededReportInterval)
+			streamer = log_streamer.New("", sourceName, index, tags, fakeClient, fakeClient, maxLogLinesPerSecond, 0, logRateLimitExceededReportInterval, nil, nil, nil, nil, nil)
 
 			streamer.Stdout().Write([]byte("hi"))
 			streamer.Stderr().Write([]byte("hi"))
@@ -521,9 +827,51 @@ var _ = Describe("LogStreamer", func() {
 		})
 	})
 
+	Context("when the sink is not the metron client", func() {
+		var fakeSink *mfakes.FakeIngressClient
+
+		BeforeEach(func() {
+			maxLogLinesPerSecond = 1
+			fakeSink = &mfakes.FakeIngressClient{}
+			streamer = log_streamer.New(guid, sourceName, index, tags, fakeSink, fakeClient, maxLogLinesPerSecond, 0, logRateLimitExceededReportInterval, nil, nil, nil, nil, nil)
+		})
+
+		It("sends the split lines to the sink", func() {
+			fmt.Fprintln(streamer.Stderr(), strings.Repeat("7", log_streamer.MAX_MESSAGE_SIZE+1))
+
+			Eventually(fakeSink.SendAppErrorLogCallCount, 3*time.Second).Should(Equal(2))
+			message, _, _ := fakeSink.SendAppErrorLogArgsForCall(0)
+			Expect(message).To(HaveLen(log_streamer.MAX_MESSAGE_SIZE))
+			message, _, _ = fakeSink.SendAppErrorLogArgsForCall(1)
+			Expect(message).To(Equal("7"))
+
+			Expect(fakeClient.SendAppErrorLogCallCount()).To(Equal(0))
+		})
+
+		It("rate limits the lines, reporting to the sink and counting with the metron client", func() {
+			for i := 0; i < 3; i++ {
+				go fmt.Fprintf(streamer.Stdout(), "this is log # %d\n", i)
+			}
+
+			Eventually(fakeClient.IncrementCounterCallCount, 3*time.Second).Should(Equal(1))
+			Expect(fakeClient.IncrementCounterArgsForCall(0)).To(Equal(log_streamer.AppInstanceExceededLogRateLimitCount))
+
+			Eventually(func() []string {
+				messages := []string{}
+				for i := 0; i < fakeSink.SendAppLogCallCount(); i++ {
+					msg, _, _ := fakeSink.SendAppLogArgsForCall(i)
+					messages = append(messages, msg)
+				}
+				return messages
+			}, 10*time.Second).Should(ContainElement(ContainSubstring("app instance exceeded log rate limit")))
+
+			Expect(fakeClient.SendAppLogCallCount()).To(Equal(0))
+		})
+	})
+
 	Context("when there is no log source", func() {
 		It("defaults to LOG", func() {
-			streamer = log_streamer.New(guid, "", -1, tags, fakeClient, maxLogLinesPerSecond, logRateLimitExceededReportInterval)
+			streamer = log_streamer.New(guid, "", -1, tags, fakeClient, fakeClient, maxLogLinesPerSecond, 0, logRateLimitExceededReportInterval, nil, nil, nil, nil, nil)
 
 			streamer.Stdout().Write([]byte("hi"))
 			streamer.Flush()
@@ -536,7 +884,7 @@ var _ = Describe("LogStreamer", func() {
 
 	Context("when there is no source index", func() {
 		It("defaults to 0", func() {
-			streamer = log_streamer.New(guid, sourceName, -1, tags, fakeClient, maxLogLinesPerSecond, logRateLimitExceededReportInterval)
+			streamer = log_streamer.New(guid, sourceName, -1, tags, fakeClient, fakeClient, maxLogLinesPerSecond, 0, logRateLimitExceededReportInterval, nil, nil, nil, nil, nil)
 
 			streamer.Stdout().Write([]byte("hi"))
 			streamer.Flush()
@@ -0,0 +1,105 @@
+package log_streamer
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/executor"
+)
+
+const DefaultMultilineFlushTimeout = 500 * time.Millisecond
+
+// multilineGrouper tells the lines starting a record from the lines
+// continuing it.
+type multilineGrouper struct {
+	start        *regexp.Regexp
+	flushTimeout time.Duration
+}
+
+// newMultilineGrouper returns nil when config is nil. A StartPattern that does
+// not compile falls back to the indentation heuristic; the containerstore
+// rejects it before any LogStreamer is made.
+func newMultilineGrouper(config *executor.MultilineConfig) *multilineGrouper {
+	if config == nil {
+		return nil
+	}
+
+	grouper := &multilineGrouper{flushTimeout: DefaultMultilineFlushTimeout}
+	if config.FlushTimeoutMs > 0 {
+		grouper.flushTimeout = time.Duration(config.FlushTimeoutMs) * time.Millisecond
+	}
+
+	if config.StartPattern != "" {
+		start, err := regexp.Compile(config.StartPattern)
+		if err == nil {
+			grouper.start = start
+		}
+	}
+
+	return grouper
+}
+
+func (g *multilineGrouper) continues(line string) bool {
+	if g.start != nil {
+		return !g.start.MatchString(line)
+	}
+
+	return strings.HasPrefix(line, " ") ||
+		strings.HasPrefix(line, "\t") ||
+		strings.HasPrefix(line, "Caused by:")
+}
+
+// Not thread safe.  should only be called when holding the processLock
+//
+// The line being written is held back until it ends, so that it can be told
+// apart from the lines continuing the record in the buffer. A line that grows
+// to MAX_MESSAGE_SIZE without ending is taken as it is.
+func (destination *streamDestination) processMultilineString(message string, terminates bool) {
+	destination.partialLine += message
+	if terminates || len(destination.partialLine) >= MAX_MESSAGE_SIZE {
+		destination.appendLine()
+	}
+
+	if destination.stopped {
+		destination.appendLine()
+		destination.flush()
+		return
+	}
+
+	if destination.flushTimer == nil {
+		destination.flushTimer = time.AfterFunc(destination.multiline.flushTimeout, destination.lockAndFlush)
+	} else {
+		destination.flushTimer.Reset(destination.multiline.flushTimeout)
+	}
+}
+
+// Not thread safe.  should only be called when holding the processLock
+//
+// appendLine adds the held back line to the record in the buffer when it
+// continues it; otherwise the record is flushed and the line starts the next
+// one. Empty lines are dropped.
+func (destination *streamDestination) appendLine() {
+	line := destination.partialLine
+	destination.partialLine = ""
+	if line == "" {
+		return
+	}
+	line = destination.redact(line)
+
+	if len(destination.buffer) > 0 {
+		if destination.multiline.continues(line) {
+			line = "\n" + line
+		} else {
+			destination.flush()
+		}
+	}
+
+	for {
+		line = destination.appendToBuffer(line)
+		if len(line) == 0 {
+			break
+		}
+		destination.flush()
+	}
+}
@@ -0,0 +1,249 @@
+package log_streamer
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/lager"
+)
+
+const (
+	// OTLP_MAX_BATCH_SIZE is the number of records that triggers a flush
+	// before the flush interval.
+	OTLP_MAX_BATCH_SIZE = 512
+
+	// OTLP_MAX_BUFFERED_RECORDS bounds the records waiting for a flush; the
+	// lines sent past it are dropped.
+	OTLP_MAX_BUFFERED_RECORDS = 8192
+
+	otlpScopeName = "code.cloudfoundry.org/executor"
+
+	otlpSeverityInfo  = 9
+	otlpSeverityError = 17
+)
+
+var ErrOTLPBufferFull = errors.New("otlp log buffer is full")
+
+// OTLPSink is a LogSink exporting the lines as OTLP log records, in the JSON
+// encoding of OTLP/HTTP, to a collector endpoint such as
+// http://localhost:4318/v1/logs. The records are buffered and exported in
+// batches while the sink runs, and once more when it is signalled. A batch the
+// collector fails to accept is dropped.
+type OTLPSink struct {
+	logger        lager.Logger
+	client        *http.Client
+	endpoint      string
+	flushInterval time.Duration
+	clock         clock.Clock
+
+	lock    sync.Mutex
+	records []otlpRecord
+	full    chan struct{}
+}
+
+type otlpRecord struct {
+	timestamp  time.Time
+	severity   int
+	message    string
+	sourceType string
+	tags       map[string]string
+}
+
+func NewOTLPSink(logger lager.Logger, client *http.Client, endpoint string, flushInterval time.Duration, clock clock.Clock) *OTLPSink {
+	return &OTLPSink{
+		logger:        logger.Session("otlp-sink"),
+		client:        client,
+		endpoint:      endpoint,
+		flushInterval: flushInterval,
+		clock:         clock,
+		full:          make(chan struct{}, 1),
+	}
+}
+
+func (s *OTLPSink) SendAppLog(message, sourceType string, tags map[string]string) error {
+	return s.add(otlpSeverityInfo, message, sourceType, tags)
+}
+
+func (s *OTLPSink) SendAppErrorLog(message, sourceType string, tags map[string]string) error {
+	return s.add(otlpSeverityError, message, sourceType, tags)
+}
+
+func (s *OTLPSink) add(severity int, message, sourceType string, tags map[string]string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if len(s.records) >= OTLP_MAX_BUFFERED_RECORDS {
+		return ErrOTLPBufferFull
+	}
+
+	s.records = append(s.records, otlpRecord{
+		timestamp:  s.clock.Now(),
+		severity:   severity,
+		message:    message,
+		sourceType: sourceType,
+		tags:       tags,
+	})
+
+	if len(s.records) >= OTLP_MAX_BATCH_SIZE {
+		select {
+		case s.full <- struct{}{}:
+		default:
+		}
+	}
+
+	return nil
+}
+
+func (s *OTLPSink) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	close(ready)
+
+	ticker := s.clock.NewTicker(s.flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-signals:
+			s.logger.Info("signalled")
+			s.flush()
+			return nil
+		case <-ticker.C():
+			s.flush()
+		case <-s.full:
+			s.flush()
+		}
+	}
+}
+
+func (s *OTLPSink) flush() {
+	s.lock.Lock()
+	records := s.records
+	s.records = nil
+	s.lock.Unlock()
+
+	for len(records) > 0 {
+		batch := records
+		if len(batch) > OTLP_MAX_BATCH_SIZE {
+			batch = batch[:OTLP_MAX_BATCH_SIZE]
+		}
+		records = records[len(batch):]
+
+		err := s.export(batch)
+		if err != nil {
+			s.logger.Error("failed-to-export-logs", err, lager.Data{"records": len(batch)})
+		}
+	}
+}
+
+func (s *OTLPSink) export(records []otlpRecord) error {
+	payload, err := json.Marshal(newOTLPExportLogsRequest(records))
+	if err != nil {
+		return err
+	}
+
+	res, err := s.client.Post(s.endpoint, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("otlp collector responded with status %d", res.StatusCode)
+	}
+	return nil
+}
+
+type otlpExportLogsRequest struct {
+	ResourceLogs []otlpResourceLogs `json:"resourceLogs"`
+}
+
+type otlpResourceLogs struct {
+	Resource  otlpResource    `json:"resource"`
+	ScopeLogs []otlpScopeLogs `json:"scopeLogs"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpScopeLogs struct {
+	Scope      otlpScope       `json:"scope"`
+	LogRecords []otlpLogRecord `json:"logRecords"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpLogRecord struct {
+	TimeUnixNano   string         `json:"timeUnixNano"`
+	SeverityNumber int            `json:"severityNumber"`
+	SeverityText   string         `json:"severityText"`
+	Body           otlpAnyValue   `json:"body"`
+	Attributes     []otlpKeyValue `json:"attributes"`
+}
+
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpAnyValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+// newOTLPExportLogsRequest groups the records by their source_id tag, which
+// becomes the service.name of their resource. The other tags and the source
+// type are attributes of every record.
+func newOTLPExportLogsRequest(records []otlpRecord) otlpExportLogsRequest {
+	request := otlpExportLogsRequest{}
+	resources := map[string]int{}
+
+	for _, record := range records {
+		sourceID := record.tags["source_id"]
+		i, ok := resources[sourceID]
+		if !ok {
+			i = len(request.ResourceLogs)
+			resources[sourceID] = i
+			request.ResourceLogs = append(request.ResourceLogs, otlpResourceLogs{
+				Resource: otlpResource{
+					Attributes: []otlpKeyValue{{Key: "service.name", Value: otlpAnyValue{StringValue: sourceID}}},
+				},
+				ScopeLogs: []otlpScopeLogs{{Scope: otlpScope{Name: otlpScopeName}}},
+			})
+		}
+
+		attributes := []otlpKeyValue{{Key: "source_type", Value: otlpAnyValue{StringValue: record.sourceType}}}
+		for key, value := range record.tags {
+			if key == "source_id" {
+				continue
+			}
+			attributes = append(attributes, otlpKeyValue{Key: key, Value: otlpAnyValue{StringValue: value}})
+		}
+		sort.Slice(attributes, func(i, j int) bool { return attributes[i].Key < attributes[j].Key })
+
+		severityText := "INFO"
+		if record.severity == otlpSeverityError {
+			severityText = "ERROR"
+		}
+
+		scopeLogs := &request.ResourceLogs[i].ScopeLogs[0]
+		scopeLogs.LogRecords = append(scopeLogs.LogRecords, otlpLogRecord{
+			TimeUnixNano:   strconv.FormatInt(record.timestamp.UnixNano(), 10),
+			SeverityNumber: record.severity,
+			SeverityText:   severityText,
+			Body:           otlpAnyValue{StringValue: record.message},
+			Attributes:     attributes,
+		})
+	}
+
+	return request
+}
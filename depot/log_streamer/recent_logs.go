@@ -0,0 +1,136 @@
+package log_streamer
+
+import (
+	"sync"
+	"unicode/utf8"
+
+	"code.cloudfoundry.org/executor"
+)
+
+// RecentLogs keeps the last lines logged by every container, across all of
+// its sources, so that they outlive Loggregator's retention. Each container
+// keeps at most maxBytes of messages until it is forgotten.
+type RecentLogs interface {
+	// Container returns the LineTap for the LogStreamers of a container.
+	Container(guid string) LineTap
+
+	// Get returns the kept lines of a container, oldest first.
+	Get(guid string) []executor.LogLine
+
+	Forget(guid string)
+}
+
+func NewRecentLogs(maxBytes int) RecentLogs {
+	return &recentLogs{
+		maxBytes:   maxBytes,
+		containers: map[string]*recentContainerLogs{},
+	}
+}
+
+type recentLogs struct {
+	lock       sync.Mutex
+	maxBytes   int
+	containers map[string]*recentContainerLogs
+}
+
+type recentContainerLogs struct {
+	lines []executor.LogLine
+	bytes int
+}
+
+func (r *recentLogs) Container(guid string) LineTap {
+	if r.maxBytes <= 0 {
+		return recentLogsTap{recentLogs: r, guid: guid}
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	container, ok := r.containers[guid]
+	if !ok {
+		container = &recentContainerLogs{}
+		r.containers[guid] = container
+	}
+	return recentLogsTap{recentLogs: r, guid: guid, container: container}
+}
+
+func (r *recentLogs) Get(guid string) []executor.LogLine {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	container, ok := r.containers[guid]
+	if !ok {
+		return []executor.LogLine{}
+	}
+
+	lines := make([]executor.LogLine, len(container.lines))
+	copy(lines, container.lines)
+	return lines
+}
+
+func (r *recentLogs) Forget(guid string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	delete(r.containers, guid)
+}
+
+// add ignores the lines of a container that was forgotten since its LineTap
+// was made, which would otherwise be kept for good.
+func (r *recentLogs) add(guid string, container *recentContainerLogs, line executor.LogLine) {
+	if r.maxBytes <= 0 {
+		return
+	}
+
+	if len(line.Message) > r.maxBytes {
+		line.Message = tail(line.Message, r.maxBytes)
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.containers[guid] != container {
+		return
+	}
+
+	container.lines = append(container.lines, line)
+	container.bytes += len(line.Message)
+
+	dropped := 0
+	for container.bytes > r.maxBytes {
+		container.bytes -= len(container.lines[dropped].Message)
+		dropped++
+	}
+	if dropped > 0 {
+		container.lines = append(container.lines[:0:0], container.lines[dropped:]...)
+	}
+}
+
+// tail returns the last maxBytes of message at most, without splitting a
+// rune.
+func tail(message string, maxBytes int) string {
+	start := len(message) - maxBytes
+	for start < len(message) && !utf8.RuneStart(message[start]) {
+		start++
+	}
+	return message[start:]
+}
+
+type recentLogsTap struct {
+	recentLogs *recentLogs
+	guid       string
+	container  *recentContainerLogs
+}
+
+func (t recentLogsTap) Publish(line executor.LogLine) {
+	t.recentLogs.add(t.guid, t.container, line)
+}
+
+// LineTaps publishes every line to each of its taps in turn.
+type LineTaps []LineTap
+
+func (t LineTaps) Publish(line executor.LogLine) {
+	for _, tap := range t {
+		tap.Publish(line)
+	}
+}
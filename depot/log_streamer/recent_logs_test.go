@@ -0,0 +1,85 @@
+package log_streamer_test
+
+import (
+	"strings"
+
+	"code.cloudfoundry.org/executor"
+	"code.cloudfoundry.org/executor/depot/log_streamer"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RecentLogs", func() {
+	var recentLogs log_streamer.RecentLogs
+
+	publish := func(guid string, messages ...string) {
+		for _, message := range messages {
+			recentLogs.Container(guid).Publish(executor.LogLine{SourceName: "APP", Message: message})
+		}
+	}
+
+	messages := func(guid string) []string {
+		kept := []string{}
+		for _, line := range recentLogs.Get(guid) {
+			kept = append(kept, line.Message)
+		}
+		return kept
+	}
+
+	BeforeEach(func() {
+		recentLogs = log_streamer.NewRecentLogs(10)
+	})
+
+	It("keeps the lines of each container, oldest first", func() {
+		publish("guid-a", "a1", "a2")
+		publish("guid-b", "b1")
+
+		Expect(messages("guid-a")).To(Equal([]string{"a1", "a2"}))
+		Expect(messages("guid-b")).To(Equal([]string{"b1"}))
+		Expect(messages("guid-c")).To(BeEmpty())
+	})
+
+	It("drops the oldest lines beyond the byte limit", func() {
+		publish("guid-a", "1234", "5678", "90ab")
+		Expect(messages("guid-a")).To(Equal([]string{"5678", "90ab"}))
+	})
+
+	It("keeps the end of lines longer than the limit", func() {
+		publish("guid-a", "old", "a€"+strings.Repeat("x", 9))
+		Expect(messages("guid-a")).To(Equal([]string{strings.Repeat("x", 9)}))
+	})
+
+	It("forgets the lines of a container", func() {
+		publish("guid-a", "a1")
+		recentLogs.Forget("guid-a")
+		Expect(messages("guid-a")).To(BeEmpty())
+	})
+
+	It("ignores the lines published through the taps of forgotten containers", func() {
+		containerTap := recentLogs.Container("guid-a")
+		recentLogs.Forget("guid-a")
+
+		containerTap.Publish(executor.LogLine{SourceName: "APP", Message: "late"})
+		Expect(messages("guid-a")).To(BeEmpty())
+
+		publish("guid-a", "new")
+		Expect(messages("guid-a")).To(Equal([]string{"new"}))
+	})
+
+	It("returns a copy of the lines", func() {
+		publish("guid-a", "a1")
+		recentLogs.Get("guid-a")[0].Message = "changed"
+		Expect(messages("guid-a")).To(Equal([]string{"a1"}))
+	})
+
+	Describe("LineTaps", func() {
+		It("publishes to every tap", func() {
+			other := log_streamer.NewRecentLogs(10)
+			taps := log_streamer.LineTaps{recentLogs.Container("guid-a"), other.Container("guid-a")}
+			taps.Publish(executor.LogLine{Message: "both"})
+
+			Expect(messages("guid-a")).To(Equal([]string{"both"}))
+			Expect(other.Get("guid-a")).To(HaveLen(1))
+		})
+	})
+})
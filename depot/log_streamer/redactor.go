@@ -0,0 +1,128 @@
+package log_streamer
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	loggingclient "code.cloudfoundry.org/diego-logging-client"
+	loggregator "code.cloudfoundry.org/go-loggregator"
+	"golang.org/x/time/rate"
+)
+
+const (
+	REDACTED = "[REDACTED]"
+
+	// secrets shorter than this are not redacted, as they would mask common
+	// words in every line
+	MIN_SECRET_LENGTH = 4
+)
+
+// the total of the redactions made in the lines of an app instance; it is a
+// gauge for the same reason as AppInstanceDroppedLogLinesTotal
+const AppInstanceRedactedLogSecretsTotal = "AppInstanceRedactedLogSecretsTotal"
+
+// Redactor masks the secrets of a container and the matches of its patterns
+// in the lines it logs. It is shared by all the LogStreamers of the container,
+// so that their redactions are counted together.
+//
+// The secrets are searched as plain strings, which is much faster than a
+// regular expression; only the configured patterns go through the regexp
+// package.
+type Redactor struct {
+	secrets        []string
+	patterns       []*regexp.Regexp
+	reportLimiter  *rate.Limiter
+	redactionCount uint64
+
+	reportLock    sync.Mutex
+	reportedCount uint64
+}
+
+func NewRedactor(secrets []string, patterns []string, reportInterval time.Duration) (*Redactor, error) {
+	redactor := &Redactor{
+		reportLimiter: rate.NewLimiter(rate.Every(reportInterval), 1),
+	}
+
+	seen := map[string]bool{}
+	for _, secret := range secrets {
+		if len(secret) < MIN_SECRET_LENGTH || seen[secret] {
+			continue
+		}
+		seen[secret] = true
+		redactor.secrets = append(redactor.secrets, secret)
+	}
+	// the longest first, so that a secret containing another is masked whole
+	sort.Slice(redactor.secrets, func(i, j int) bool {
+		return len(redactor.secrets[i]) > len(redactor.secrets[j])
+	})
+
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, err
+		}
+		redactor.patterns = append(redactor.patterns, re)
+	}
+
+	return redactor, nil
+}
+
+// Redact returns message with the secrets and the pattern matches masked, and
+// how many were.
+func (r *Redactor) Redact(message string) (string, int) {
+	count := 0
+
+	for _, secret := range r.secrets {
+		n := strings.Count(message, secret)
+		if n == 0 {
+			continue
+		}
+		count += n
+		message = strings.Replace(message, secret, REDACTED, -1)
+	}
+
+	for _, re := range r.patterns {
+		message = re.ReplaceAllStringFunc(message, func(match string) string {
+			if match == "" {
+				return match
+			}
+			count++
+			return REDACTED
+		})
+	}
+
+	if count > 0 {
+		atomic.AddUint64(&r.redactionCount, uint64(count))
+	}
+	return message, count
+}
+
+// RedactionCount is the total of the redactions made so far.
+func (r *Redactor) RedactionCount() uint64 {
+	return atomic.LoadUint64(&r.redactionCount)
+}
+
+// report sends the total of the redactions when it changed since it was last
+// sent, at most once per report interval unless final is set.
+func (r *Redactor) report(metronClient loggingclient.IngressClient, tags map[string]string, final bool) {
+	r.reportLock.Lock()
+	defer r.reportLock.Unlock()
+
+	count := r.RedactionCount()
+	if count == r.reportedCount {
+		return
+	}
+	if !final && !r.reportLimiter.Allow() {
+		return
+	}
+	r.reportedCount = count
+
+	metronClient.SendMetric(AppInstanceRedactedLogSecretsTotal, int(count),
+		loggregator.WithGaugeSourceInfo(tags["source_id"], tags["instance_id"]),
+		loggregator.EmitGaugeOption(loggregator.WithEnvelopeTags(tags)),
+	)
+}
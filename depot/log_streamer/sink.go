@@ -0,0 +1,51 @@
+package log_streamer
+
+// LogSink is where a LogStreamer sends the lines written to it, once they are
+// split to MAX_MESSAGE_SIZE and rate limited. Loggregator's IngressClient is a
+// LogSink.
+type LogSink interface {
+	SendAppLog(message, sourceType string, tags map[string]string) error
+	SendAppErrorLog(message, sourceType string, tags map[string]string) error
+}
+
+// InstanceForgetter is implemented by the LogSinks holding on to resources,
+// such as open files, for the instances they received lines from.
+type InstanceForgetter interface {
+	// ForgetInstance releases the resources of the instance with tags, the
+	// tags its LogStreamer sent with the lines, as returned by InstanceTags.
+	ForgetInstance(tags map[string]string)
+}
+
+// LogSinks sends every line to each of its sinks in turn. It returns the first
+// error, after the line was sent to all of them.
+type LogSinks []LogSink
+
+func (s LogSinks) SendAppLog(message, sourceType string, tags map[string]string) error {
+	var firstErr error
+	for _, sink := range s {
+		err := sink.SendAppLog(message, sourceType, tags)
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (s LogSinks) SendAppErrorLog(message, sourceType string, tags map[string]string) error {
+	var firstErr error
+	for _, sink := range s {
+		err := sink.SendAppErrorLog(message, sourceType, tags)
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (s LogSinks) ForgetInstance(tags map[string]string) {
+	for _, sink := range s {
+		if forgetter, ok := sink.(InstanceForgetter); ok {
+			forgetter.ForgetInstance(tags)
+		}
+	}
+}
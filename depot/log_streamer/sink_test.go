@@ -0,0 +1,378 @@
+package log_streamer_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	mfakes "code.cloudfoundry.org/diego-logging-client/testhelpers"
+	"code.cloudfoundry.org/executor/depot/log_streamer"
+	"code.cloudfoundry.org/lager/lagertest"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+	"github.com/tedsuo/ifrit"
+	"github.com/tedsuo/ifrit/ginkgomon"
+)
+
+var _ = Describe("LogSinks", func() {
+	It("sends every line to each sink and returns the first error", func() {
+		first := &mfakes.FakeIngressClient{}
+		second := &mfakes.FakeIngressClient{}
+		first.SendAppErrorLogReturns(errors.New("boom"))
+		sinks := log_streamer.LogSinks{first, second}
+
+		Expect(sinks.SendAppLog("out", "APP", nil)).To(Succeed())
+		Expect(sinks.SendAppErrorLog("err", "APP", nil)).To(MatchError("boom"))
+
+		Expect(first.SendAppLogCallCount()).To(Equal(1))
+		Expect(second.SendAppLogCallCount()).To(Equal(1))
+		Expect(second.SendAppErrorLogCallCount()).To(Equal(1))
+	})
+
+	It("has the sinks that hold on to instances forget them", func() {
+		dir, err := ioutil.TempDir("", "file-sink")
+		Expect(err).NotTo(HaveOccurred())
+		defer os.RemoveAll(dir)
+
+		tags := map[string]string{"source_id": "app-guid", "instance_id": "0"}
+		sinks := log_streamer.LogSinks{&mfakes.FakeIngressClient{}, log_streamer.NewFileSink(dir, 0, 0)}
+		Expect(sinks.SendAppLog("before", "APP", tags)).To(Succeed())
+		sinks.ForgetInstance(tags)
+
+		path := filepath.Join(dir, "app-guid", "0.log")
+		Expect(os.Remove(path)).To(Succeed())
+		Expect(sinks.SendAppLog("after", "APP", tags)).To(Succeed())
+		Expect(path).To(BeAnExistingFile())
+	})
+})
+
+var _ = Describe("SyslogSink", func() {
+	var (
+		logger           *lagertest.TestLogger
+		fakeMetronClient *mfakes.FakeIngressClient
+		process          ifrit.Process
+	)
+
+	tags := map[string]string{"source_id": "app-guid", "instance_id": "3", "space": `a "quoted" ]name`}
+
+	readFrame := func(reader *bufio.Reader) string {
+		length, err := reader.ReadString(' ')
+		Expect(err).NotTo(HaveOccurred())
+		n, err := strconv.Atoi(strings.TrimSuffix(length, " "))
+		Expect(err).NotTo(HaveOccurred())
+		frame := make([]byte, n)
+		_, err = io.ReadFull(reader, frame)
+		Expect(err).NotTo(HaveOccurred())
+		return string(frame)
+	}
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("syslog-sink")
+		fakeMetronClient = &mfakes.FakeIngressClient{}
+		process = nil
+	})
+
+	AfterEach(func() {
+		if process != nil {
+			ginkgomon.Interrupt(process)
+		}
+	})
+
+	It("rejects networks other than tcp and udp", func() {
+		_, err := log_streamer.NewSyslogSink(logger, fakeMetronClient, "unix", "/tmp/syslog.sock", "cell")
+		Expect(err).To(Equal(log_streamer.ErrUnsupportedSyslogNetwork))
+	})
+
+	Context("over tcp", func() {
+		var (
+			listener net.Listener
+			sink     *log_streamer.SyslogSink
+		)
+
+		BeforeEach(func() {
+			var err error
+			listener, err = net.Listen("tcp", "127.0.0.1:0")
+			Expect(err).NotTo(HaveOccurred())
+
+			sink, err = log_streamer.NewSyslogSink(logger, fakeMetronClient, "tcp", listener.Addr().String(), "the-cell")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			listener.Close()
+		})
+
+		Context("while it runs", func() {
+			BeforeEach(func() {
+				process = ginkgomon.Invoke(sink)
+			})
+
+			It("sends RFC 5424 messages framed with octet counting", func() {
+				Expect(sink.SendAppLog("hello world", "APP/PROC/WEB", tags)).To(Succeed())
+				Expect(sink.SendAppErrorLog("oops", "APP/PROC/WEB", tags)).To(Succeed())
+
+				conn, err := listener.Accept()
+				Expect(err).NotTo(HaveOccurred())
+				defer conn.Close()
+				reader := bufio.NewReader(conn)
+
+				message := readFrame(reader)
+				Expect(message).To(MatchRegexp(`^<14>1 \d{4}-\d\d-\d\dT\d\d:\d\d:\d\d\.\d{6}Z the-cell app-guid \[APP/PROC/WEB/3\] - `))
+				Expect(message).To(HaveSuffix(`[tags@47450 instance_id="3" source_id="app-guid" space="a \"quoted\" \]name"] hello world`))
+
+				Expect(readFrame(reader)).To(And(HavePrefix("<11>1 "), HaveSuffix(" oops")))
+			})
+
+			It("redials after the connection fails", func() {
+				Expect(sink.SendAppLog("first", "APP", tags)).To(Succeed())
+				conn, err := listener.Accept()
+				Expect(err).NotTo(HaveOccurred())
+				conn.Close()
+
+				accepted := make(chan net.Conn, 1)
+				go func() {
+					conn, err := listener.Accept()
+					if err == nil {
+						accepted <- conn
+					}
+				}()
+
+				Eventually(func() chan net.Conn {
+					Expect(sink.SendAppLog("again", "APP", tags)).To(Succeed())
+					return accepted
+				}).Should(Receive(&conn))
+				defer conn.Close()
+
+				Expect(readFrame(bufio.NewReader(conn))).To(HaveSuffix(" again"))
+			})
+		})
+
+		It("drops and counts the lines past the queue limit", func() {
+			for i := 0; i < log_streamer.SYSLOG_MAX_QUEUED_MESSAGES; i++ {
+				Expect(sink.SendAppLog("line", "APP", tags)).To(Succeed())
+			}
+			Expect(sink.SendAppLog("dropped", "APP", tags)).To(Equal(log_streamer.ErrSyslogQueueFull))
+
+			Expect(fakeMetronClient.IncrementCounterCallCount()).To(Equal(1))
+			Expect(fakeMetronClient.IncrementCounterArgsForCall(0)).To(Equal(log_streamer.SyslogSinkDroppedLogLinesMetric))
+		})
+
+		It("writes the queued messages when signalled", func() {
+			Expect(sink.SendAppLog("queued", "APP", tags)).To(Succeed())
+
+			process = ifrit.Background(sink)
+			process.Signal(os.Interrupt)
+			Eventually(process.Wait()).Should(Receive(BeNil()))
+			process = nil
+
+			conn, err := listener.Accept()
+			Expect(err).NotTo(HaveOccurred())
+			defer conn.Close()
+
+			Expect(readFrame(bufio.NewReader(conn))).To(HaveSuffix(" queued"))
+		})
+	})
+
+	Context("over udp", func() {
+		It("sends every message as a datagram", func() {
+			conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+			Expect(err).NotTo(HaveOccurred())
+			defer conn.Close()
+
+			sink, err := log_streamer.NewSyslogSink(logger, fakeMetronClient, "udp", conn.LocalAddr().String(), "the-cell")
+			Expect(err).NotTo(HaveOccurred())
+			process = ginkgomon.Invoke(sink)
+
+			Expect(sink.SendAppLog("hello world", "APP", tags)).To(Succeed())
+
+			datagram := make([]byte, 1024)
+			conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+			n, _, err := conn.ReadFrom(datagram)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(datagram[:n])).To(And(HavePrefix("<14>1 "), HaveSuffix("] hello world")))
+		})
+	})
+})
+
+var _ = Describe("FileSink", func() {
+	var (
+		dir  string
+		sink log_streamer.LogSink
+		tags map[string]string
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "file-sink")
+		Expect(err).NotTo(HaveOccurred())
+
+		tags = map[string]string{"source_id": "app-guid", "instance_id": "2"}
+		sink = log_streamer.NewFileSink(dir, 100, 2)
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	readFile := func(name string) string {
+		contents, err := ioutil.ReadFile(filepath.Join(dir, "app-guid", name))
+		Expect(err).NotTo(HaveOccurred())
+		return string(contents)
+	}
+
+	It("appends the lines of an instance to its file", func() {
+		Expect(sink.SendAppLog("to stdout", "APP", tags)).To(Succeed())
+		Expect(sink.SendAppErrorLog("to stderr", "CELL", tags)).To(Succeed())
+
+		lines := strings.Split(strings.TrimSuffix(readFile("2.log"), "\n"), "\n")
+		Expect(lines).To(HaveLen(2))
+		Expect(lines[0]).To(HaveSuffix(" [APP] OUT to stdout"))
+		Expect(lines[1]).To(HaveSuffix(" [CELL] ERR to stderr"))
+	})
+
+	It("keeps the tags from escaping the directory", func() {
+		tags["source_id"] = "../.."
+		Expect(sink.SendAppLog("contained", "APP", tags)).To(Succeed())
+
+		contents, err := ioutil.ReadFile(filepath.Join(dir, ".._..", "2.log"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(contents)).To(ContainSubstring("contained"))
+	})
+
+	It("rotates the files that grow past the max size, keeping max files", func() {
+		for i := 0; i < 4; i++ {
+			Expect(sink.SendAppLog(strings.Repeat(strconv.Itoa(i), 60), "APP", tags)).To(Succeed())
+		}
+
+		Expect(readFile("2.log")).To(ContainSubstring(strings.Repeat("3", 60)))
+		Expect(readFile("2.log.1")).To(ContainSubstring(strings.Repeat("2", 60)))
+		Expect(readFile("2.log.2")).To(ContainSubstring(strings.Repeat("1", 60)))
+		Expect(filepath.Join(dir, "app-guid", "2.log.3")).NotTo(BeAnExistingFile())
+	})
+
+	It("closes the file of an instance it forgets", func() {
+		Expect(sink.SendAppLog("before", "APP", tags)).To(Succeed())
+		sink.(log_streamer.InstanceForgetter).ForgetInstance(tags)
+
+		Expect(os.Remove(filepath.Join(dir, "app-guid", "2.log"))).To(Succeed())
+		Expect(sink.SendAppLog("after", "APP", tags)).To(Succeed())
+
+		Expect(readFile("2.log")).To(And(ContainSubstring("after"), Not(ContainSubstring("before"))))
+	})
+})
+
+var _ = Describe("OTLPSink", func() {
+	var (
+		server    *ghttp.Server
+		fakeClock *fakeclock.FakeClock
+		sink      *log_streamer.OTLPSink
+		process   ifrit.Process
+		requests  chan map[string]interface{}
+	)
+
+	BeforeEach(func() {
+		requests = make(chan map[string]interface{}, 10)
+
+		server = ghttp.NewServer()
+		server.RouteToHandler("POST", "/v1/logs", ghttp.CombineHandlers(
+			ghttp.VerifyContentType("application/json"),
+			func(w http.ResponseWriter, req *http.Request) {
+				request := map[string]interface{}{}
+				Expect(json.NewDecoder(req.Body).Decode(&request)).To(Succeed())
+				requests <- request
+			},
+		))
+
+		fakeClock = fakeclock.NewFakeClock(time.Unix(0, 1000))
+		sink = log_streamer.NewOTLPSink(lagertest.NewTestLogger("test"), http.DefaultClient, server.URL()+"/v1/logs", time.Second, fakeClock)
+		process = ifrit.Invoke(sink)
+	})
+
+	AfterEach(func() {
+		process.Signal(os.Interrupt)
+		Eventually(process.Wait()).Should(Receive())
+		server.Close()
+	})
+
+	It("exports the buffered records every flush interval, grouped by source id", func() {
+		Expect(sink.SendAppLog("hello", "APP", map[string]string{"source_id": "app-guid", "instance_id": "0"})).To(Succeed())
+		Expect(sink.SendAppErrorLog("oops", "CELL", map[string]string{"source_id": "app-guid", "instance_id": "0"})).To(Succeed())
+		Consistently(requests).ShouldNot(Receive())
+
+		fakeClock.WaitForWatcherAndIncrement(time.Second)
+
+		var request map[string]interface{}
+		Eventually(requests).Should(Receive(&request))
+
+		encoded, err := json.Marshal(request)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(encoded).To(MatchJSON(`{
+			"resourceLogs": [{
+				"resource": {"attributes": [{"key": "service.name", "value": {"stringValue": "app-guid"}}]},
+				"scopeLogs": [{
+					"scope": {"name": "code.cloudfoundry.org/executor"},
+					"logRecords": [
+						{
+							"timeUnixNano": "1000",
+							"severityNumber": 9,
+							"severityText": "INFO",
+							"body": {"stringValue": "hello"},
+							"attributes": [
+								{"key": "instance_id", "value": {"stringValue": "0"}},
+								{"key": "source_type", "value": {"stringValue": "APP"}}
+							]
+						},
+						{
+							"timeUnixNano": "1000",
+							"severityNumber": 17,
+							"severityText": "ERROR",
+							"body": {"stringValue": "oops"},
+							"attributes": [
+								{"key": "instance_id", "value": {"stringValue": "0"}},
+								{"key": "source_type", "value": {"stringValue": "CELL"}}
+							]
+						}
+					]
+				}]
+			}]
+		}`))
+	})
+
+	It("exports a full batch without waiting for the interval", func() {
+		for i := 0; i < log_streamer.OTLP_MAX_BATCH_SIZE; i++ {
+			Expect(sink.SendAppLog("line", "APP", map[string]string{"source_id": "app-guid"})).To(Succeed())
+		}
+
+		Eventually(requests).Should(Receive())
+	})
+
+	It("exports the remaining records when signalled", func() {
+		Expect(sink.SendAppLog("last words", "APP", map[string]string{"source_id": "app-guid"})).To(Succeed())
+
+		process.Signal(os.Interrupt)
+		Eventually(process.Wait()).Should(Receive())
+
+		Expect(requests).To(Receive())
+	})
+
+	It("drops the lines past the buffer limit", func() {
+		process.Signal(os.Interrupt)
+		Eventually(process.Wait()).Should(Receive())
+
+		for i := 0; i < log_streamer.OTLP_MAX_BUFFERED_RECORDS; i++ {
+			Expect(sink.SendAppLog("line", "APP", nil)).To(Succeed())
+		}
+		Expect(sink.SendAppLog("dropped", "APP", nil)).To(Equal(log_streamer.ErrOTLPBufferFull))
+	})
+})
@@ -7,6 +7,7 @@ import (
 	"unicode/utf8"
 
 	loggingclient "code.cloudfoundry.org/diego-logging-client"
+	"code.cloudfoundry.org/executor"
 	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
 	"code.cloudfoundry.org/lager"
 )
@@ -18,9 +19,19 @@ type streamDestination struct {
 	messageType          loggregator_v2.Log_Type
 	buffer               []byte
 	processLock          sync.Mutex
+	sink                 LogSink
 	metronClient         loggingclient.IngressClient
 	logRateLimitReporter *logRateLimitReporter
+	tap                  LineTap
 	logger               lager.Logger
+
+	multiline   *multilineGrouper
+	partialLine string
+	flushTimer  *time.Timer
+	stopped     bool
+
+	jsonLines *jsonLineParser
+	redactor  *Redactor
 }
 
 func newStreamDestination(
@@ -28,9 +39,16 @@ func newStreamDestination(
 	sourceName string,
 	tags map[string]string,
 	messageType loggregator_v2.Log_Type,
+	sink LogSink,
 	metronClient loggingclient.IngressClient,
 	maxLogLinesPerSecond int,
+	maxLogBytesPerSecond int64,
 	logRateLimitExceededReportInterval time.Duration,
+	droppedLogs *DroppedLogs,
+	multiline *multilineGrouper,
+	jsonLines *jsonLineParser,
+	redactor *Redactor,
+	tap LineTap,
 ) *streamDestination {
 	return &streamDestination{
 		ctx:                  ctx,
@@ -38,17 +56,45 @@ func newStreamDestination(
 		tags:                 tags,
 		messageType:          messageType,
 		buffer:               make([]byte, 0, MAX_MESSAGE_SIZE),
+		sink:                 sink,
 		metronClient:         metronClient,
-		logRateLimitReporter: newLogRateLimitReporter(ctx, metronClient, maxLogLinesPerSecond, logRateLimitExceededReportInterval),
+		logRateLimitReporter: newLogRateLimitReporter(ctx, sink, metronClient, maxLogLinesPerSecond, maxLogBytesPerSecond, logRateLimitExceededReportInterval, droppedLogs),
+		tap:                  tap,
+		multiline:            multiline,
+		jsonLines:            jsonLines,
+		redactor:             redactor,
 	}
 }
 
 func (destination *streamDestination) lockAndFlush() {
 	destination.processLock.Lock()
 	defer destination.processLock.Unlock()
+
+	if destination.multiline != nil {
+		destination.appendLine()
+	}
 	destination.flush()
 }
 
+// stop sends the record held back by the multiline grouping and stops its
+// flush timer. The lines written afterwards are sent without waiting for the
+// ones continuing them.
+func (destination *streamDestination) stop() {
+	destination.processLock.Lock()
+	defer destination.processLock.Unlock()
+
+	destination.stopped = true
+	if destination.flushTimer != nil {
+		destination.flushTimer.Stop()
+		destination.flushTimer = nil
+	}
+
+	if destination.multiline != nil {
+		destination.appendLine()
+		destination.flush()
+	}
+}
+
 func (destination *streamDestination) Write(data []byte) (int, error) {
 	destination.processMessage(string(data))
 	return len(data), nil
@@ -57,19 +103,43 @@ func (destination *streamDestination) Write(data []byte) (int, error) {
 func (destination *streamDestination) flush() {
 	msg := destination.copyAndResetBuffer()
 
-	err := destination.logRateLimitReporter.Report(destination.sourceName, destination.tags)
+	err := destination.logRateLimitReporter.Report(destination.sourceName, destination.tags, len(msg))
 	if err != nil {
 		return
 	}
 
 	if len(msg) > 0 {
-		switch destination.messageType {
+		message := string(msg)
+		tags := destination.tags
+		messageType := destination.messageType
+		if destination.jsonLines != nil {
+			tags, messageType = destination.jsonLines.parse(message, tags, messageType)
+		}
+
+		switch messageType {
 		case loggregator_v2.Log_OUT:
-			destination.metronClient.SendAppLog(string(msg), destination.sourceName, destination.tags)
+			destination.sink.SendAppLog(message, destination.sourceName, tags)
 		case loggregator_v2.Log_ERR:
-			destination.metronClient.SendAppErrorLog(string(msg), destination.sourceName, destination.tags)
+			destination.sink.SendAppErrorLog(message, destination.sourceName, tags)
 		}
+
+		if destination.tap != nil {
+			destination.tap.Publish(executor.LogLine{
+				SourceName: destination.sourceName,
+				Stream:     logStream(messageType),
+				Message:    message,
+				Tags:       tags,
+				Timestamp:  time.Now().UnixNano(),
+			})
+		}
+	}
+}
+
+func logStream(messageType loggregator_v2.Log_Type) executor.LogStream {
+	if messageType == loggregator_v2.Log_ERR {
+		return executor.LogStreamStderr
 	}
+	return executor.LogStreamStdout
 }
 
 // Not thread safe.  should only be called when holding the processLock
@@ -103,6 +173,12 @@ func (destination *streamDestination) processString(message string, terminates b
 	destination.processLock.Lock()
 	defer destination.processLock.Unlock()
 
+	if destination.multiline != nil {
+		destination.processMultilineString(message, terminates)
+		return
+	}
+
+	message = destination.redact(message)
 	for {
 		message = destination.appendToBuffer(message)
 		if len(message) == 0 {
@@ -116,6 +192,29 @@ func (destination *streamDestination) processString(message string, terminates b
 	}
 }
 
+// redact masks the secrets in a line before it is split into messages, so
+// that neither a secret spanning two messages is missed nor a message grows
+// past MAX_MESSAGE_SIZE once masked.
+func (destination *streamDestination) redact(line string) string {
+	if destination.redactor == nil {
+		return line
+	}
+
+	line, redactions := destination.redactor.Redact(line)
+	if redactions > 0 {
+		destination.redactor.report(destination.metronClient, destination.tags, false)
+	}
+	return line
+}
+
+// reportRedactions sends the total of the redactions without waiting for the
+// report interval, so that the last of them are not left out.
+func (destination *streamDestination) reportRedactions() {
+	if destination.redactor != nil {
+		destination.redactor.report(destination.metronClient, destination.tags, true)
+	}
+}
+
 // Not thread safe.  should only be called when holding the processLock
 func (destination *streamDestination) appendToBuffer(message string) string {
 	if len(message)+len(destination.buffer) >= MAX_MESSAGE_SIZE {
@@ -153,7 +252,12 @@ func (d *streamDestination) withSource(ctx context.Context, sourceName string) *
 		tags:                 d.tags,
 		messageType:          d.messageType,
 		buffer:               make([]byte, 0, MAX_MESSAGE_SIZE),
+		sink:                 d.sink,
 		metronClient:         d.metronClient,
 		logRateLimitReporter: d.logRateLimitReporter,
+		tap:                  d.tap,
+		multiline:            d.multiline,
+		jsonLines:            d.jsonLines,
+		redactor:             d.redactor,
 	}
 }
@@ -0,0 +1,254 @@
+package log_streamer
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	loggingclient "code.cloudfoundry.org/diego-logging-client"
+	"code.cloudfoundry.org/lager"
+)
+
+const (
+	SYSLOG_DIAL_TIMEOUT  = 5 * time.Second
+	SYSLOG_WRITE_TIMEOUT = 5 * time.Second
+
+	// SYSLOG_MAX_QUEUED_MESSAGES bounds the messages waiting to be written;
+	// the lines sent past it are dropped.
+	SYSLOG_MAX_QUEUED_MESSAGES = 8192
+
+	// the lines the syslog sinks of the cell dropped because their queue was
+	// full
+	SyslogSinkDroppedLogLinesMetric = "SyslogSinkDroppedLogLines"
+
+	// syslogFacilityUser is the facility of every message, as in RFC 5424
+	// section 6.2.1.
+	syslogFacilityUser = 1
+
+	syslogSeverityError = 3
+	syslogSeverityInfo  = 6
+
+	// syslogTagsSDID is the structured data element carrying the tags of a
+	// line. 47450 is the enterprise number Loggregator's syslog drains use.
+	syslogTagsSDID = "tags@47450"
+
+	syslogTimestampFormat = "2006-01-02T15:04:05.000000Z07:00"
+)
+
+var (
+	ErrUnsupportedSyslogNetwork = errors.New("syslog network must be tcp or udp")
+	ErrSyslogQueueFull          = errors.New("syslog queue is full")
+)
+
+// SyslogSink is a LogSink sending every line as an RFC 5424 message to a
+// syslog server. Over tcp, messages are framed with octet counting (RFC 6587);
+// over udp, every message is a datagram.
+//
+// The messages are queued and written while the sink runs, and the ones still
+// queued once more when it is signalled, so that a slow or unreachable server
+// never holds up the containers' logs. The connection is dialed for the first
+// message and again for the message after a failed write; a message that
+// cannot be written is dropped.
+type SyslogSink struct {
+	logger       lager.Logger
+	metronClient loggingclient.IngressClient
+	network      string
+	address      string
+	hostname     string
+
+	queue chan string
+
+	// conn is only used by Run
+	conn net.Conn
+}
+
+func NewSyslogSink(logger lager.Logger, metronClient loggingclient.IngressClient, network, address, hostname string) (*SyslogSink, error) {
+	switch network {
+	case "tcp", "udp":
+	default:
+		return nil, ErrUnsupportedSyslogNetwork
+	}
+
+	return &SyslogSink{
+		logger:       logger.Session("syslog-sink", lager.Data{"network": network, "address": address}),
+		metronClient: metronClient,
+		network:      network,
+		address:      address,
+		hostname:     hostname,
+		queue:        make(chan string, SYSLOG_MAX_QUEUED_MESSAGES),
+	}, nil
+}
+
+func (s *SyslogSink) SendAppLog(message, sourceType string, tags map[string]string) error {
+	return s.send(syslogSeverityInfo, message, sourceType, tags)
+}
+
+func (s *SyslogSink) SendAppErrorLog(message, sourceType string, tags map[string]string) error {
+	return s.send(syslogSeverityError, message, sourceType, tags)
+}
+
+func (s *SyslogSink) send(severity int, message, sourceType string, tags map[string]string) error {
+	msg := formatRFC5424(severity, time.Now(), s.hostname, message, sourceType, tags)
+	if s.network == "tcp" {
+		msg = fmt.Sprintf("%d %s", len(msg), msg)
+	}
+
+	select {
+	case s.queue <- msg:
+		return nil
+	default:
+		s.metronClient.IncrementCounter(SyslogSinkDroppedLogLinesMetric)
+		return ErrSyslogQueueFull
+	}
+}
+
+func (s *SyslogSink) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	close(ready)
+
+	defer func() {
+		if s.conn != nil {
+			s.conn.Close()
+		}
+	}()
+
+	failing := false
+	for {
+		select {
+		case <-signals:
+			s.logger.Info("signalled")
+			s.drain()
+			return nil
+		case msg := <-s.queue:
+			err := s.write(msg)
+			if err != nil && !failing {
+				s.logger.Error("failed-to-write", err)
+			}
+			failing = err != nil
+		}
+	}
+}
+
+// drain writes the queued messages until the queue is empty or a write fails.
+func (s *SyslogSink) drain() {
+	for {
+		select {
+		case msg := <-s.queue:
+			err := s.write(msg)
+			if err != nil {
+				s.logger.Error("failed-to-write-queued-messages", err, lager.Data{"dropped": len(s.queue) + 1})
+				return
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (s *SyslogSink) write(msg string) error {
+	if s.conn == nil {
+		conn, err := net.DialTimeout(s.network, s.address, SYSLOG_DIAL_TIMEOUT)
+		if err != nil {
+			return err
+		}
+		s.conn = conn
+	}
+
+	s.conn.SetWriteDeadline(time.Now().Add(SYSLOG_WRITE_TIMEOUT))
+	_, err := s.conn.Write([]byte(msg))
+	if err != nil {
+		s.conn.Close()
+		s.conn = nil
+		return err
+	}
+
+	return nil
+}
+
+// formatRFC5424 formats a line as an RFC 5424 syslog message. The app name is
+// the source_id tag and the process id the source type and the instance_id
+// tag, as in Loggregator's syslog drains. All of the tags are kept as
+// structured data.
+func formatRFC5424(severity int, timestamp time.Time, hostname, message, sourceType string, tags map[string]string) string {
+	procID := sourceType
+	if instanceID, ok := tags["instance_id"]; ok {
+		procID = fmt.Sprintf("[%s/%s]", sourceType, instanceID)
+	}
+
+	return fmt.Sprintf(
+		"<%d>1 %s %s %s %s - %s %s",
+		syslogFacilityUser*8+severity,
+		timestamp.UTC().Format(syslogTimestampFormat),
+		syslogHeaderField(hostname, 255),
+		syslogHeaderField(tags["source_id"], 48),
+		syslogHeaderField(procID, 128),
+		syslogStructuredData(tags),
+		message,
+	)
+}
+
+// syslogHeaderField replaces the characters a header field cannot contain and
+// truncates it to maxLen. An empty field is the nil value "-".
+func syslogHeaderField(field string, maxLen int) string {
+	if field == "" {
+		return "-"
+	}
+
+	field = strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 {
+			return '_'
+		}
+		return r
+	}, field)
+
+	if len(field) > maxLen {
+		field = field[:maxLen]
+	}
+	return field
+}
+
+func syslogStructuredData(tags map[string]string) string {
+	if len(tags) == 0 {
+		return "-"
+	}
+
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	sd := &strings.Builder{}
+	sd.WriteString("[" + syslogTagsSDID)
+	for _, key := range keys {
+		fmt.Fprintf(sd, " %s=\"%s\"", syslogParamName(key), syslogParamValueEscaper.Replace(tags[key]))
+	}
+	sd.WriteString("]")
+
+	return sd.String()
+}
+
+// syslogParamName replaces the characters a structured data parameter name
+// cannot contain and truncates it to 32 characters.
+func syslogParamName(name string) string {
+	if name == "" {
+		return "_"
+	}
+
+	name = strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 || r == '=' || r == ']' || r == '"' {
+			return '_'
+		}
+		return r
+	}, name)
+
+	if len(name) > 32 {
+		name = name[:32]
+	}
+	return name
+}
+
+var syslogParamValueEscaper = strings.NewReplacer(`"`, `\"`, `\`, `\\`, `]`, `\]`)
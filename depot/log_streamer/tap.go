@@ -0,0 +1,200 @@
+package log_streamer
+
+import (
+	"errors"
+	"sync"
+
+	"code.cloudfoundry.org/executor"
+)
+
+const TAP_SUBSCRIBER_BUFFER = 1024
+
+var ErrReadFromClosedLogSource = errors.New("read from closed log source")
+
+// LineTap receives every line a LogStreamer sends to its LogSink.
+type LineTap interface {
+	Publish(line executor.LogLine)
+}
+
+// Tap fans the lines of the containers out to local subscribers. The last
+// lines of every container are kept in a bounded backlog, which a new
+// subscriber receives before the lines that follow.
+type Tap interface {
+	// Container returns the LineTap for the LogStreamers of a container. The
+	// container can be subscribed to from then on, until it is forgotten.
+	Container(guid string) LineTap
+
+	// Subscribe returns executor.ErrContainerNotFound for a container that
+	// has no LineTap.
+	Subscribe(guid string) (executor.LogSource, error)
+
+	// Forget drops the backlog of a container and closes its subscriptions.
+	Forget(guid string)
+}
+
+func NewTap(backlogSize int) Tap {
+	return &tap{
+		backlogSize: backlogSize,
+		containers:  map[string]*tappedContainer{},
+	}
+}
+
+// tap delivers lines without blocking: a subscriber whose buffer is full
+// misses the line.
+type tap struct {
+	lock        sync.Mutex
+	backlogSize int
+	containers  map[string]*tappedContainer
+}
+
+type tappedContainer struct {
+	backlog     []executor.LogLine
+	oldest      int
+	subscribers map[*logSource]struct{}
+}
+
+func (t *tap) Container(guid string) LineTap {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	return containerTap{tap: t, guid: guid, container: t.container(guid)}
+}
+
+func (t *tap) Subscribe(guid string) (executor.LogSource, error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	container, ok := t.containers[guid]
+	if !ok {
+		return nil, executor.ErrContainerNotFound
+	}
+
+	replay := make([]executor.LogLine, 0, len(container.backlog))
+	for i := range container.backlog {
+		replay = append(replay, container.backlog[(container.oldest+i)%len(container.backlog)])
+	}
+
+	source := &logSource{
+		tap:    t,
+		guid:   guid,
+		replay: replay,
+		lines:  make(chan executor.LogLine, TAP_SUBSCRIBER_BUFFER),
+		done:   make(chan struct{}),
+	}
+	container.subscribers[source] = struct{}{}
+
+	return source, nil
+}
+
+func (t *tap) Forget(guid string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	container, ok := t.containers[guid]
+	if !ok {
+		return
+	}
+	delete(t.containers, guid)
+
+	for source := range container.subscribers {
+		source.close()
+	}
+}
+
+// publish ignores the lines of a container that was forgotten since its
+// LineTap was made, which would otherwise bring it back for good.
+func (t *tap) publish(guid string, container *tappedContainer, line executor.LogLine) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.containers[guid] != container {
+		return
+	}
+
+	if t.backlogSize > 0 {
+		if len(container.backlog) < t.backlogSize {
+			container.backlog = append(container.backlog, line)
+		} else {
+			container.backlog[container.oldest] = line
+			container.oldest = (container.oldest + 1) % len(container.backlog)
+		}
+	}
+
+	for source := range container.subscribers {
+		select {
+		case source.lines <- line:
+		default:
+		}
+	}
+}
+
+// container must be called with the lock held.
+func (t *tap) container(guid string) *tappedContainer {
+	container, ok := t.containers[guid]
+	if !ok {
+		container = &tappedContainer{subscribers: map[*logSource]struct{}{}}
+		t.containers[guid] = container
+	}
+	return container
+}
+
+func (t *tap) unsubscribe(source *logSource) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if container, ok := t.containers[source.guid]; ok {
+		delete(container.subscribers, source)
+	}
+}
+
+type containerTap struct {
+	tap       *tap
+	guid      string
+	container *tappedContainer
+}
+
+func (c containerTap) Publish(line executor.LogLine) {
+	c.tap.publish(c.guid, c.container, line)
+}
+
+type logSource struct {
+	tap  *tap
+	guid string
+
+	lock   sync.Mutex
+	replay []executor.LogLine
+
+	lines     chan executor.LogLine
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func (source *logSource) Next() (executor.LogLine, error) {
+	source.lock.Lock()
+	if len(source.replay) > 0 {
+		line := source.replay[0]
+		source.replay = source.replay[1:]
+		source.lock.Unlock()
+		return line, nil
+	}
+	source.lock.Unlock()
+
+	select {
+	case line := <-source.lines:
+		return line, nil
+	case <-source.done:
+		return executor.LogLine{}, ErrReadFromClosedLogSource
+	}
+}
+
+func (source *logSource) Close() error {
+	source.tap.unsubscribe(source)
+	source.close()
+	return nil
+}
+
+func (source *logSource) close() {
+	source.closeOnce.Do(func() {
+		close(source.done)
+	})
+}
@@ -0,0 +1,166 @@
+package log_streamer_test
+
+import (
+	"fmt"
+	"time"
+
+	mfakes "code.cloudfoundry.org/diego-logging-client/testhelpers"
+	"code.cloudfoundry.org/executor"
+	"code.cloudfoundry.org/executor/depot/log_streamer"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Tap", func() {
+	var tap log_streamer.Tap
+
+	line := func(message string) executor.LogLine {
+		return executor.LogLine{SourceName: "APP", Stream: executor.LogStreamStdout, Message: message}
+	}
+
+	next := func(source executor.LogSource) string {
+		l, err := source.Next()
+		Expect(err).NotTo(HaveOccurred())
+		return l.Message
+	}
+
+	subscribe := func(guid string) executor.LogSource {
+		source, err := tap.Subscribe(guid)
+		Expect(err).NotTo(HaveOccurred())
+		return source
+	}
+
+	BeforeEach(func() {
+		tap = log_streamer.NewTap(2)
+	})
+
+	It("delivers the lines of a container to its subscribers", func() {
+		containerTap := tap.Container("guid-a")
+		source := subscribe("guid-a")
+		defer source.Close()
+
+		tap.Container("guid-b").Publish(line("other"))
+		containerTap.Publish(line("mine"))
+
+		Expect(next(source)).To(Equal("mine"))
+	})
+
+	It("refuses to subscribe to containers without a LineTap", func() {
+		_, err := tap.Subscribe("guid-a")
+		Expect(err).To(Equal(executor.ErrContainerNotFound))
+
+		tap.Container("guid-a")
+		tap.Forget("guid-a")
+
+		_, err = tap.Subscribe("guid-a")
+		Expect(err).To(Equal(executor.ErrContainerNotFound))
+	})
+
+	It("replays the last lines of the container to new subscribers", func() {
+		for i := 1; i <= 3; i++ {
+			tap.Container("guid-a").Publish(line(fmt.Sprintf("line-%d", i)))
+		}
+
+		source := subscribe("guid-a")
+		defer source.Close()
+
+		tap.Container("guid-a").Publish(line("line-4"))
+
+		Expect(next(source)).To(Equal("line-2"))
+		Expect(next(source)).To(Equal("line-3"))
+		Expect(next(source)).To(Equal("line-4"))
+	})
+
+	Context("when the backlog size is zero", func() {
+		BeforeEach(func() {
+			tap = log_streamer.NewTap(0)
+		})
+
+		It("only delivers new lines", func() {
+			tap.Container("guid-a").Publish(line("old"))
+
+			source := subscribe("guid-a")
+			defer source.Close()
+
+			tap.Container("guid-a").Publish(line("new"))
+			Expect(next(source)).To(Equal("new"))
+		})
+	})
+
+	It("stops delivering to closed sources", func() {
+		tap.Container("guid-a")
+		source := subscribe("guid-a")
+		Expect(source.Close()).To(Succeed())
+
+		tap.Container("guid-a").Publish(line("dropped"))
+
+		_, err := source.Next()
+		Expect(err).To(Equal(log_streamer.ErrReadFromClosedLogSource))
+	})
+
+	It("closes the subscriptions and drops the backlog of forgotten containers", func() {
+		tap.Container("guid-a").Publish(line("old"))
+		source := subscribe("guid-a")
+		Expect(next(source)).To(Equal("old"))
+
+		tap.Forget("guid-a")
+
+		_, err := source.Next()
+		Expect(err).To(Equal(log_streamer.ErrReadFromClosedLogSource))
+
+		tap.Container("guid-a").Publish(line("new"))
+		newSource := subscribe("guid-a")
+		defer newSource.Close()
+		Expect(next(newSource)).To(Equal("new"))
+	})
+
+	It("ignores the lines published through the taps of forgotten containers", func() {
+		containerTap := tap.Container("guid-a")
+		tap.Forget("guid-a")
+
+		containerTap.Publish(line("late"))
+
+		newContainerTap := tap.Container("guid-a")
+		source := subscribe("guid-a")
+		defer source.Close()
+		newContainerTap.Publish(line("new"))
+		Expect(next(source)).To(Equal("new"))
+	})
+
+	Describe("as the tap of a LogStreamer", func() {
+		var (
+			fakeClient *mfakes.FakeIngressClient
+			streamer   log_streamer.LogStreamer
+		)
+
+		BeforeEach(func() {
+			fakeClient = &mfakes.FakeIngressClient{}
+			streamer = log_streamer.New("log-guid", "APP", 0, map[string]string{"foo": "bar"}, fakeClient, fakeClient, 0, 0, time.Minute, nil, nil, nil, nil, tap.Container("container-guid"))
+		})
+
+		It("receives the lines sent to the sink, with their source and stream", func() {
+			source := subscribe("container-guid")
+			defer source.Close()
+
+			fmt.Fprintln(streamer.Stdout(), "to stdout")
+			fmt.Fprintln(streamer.WithSource("CELL").Stderr(), "to stderr")
+
+			l, err := source.Next()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(l.SourceName).To(Equal("APP"))
+			Expect(l.Stream).To(Equal(executor.LogStreamStdout))
+			Expect(l.Message).To(Equal("to stdout"))
+			Expect(l.Tags).To(HaveKeyWithValue("foo", "bar"))
+			Expect(l.Timestamp).NotTo(BeZero())
+
+			l, err = source.Next()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(l.SourceName).To(Equal("CELL"))
+			Expect(l.Stream).To(Equal(executor.LogStreamStderr))
+			Expect(l.Message).To(Equal("to stderr"))
+
+			Expect(fakeClient.SendAppLogCallCount()).To(Equal(1))
+			Expect(fakeClient.SendAppErrorLogCallCount()).To(Equal(1))
+		})
+	})
+})
@@ -23,6 +23,9 @@ const (
 	allocatedMemoryMetric = "CapacityAllocatedMemory"
 	allocatedDiskMetric   = "CapacityAllocatedDisk"
 
+	effectiveMemoryOvercommitMetric = "CapacityEffectiveMemoryOvercommitPercent"
+	effectiveDiskOvercommitMetric   = "CapacityEffectiveDiskOvercommitPercent"
+
 	containerUsageMemoryMetric = "ContainerUsageMemory"
 	containerUsageDiskMetric   = "ContainerUsageDisk"
 
@@ -44,6 +47,11 @@ type Reporter struct {
 	Logger         lager.Logger
 	MetronClient   loggingclient.IngressClient
 	Tags           map[string]string
+
+	// the factors the advertised total capacity was scaled by; zero is
+	// treated as no overcommit
+	MemoryOvercommitRatio float64
+	DiskOvercommitRatio   float64
 }
 
 func (reporter *Reporter) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
@@ -147,6 +155,15 @@ func (reporter *Reporter) Run(signals <-chan os.Signal, ready chan<- struct{}) e
 				logger.Error("failed-to-send-allocated-disk-metric", err)
 			}
 
+			err = reporter.MetronClient.SendMetric(effectiveMemoryOvercommitMetric, effectiveOvercommitPercent(allocatedMemoryMB, totalCapacity.MemoryMB, reporter.MemoryOvercommitRatio), tagOption)
+			if err != nil {
+				logger.Error("failed-to-send-effective-memory-overcommit-metric", err)
+			}
+			err = reporter.MetronClient.SendMetric(effectiveDiskOvercommitMetric, effectiveOvercommitPercent(allocatedDiskMB, totalCapacity.DiskMB, reporter.DiskOvercommitRatio), tagOption)
+			if err != nil {
+				logger.Error("failed-to-send-effective-disk-overcommit-metric", err)
+			}
+
 			err = reporter.MetronClient.SendMebiBytes(containerUsageMemoryMetric, containerUsageMemoryMB, tagOption)
 			if err != nil {
 				logger.Error("failed-to-send-container-memory-metric", err)
@@ -177,6 +194,19 @@ func containerIsStarting(container executor.Container) bool {
 		container.State == executor.StateCreated
 }
 
+// effectiveOvercommitPercent reports allocated resources as a percentage of
+// the physical capacity, i.e. the advertised capacity before overcommit
+func effectiveOvercommitPercent(allocated, advertised int, ratio float64) int {
+	if allocated < 0 || advertised <= 0 {
+		return -1
+	}
+	if ratio <= 0 {
+		ratio = 1
+	}
+	physical := float64(advertised) / ratio
+	return int(float64(allocated) * 100 / physical)
+}
+
 func bytesToMebibytes(bytes int) int {
 	return bytes / 1024 / 1024
 }
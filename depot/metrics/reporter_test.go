@@ -28,6 +28,8 @@ type metricEnvelope struct {
 var _ = Describe("Reporter", func() {
 	var (
 		reportInterval   time.Duration
+		memoryOvercommit float64
+		diskOvercommit   float64
 		executorClient   *fakes.FakeClient
 		fakeClock        *fakeclock.FakeClock
 		fakeMetronClient *mfakes.FakeIngressClient
@@ -41,6 +43,8 @@ var _ = Describe("Reporter", func() {
 	BeforeEach(func() {
 		logger = lagertest.NewTestLogger("test")
 		reportInterval = 1 * time.Millisecond
+		memoryOvercommit = 0
+		diskOvercommit = 0
 		executorClient = new(fakes.FakeClient)
 
 		fakeClock = fakeclock.NewFakeClock(time.Now())
@@ -111,6 +115,9 @@ var _ = Describe("Reporter", func() {
 			Logger:         logger,
 			MetronClient:   fakeMetronClient,
 			Tags:           map[string]string{"foo": "bar"},
+
+			MemoryOvercommitRatio: memoryOvercommit,
+			DiskOvercommitRatio:   diskOvercommit,
 		})
 		fakeClock.WaitForWatcherAndIncrement(reportInterval)
 
@@ -123,7 +130,7 @@ var _ = Describe("Reporter", func() {
 
 	It("reports the current capacity on the given interval", func() {
 		Eventually(fakeMetronClient.SendMebiBytesCallCount).Should(Equal(8))
-		Eventually(fakeMetronClient.SendMetricCallCount).Should(Equal(4))
+		Eventually(fakeMetronClient.SendMetricCallCount).Should(Equal(6))
 
 		m.RLock()
 		remainingMemory := metricMap["CapacityRemainingMemory"]
@@ -195,7 +202,7 @@ var _ = Describe("Reporter", func() {
 		m.RUnlock()
 
 		Eventually(fakeMetronClient.SendMebiBytesCallCount).Should(Equal(16))
-		Eventually(fakeMetronClient.SendMetricCallCount).Should(Equal(8))
+		Eventually(fakeMetronClient.SendMetricCallCount).Should(Equal(12))
 
 		m.RLock()
 
@@ -217,6 +224,49 @@ var _ = Describe("Reporter", func() {
 		m.RUnlock()
 	})
 
+	Describe("effective overcommit", func() {
+		It("reports allocated resources as a percentage of total capacity when there is no overcommit", func() {
+			Eventually(fakeMetronClient.SendMetricCallCount).Should(Equal(6))
+
+			m.RLock()
+			Expect(metricMap["CapacityEffectiveMemoryOvercommitPercent"].value).To(Equal(87))
+			Expect(metricMap["CapacityEffectiveMemoryOvercommitPercent"].tags).To(Equal(map[string]string{"foo": "bar"}))
+			Expect(metricMap["CapacityEffectiveDiskOvercommitPercent"].value).To(Equal(87))
+			m.RUnlock()
+		})
+
+		Context("when the capacity is overcommitted", func() {
+			BeforeEach(func() {
+				memoryOvercommit = 2
+				diskOvercommit = 1.25
+			})
+
+			It("reports allocated resources as a percentage of the physical capacity", func() {
+				Eventually(fakeMetronClient.SendMetricCallCount).Should(Equal(6))
+
+				m.RLock()
+				Expect(metricMap["CapacityEffectiveMemoryOvercommitPercent"].value).To(Equal(175))
+				Expect(metricMap["CapacityEffectiveDiskOvercommitPercent"].value).To(Equal(109))
+				m.RUnlock()
+			})
+		})
+
+		Context("when the allocated resources are unknown", func() {
+			BeforeEach(func() {
+				executorClient.RemainingResourcesReturns(executor.ExecutorResources{}, errors.New("oh no!"))
+			})
+
+			It("reports -1", func() {
+				Eventually(fakeMetronClient.SendMetricCallCount).Should(Equal(6))
+
+				m.RLock()
+				Expect(metricMap["CapacityEffectiveMemoryOvercommitPercent"].value).To(Equal(-1))
+				Expect(metricMap["CapacityEffectiveDiskOvercommitPercent"].value).To(Equal(-1))
+				m.RUnlock()
+			})
+		})
+	})
+
 	Context("when getting remaining resources fails", func() {
 		BeforeEach(func() {
 			executorClient.RemainingResourcesReturns(executor.ExecutorResources{}, errors.New("oh no!"))
@@ -273,7 +323,7 @@ var _ = Describe("Reporter", func() {
 		})
 
 		It("reports garden.containers as -1", func() {
-			Eventually(fakeMetronClient.SendMetricCallCount).Should(Equal(4))
+			Eventually(fakeMetronClient.SendMetricCallCount).Should(Equal(6))
 
 			m.RLock()
 			Eventually(metricMap["ContainerCount"].value).Should(Equal(-1))
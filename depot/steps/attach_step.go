@@ -0,0 +1,32 @@
+package steps
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/executor/depot/log_streamer"
+	"code.cloudfoundry.org/garden"
+	"code.cloudfoundry.org/lager"
+)
+
+// NewAttach returns a step that reattaches to a process already running in
+// the container instead of spawning a new one. Signalling and exit handling
+// behave exactly like a run step.
+func NewAttach(
+	container garden.Container,
+	processID string,
+	streamer log_streamer.LogStreamer,
+	logger lager.Logger,
+	clock clock.Clock,
+	gracefulShutdownInterval time.Duration,
+) *runStep {
+	logger = logger.Session("attach-step", lager.Data{"process-id": processID})
+	return &runStep{
+		container:                container,
+		streamer:                 streamer,
+		logger:                   logger,
+		clock:                    clock,
+		gracefulShutdownInterval: gracefulShutdownInterval,
+		attachProcessID:          processID,
+	}
+}
@@ -0,0 +1,155 @@
+package steps_test
+
+import (
+	"errors"
+	"os"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/executor/depot/log_streamer/fake_log_streamer"
+	"code.cloudfoundry.org/executor/depot/steps"
+	"code.cloudfoundry.org/executor/fakes"
+	"code.cloudfoundry.org/garden"
+	"code.cloudfoundry.org/garden/gardenfakes"
+	"code.cloudfoundry.org/lager/lagertest"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/tedsuo/ifrit"
+)
+
+var _ = Describe("AttachStep", func() {
+	var (
+		step ifrit.Runner
+
+		fakeStreamer     *fake_log_streamer.FakeLogStreamer
+		gardenClient     *fakes.FakeGardenClient
+		logger           *lagertest.TestLogger
+		fakeClock        *fakeclock.FakeClock
+		attachedProcess  *gardenfakes.FakeProcess
+		attachError      error
+		gracefulShutdown time.Duration = 5 * time.Second
+	)
+
+	handle := "some-container-handle"
+
+	BeforeEach(func() {
+		fakeStreamer = new(fake_log_streamer.FakeLogStreamer)
+		fakeStreamer.StdoutReturns(gbytes.NewBuffer())
+		fakeStreamer.StderrReturns(gbytes.NewBuffer())
+		fakeStreamer.SourceNameReturns("testlogsource")
+		gardenClient = fakes.NewGardenClient()
+		logger = lagertest.NewTestLogger("test")
+		fakeClock = fakeclock.NewFakeClock(time.Unix(123, 456))
+
+		attachedProcess = new(gardenfakes.FakeProcess)
+		attachError = nil
+
+		gardenClient.Connection.AttachStub = func(string, string, garden.ProcessIO) (garden.Process, error) {
+			return attachedProcess, attachError
+		}
+	})
+
+	JustBeforeEach(func() {
+		gardenClient.Connection.CreateReturns(handle, nil)
+
+		container, err := gardenClient.Create(garden.ContainerSpec{})
+		Expect(err).NotTo(HaveOccurred())
+
+		step = steps.NewAttach(container, "some-process-id", fakeStreamer, logger, fakeClock, gracefulShutdown)
+	})
+
+	Describe("Run", func() {
+		var process ifrit.Process
+
+		JustBeforeEach(func() {
+			process = ifrit.Background(step)
+		})
+
+		Context("when the attached process exits successfully", func() {
+			BeforeEach(func() {
+				attachedProcess.WaitReturns(0, nil)
+			})
+
+			It("attaches to the process instead of running a new one", func() {
+				Eventually(process.Wait()).Should(Receive(BeNil()))
+
+				Expect(gardenClient.Connection.RunCallCount()).To(Equal(0))
+				Expect(gardenClient.Connection.AttachCallCount()).To(Equal(1))
+
+				attachedHandle, processID, processIO := gardenClient.Connection.AttachArgsForCall(0)
+				Expect(attachedHandle).To(Equal(handle))
+				Expect(processID).To(Equal("some-process-id"))
+				Expect(processIO.Stdout).To(Equal(fakeStreamer.Stdout()))
+				Expect(processIO.Stderr).To(Equal(fakeStreamer.Stderr()))
+			})
+
+			It("becomes ready once attached", func() {
+				Eventually(process.Ready()).Should(BeClosed())
+			})
+		})
+
+		Context("when the attached process exits with a non-zero status", func() {
+			BeforeEach(func() {
+				attachedProcess.WaitReturns(19, nil)
+			})
+
+			It("returns an emittable error", func() {
+				var err error
+				Eventually(process.Wait()).Should(Receive(&err))
+				Expect(err).To(MatchError("testlogsource: Exited with status 19"))
+			})
+		})
+
+		Context("when attaching fails", func() {
+			BeforeEach(func() {
+				attachError = errors.New("process not found")
+			})
+
+			It("returns the error without becoming ready", func() {
+				Eventually(process.Wait()).Should(Receive(MatchError("process not found")))
+				Expect(process.Ready()).NotTo(BeClosed())
+			})
+		})
+	})
+
+	Describe("Signalling", func() {
+		var (
+			process    ifrit.Process
+			waitExited chan int
+		)
+
+		BeforeEach(func() {
+			waitExited = make(chan int, 1)
+			attachedProcess.WaitStub = func() (int, error) {
+				return <-waitExited, nil
+			}
+		})
+
+		JustBeforeEach(func() {
+			process = ifrit.Background(step)
+			Eventually(process.Ready()).Should(BeClosed())
+			process.Signal(os.Interrupt)
+		})
+
+		It("terminates the attached process", func() {
+			Eventually(attachedProcess.SignalCallCount).Should(Equal(1))
+			Expect(attachedProcess.SignalArgsForCall(0)).To(Equal(garden.SignalTerminate))
+
+			waitExited <- (128 + 15)
+			Eventually(process.Wait()).Should(Receive(Equal(new(steps.CancelledError))))
+		})
+
+		It("kills the attached process after the graceful shutdown interval", func() {
+			Eventually(attachedProcess.SignalCallCount).Should(Equal(1))
+
+			fakeClock.WaitForWatcherAndIncrement(gracefulShutdown + time.Second)
+
+			Eventually(attachedProcess.SignalCallCount).Should(Equal(2))
+			Expect(attachedProcess.SignalArgsForCall(1)).To(Equal(garden.SignalKill))
+
+			waitExited <- (128 + 9)
+			Eventually(process.Wait()).Should(Receive(Equal(new(steps.ExceededGracefulShutdownIntervalError))))
+		})
+	})
+})
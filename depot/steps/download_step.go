@@ -9,6 +9,7 @@ import (
 	"code.cloudfoundry.org/bbs/models"
 	"code.cloudfoundry.org/bytefmt"
 	"code.cloudfoundry.org/cacheddownloader"
+	"code.cloudfoundry.org/executor"
 	"code.cloudfoundry.org/executor/depot/log_streamer"
 	"code.cloudfoundry.org/garden"
 	"code.cloudfoundry.org/lager"
@@ -92,7 +93,7 @@ func (step *downloadStep) perform() error {
 		}
 
 		step.emitError(fmt.Sprintf("%s\n", errString))
-		return NewEmittableError(err, errString)
+		return NewEmittableErrorWithCode(executor.FailureCodeDownloadFailed, err, errString)
 	}
 
 	err = step.streamIn(step.model.To, downloadedFile)
@@ -104,7 +105,7 @@ func (step *downloadStep) perform() error {
 			errString = fmt.Sprintf("Copying into the container failed: %v", err)
 		}
 		step.emitError(fmt.Sprintf("%s\n", errString))
-		return NewEmittableError(err, errString)
+		return NewEmittableErrorWithCode(executor.FailureCodeDownloadFailed, err, errString)
 	}
 
 	if downloadedSize != 0 {
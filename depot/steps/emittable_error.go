@@ -1,13 +1,22 @@
 package steps
 
-import "fmt"
+import (
+	"fmt"
+
+	"code.cloudfoundry.org/executor"
+)
 
 type EmittableError struct {
 	msg          string
 	wrappedError error
+	failureCode  executor.FailureCode
 }
 
 func NewEmittableError(wrappedError error, message string, args ...interface{}) *EmittableError {
+	return NewEmittableErrorWithCode(executor.FailureCodeNone, wrappedError, message, args...)
+}
+
+func NewEmittableErrorWithCode(failureCode executor.FailureCode, wrappedError error, message string, args ...interface{}) *EmittableError {
 	msg := message
 	if len(args) > 0 {
 		msg = fmt.Sprintf(message, args...)
@@ -16,6 +25,7 @@ func NewEmittableError(wrappedError error, message string, args ...interface{})
 	return &EmittableError{
 		wrappedError: wrappedError,
 		msg:          msg,
+		failureCode:  failureCode,
 	}
 }
 
@@ -26,3 +36,7 @@ func (e *EmittableError) Error() string {
 func (e *EmittableError) WrappedError() error {
 	return e.wrappedError
 }
+
+func (e *EmittableError) FailureCode() executor.FailureCode {
+	return e.failureCode
+}
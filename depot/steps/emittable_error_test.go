@@ -3,7 +3,9 @@ package steps_test
 import (
 	"errors"
 
+	"code.cloudfoundry.org/executor"
 	"code.cloudfoundry.org/executor/depot/steps"
+	multierror "github.com/hashicorp/go-multierror"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -46,4 +48,42 @@ var _ = Describe("EmittableError", func() {
 			})
 		})
 	})
+
+	Describe("FailureCode", func() {
+		It("has no failure code by default", func() {
+			Expect(steps.NewEmittableError(wrappedError, "Fancy").FailureCode()).To(Equal(executor.FailureCodeNone))
+		})
+
+		It("returns the failure code it was created with", func() {
+			err := steps.NewEmittableErrorWithCode(executor.FailureCodeOOM, wrappedError, "Fancy %s", "hi")
+			Expect(err.FailureCode()).To(Equal(executor.FailureCodeOOM))
+			Expect(err.Error()).To(Equal("Fancy hi"))
+		})
+	})
+})
+
+var _ = Describe("FailureCodeForError", func() {
+	It("returns the code of an emittable error", func() {
+		err := steps.NewEmittableErrorWithCode(executor.FailureCodeDownloadFailed, nil, "Downloading failed")
+		Expect(steps.FailureCodeForError(err)).To(Equal(executor.FailureCodeDownloadFailed))
+	})
+
+	It("classifies processes that did not exit in time", func() {
+		Expect(steps.FailureCodeForError(new(steps.ExitTimeoutError))).To(Equal(executor.FailureCodeExitTimeout))
+		Expect(steps.FailureCodeForError(new(steps.ExceededGracefulShutdownIntervalError))).To(Equal(executor.FailureCodeExitTimeout))
+	})
+
+	It("returns the first code found in aggregated errors", func() {
+		err := multierror.Append(
+			errors.New("no code"),
+			steps.NewEmittableErrorWithCode(executor.FailureCodeHealthCheckFailed, nil, "unhealthy"),
+			steps.NewEmittableErrorWithCode(executor.FailureCodeOOM, nil, "oom"),
+		)
+		Expect(steps.FailureCodeForError(err)).To(Equal(executor.FailureCodeHealthCheckFailed))
+	})
+
+	It("returns no code for other errors", func() {
+		Expect(steps.FailureCodeForError(errors.New("boom"))).To(Equal(executor.FailureCodeNone))
+		Expect(steps.FailureCodeForError(nil)).To(Equal(executor.FailureCodeNone))
+	})
 })
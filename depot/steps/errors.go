@@ -1,6 +1,11 @@
 package steps
 
-import "fmt"
+import (
+	"fmt"
+
+	"code.cloudfoundry.org/executor"
+	"github.com/hashicorp/errwrap"
+)
 
 type IsDisplayableError interface {
 	IsDisplayable() bool
@@ -48,3 +53,22 @@ func multiErrorFormat(errs []error) string {
 	}
 	return errStr
 }
+
+// FailureCodeForError returns the failure code of the outermost error in err
+// that carries one, or FailureCodeNone if there is none.
+func FailureCodeForError(err error) executor.FailureCode {
+	code := executor.FailureCodeNone
+	errwrap.Walk(err, func(err error) {
+		if code != executor.FailureCodeNone {
+			return
+		}
+
+		switch err := err.(type) {
+		case *EmittableError:
+			code = err.FailureCode()
+		case *ExceededGracefulShutdownIntervalError, *ExitTimeoutError:
+			code = executor.FailureCodeExitTimeout
+		}
+	})
+	return code
+}
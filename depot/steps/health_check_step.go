@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/executor"
 	"code.cloudfoundry.org/executor/depot/log_streamer"
 	"code.cloudfoundry.org/lager"
 	"github.com/tedsuo/ifrit"
@@ -17,9 +18,21 @@ const (
 	healthcheckNowUnhealthy = "Instance became unhealthy: %s"
 )
 
+// HealthObserver is told when the container becomes healthy and, later, when
+// it becomes unhealthy. A nil HealthObserver is ignored.
+type HealthObserver func(healthy bool)
+
+func (o HealthObserver) observe(healthy bool) {
+	if o != nil {
+		o(healthy)
+	}
+}
+
 type healthCheckStep struct {
 	readinessCheck ifrit.Runner
 	livenessCheck  ifrit.Runner
+	monitorGate    *MonitorGate
+	healthObserver HealthObserver
 
 	logger              lager.Logger
 	clock               clock.Clock
@@ -37,6 +50,8 @@ func NewHealthCheckStep(
 	logStreamer log_streamer.LogStreamer,
 	healthcheckStreamer log_streamer.LogStreamer,
 	startTimeout time.Duration,
+	monitorGate *MonitorGate,
+	healthObserver HealthObserver,
 ) ifrit.Runner {
 	logger = logger.Session("health-check-step")
 
@@ -48,10 +63,25 @@ func NewHealthCheckStep(
 		logStreamer:         logStreamer,
 		healthCheckStreamer: healthcheckStreamer,
 		startTimeout:        startTimeout,
+		monitorGate:         monitorGate,
+		healthObserver:      healthObserver,
 	}
 }
 
 func (step *healthCheckStep) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	// a container reattached while paused cannot become ready until resumed
+	for {
+		suspended, changed := step.monitorGate.state()
+		if !suspended {
+			break
+		}
+		select {
+		case <-changed:
+		case <-signals:
+			return new(CancelledError)
+		}
+	}
+
 	fmt.Fprint(step.logStreamer.Stdout(), "Starting health monitoring of container\n")
 
 	readinessProcess := ifrit.Background(step.readinessCheck)
@@ -67,7 +97,7 @@ func (step *healthCheckStep) Run(signals <-chan os.Signal, ready chan<- struct{}
 			step.logger.Info("timed-out-before-healthy", lager.Data{
 				"step-error": err.Error(),
 			})
-			return NewEmittableError(err, timeoutCrashReason, healthCheckFailedTime, err.Error())
+			return NewEmittableErrorWithCode(executor.FailureCodeHealthCheckTimeout, err, timeoutCrashReason, healthCheckFailedTime, err.Error())
 		}
 	case s := <-signals:
 		readinessProcess.Signal(s)
@@ -77,19 +107,45 @@ func (step *healthCheckStep) Run(signals <-chan os.Signal, ready chan<- struct{}
 
 	step.logger.Info("transitioned-to-healthy")
 	fmt.Fprint(step.logStreamer.Stdout(), "Container became healthy\n")
+	step.healthObserver.observe(true)
 	close(ready)
 
-	livenessProcess := ifrit.Background(step.livenessCheck)
+	for {
+		suspended, changed := step.monitorGate.state()
+		if suspended {
+			step.logger.Info("liveness-check-suspended")
+			select {
+			case <-changed:
+				step.logger.Info("liveness-check-resumed")
+				continue
+			case <-signals:
+				return new(CancelledError)
+			}
+		}
 
-	select {
-	case err := <-livenessProcess.Wait():
-		step.logger.Info("transitioned-to-unhealthy")
-		fmt.Fprintf(step.healthCheckStreamer.Stderr(), "%s\n", err.Error())
-		fmt.Fprint(step.logStreamer.Stdout(), "Container became unhealthy\n")
-		return NewEmittableError(err, healthcheckNowUnhealthy, err.Error())
-	case s := <-signals:
-		livenessProcess.Signal(s)
-		<-livenessProcess.Wait()
-		return new(CancelledError)
+		livenessProcess := ifrit.Background(step.livenessCheck)
+
+		select {
+		case err := <-livenessProcess.Wait():
+			select {
+			case <-changed:
+				// the container was paused while the check was running, so the
+				// failure says nothing about its health
+				continue
+			default:
+			}
+			step.logger.Info("transitioned-to-unhealthy")
+			fmt.Fprintf(step.healthCheckStreamer.Stderr(), "%s\n", err.Error())
+			fmt.Fprint(step.logStreamer.Stdout(), "Container became unhealthy\n")
+			step.healthObserver.observe(false)
+			return NewEmittableErrorWithCode(executor.FailureCodeHealthCheckFailed, err, healthcheckNowUnhealthy, err.Error())
+		case <-changed:
+			livenessProcess.Signal(os.Interrupt)
+			<-livenessProcess.Wait()
+		case s := <-signals:
+			livenessProcess.Signal(s)
+			<-livenessProcess.Wait()
+			return new(CancelledError)
+		}
 	}
 }
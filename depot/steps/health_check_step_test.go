@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/executor"
 	"code.cloudfoundry.org/executor/depot/log_streamer/fake_log_streamer"
 	"code.cloudfoundry.org/executor/depot/steps"
 	"code.cloudfoundry.org/lager/lagertest"
@@ -26,6 +27,9 @@ var _ = Describe("NewHealthCheckStep", func() {
 		fakeHealthCheckStreamer       *fake_log_streamer.FakeLogStreamer
 
 		startTimeout time.Duration
+		monitorGate  *steps.MonitorGate
+
+		healthTransitions chan bool
 
 		step    ifrit.Runner
 		process ifrit.Process
@@ -34,6 +38,8 @@ var _ = Describe("NewHealthCheckStep", func() {
 
 	BeforeEach(func() {
 		startTimeout = 1 * time.Second
+		monitorGate = nil
+		healthTransitions = make(chan bool, 2)
 
 		readinessCheck = fake_runner.NewTestRunner()
 		livenessCheck = fake_runner.NewTestRunner()
@@ -57,6 +63,8 @@ var _ = Describe("NewHealthCheckStep", func() {
 			fakeStreamer,
 			fakeHealthCheckStreamer,
 			startTimeout,
+			monitorGate,
+			func(healthy bool) { healthTransitions <- healthy },
 		)
 
 		process = ifrit.Background(step)
@@ -88,6 +96,7 @@ var _ = Describe("NewHealthCheckStep", func() {
 				var err *steps.EmittableError
 				Eventually(process.Wait()).Should(Receive(&err))
 				Expect(err.WrappedError()).To(MatchError(ContainSubstring("booom!")))
+				Expect(err.FailureCode()).To(Equal(executor.FailureCodeHealthCheckTimeout))
 			})
 
 			It("logs the step", func() {
@@ -107,6 +116,11 @@ var _ = Describe("NewHealthCheckStep", func() {
 					"Failed after .*: readiness health check never passed.\n",
 				))
 			})
+
+			It("does not report a health transition", func() {
+				Eventually(process.Wait()).Should(Receive())
+				Expect(healthTransitions).NotTo(Receive())
+			})
 		})
 
 		Context("when the readiness check passes", func() {
@@ -124,6 +138,10 @@ var _ = Describe("NewHealthCheckStep", func() {
 				)
 			})
 
+			It("reports the container as healthy", func() {
+				Eventually(healthTransitions).Should(Receive(BeTrue()))
+			})
+
 			It("logs the step", func() {
 				Eventually(logger.TestSink.LogMessages).Should(ConsistOf([]string{
 					"test.health-check-step.transitioned-to-healthy",
@@ -151,6 +169,11 @@ var _ = Describe("NewHealthCheckStep", func() {
 					)
 				})
 
+				It("reports the container as healthy and then unhealthy", func() {
+					Eventually(healthTransitions).Should(Receive(BeTrue()))
+					Eventually(healthTransitions).Should(Receive(BeFalse()))
+				})
+
 				It("emits the healthcheck process response for the failure", func() {
 					Eventually(fakeHealthCheckStreamer.Stderr().(*gbytes.Buffer)).Should(
 						gbytes.Say(fmt.Sprintf("oh no!\n")),
@@ -161,6 +184,49 @@ var _ = Describe("NewHealthCheckStep", func() {
 					var err *steps.EmittableError
 					Eventually(process.Wait()).Should(Receive(&err))
 					Expect(err.WrappedError()).To(Equal(disaster))
+					Expect(err.FailureCode()).To(Equal(executor.FailureCodeHealthCheckFailed))
+				})
+			})
+
+			Context("and the monitor gate is suspended", func() {
+				var liveness *fake_runner.TestRunner
+
+				BeforeEach(func() {
+					monitorGate = steps.NewMonitorGate()
+				})
+
+				JustBeforeEach(func() {
+					liveness = livenessCheck
+					livenessCheck = nil
+
+					Eventually(liveness.RunCallCount).Should(Equal(1))
+					monitorGate.Suspend()
+				})
+
+				It("stops the liveness check without failing", func() {
+					Eventually(liveness.WaitForCall()).Should(Receive(Equal(os.Interrupt)))
+					liveness.TriggerExit(errors.New("interrupted"))
+
+					Consistently(process.Wait()).ShouldNot(Receive())
+					Expect(liveness.RunCallCount()).To(Equal(1))
+
+					process.Signal(os.Interrupt)
+					Eventually(process.Wait()).Should(Receive(Equal(new(steps.CancelledError))))
+				})
+
+				It("restarts the liveness check once resumed", func() {
+					Eventually(liveness.WaitForCall()).Should(Receive(Equal(os.Interrupt)))
+					liveness.TriggerExit(errors.New("interrupted"))
+
+					monitorGate.Resume()
+					Eventually(liveness.RunCallCount).Should(Equal(2))
+
+					disaster := errors.New("oh no!")
+					liveness.TriggerExit(disaster)
+
+					var err *steps.EmittableError
+					Eventually(process.Wait()).Should(Receive(&err))
+					Expect(err.WrappedError()).To(Equal(disaster))
 				})
 			})
 		})
@@ -0,0 +1,54 @@
+package steps
+
+import "sync"
+
+// MonitorGate suspends the liveness checks of a health check step, e.g. while
+// the container is paused and its processes cannot answer them. A nil gate is
+// never suspended.
+type MonitorGate struct {
+	lock      sync.Mutex
+	suspended bool
+	changed   chan struct{}
+}
+
+func NewMonitorGate() *MonitorGate {
+	return &MonitorGate{changed: make(chan struct{})}
+}
+
+func (g *MonitorGate) Suspend() {
+	g.set(true)
+}
+
+func (g *MonitorGate) Resume() {
+	g.set(false)
+}
+
+func (g *MonitorGate) set(suspended bool) {
+	if g == nil {
+		return
+	}
+
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	if g.suspended == suspended {
+		return
+	}
+
+	g.suspended = suspended
+	close(g.changed)
+	g.changed = make(chan struct{})
+}
+
+// state returns whether the gate is suspended and a channel that is closed
+// the next time that changes.
+func (g *MonitorGate) state() (bool, <-chan struct{}) {
+	if g == nil {
+		return false, nil
+	}
+
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	return g.suspended, g.changed
+}
@@ -19,6 +19,8 @@ func NewMonitor(
 	healthyInterval time.Duration,
 	unhealthyInterval time.Duration,
 	workPool *workpool.WorkPool,
+	monitorGate *MonitorGate,
+	healthObserver HealthObserver,
 	proxyReadinessChecks ...ifrit.Runner,
 ) ifrit.Runner {
 	throttledCheckFunc := func() ifrit.Runner {
@@ -31,5 +33,5 @@ func NewMonitor(
 	// add the proxy readiness checks (if any)
 	readiness = NewParallel(append(proxyReadinessChecks, readiness))
 
-	return NewHealthCheckStep(readiness, liveness, logger, clock, logStreamer, logStreamer, startTimeout)
+	return NewHealthCheckStep(readiness, liveness, logger, clock, logStreamer, logStreamer, startTimeout, monitorGate, healthObserver)
 }
@@ -84,6 +84,8 @@ var _ = Describe("MonitorStep", func() {
 			healthyInterval,
 			unhealthyInterval,
 			workPool,
+			nil,
+			nil,
 		)
 	})
 
@@ -48,7 +48,7 @@ func (step *outputWrapperStep) Run(signals <-chan os.Signal, ready chan<- struct
 		if step.prefix != "" {
 			msg = step.prefix + ": " + msg
 		}
-		return NewEmittableError(nil, msg)
+		return NewEmittableErrorWithCode(FailureCodeForError(subStepErr), nil, msg)
 	}
 
 	return subStepErr
@@ -30,6 +30,7 @@ type runStep struct {
 	gracefulShutdownInterval time.Duration
 	suppressExitStatusCode   bool
 	sidecar                  Sidecar
+	attachProcessID          string
 }
 
 type Sidecar struct {
@@ -137,6 +138,16 @@ func (step *runStep) Run(signals <-chan os.Signal, ready chan<- struct{}) error
 	processChan := make(chan garden.Process, 1)
 	runStartTime := step.clock.Now()
 	go func() {
+		if step.attachProcessID != "" {
+			process, err := step.container.Attach(step.attachProcessID, processIO)
+			if err != nil {
+				errChan <- err
+			} else {
+				processChan <- process
+			}
+			return
+		}
+
 		process, err := step.container.Run(garden.ProcessSpec{
 			ID:   step.sidecar.Name,
 			Path: step.model.Path,
@@ -202,6 +213,7 @@ func (step *runStep) Run(signals <-chan os.Signal, ready chan<- struct{}) error
 			})
 
 			var exitErrorMessage, emittableExitErrorMessage string
+			failureCode := executor.FailureCodeProcessExited
 
 			if !step.suppressExitStatusCode {
 				exitErrorMessage = fmt.Sprintf("Exit status %d", exitStatus)
@@ -221,6 +233,7 @@ func (step *runStep) Run(signals <-chan os.Signal, ready chan<- struct{}) error
 						if ev == "out of memory" || ev == "Out of memory" {
 							exitErrorMessage = fmt.Sprintf("%s (out of memory)", exitErrorMessage)
 							emittableExitErrorMessage = fmt.Sprintf("%s (out of memory)", emittableExitErrorMessage)
+							failureCode = executor.FailureCodeOOM
 							break
 						}
 					}
@@ -242,7 +255,7 @@ func (step *runStep) Run(signals <-chan os.Signal, ready chan<- struct{}) error
 
 			if exitStatus != 0 {
 				logger.Error("run-step-failed-with-nonzero-status-code", errors.New(exitErrorMessage), lager.Data{"status-code": exitStatus})
-				return NewEmittableError(nil, emittableExitErrorMessage)
+				return NewEmittableErrorWithCode(failureCode, nil, emittableExitErrorMessage)
 			}
 
 			return nil
@@ -304,15 +317,21 @@ func convertEnvironmentVariables(environmentVariables []*models.EnvironmentVaria
 }
 
 func (step *runStep) networkingEnvVars() []string {
+	return NetworkingEnvVars(step.logger, step.externalIP, step.internalIP, step.portMappings)
+}
+
+// NetworkingEnvVars returns the CF_INSTANCE_* variables describing how the
+// container can be reached.
+func NetworkingEnvVars(logger lager.Logger, externalIP, internalIP string, portMappings []executor.PortMapping) []string {
 	var envVars []string
 
-	envVars = append(envVars, "CF_INSTANCE_IP="+step.externalIP)
-	envVars = append(envVars, "CF_INSTANCE_INTERNAL_IP="+step.internalIP)
+	envVars = append(envVars, "CF_INSTANCE_IP="+externalIP)
+	envVars = append(envVars, "CF_INSTANCE_INTERNAL_IP="+internalIP)
 
-	if len(step.portMappings) > 0 {
-		if step.portMappings[0].HostPort > 0 {
-			envVars = append(envVars, fmt.Sprintf("CF_INSTANCE_PORT=%d", step.portMappings[0].HostPort))
-			envVars = append(envVars, fmt.Sprintf("CF_INSTANCE_ADDR=%s:%d", step.externalIP, step.portMappings[0].HostPort))
+	if len(portMappings) > 0 {
+		if portMappings[0].HostPort > 0 {
+			envVars = append(envVars, fmt.Sprintf("CF_INSTANCE_PORT=%d", portMappings[0].HostPort))
+			envVars = append(envVars, fmt.Sprintf("CF_INSTANCE_ADDR=%s:%d", externalIP, portMappings[0].HostPort))
 		}
 
 		type cfPortMapping struct {
@@ -324,7 +343,7 @@ func (step *runStep) networkingEnvVars() []string {
 
 		cfPortMappings := []cfPortMapping{}
 
-		for _, portMap := range step.portMappings {
+		for _, portMap := range portMappings {
 			cfPortMappings = append(cfPortMappings,
 				cfPortMapping{
 					Internal:         portMap.ContainerPort,
@@ -336,7 +355,7 @@ func (step *runStep) networkingEnvVars() []string {
 
 		mappingsValue, err := json.Marshal(cfPortMappings)
 		if err != nil {
-			step.logger.Error("marshal-networking-env-vars-failed", err)
+			logger.Error("marshal-networking-env-vars-failed", err)
 			mappingsValue = []byte("[]")
 		}
 
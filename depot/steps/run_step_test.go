@@ -437,7 +437,7 @@ var _ = Describe("RunAction", func() {
 
 				It("should return an emittable error with the exit code", func() {
 					errMsg := fmt.Sprintf("%s: Exited with status 19", testLogSource)
-					Eventually(process.Wait()).Should(Receive(MatchError(steps.NewEmittableError(nil, errMsg))))
+					Eventually(process.Wait()).Should(Receive(MatchError(steps.NewEmittableErrorWithCode(executor.FailureCodeProcessExited, nil, errMsg))))
 				})
 			})
 
@@ -448,7 +448,7 @@ var _ = Describe("RunAction", func() {
 
 				It("should return an emittable error with the exit code", func() {
 					errMsg := fmt.Sprintf("%s: Exited with status 19", testLogSource)
-					Eventually(process.Wait()).Should(Receive(MatchError(steps.NewEmittableError(nil, errMsg))))
+					Eventually(process.Wait()).Should(Receive(MatchError(steps.NewEmittableErrorWithCode(executor.FailureCodeProcessExited, nil, errMsg))))
 				})
 			})
 		})
@@ -522,7 +522,7 @@ var _ = Describe("RunAction", func() {
 
 			It("returns an emittable error", func() {
 				errMsg := fmt.Sprintf("%s: Exited with status 19 (out of memory)", testLogSource)
-				Eventually(process.Wait()).Should(Receive(MatchError(steps.NewEmittableError(nil, errMsg))))
+				Eventually(process.Wait()).Should(Receive(MatchError(steps.NewEmittableErrorWithCode(executor.FailureCodeOOM, nil, errMsg))))
 			})
 		})
 
@@ -540,7 +540,7 @@ var _ = Describe("RunAction", func() {
 
 			It("returns an emittable error", func() {
 				errMsg := fmt.Sprintf("%s: Exited with status 19 (out of memory)", testLogSource)
-				Eventually(process.Wait()).Should(Receive(MatchError(steps.NewEmittableError(nil, errMsg))))
+				Eventually(process.Wait()).Should(Receive(MatchError(steps.NewEmittableErrorWithCode(executor.FailureCodeOOM, nil, errMsg))))
 			})
 		})
 
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/executor"
 	"code.cloudfoundry.org/lager"
 	"github.com/tedsuo/ifrit"
 )
@@ -46,7 +47,7 @@ func (step *timeoutStep) Run(signals <-chan os.Signal, ready chan<- struct{}) (e
 			step.logger.Error("timed-out", nil)
 			subStepSignals <- os.Interrupt
 			err := <-resultCh
-			return NewEmittableError(err, emittableMessage(step.timeout, err))
+			return NewEmittableErrorWithCode(executor.FailureCodeStepTimeout, err, emittableMessage(step.timeout, err))
 		}
 	}
 }
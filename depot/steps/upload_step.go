@@ -11,6 +11,7 @@ import (
 	"code.cloudfoundry.org/archiver/compressor"
 	"code.cloudfoundry.org/bbs/models"
 	"code.cloudfoundry.org/bytefmt"
+	"code.cloudfoundry.org/executor"
 	"code.cloudfoundry.org/executor/depot/log_streamer"
 	"code.cloudfoundry.org/executor/depot/uploader"
 	"code.cloudfoundry.org/garden"
@@ -92,7 +93,7 @@ func (step *uploadStep) Run(signals <-chan os.Signal, ready chan<- struct{}) (er
 		step.logger.Error("failed-to-create-tmp-dir", err)
 		errString := step.artifactErrString(ErrCreateTmpDir)
 		step.emitError(errString)
-		return NewEmittableError(err, errString)
+		return NewEmittableErrorWithCode(executor.FailureCodeUploadFailed, err, errString)
 	}
 
 	defer os.RemoveAll(tempDir)
@@ -102,7 +103,7 @@ func (step *uploadStep) Run(signals <-chan os.Signal, ready chan<- struct{}) (er
 		step.logger.Error("failed-to-stream-out", err)
 		errString := step.artifactErrString(ErrEstablishStream)
 		step.emitError(errString)
-		return NewEmittableError(err, errString)
+		return NewEmittableErrorWithCode(executor.FailureCodeUploadFailed, err, errString)
 	}
 	defer outStream.Close()
 
@@ -113,7 +114,7 @@ func (step *uploadStep) Run(signals <-chan os.Signal, ready chan<- struct{}) (er
 		step.logger.Error("failed-to-read-stream", err)
 		errString := step.artifactErrString(ErrReadTar)
 		step.emitError(errString)
-		return NewEmittableError(err, errString)
+		return NewEmittableErrorWithCode(executor.FailureCodeUploadFailed, err, errString)
 	}
 
 	tempFile, err := ioutil.TempFile(step.tempDir, "compressed")
@@ -121,7 +122,7 @@ func (step *uploadStep) Run(signals <-chan os.Signal, ready chan<- struct{}) (er
 		step.logger.Error("failed-to-create-tmp-dir", err)
 		errString := step.artifactErrString(ErrCreateTmpFile)
 		step.emitError(errString)
-		return NewEmittableError(err, errString)
+		return NewEmittableErrorWithCode(executor.FailureCodeUploadFailed, err, errString)
 	}
 	finalFileLocation := tempFile.Name()
 	defer func() {
@@ -134,7 +135,7 @@ func (step *uploadStep) Run(signals <-chan os.Signal, ready chan<- struct{}) (er
 		step.logger.Error("failed-to-copy-stream", err)
 		errString := step.artifactErrString(ErrCopyStreamToTmp)
 		step.emitError(errString)
-		return NewEmittableError(err, errString)
+		return NewEmittableErrorWithCode(executor.FailureCodeUploadFailed, err, errString)
 	}
 
 	finished := make(chan struct{})
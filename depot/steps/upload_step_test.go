@@ -22,6 +22,7 @@ import (
 	"github.com/tedsuo/ifrit"
 
 	Compressor "code.cloudfoundry.org/archiver/compressor"
+	"code.cloudfoundry.org/executor"
 	"code.cloudfoundry.org/executor/depot/log_streamer/fake_log_streamer"
 	"code.cloudfoundry.org/executor/depot/steps"
 	Uploader "code.cloudfoundry.org/executor/depot/uploader"
@@ -441,7 +442,7 @@ var _ = Describe("UploadStep", func() {
 
 			It("returns the appropriate error", func() {
 				err := <-ifrit.Invoke(step).Wait()
-				Expect(err).To(MatchError(steps.NewEmittableError(errStream, steps.ErrEstablishStream)))
+				Expect(err).To(MatchError(steps.NewEmittableErrorWithCode(executor.FailureCodeUploadFailed, errStream, steps.ErrEstablishStream)))
 			})
 
 			It("logs the step", func() {
@@ -463,7 +464,7 @@ var _ = Describe("UploadStep", func() {
 				It("should emits an error with the artifact name", func() {
 					err := <-ifrit.Invoke(step).Wait()
 					Expect(err).To(HaveOccurred())
-					Expect(err).To(MatchError(steps.NewEmittableError(errStream, fmt.Sprintf("%s for %s", steps.ErrEstablishStream, "artifact"))))
+					Expect(err).To(MatchError(steps.NewEmittableErrorWithCode(executor.FailureCodeUploadFailed, errStream, fmt.Sprintf("%s for %s", steps.ErrEstablishStream, "artifact"))))
 				})
 
 				It("should log error with artifact name", func() {
@@ -483,7 +484,7 @@ var _ = Describe("UploadStep", func() {
 
 			It("returns the appropriate error", func() {
 				err := <-ifrit.Invoke(step).Wait()
-				Expect(err).To(MatchError(steps.NewEmittableError(errStream, steps.ErrReadTar)))
+				Expect(err).To(MatchError(steps.NewEmittableErrorWithCode(executor.FailureCodeUploadFailed, errStream, steps.ErrReadTar)))
 			})
 
 			It("logs the step", func() {
@@ -506,7 +507,7 @@ var _ = Describe("UploadStep", func() {
 				It("should emits an error with the artifact name", func() {
 					err := <-ifrit.Invoke(step).Wait()
 					Expect(err).To(HaveOccurred())
-					Expect(err).To(MatchError(steps.NewEmittableError(errStream, fmt.Sprintf("%s for %s", steps.ErrReadTar, "artifact"))))
+					Expect(err).To(MatchError(steps.NewEmittableErrorWithCode(executor.FailureCodeUploadFailed, errStream, fmt.Sprintf("%s for %s", steps.ErrReadTar, "artifact"))))
 				})
 
 				It("should log error with artifact name", func() {
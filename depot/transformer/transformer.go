@@ -45,6 +45,40 @@ type Config struct {
 	BindMounts        []garden.BindMount
 	CreationStartTime time.Time
 	MetronClient      loggingclient.IngressClient
+	MonitorGate       *steps.MonitorGate
+	HealthObserver    steps.HealthObserver
+
+	// Reattach skips the setup and attaches to the processes of the action,
+	// the sidecars and the proxy, which are still running from before a
+	// restart, instead of starting them. The health checks are started anew.
+	Reattach bool
+
+	// ProcessNamed, if not nil, is given the ID of each of those processes as
+	// the steps are made.
+	ProcessNamed func(processID string)
+}
+
+// processNamer gives the processes of the action and the sidecars
+// deterministic IDs, so that they can be told apart from health checks and
+// ad-hoc processes, and attached to again after a restart.
+type processNamer struct {
+	prefix   string
+	count    int
+	reattach bool
+	named    func(processID string)
+}
+
+func (n *processNamer) next() string {
+	processID := fmt.Sprintf("%s-%d", n.prefix, n.count)
+	n.count++
+	n.name(processID)
+	return processID
+}
+
+func (n *processNamer) name(processID string) {
+	if n.named != nil {
+		n.named(processID)
+	}
 }
 
 type transformer struct {
@@ -144,12 +178,39 @@ func (t *transformer) stepFor(
 	ports []executor.PortMapping,
 	suppressExitStatusCode bool,
 	monitorOutputWrapper bool,
+	namer *processNamer,
 	logger lager.Logger,
 ) ifrit.Runner {
 	a := action.GetValue()
 	switch actionModel := a.(type) {
 	case *models.RunAction:
-		return steps.NewRun(
+		if namer == nil {
+			return steps.NewRun(
+				container,
+				*actionModel,
+				logStreamer.WithSource(actionModel.LogSource),
+				logger,
+				externalIP,
+				internalIP,
+				ports,
+				t.clock,
+				t.gracefulShutdownInterval,
+				suppressExitStatusCode,
+			)
+		}
+
+		processID := namer.next()
+		if namer.reattach {
+			return steps.NewAttach(
+				container,
+				processID,
+				logStreamer.WithSource(actionModel.LogSource),
+				logger,
+				t.clock,
+				t.gracefulShutdownInterval,
+			)
+		}
+		return steps.NewRunWithSidecar(
 			container,
 			*actionModel,
 			logStreamer.WithSource(actionModel.LogSource),
@@ -160,6 +221,8 @@ func (t *transformer) stepFor(
 			t.clock,
 			t.gracefulShutdownInterval,
 			suppressExitStatusCode,
+			steps.Sidecar{Name: processID},
+			false,
 		)
 
 	case *models.DownloadAction:
@@ -195,6 +258,7 @@ func (t *transformer) stepFor(
 				ports,
 				suppressExitStatusCode,
 				monitorOutputWrapper,
+				namer,
 				logger,
 			),
 			actionModel.StartMessage,
@@ -215,6 +279,7 @@ func (t *transformer) stepFor(
 				ports,
 				suppressExitStatusCode,
 				monitorOutputWrapper,
+				namer,
 				logger,
 			),
 			time.Duration(actionModel.TimeoutMs)*time.Millisecond,
@@ -233,6 +298,7 @@ func (t *transformer) stepFor(
 				ports,
 				suppressExitStatusCode,
 				monitorOutputWrapper,
+				namer,
 				logger,
 			),
 			logger,
@@ -254,6 +320,7 @@ func (t *transformer) stepFor(
 					ports,
 					suppressExitStatusCode,
 					monitorOutputWrapper,
+					namer,
 					logger,
 				),
 					buffer,
@@ -268,6 +335,7 @@ func (t *transformer) stepFor(
 					ports,
 					suppressExitStatusCode,
 					monitorOutputWrapper,
+					namer,
 					logger,
 				)
 			}
@@ -291,6 +359,7 @@ func (t *transformer) stepFor(
 					ports,
 					suppressExitStatusCode,
 					monitorOutputWrapper,
+					namer,
 					logger,
 				),
 					buffer,
@@ -305,6 +374,7 @@ func (t *transformer) stepFor(
 					ports,
 					suppressExitStatusCode,
 					monitorOutputWrapper,
+					namer,
 					logger,
 				)
 			}
@@ -325,6 +395,7 @@ func (t *transformer) stepFor(
 				ports,
 				suppressExitStatusCode,
 				monitorOutputWrapper,
+				namer,
 				logger,
 			)
 		}
@@ -377,6 +448,7 @@ func (t *transformer) StepsRunner(
 			container.Ports,
 			false,
 			false,
+			nil,
 			logger.Session("setup"),
 		)
 	}
@@ -409,6 +481,12 @@ func (t *transformer) StepsRunner(
 		return nil, err
 	}
 
+	namer := &processNamer{
+		prefix:   gardenContainer.Handle() + "-action",
+		reattach: config.Reattach,
+		named:    config.ProcessNamed,
+	}
+
 	action = t.stepFor(
 		logStreamer,
 		container.Action,
@@ -418,6 +496,7 @@ func (t *transformer) StepsRunner(
 		container.Ports,
 		false,
 		false,
+		namer,
 		logger.Session("action"),
 	)
 
@@ -432,6 +511,7 @@ func (t *transformer) StepsRunner(
 			container.Ports,
 			false,
 			false,
+			namer,
 			logger.Session("sidecar"),
 		))
 	}
@@ -470,6 +550,8 @@ func (t *transformer) StepsRunner(
 			logStreamer,
 			config.BindMounts,
 			proxyReadinessChecks,
+			config.MonitorGate,
+			config.HealthObserver,
 		)
 		substeps = append(substeps, monitor)
 	} else if container.Monitor != nil {
@@ -485,6 +567,7 @@ func (t *transformer) StepsRunner(
 					container.Ports,
 					true,
 					true,
+					nil,
 					logger.Session("monitor-run"),
 				)
 			},
@@ -495,6 +578,8 @@ func (t *transformer) StepsRunner(
 			t.healthyMonitoringInterval,
 			t.unhealthyMonitoringInterval,
 			t.healthCheckWorkPool,
+			config.MonitorGate,
+			config.HealthObserver,
 			proxyReadinessChecks...,
 		)
 		substeps = append(substeps, monitor)
@@ -513,12 +598,13 @@ func (t *transformer) StepsRunner(
 			logger,
 			logStreamer,
 			config.BindMounts,
+			namer,
 		)
 		longLivedAction = steps.NewCodependent([]ifrit.Runner{longLivedAction, containerProxyStep}, false, true)
 	}
 
 	var cumulativeStep ifrit.Runner
-	if setup == nil {
+	if config.Reattach || setup == nil {
 		cumulativeStep = longLivedAction
 	} else {
 		if postSetup == nil {
@@ -612,6 +698,8 @@ func (t *transformer) transformCheckDefinition(
 	logstreamer log_streamer.LogStreamer,
 	bindMounts []garden.BindMount,
 	proxyReadinessChecks []ifrit.Runner,
+	monitorGate *steps.MonitorGate,
+	healthObserver steps.HealthObserver,
 ) ifrit.Runner {
 	var readinessChecks []ifrit.Runner
 	var livenessChecks []ifrit.Runner
@@ -723,6 +811,8 @@ func (t *transformer) transformCheckDefinition(
 		logstreamer,
 		logstreamer.WithSource(sourceName),
 		time.Duration(container.StartTimeoutMs)*time.Millisecond,
+		monitorGate,
+		healthObserver,
 	)
 }
 
@@ -732,7 +822,22 @@ func (t *transformer) transformContainerProxyStep(
 	logger lager.Logger,
 	streamer log_streamer.LogStreamer,
 	bindMounts []garden.BindMount,
+	namer *processNamer,
 ) ifrit.Runner {
+	proxyLogger := logger.Session("proxy")
+	processID := fmt.Sprintf("%s-envoy", container.Handle())
+	namer.name(processID)
+
+	if namer.reattach {
+		return steps.NewBackground(steps.NewAttach(
+			container,
+			processID,
+			streamer.WithSource("PROXY"),
+			proxyLogger,
+			t.clock,
+			t.gracefulShutdownInterval,
+		), proxyLogger)
+	}
 
 	envoyArgs := []string{
 		"-c", "/etc/cf-assets/envoy_config/envoy.yaml",
@@ -745,11 +850,9 @@ func (t *transformer) transformContainerProxyStep(
 	sidecar := steps.Sidecar{
 		Image:      garden.ImageRef{URI: t.sidecarRootFS},
 		BindMounts: bindMounts,
-		Name:       fmt.Sprintf("%s-envoy", container.Handle()),
+		Name:       processID,
 	}
 
-	proxyLogger := logger.Session("proxy")
-
 	return steps.NewBackground(steps.NewRunWithSidecar(
 		container,
 		runAction,
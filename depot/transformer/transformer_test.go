@@ -54,7 +54,7 @@ var _ = Describe("Transformer", func() {
 			fakeMetronClient = &mfakes.FakeIngressClient{}
 
 			logger = lagertest.NewTestLogger("test-container-store")
-			logStreamer = log_streamer.New("test", "test", 1, map[string]string{}, fakeMetronClient, 100, 5*time.Minute)
+			logStreamer = log_streamer.New("test", "test", 1, map[string]string{}, fakeMetronClient, fakeMetronClient, 100, 0, 5*time.Minute, nil, nil, nil, nil, nil)
 
 			healthyMonitoringInterval = 1 * time.Second
 			unhealthyMonitoringInterval = 1 * time.Millisecond
@@ -210,6 +210,65 @@ var _ = Describe("Transformer", func() {
 			})
 		})
 
+		Context("when the processes are named", func() {
+			var named []string
+
+			BeforeEach(func() {
+				named = []string{}
+				gardenContainer.HandleReturns("some-handle")
+				cfg.ProcessNamed = func(processID string) {
+					named = append(named, processID)
+				}
+				container.Sidecars = []executor.Sidecar{
+					{
+						Action: &models.Action{
+							RunAction: &models.RunAction{
+								Path: "/sidecar-action",
+							},
+						},
+					},
+				}
+			})
+
+			It("gives the action and the sidecars deterministic process IDs", func() {
+				_, err := optimusPrime.StepsRunner(logger, container, gardenContainer, logStreamer, cfg)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(named).To(ConsistOf("some-handle-action-0", "some-handle-action-1"))
+			})
+
+			Context("when reattaching", func() {
+				BeforeEach(func() {
+					cfg.Reattach = true
+					gardenContainer.AttachReturns(&gardenfakes.FakeProcess{}, nil)
+				})
+
+				It("attaches to the action and the sidecars, and runs only the monitor", func() {
+					runner, err := optimusPrime.StepsRunner(logger, container, gardenContainer, logStreamer, cfg)
+					Expect(err).NotTo(HaveOccurred())
+
+					process := ifrit.Background(runner)
+
+					Eventually(gardenContainer.AttachCallCount).Should(Equal(2))
+					attached := []string{}
+					for i := 0; i < 2; i++ {
+						processID, _ := gardenContainer.AttachArgsForCall(i)
+						attached = append(attached, processID)
+					}
+					Expect(attached).To(ConsistOf(named))
+
+					clock.Increment(1 * time.Second)
+					Eventually(gardenContainer.RunCallCount).Should(Equal(1))
+					processSpec, _ := gardenContainer.RunArgsForCall(0)
+					Expect(processSpec.Path).To(Equal("/monitor/path"))
+
+					process.Signal(os.Interrupt)
+					clock.Increment(1 * time.Second)
+					Eventually(process.Wait()).Should(Receive())
+				})
+			})
+		})
+
 		It("logs container setup time", func() {
 			gardenContainer.RunStub = func(processSpec garden.ProcessSpec, processIO garden.ProcessIO) (garden.Process, error) {
 				if processSpec.Path == "/setup/path" {
@@ -39,4 +39,18 @@ var (
 	ErrFailureToCheckSpace            = registerError("ErrFailureToCheckSpace", "failed to check available space")
 	ErrInvalidSecurityGroup           = registerError("ErrInvalidSecurityGroup", "security group has invalid values")
 	ErrNoProcessToStop                = registerError("ErrNoProcessToStop", "failed to find a process to stop")
+	ErrTagQuotaExceeded               = registerError("TagQuotaExceeded", "tag quota exceeded")
+	ErrTagConcurrencyLimitExceeded    = registerError("TagConcurrencyLimitExceeded", "too many containers with the same tag")
+	ErrContainerNotExportable         = registerError("ContainerNotExportable", "container must be created before its data can be exported")
+	ErrInvalidContainerExport         = registerError("InvalidContainerExport", "container data export is invalid")
+	ErrPauseNotSupported              = registerError("PauseNotSupported", "pausing containers is not supported on this cell")
+	ErrLimitsUpdateNotSupported       = registerError("LimitsUpdateNotSupported", "updating container limits is not supported on this cell")
+	ErrDiskLimitNotUpdatable          = registerError("DiskLimitNotUpdatable", "the disk limit of a created container cannot be changed")
+	ErrEventsEvicted                  = registerError("EventsEvicted", "the requested events are no longer available")
+	ErrContainerNotRunning            = registerError("ContainerNotRunning", "container must be running")
+	ErrInvalidProcessSignal           = registerError("InvalidProcessSignal", "process signal must be terminate or kill")
+	ErrLogTapNotEnabled               = registerError("LogTapNotEnabled", "streaming container logs is not enabled on this cell")
+	ErrRecentLogsNotEnabled           = registerError("RecentLogsNotEnabled", "keeping recent container logs is not enabled on this cell")
+	ErrLogConfigInvalid               = registerError("LogConfigInvalid", "container log config invalid")
+	ErrCPUWeightExceedsReservation    = registerError("CPUWeightExceedsReservation", "container cpu weight exceeds the one it was reserved with")
 )
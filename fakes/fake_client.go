@@ -39,6 +39,19 @@ type FakeClient struct {
 	deleteContainerReturnsOnCall map[int]struct {
 		result1 error
 	}
+	ExportContainerDataStub        func(lager.Logger, string, io.Writer) error
+	exportContainerDataMutex       sync.RWMutex
+	exportContainerDataArgsForCall []struct {
+		arg1 lager.Logger
+		arg2 string
+		arg3 io.Writer
+	}
+	exportContainerDataReturns struct {
+		result1 error
+	}
+	exportContainerDataReturnsOnCall map[int]struct {
+		result1 error
+	}
 	GetBulkMetricsStub        func(lager.Logger) (map[string]executor.Metrics, error)
 	getBulkMetricsMutex       sync.RWMutex
 	getBulkMetricsArgsForCall []struct {
@@ -81,6 +94,20 @@ type FakeClient struct {
 		result1 io.ReadCloser
 		result2 error
 	}
+	GetRecentLogsStub        func(lager.Logger, string) ([]executor.LogLine, error)
+	getRecentLogsMutex       sync.RWMutex
+	getRecentLogsArgsForCall []struct {
+		arg1 lager.Logger
+		arg2 string
+	}
+	getRecentLogsReturns struct {
+		result1 []executor.LogLine
+		result2 error
+	}
+	getRecentLogsReturnsOnCall map[int]struct {
+		result1 []executor.LogLine
+		result2 error
+	}
 	HealthyStub        func(lager.Logger) bool
 	healthyMutex       sync.RWMutex
 	healthyArgsForCall []struct {
@@ -92,6 +119,19 @@ type FakeClient struct {
 	healthyReturnsOnCall map[int]struct {
 		result1 bool
 	}
+	ImportContainerDataStub        func(lager.Logger, string, io.Reader) error
+	importContainerDataMutex       sync.RWMutex
+	importContainerDataArgsForCall []struct {
+		arg1 lager.Logger
+		arg2 string
+		arg3 io.Reader
+	}
+	importContainerDataReturns struct {
+		result1 error
+	}
+	importContainerDataReturnsOnCall map[int]struct {
+		result1 error
+	}
 	ListContainersStub        func(lager.Logger) ([]executor.Container, error)
 	listContainersMutex       sync.RWMutex
 	listContainersArgsForCall []struct {
@@ -105,6 +145,18 @@ type FakeClient struct {
 		result1 []executor.Container
 		result2 error
 	}
+	PauseContainerStub        func(lager.Logger, string) error
+	pauseContainerMutex       sync.RWMutex
+	pauseContainerArgsForCall []struct {
+		arg1 lager.Logger
+		arg2 string
+	}
+	pauseContainerReturns struct {
+		result1 error
+	}
+	pauseContainerReturnsOnCall map[int]struct {
+		result1 error
+	}
 	PingStub        func(lager.Logger) error
 	pingMutex       sync.RWMutex
 	pingArgsForCall []struct {
@@ -116,6 +168,21 @@ type FakeClient struct {
 	pingReturnsOnCall map[int]struct {
 		result1 error
 	}
+	PutFilesStub        func(lager.Logger, string, string, io.Reader, string) error
+	putFilesMutex       sync.RWMutex
+	putFilesArgsForCall []struct {
+		arg1 lager.Logger
+		arg2 string
+		arg3 string
+		arg4 io.Reader
+		arg5 string
+	}
+	putFilesReturns struct {
+		result1 error
+	}
+	putFilesReturnsOnCall map[int]struct {
+		result1 error
+	}
 	RemainingResourcesStub        func(lager.Logger) (executor.ExecutorResources, error)
 	remainingResourcesMutex       sync.RWMutex
 	remainingResourcesArgsForCall []struct {
@@ -129,6 +196,18 @@ type FakeClient struct {
 		result1 executor.ExecutorResources
 		result2 error
 	}
+	ResumeContainerStub        func(lager.Logger, string) error
+	resumeContainerMutex       sync.RWMutex
+	resumeContainerArgsForCall []struct {
+		arg1 lager.Logger
+		arg2 string
+	}
+	resumeContainerReturns struct {
+		result1 error
+	}
+	resumeContainerReturnsOnCall map[int]struct {
+		result1 error
+	}
 	RunContainerStub        func(lager.Logger, *executor.RunRequest) error
 	runContainerMutex       sync.RWMutex
 	runContainerArgsForCall []struct {
@@ -141,6 +220,22 @@ type FakeClient struct {
 	runContainerReturnsOnCall map[int]struct {
 		result1 error
 	}
+	RunProcessStub        func(lager.Logger, string, executor.ProcessSpec, executor.ProcessIO) (executor.Process, error)
+	runProcessMutex       sync.RWMutex
+	runProcessArgsForCall []struct {
+		arg1 lager.Logger
+		arg2 string
+		arg3 executor.ProcessSpec
+		arg4 executor.ProcessIO
+	}
+	runProcessReturns struct {
+		result1 executor.Process
+		result2 error
+	}
+	runProcessReturnsOnCall map[int]struct {
+		result1 executor.Process
+		result2 error
+	}
 	SetHealthyStub        func(lager.Logger, bool)
 	setHealthyMutex       sync.RWMutex
 	setHealthyArgsForCall []struct {
@@ -159,6 +254,21 @@ type FakeClient struct {
 	stopContainerReturnsOnCall map[int]struct {
 		result1 error
 	}
+	SubscribeFromStub        func(lager.Logger, uint64, executor.EventFilter) (executor.EventSource, error)
+	subscribeFromMutex       sync.RWMutex
+	subscribeFromArgsForCall []struct {
+		arg1 lager.Logger
+		arg2 uint64
+		arg3 executor.EventFilter
+	}
+	subscribeFromReturns struct {
+		result1 executor.EventSource
+		result2 error
+	}
+	subscribeFromReturnsOnCall map[int]struct {
+		result1 executor.EventSource
+		result2 error
+	}
 	SubscribeToEventsStub        func(lager.Logger) (executor.EventSource, error)
 	subscribeToEventsMutex       sync.RWMutex
 	subscribeToEventsArgsForCall []struct {
@@ -172,6 +282,34 @@ type FakeClient struct {
 		result1 executor.EventSource
 		result2 error
 	}
+	SubscribeToEventsWithFilterStub        func(lager.Logger, executor.EventFilter) (executor.EventSource, error)
+	subscribeToEventsWithFilterMutex       sync.RWMutex
+	subscribeToEventsWithFilterArgsForCall []struct {
+		arg1 lager.Logger
+		arg2 executor.EventFilter
+	}
+	subscribeToEventsWithFilterReturns struct {
+		result1 executor.EventSource
+		result2 error
+	}
+	subscribeToEventsWithFilterReturnsOnCall map[int]struct {
+		result1 executor.EventSource
+		result2 error
+	}
+	SubscribeToLogsStub        func(lager.Logger, string) (executor.LogSource, error)
+	subscribeToLogsMutex       sync.RWMutex
+	subscribeToLogsArgsForCall []struct {
+		arg1 lager.Logger
+		arg2 string
+	}
+	subscribeToLogsReturns struct {
+		result1 executor.LogSource
+		result2 error
+	}
+	subscribeToLogsReturnsOnCall map[int]struct {
+		result1 executor.LogSource
+		result2 error
+	}
 	TotalResourcesStub        func(lager.Logger) (executor.ExecutorResources, error)
 	totalResourcesMutex       sync.RWMutex
 	totalResourcesArgsForCall []struct {
@@ -185,6 +323,19 @@ type FakeClient struct {
 		result1 executor.ExecutorResources
 		result2 error
 	}
+	UpdateContainerLimitsStub        func(lager.Logger, string, executor.Resource) error
+	updateContainerLimitsMutex       sync.RWMutex
+	updateContainerLimitsArgsForCall []struct {
+		arg1 lager.Logger
+		arg2 string
+		arg3 executor.Resource
+	}
+	updateContainerLimitsReturns struct {
+		result1 error
+	}
+	updateContainerLimitsReturnsOnCall map[int]struct {
+		result1 error
+	}
 	VolumeDriversStub        func(lager.Logger) ([]string, error)
 	volumeDriversMutex       sync.RWMutex
 	volumeDriversArgsForCall []struct {
@@ -363,6 +514,69 @@ func (fake *FakeClient) DeleteContainerReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *FakeClient) ExportContainerData(arg1 lager.Logger, arg2 string, arg3 io.Writer) error {
+	fake.exportContainerDataMutex.Lock()
+	ret, specificReturn := fake.exportContainerDataReturnsOnCall[len(fake.exportContainerDataArgsForCall)]
+	fake.exportContainerDataArgsForCall = append(fake.exportContainerDataArgsForCall, struct {
+		arg1 lager.Logger
+		arg2 string
+		arg3 io.Writer
+	}{arg1, arg2, arg3})
+	stub := fake.ExportContainerDataStub
+	fakeReturns := fake.exportContainerDataReturns
+	fake.recordInvocation("ExportContainerData", []interface{}{arg1, arg2, arg3})
+	fake.exportContainerDataMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeClient) ExportContainerDataCallCount() int {
+	fake.exportContainerDataMutex.RLock()
+	defer fake.exportContainerDataMutex.RUnlock()
+	return len(fake.exportContainerDataArgsForCall)
+}
+
+func (fake *FakeClient) ExportContainerDataCalls(stub func(lager.Logger, string, io.Writer) error) {
+	fake.exportContainerDataMutex.Lock()
+	defer fake.exportContainerDataMutex.Unlock()
+	fake.ExportContainerDataStub = stub
+}
+
+func (fake *FakeClient) ExportContainerDataArgsForCall(i int) (lager.Logger, string, io.Writer) {
+	fake.exportContainerDataMutex.RLock()
+	defer fake.exportContainerDataMutex.RUnlock()
+	argsForCall := fake.exportContainerDataArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeClient) ExportContainerDataReturns(result1 error) {
+	fake.exportContainerDataMutex.Lock()
+	defer fake.exportContainerDataMutex.Unlock()
+	fake.ExportContainerDataStub = nil
+	fake.exportContainerDataReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeClient) ExportContainerDataReturnsOnCall(i int, result1 error) {
+	fake.exportContainerDataMutex.Lock()
+	defer fake.exportContainerDataMutex.Unlock()
+	fake.ExportContainerDataStub = nil
+	if fake.exportContainerDataReturnsOnCall == nil {
+		fake.exportContainerDataReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.exportContainerDataReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeClient) GetBulkMetrics(arg1 lager.Logger) (map[string]executor.Metrics, error) {
 	fake.getBulkMetricsMutex.Lock()
 	ret, specificReturn := fake.getBulkMetricsReturnsOnCall[len(fake.getBulkMetricsArgsForCall)]
@@ -558,6 +772,71 @@ func (fake *FakeClient) GetFilesReturnsOnCall(i int, result1 io.ReadCloser, resu
 	}{result1, result2}
 }
 
+func (fake *FakeClient) GetRecentLogs(arg1 lager.Logger, arg2 string) ([]executor.LogLine, error) {
+	fake.getRecentLogsMutex.Lock()
+	ret, specificReturn := fake.getRecentLogsReturnsOnCall[len(fake.getRecentLogsArgsForCall)]
+	fake.getRecentLogsArgsForCall = append(fake.getRecentLogsArgsForCall, struct {
+		arg1 lager.Logger
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.GetRecentLogsStub
+	fakeReturns := fake.getRecentLogsReturns
+	fake.recordInvocation("GetRecentLogs", []interface{}{arg1, arg2})
+	fake.getRecentLogsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeClient) GetRecentLogsCallCount() int {
+	fake.getRecentLogsMutex.RLock()
+	defer fake.getRecentLogsMutex.RUnlock()
+	return len(fake.getRecentLogsArgsForCall)
+}
+
+func (fake *FakeClient) GetRecentLogsCalls(stub func(lager.Logger, string) ([]executor.LogLine, error)) {
+	fake.getRecentLogsMutex.Lock()
+	defer fake.getRecentLogsMutex.Unlock()
+	fake.GetRecentLogsStub = stub
+}
+
+func (fake *FakeClient) GetRecentLogsArgsForCall(i int) (lager.Logger, string) {
+	fake.getRecentLogsMutex.RLock()
+	defer fake.getRecentLogsMutex.RUnlock()
+	argsForCall := fake.getRecentLogsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeClient) GetRecentLogsReturns(result1 []executor.LogLine, result2 error) {
+	fake.getRecentLogsMutex.Lock()
+	defer fake.getRecentLogsMutex.Unlock()
+	fake.GetRecentLogsStub = nil
+	fake.getRecentLogsReturns = struct {
+		result1 []executor.LogLine
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeClient) GetRecentLogsReturnsOnCall(i int, result1 []executor.LogLine, result2 error) {
+	fake.getRecentLogsMutex.Lock()
+	defer fake.getRecentLogsMutex.Unlock()
+	fake.GetRecentLogsStub = nil
+	if fake.getRecentLogsReturnsOnCall == nil {
+		fake.getRecentLogsReturnsOnCall = make(map[int]struct {
+			result1 []executor.LogLine
+			result2 error
+		})
+	}
+	fake.getRecentLogsReturnsOnCall[i] = struct {
+		result1 []executor.LogLine
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeClient) Healthy(arg1 lager.Logger) bool {
 	fake.healthyMutex.Lock()
 	ret, specificReturn := fake.healthyReturnsOnCall[len(fake.healthyArgsForCall)]
@@ -619,6 +898,69 @@ func (fake *FakeClient) HealthyReturnsOnCall(i int, result1 bool) {
 	}{result1}
 }
 
+func (fake *FakeClient) ImportContainerData(arg1 lager.Logger, arg2 string, arg3 io.Reader) error {
+	fake.importContainerDataMutex.Lock()
+	ret, specificReturn := fake.importContainerDataReturnsOnCall[len(fake.importContainerDataArgsForCall)]
+	fake.importContainerDataArgsForCall = append(fake.importContainerDataArgsForCall, struct {
+		arg1 lager.Logger
+		arg2 string
+		arg3 io.Reader
+	}{arg1, arg2, arg3})
+	stub := fake.ImportContainerDataStub
+	fakeReturns := fake.importContainerDataReturns
+	fake.recordInvocation("ImportContainerData", []interface{}{arg1, arg2, arg3})
+	fake.importContainerDataMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeClient) ImportContainerDataCallCount() int {
+	fake.importContainerDataMutex.RLock()
+	defer fake.importContainerDataMutex.RUnlock()
+	return len(fake.importContainerDataArgsForCall)
+}
+
+func (fake *FakeClient) ImportContainerDataCalls(stub func(lager.Logger, string, io.Reader) error) {
+	fake.importContainerDataMutex.Lock()
+	defer fake.importContainerDataMutex.Unlock()
+	fake.ImportContainerDataStub = stub
+}
+
+func (fake *FakeClient) ImportContainerDataArgsForCall(i int) (lager.Logger, string, io.Reader) {
+	fake.importContainerDataMutex.RLock()
+	defer fake.importContainerDataMutex.RUnlock()
+	argsForCall := fake.importContainerDataArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeClient) ImportContainerDataReturns(result1 error) {
+	fake.importContainerDataMutex.Lock()
+	defer fake.importContainerDataMutex.Unlock()
+	fake.ImportContainerDataStub = nil
+	fake.importContainerDataReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeClient) ImportContainerDataReturnsOnCall(i int, result1 error) {
+	fake.importContainerDataMutex.Lock()
+	defer fake.importContainerDataMutex.Unlock()
+	fake.ImportContainerDataStub = nil
+	if fake.importContainerDataReturnsOnCall == nil {
+		fake.importContainerDataReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.importContainerDataReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeClient) ListContainers(arg1 lager.Logger) ([]executor.Container, error) {
 	fake.listContainersMutex.Lock()
 	ret, specificReturn := fake.listContainersReturnsOnCall[len(fake.listContainersArgsForCall)]
@@ -683,6 +1025,68 @@ func (fake *FakeClient) ListContainersReturnsOnCall(i int, result1 []executor.Co
 	}{result1, result2}
 }
 
+func (fake *FakeClient) PauseContainer(arg1 lager.Logger, arg2 string) error {
+	fake.pauseContainerMutex.Lock()
+	ret, specificReturn := fake.pauseContainerReturnsOnCall[len(fake.pauseContainerArgsForCall)]
+	fake.pauseContainerArgsForCall = append(fake.pauseContainerArgsForCall, struct {
+		arg1 lager.Logger
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.PauseContainerStub
+	fakeReturns := fake.pauseContainerReturns
+	fake.recordInvocation("PauseContainer", []interface{}{arg1, arg2})
+	fake.pauseContainerMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeClient) PauseContainerCallCount() int {
+	fake.pauseContainerMutex.RLock()
+	defer fake.pauseContainerMutex.RUnlock()
+	return len(fake.pauseContainerArgsForCall)
+}
+
+func (fake *FakeClient) PauseContainerCalls(stub func(lager.Logger, string) error) {
+	fake.pauseContainerMutex.Lock()
+	defer fake.pauseContainerMutex.Unlock()
+	fake.PauseContainerStub = stub
+}
+
+func (fake *FakeClient) PauseContainerArgsForCall(i int) (lager.Logger, string) {
+	fake.pauseContainerMutex.RLock()
+	defer fake.pauseContainerMutex.RUnlock()
+	argsForCall := fake.pauseContainerArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeClient) PauseContainerReturns(result1 error) {
+	fake.pauseContainerMutex.Lock()
+	defer fake.pauseContainerMutex.Unlock()
+	fake.PauseContainerStub = nil
+	fake.pauseContainerReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeClient) PauseContainerReturnsOnCall(i int, result1 error) {
+	fake.pauseContainerMutex.Lock()
+	defer fake.pauseContainerMutex.Unlock()
+	fake.PauseContainerStub = nil
+	if fake.pauseContainerReturnsOnCall == nil {
+		fake.pauseContainerReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.pauseContainerReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeClient) Ping(arg1 lager.Logger) error {
 	fake.pingMutex.Lock()
 	ret, specificReturn := fake.pingReturnsOnCall[len(fake.pingArgsForCall)]
@@ -744,6 +1148,71 @@ func (fake *FakeClient) PingReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *FakeClient) PutFiles(arg1 lager.Logger, arg2 string, arg3 string, arg4 io.Reader, arg5 string) error {
+	fake.putFilesMutex.Lock()
+	ret, specificReturn := fake.putFilesReturnsOnCall[len(fake.putFilesArgsForCall)]
+	fake.putFilesArgsForCall = append(fake.putFilesArgsForCall, struct {
+		arg1 lager.Logger
+		arg2 string
+		arg3 string
+		arg4 io.Reader
+		arg5 string
+	}{arg1, arg2, arg3, arg4, arg5})
+	stub := fake.PutFilesStub
+	fakeReturns := fake.putFilesReturns
+	fake.recordInvocation("PutFiles", []interface{}{arg1, arg2, arg3, arg4, arg5})
+	fake.putFilesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4, arg5)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeClient) PutFilesCallCount() int {
+	fake.putFilesMutex.RLock()
+	defer fake.putFilesMutex.RUnlock()
+	return len(fake.putFilesArgsForCall)
+}
+
+func (fake *FakeClient) PutFilesCalls(stub func(lager.Logger, string, string, io.Reader, string) error) {
+	fake.putFilesMutex.Lock()
+	defer fake.putFilesMutex.Unlock()
+	fake.PutFilesStub = stub
+}
+
+func (fake *FakeClient) PutFilesArgsForCall(i int) (lager.Logger, string, string, io.Reader, string) {
+	fake.putFilesMutex.RLock()
+	defer fake.putFilesMutex.RUnlock()
+	argsForCall := fake.putFilesArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5
+}
+
+func (fake *FakeClient) PutFilesReturns(result1 error) {
+	fake.putFilesMutex.Lock()
+	defer fake.putFilesMutex.Unlock()
+	fake.PutFilesStub = nil
+	fake.putFilesReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeClient) PutFilesReturnsOnCall(i int, result1 error) {
+	fake.putFilesMutex.Lock()
+	defer fake.putFilesMutex.Unlock()
+	fake.PutFilesStub = nil
+	if fake.putFilesReturnsOnCall == nil {
+		fake.putFilesReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.putFilesReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeClient) RemainingResources(arg1 lager.Logger) (executor.ExecutorResources, error) {
 	fake.remainingResourcesMutex.Lock()
 	ret, specificReturn := fake.remainingResourcesReturnsOnCall[len(fake.remainingResourcesArgsForCall)]
@@ -808,6 +1277,68 @@ func (fake *FakeClient) RemainingResourcesReturnsOnCall(i int, result1 executor.
 	}{result1, result2}
 }
 
+func (fake *FakeClient) ResumeContainer(arg1 lager.Logger, arg2 string) error {
+	fake.resumeContainerMutex.Lock()
+	ret, specificReturn := fake.resumeContainerReturnsOnCall[len(fake.resumeContainerArgsForCall)]
+	fake.resumeContainerArgsForCall = append(fake.resumeContainerArgsForCall, struct {
+		arg1 lager.Logger
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.ResumeContainerStub
+	fakeReturns := fake.resumeContainerReturns
+	fake.recordInvocation("ResumeContainer", []interface{}{arg1, arg2})
+	fake.resumeContainerMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeClient) ResumeContainerCallCount() int {
+	fake.resumeContainerMutex.RLock()
+	defer fake.resumeContainerMutex.RUnlock()
+	return len(fake.resumeContainerArgsForCall)
+}
+
+func (fake *FakeClient) ResumeContainerCalls(stub func(lager.Logger, string) error) {
+	fake.resumeContainerMutex.Lock()
+	defer fake.resumeContainerMutex.Unlock()
+	fake.ResumeContainerStub = stub
+}
+
+func (fake *FakeClient) ResumeContainerArgsForCall(i int) (lager.Logger, string) {
+	fake.resumeContainerMutex.RLock()
+	defer fake.resumeContainerMutex.RUnlock()
+	argsForCall := fake.resumeContainerArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeClient) ResumeContainerReturns(result1 error) {
+	fake.resumeContainerMutex.Lock()
+	defer fake.resumeContainerMutex.Unlock()
+	fake.ResumeContainerStub = nil
+	fake.resumeContainerReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeClient) ResumeContainerReturnsOnCall(i int, result1 error) {
+	fake.resumeContainerMutex.Lock()
+	defer fake.resumeContainerMutex.Unlock()
+	fake.ResumeContainerStub = nil
+	if fake.resumeContainerReturnsOnCall == nil {
+		fake.resumeContainerReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.resumeContainerReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeClient) RunContainer(arg1 lager.Logger, arg2 *executor.RunRequest) error {
 	fake.runContainerMutex.Lock()
 	ret, specificReturn := fake.runContainerReturnsOnCall[len(fake.runContainerArgsForCall)]
@@ -870,6 +1401,73 @@ func (fake *FakeClient) RunContainerReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *FakeClient) RunProcess(arg1 lager.Logger, arg2 string, arg3 executor.ProcessSpec, arg4 executor.ProcessIO) (executor.Process, error) {
+	fake.runProcessMutex.Lock()
+	ret, specificReturn := fake.runProcessReturnsOnCall[len(fake.runProcessArgsForCall)]
+	fake.runProcessArgsForCall = append(fake.runProcessArgsForCall, struct {
+		arg1 lager.Logger
+		arg2 string
+		arg3 executor.ProcessSpec
+		arg4 executor.ProcessIO
+	}{arg1, arg2, arg3, arg4})
+	stub := fake.RunProcessStub
+	fakeReturns := fake.runProcessReturns
+	fake.recordInvocation("RunProcess", []interface{}{arg1, arg2, arg3, arg4})
+	fake.runProcessMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeClient) RunProcessCallCount() int {
+	fake.runProcessMutex.RLock()
+	defer fake.runProcessMutex.RUnlock()
+	return len(fake.runProcessArgsForCall)
+}
+
+func (fake *FakeClient) RunProcessCalls(stub func(lager.Logger, string, executor.ProcessSpec, executor.ProcessIO) (executor.Process, error)) {
+	fake.runProcessMutex.Lock()
+	defer fake.runProcessMutex.Unlock()
+	fake.RunProcessStub = stub
+}
+
+func (fake *FakeClient) RunProcessArgsForCall(i int) (lager.Logger, string, executor.ProcessSpec, executor.ProcessIO) {
+	fake.runProcessMutex.RLock()
+	defer fake.runProcessMutex.RUnlock()
+	argsForCall := fake.runProcessArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *FakeClient) RunProcessReturns(result1 executor.Process, result2 error) {
+	fake.runProcessMutex.Lock()
+	defer fake.runProcessMutex.Unlock()
+	fake.RunProcessStub = nil
+	fake.runProcessReturns = struct {
+		result1 executor.Process
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeClient) RunProcessReturnsOnCall(i int, result1 executor.Process, result2 error) {
+	fake.runProcessMutex.Lock()
+	defer fake.runProcessMutex.Unlock()
+	fake.RunProcessStub = nil
+	if fake.runProcessReturnsOnCall == nil {
+		fake.runProcessReturnsOnCall = make(map[int]struct {
+			result1 executor.Process
+			result2 error
+		})
+	}
+	fake.runProcessReturnsOnCall[i] = struct {
+		result1 executor.Process
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeClient) SetHealthy(arg1 lager.Logger, arg2 bool) {
 	fake.setHealthyMutex.Lock()
 	fake.setHealthyArgsForCall = append(fake.setHealthyArgsForCall, struct {
@@ -965,6 +1563,72 @@ func (fake *FakeClient) StopContainerReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *FakeClient) SubscribeFrom(arg1 lager.Logger, arg2 uint64, arg3 executor.EventFilter) (executor.EventSource, error) {
+	fake.subscribeFromMutex.Lock()
+	ret, specificReturn := fake.subscribeFromReturnsOnCall[len(fake.subscribeFromArgsForCall)]
+	fake.subscribeFromArgsForCall = append(fake.subscribeFromArgsForCall, struct {
+		arg1 lager.Logger
+		arg2 uint64
+		arg3 executor.EventFilter
+	}{arg1, arg2, arg3})
+	stub := fake.SubscribeFromStub
+	fakeReturns := fake.subscribeFromReturns
+	fake.recordInvocation("SubscribeFrom", []interface{}{arg1, arg2, arg3})
+	fake.subscribeFromMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeClient) SubscribeFromCallCount() int {
+	fake.subscribeFromMutex.RLock()
+	defer fake.subscribeFromMutex.RUnlock()
+	return len(fake.subscribeFromArgsForCall)
+}
+
+func (fake *FakeClient) SubscribeFromCalls(stub func(lager.Logger, uint64, executor.EventFilter) (executor.EventSource, error)) {
+	fake.subscribeFromMutex.Lock()
+	defer fake.subscribeFromMutex.Unlock()
+	fake.SubscribeFromStub = stub
+}
+
+func (fake *FakeClient) SubscribeFromArgsForCall(i int) (lager.Logger, uint64, executor.EventFilter) {
+	fake.subscribeFromMutex.RLock()
+	defer fake.subscribeFromMutex.RUnlock()
+	argsForCall := fake.subscribeFromArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeClient) SubscribeFromReturns(result1 executor.EventSource, result2 error) {
+	fake.subscribeFromMutex.Lock()
+	defer fake.subscribeFromMutex.Unlock()
+	fake.SubscribeFromStub = nil
+	fake.subscribeFromReturns = struct {
+		result1 executor.EventSource
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeClient) SubscribeFromReturnsOnCall(i int, result1 executor.EventSource, result2 error) {
+	fake.subscribeFromMutex.Lock()
+	defer fake.subscribeFromMutex.Unlock()
+	fake.SubscribeFromStub = nil
+	if fake.subscribeFromReturnsOnCall == nil {
+		fake.subscribeFromReturnsOnCall = make(map[int]struct {
+			result1 executor.EventSource
+			result2 error
+		})
+	}
+	fake.subscribeFromReturnsOnCall[i] = struct {
+		result1 executor.EventSource
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeClient) SubscribeToEvents(arg1 lager.Logger) (executor.EventSource, error) {
 	fake.subscribeToEventsMutex.Lock()
 	ret, specificReturn := fake.subscribeToEventsReturnsOnCall[len(fake.subscribeToEventsArgsForCall)]
@@ -1029,6 +1693,136 @@ func (fake *FakeClient) SubscribeToEventsReturnsOnCall(i int, result1 executor.E
 	}{result1, result2}
 }
 
+func (fake *FakeClient) SubscribeToEventsWithFilter(arg1 lager.Logger, arg2 executor.EventFilter) (executor.EventSource, error) {
+	fake.subscribeToEventsWithFilterMutex.Lock()
+	ret, specificReturn := fake.subscribeToEventsWithFilterReturnsOnCall[len(fake.subscribeToEventsWithFilterArgsForCall)]
+	fake.subscribeToEventsWithFilterArgsForCall = append(fake.subscribeToEventsWithFilterArgsForCall, struct {
+		arg1 lager.Logger
+		arg2 executor.EventFilter
+	}{arg1, arg2})
+	stub := fake.SubscribeToEventsWithFilterStub
+	fakeReturns := fake.subscribeToEventsWithFilterReturns
+	fake.recordInvocation("SubscribeToEventsWithFilter", []interface{}{arg1, arg2})
+	fake.subscribeToEventsWithFilterMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeClient) SubscribeToEventsWithFilterCallCount() int {
+	fake.subscribeToEventsWithFilterMutex.RLock()
+	defer fake.subscribeToEventsWithFilterMutex.RUnlock()
+	return len(fake.subscribeToEventsWithFilterArgsForCall)
+}
+
+func (fake *FakeClient) SubscribeToEventsWithFilterCalls(stub func(lager.Logger, executor.EventFilter) (executor.EventSource, error)) {
+	fake.subscribeToEventsWithFilterMutex.Lock()
+	defer fake.subscribeToEventsWithFilterMutex.Unlock()
+	fake.SubscribeToEventsWithFilterStub = stub
+}
+
+func (fake *FakeClient) SubscribeToEventsWithFilterArgsForCall(i int) (lager.Logger, executor.EventFilter) {
+	fake.subscribeToEventsWithFilterMutex.RLock()
+	defer fake.subscribeToEventsWithFilterMutex.RUnlock()
+	argsForCall := fake.subscribeToEventsWithFilterArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeClient) SubscribeToEventsWithFilterReturns(result1 executor.EventSource, result2 error) {
+	fake.subscribeToEventsWithFilterMutex.Lock()
+	defer fake.subscribeToEventsWithFilterMutex.Unlock()
+	fake.SubscribeToEventsWithFilterStub = nil
+	fake.subscribeToEventsWithFilterReturns = struct {
+		result1 executor.EventSource
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeClient) SubscribeToEventsWithFilterReturnsOnCall(i int, result1 executor.EventSource, result2 error) {
+	fake.subscribeToEventsWithFilterMutex.Lock()
+	defer fake.subscribeToEventsWithFilterMutex.Unlock()
+	fake.SubscribeToEventsWithFilterStub = nil
+	if fake.subscribeToEventsWithFilterReturnsOnCall == nil {
+		fake.subscribeToEventsWithFilterReturnsOnCall = make(map[int]struct {
+			result1 executor.EventSource
+			result2 error
+		})
+	}
+	fake.subscribeToEventsWithFilterReturnsOnCall[i] = struct {
+		result1 executor.EventSource
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeClient) SubscribeToLogs(arg1 lager.Logger, arg2 string) (executor.LogSource, error) {
+	fake.subscribeToLogsMutex.Lock()
+	ret, specificReturn := fake.subscribeToLogsReturnsOnCall[len(fake.subscribeToLogsArgsForCall)]
+	fake.subscribeToLogsArgsForCall = append(fake.subscribeToLogsArgsForCall, struct {
+		arg1 lager.Logger
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.SubscribeToLogsStub
+	fakeReturns := fake.subscribeToLogsReturns
+	fake.recordInvocation("SubscribeToLogs", []interface{}{arg1, arg2})
+	fake.subscribeToLogsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeClient) SubscribeToLogsCallCount() int {
+	fake.subscribeToLogsMutex.RLock()
+	defer fake.subscribeToLogsMutex.RUnlock()
+	return len(fake.subscribeToLogsArgsForCall)
+}
+
+func (fake *FakeClient) SubscribeToLogsCalls(stub func(lager.Logger, string) (executor.LogSource, error)) {
+	fake.subscribeToLogsMutex.Lock()
+	defer fake.subscribeToLogsMutex.Unlock()
+	fake.SubscribeToLogsStub = stub
+}
+
+func (fake *FakeClient) SubscribeToLogsArgsForCall(i int) (lager.Logger, string) {
+	fake.subscribeToLogsMutex.RLock()
+	defer fake.subscribeToLogsMutex.RUnlock()
+	argsForCall := fake.subscribeToLogsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeClient) SubscribeToLogsReturns(result1 executor.LogSource, result2 error) {
+	fake.subscribeToLogsMutex.Lock()
+	defer fake.subscribeToLogsMutex.Unlock()
+	fake.SubscribeToLogsStub = nil
+	fake.subscribeToLogsReturns = struct {
+		result1 executor.LogSource
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeClient) SubscribeToLogsReturnsOnCall(i int, result1 executor.LogSource, result2 error) {
+	fake.subscribeToLogsMutex.Lock()
+	defer fake.subscribeToLogsMutex.Unlock()
+	fake.SubscribeToLogsStub = nil
+	if fake.subscribeToLogsReturnsOnCall == nil {
+		fake.subscribeToLogsReturnsOnCall = make(map[int]struct {
+			result1 executor.LogSource
+			result2 error
+		})
+	}
+	fake.subscribeToLogsReturnsOnCall[i] = struct {
+		result1 executor.LogSource
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeClient) TotalResources(arg1 lager.Logger) (executor.ExecutorResources, error) {
 	fake.totalResourcesMutex.Lock()
 	ret, specificReturn := fake.totalResourcesReturnsOnCall[len(fake.totalResourcesArgsForCall)]
@@ -1093,6 +1887,69 @@ func (fake *FakeClient) TotalResourcesReturnsOnCall(i int, result1 executor.Exec
 	}{result1, result2}
 }
 
+func (fake *FakeClient) UpdateContainerLimits(arg1 lager.Logger, arg2 string, arg3 executor.Resource) error {
+	fake.updateContainerLimitsMutex.Lock()
+	ret, specificReturn := fake.updateContainerLimitsReturnsOnCall[len(fake.updateContainerLimitsArgsForCall)]
+	fake.updateContainerLimitsArgsForCall = append(fake.updateContainerLimitsArgsForCall, struct {
+		arg1 lager.Logger
+		arg2 string
+		arg3 executor.Resource
+	}{arg1, arg2, arg3})
+	stub := fake.UpdateContainerLimitsStub
+	fakeReturns := fake.updateContainerLimitsReturns
+	fake.recordInvocation("UpdateContainerLimits", []interface{}{arg1, arg2, arg3})
+	fake.updateContainerLimitsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeClient) UpdateContainerLimitsCallCount() int {
+	fake.updateContainerLimitsMutex.RLock()
+	defer fake.updateContainerLimitsMutex.RUnlock()
+	return len(fake.updateContainerLimitsArgsForCall)
+}
+
+func (fake *FakeClient) UpdateContainerLimitsCalls(stub func(lager.Logger, string, executor.Resource) error) {
+	fake.updateContainerLimitsMutex.Lock()
+	defer fake.updateContainerLimitsMutex.Unlock()
+	fake.UpdateContainerLimitsStub = stub
+}
+
+func (fake *FakeClient) UpdateContainerLimitsArgsForCall(i int) (lager.Logger, string, executor.Resource) {
+	fake.updateContainerLimitsMutex.RLock()
+	defer fake.updateContainerLimitsMutex.RUnlock()
+	argsForCall := fake.updateContainerLimitsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeClient) UpdateContainerLimitsReturns(result1 error) {
+	fake.updateContainerLimitsMutex.Lock()
+	defer fake.updateContainerLimitsMutex.Unlock()
+	fake.UpdateContainerLimitsStub = nil
+	fake.updateContainerLimitsReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeClient) UpdateContainerLimitsReturnsOnCall(i int, result1 error) {
+	fake.updateContainerLimitsMutex.Lock()
+	defer fake.updateContainerLimitsMutex.Unlock()
+	fake.UpdateContainerLimitsStub = nil
+	if fake.updateContainerLimitsReturnsOnCall == nil {
+		fake.updateContainerLimitsReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.updateContainerLimitsReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeClient) VolumeDrivers(arg1 lager.Logger) ([]string, error) {
 	fake.volumeDriversMutex.Lock()
 	ret, specificReturn := fake.volumeDriversReturnsOnCall[len(fake.volumeDriversArgsForCall)]
@@ -1166,30 +2023,52 @@ func (fake *FakeClient) Invocations() map[string][][]interface{} {
 	defer fake.cleanupMutex.RUnlock()
 	fake.deleteContainerMutex.RLock()
 	defer fake.deleteContainerMutex.RUnlock()
+	fake.exportContainerDataMutex.RLock()
+	defer fake.exportContainerDataMutex.RUnlock()
 	fake.getBulkMetricsMutex.RLock()
 	defer fake.getBulkMetricsMutex.RUnlock()
 	fake.getContainerMutex.RLock()
 	defer fake.getContainerMutex.RUnlock()
 	fake.getFilesMutex.RLock()
 	defer fake.getFilesMutex.RUnlock()
+	fake.getRecentLogsMutex.RLock()
+	defer fake.getRecentLogsMutex.RUnlock()
 	fake.healthyMutex.RLock()
 	defer fake.healthyMutex.RUnlock()
+	fake.importContainerDataMutex.RLock()
+	defer fake.importContainerDataMutex.RUnlock()
 	fake.listContainersMutex.RLock()
 	defer fake.listContainersMutex.RUnlock()
+	fake.pauseContainerMutex.RLock()
+	defer fake.pauseContainerMutex.RUnlock()
 	fake.pingMutex.RLock()
 	defer fake.pingMutex.RUnlock()
+	fake.putFilesMutex.RLock()
+	defer fake.putFilesMutex.RUnlock()
 	fake.remainingResourcesMutex.RLock()
 	defer fake.remainingResourcesMutex.RUnlock()
+	fake.resumeContainerMutex.RLock()
+	defer fake.resumeContainerMutex.RUnlock()
 	fake.runContainerMutex.RLock()
 	defer fake.runContainerMutex.RUnlock()
+	fake.runProcessMutex.RLock()
+	defer fake.runProcessMutex.RUnlock()
 	fake.setHealthyMutex.RLock()
 	defer fake.setHealthyMutex.RUnlock()
 	fake.stopContainerMutex.RLock()
 	defer fake.stopContainerMutex.RUnlock()
+	fake.subscribeFromMutex.RLock()
+	defer fake.subscribeFromMutex.RUnlock()
 	fake.subscribeToEventsMutex.RLock()
 	defer fake.subscribeToEventsMutex.RUnlock()
+	fake.subscribeToEventsWithFilterMutex.RLock()
+	defer fake.subscribeToEventsWithFilterMutex.RUnlock()
+	fake.subscribeToLogsMutex.RLock()
+	defer fake.subscribeToLogsMutex.RUnlock()
 	fake.totalResourcesMutex.RLock()
 	defer fake.totalResourcesMutex.RUnlock()
+	fake.updateContainerLimitsMutex.RLock()
+	defer fake.updateContainerLimitsMutex.RUnlock()
 	fake.volumeDriversMutex.RLock()
 	defer fake.volumeDriversMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
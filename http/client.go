@@ -0,0 +1,378 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"code.cloudfoundry.org/executor"
+	"code.cloudfoundry.org/lager"
+	"github.com/tedsuo/rata"
+	"github.com/vito/go-sse/sse"
+)
+
+type client struct {
+	httpClient          *http.Client
+	streamingHTTPClient *http.Client
+	reqGen              *rata.RequestGenerator
+}
+
+// NewClient returns an executor.Client that talks to an executor serving the
+// API on the given network ("tcp" or "unix") and address.
+func NewClient(network, address string) executor.Client {
+	dial := func(ctx context.Context, _, _ string) (net.Conn, error) {
+		var dialer net.Dialer
+		return dialer.DialContext(ctx, network, address)
+	}
+
+	// the address is dialed directly, the host of the requests only has to be
+	// well formed
+	return NewClientWithHTTPClients(
+		&http.Client{Transport: &http.Transport{DialContext: dial}},
+		&http.Client{Transport: &http.Transport{DialContext: dial}},
+		"http://executor",
+	)
+}
+
+// NewTLSClient returns an executor.Client for an executor API served over
+// TLS on the tcp address.
+func NewTLSClient(address string, tlsConfig *tls.Config) executor.Client {
+	return NewClientWithHTTPClients(
+		&http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}},
+		&http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}},
+		"https://"+address,
+	)
+}
+
+// NewClientWithHTTPClients returns an executor.Client that sends its requests
+// to the executor at url. The streaming client is used for the requests whose
+// responses are streamed, such as events and files, and should therefore have
+// no timeout.
+func NewClientWithHTTPClients(httpClient, streamingHTTPClient *http.Client, url string) executor.Client {
+	return &client{
+		httpClient:          httpClient,
+		streamingHTTPClient: streamingHTTPClient,
+		reqGen:              rata.NewRequestGenerator(url, Routes),
+	}
+}
+
+func (c *client) Ping(logger lager.Logger) error {
+	return c.doRequest(logger, Ping, nil, nil, nil, nil)
+}
+
+func (c *client) AllocateContainers(logger lager.Logger, requests []executor.AllocationRequest) []executor.AllocationFailure {
+	failures := []executor.AllocationFailure{}
+	err := c.doRequest(logger, AllocateContainers, nil, nil, requests, &failures)
+	if err != nil {
+		// none of the containers could be allocated
+		failures = make([]executor.AllocationFailure, 0, len(requests))
+		for i := range requests {
+			failures = append(failures, executor.NewAllocationFailureFromError(&requests[i], err))
+		}
+	}
+	return failures
+}
+
+func (c *client) GetContainer(logger lager.Logger, guid string) (executor.Container, error) {
+	container := executor.Container{}
+	err := c.doRequest(logger, GetContainer, rata.Params{"guid": guid}, nil, nil, &container)
+	return container, err
+}
+
+func (c *client) RunContainer(logger lager.Logger, request *executor.RunRequest) error {
+	return c.doRequest(logger, RunContainer, rata.Params{"guid": request.Guid}, nil, request, nil)
+}
+
+func (c *client) StopContainer(logger lager.Logger, guid string) error {
+	return c.doRequest(logger, StopContainer, rata.Params{"guid": guid}, nil, nil, nil)
+}
+
+func (c *client) UpdateContainerLimits(logger lager.Logger, guid string, resource executor.Resource) error {
+	return c.doRequest(logger, UpdateContainerLimits, rata.Params{"guid": guid}, nil, resource, nil)
+}
+
+func (c *client) PauseContainer(logger lager.Logger, guid string) error {
+	return c.doRequest(logger, PauseContainer, rata.Params{"guid": guid}, nil, nil, nil)
+}
+
+func (c *client) ResumeContainer(logger lager.Logger, guid string) error {
+	return c.doRequest(logger, ResumeContainer, rata.Params{"guid": guid}, nil, nil, nil)
+}
+
+func (c *client) DeleteContainer(logger lager.Logger, guid string) error {
+	return c.doRequest(logger, DeleteContainer, rata.Params{"guid": guid}, nil, nil, nil)
+}
+
+func (c *client) ListContainers(logger lager.Logger) ([]executor.Container, error) {
+	containers := []executor.Container{}
+	err := c.doRequest(logger, ListContainers, nil, nil, nil, &containers)
+	return containers, err
+}
+
+func (c *client) GetBulkMetrics(logger lager.Logger) (map[string]executor.Metrics, error) {
+	metrics := map[string]executor.Metrics{}
+	err := c.doRequest(logger, GetBulkMetrics, nil, nil, nil, &metrics)
+	return metrics, err
+}
+
+func (c *client) RemainingResources(logger lager.Logger) (executor.ExecutorResources, error) {
+	resources := executor.ExecutorResources{}
+	err := c.doRequest(logger, RemainingResources, nil, nil, nil, &resources)
+	return resources, err
+}
+
+func (c *client) TotalResources(logger lager.Logger) (executor.ExecutorResources, error) {
+	resources := executor.ExecutorResources{}
+	err := c.doRequest(logger, TotalResources, nil, nil, nil, &resources)
+	return resources, err
+}
+
+func (c *client) GetFiles(logger lager.Logger, guid, path string) (io.ReadCloser, error) {
+	query := url.Values{PathQueryKey: []string{path}}
+	response, err := c.doStreamingRequest(logger, GetFiles, rata.Params{"guid": guid}, query, nil)
+	if err != nil {
+		return nil, err
+	}
+	return response.Body, nil
+}
+
+func (c *client) PutFiles(logger lager.Logger, guid, destPath string, tarStream io.Reader, user string) error {
+	query := url.Values{PathQueryKey: []string{destPath}, UserQueryKey: []string{user}}
+	response, err := c.doStreamingRequest(logger, PutFiles, rata.Params{"guid": guid}, query, tarStream)
+	if err != nil {
+		return err
+	}
+	return response.Body.Close()
+}
+
+func (c *client) ExportContainerData(logger lager.Logger, guid string, dest io.Writer) error {
+	response, err := c.doStreamingRequest(logger, ExportContainerData, rata.Params{"guid": guid}, nil, nil)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	_, err = io.Copy(dest, response.Body)
+	return err
+}
+
+func (c *client) ImportContainerData(logger lager.Logger, guid string, src io.Reader) error {
+	response, err := c.doStreamingRequest(logger, ImportContainerData, rata.Params{"guid": guid}, nil, src)
+	if err != nil {
+		return err
+	}
+	return response.Body.Close()
+}
+
+// RunProcess upgrades the connection of the request to ProcessProtocol, which
+// then carries the stdio, resizes, signals and exit status of the process.
+func (c *client) RunProcess(logger lager.Logger, guid string, spec executor.ProcessSpec, processIO executor.ProcessIO) (executor.Process, error) {
+	payload, err := json.Marshal(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := c.createRequest(RunProcess, rata.Params{"guid": guid}, nil, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", ProcessProtocol)
+
+	res, err := c.do(c.streamingHTTPClient, req)
+	if err != nil {
+		logger.Error("failed-request", err, lager.Data{"request": RunProcess})
+		return nil, err
+	}
+
+	conn, ok := res.Body.(io.ReadWriteCloser)
+	if res.StatusCode != http.StatusSwitchingProtocols || !ok {
+		res.Body.Close()
+		err := fmt.Errorf("executor did not switch to %s: status %d", ProcessProtocol, res.StatusCode)
+		logger.Error("failed-request", err, lager.Data{"request": RunProcess})
+		return nil, err
+	}
+
+	return newRemoteProcess(res.Header.Get(ProcessIDHeader), conn, processIO), nil
+}
+
+func (c *client) VolumeDrivers(logger lager.Logger) ([]string, error) {
+	drivers := []string{}
+	err := c.doRequest(logger, VolumeDrivers, nil, nil, nil, &drivers)
+	return drivers, err
+}
+
+func (c *client) SubscribeToEvents(logger lager.Logger) (executor.EventSource, error) {
+	return c.subscribe(logger, EventFilterQuery(executor.EventFilter{}))
+}
+
+func (c *client) SubscribeToEventsWithFilter(logger lager.Logger, filter executor.EventFilter) (executor.EventSource, error) {
+	return c.subscribe(logger, EventFilterQuery(filter))
+}
+
+func (c *client) SubscribeFrom(logger lager.Logger, seq uint64, filter executor.EventFilter) (executor.EventSource, error) {
+	query := EventFilterQuery(filter)
+	query.Set(SinceQueryKey, strconv.FormatUint(seq, 10))
+	return c.subscribe(logger, query)
+}
+
+func (c *client) subscribe(logger lager.Logger, query url.Values) (executor.EventSource, error) {
+	response, err := c.doStreamingRequest(logger, Events, nil, query, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &eventSource{source: sse.NewReadCloser(response.Body)}, nil
+}
+
+func (c *client) SubscribeToLogs(logger lager.Logger, guid string) (executor.LogSource, error) {
+	response, err := c.doStreamingRequest(logger, SubscribeToLogs, rata.Params{"guid": guid}, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &logSource{source: sse.NewReadCloser(response.Body)}, nil
+}
+
+func (c *client) GetRecentLogs(logger lager.Logger, guid string) ([]executor.LogLine, error) {
+	lines := []executor.LogLine{}
+	err := c.doRequest(logger, GetRecentLogs, rata.Params{"guid": guid}, nil, nil, &lines)
+	return lines, err
+}
+
+func (c *client) Healthy(logger lager.Logger) bool {
+	health := HealthResponse{}
+	err := c.doRequest(logger, Healthy, nil, nil, nil, &health)
+	if err != nil {
+		logger.Error("failed-to-get-health", err)
+		return false
+	}
+	return health.Healthy
+}
+
+func (c *client) SetHealthy(logger lager.Logger, healthy bool) {
+	err := c.doRequest(logger, SetHealthy, nil, nil, HealthResponse{Healthy: healthy}, nil)
+	if err != nil {
+		logger.Error("failed-to-set-health", err)
+	}
+}
+
+// Cleanup does nothing: the executor behind the API is cleaned up by the
+// process running it.
+func (c *client) Cleanup(logger lager.Logger) {}
+
+func (c *client) doRequest(logger lager.Logger, requestName string, params rata.Params, query url.Values, request, response interface{}) error {
+	var body io.Reader
+	if request != nil {
+		payload, err := json.Marshal(request)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(payload)
+	}
+
+	req, err := c.createRequest(requestName, params, query, body)
+	if err != nil {
+		return err
+	}
+
+	res, err := c.do(c.httpClient, req)
+	if err != nil {
+		logger.Error("failed-request", err, lager.Data{"request": requestName})
+		return err
+	}
+	defer res.Body.Close()
+
+	if response == nil {
+		return nil
+	}
+	return json.NewDecoder(res.Body).Decode(response)
+}
+
+func (c *client) doStreamingRequest(logger lager.Logger, requestName string, params rata.Params, query url.Values, body io.Reader) (*http.Response, error) {
+	req, err := c.createRequest(requestName, params, query, body)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := c.do(c.streamingHTTPClient, req)
+	if err != nil {
+		logger.Error("failed-request", err, lager.Data{"request": requestName})
+		return nil, err
+	}
+	return res, nil
+}
+
+func (c *client) createRequest(requestName string, params rata.Params, query url.Values, body io.Reader) (*http.Request, error) {
+	req, err := c.reqGen.CreateRequest(requestName, params, body)
+	if err != nil {
+		return nil, err
+	}
+	if query != nil {
+		req.URL.RawQuery = query.Encode()
+	}
+	return req, nil
+}
+
+// do sends the request and turns a failure response into the error it
+// carries, which is the registered executor.Error whenever there is one.
+func (c *client) do(httpClient *http.Client, req *http.Request) (*http.Response, error) {
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode < 300 {
+		return res, nil
+	}
+	defer res.Body.Close()
+
+	if executorErr, ok := executor.Errors[res.Header.Get(ErrorHeader)]; ok {
+		return nil, executorErr
+	}
+
+	errResponse := ErrorResponse{}
+	payload, _ := ioutil.ReadAll(res.Body)
+	if json.Unmarshal(payload, &errResponse) != nil || errResponse.Message == "" {
+		errResponse.Message = string(payload)
+	}
+	return nil, fmt.Errorf("executor request failed with status %d: %s", res.StatusCode, errResponse.Message)
+}
+
+type eventSource struct {
+	source *sse.ReadCloser
+}
+
+func (e *eventSource) Next() (executor.Event, error) {
+	sseEvent, err := e.source.Next()
+	if err != nil {
+		return nil, err
+	}
+	return NewEventFromSSE(sseEvent)
+}
+
+func (e *eventSource) Close() error {
+	return e.source.Close()
+}
+
+type logSource struct {
+	source *sse.ReadCloser
+}
+
+func (l *logSource) Next() (executor.LogLine, error) {
+	sseEvent, err := l.source.Next()
+	if err != nil {
+		return executor.LogLine{}, err
+	}
+	return NewLogLineFromSSE(sseEvent)
+}
+
+func (l *logSource) Close() error {
+	return l.source.Close()
+}
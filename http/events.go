@@ -0,0 +1,112 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"code.cloudfoundry.org/executor"
+	"github.com/vito/go-sse/sse"
+)
+
+// NewSSEEvent encodes an event for the Events stream. The event's sequence
+// number is used as the id, so that the client can resume from it.
+func NewSSEEvent(event executor.Event) (sse.Event, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return sse.Event{}, err
+	}
+
+	return sse.Event{
+		ID:   strconv.FormatUint(event.Sequence(), 10),
+		Name: string(event.EventType()),
+		Data: payload,
+	}, nil
+}
+
+func NewEventFromSSE(sseEvent sse.Event) (executor.Event, error) {
+	switch executor.EventType(sseEvent.Name) {
+	case executor.EventTypeContainerReserved:
+		event := executor.ContainerReservedEvent{}
+		err := json.Unmarshal(sseEvent.Data, &event)
+		return event, err
+	case executor.EventTypeContainerInitializing:
+		event := executor.ContainerInitializingEvent{}
+		err := json.Unmarshal(sseEvent.Data, &event)
+		return event, err
+	case executor.EventTypeContainerCreated:
+		event := executor.ContainerCreatedEvent{}
+		err := json.Unmarshal(sseEvent.Data, &event)
+		return event, err
+	case executor.EventTypeContainerRunning:
+		event := executor.ContainerRunningEvent{}
+		err := json.Unmarshal(sseEvent.Data, &event)
+		return event, err
+	case executor.EventTypeContainerPaused:
+		event := executor.ContainerPausedEvent{}
+		err := json.Unmarshal(sseEvent.Data, &event)
+		return event, err
+	case executor.EventTypeContainerHealthy:
+		event := executor.ContainerHealthyEvent{}
+		err := json.Unmarshal(sseEvent.Data, &event)
+		return event, err
+	case executor.EventTypeContainerUnhealthy:
+		event := executor.ContainerUnhealthyEvent{}
+		err := json.Unmarshal(sseEvent.Data, &event)
+		return event, err
+	case executor.EventTypeContainerLimitsUpdated:
+		event := executor.ContainerLimitsUpdatedEvent{}
+		err := json.Unmarshal(sseEvent.Data, &event)
+		return event, err
+	case executor.EventTypeContainerOOM:
+		event := executor.ContainerOOMEvent{}
+		err := json.Unmarshal(sseEvent.Data, &event)
+		return event, err
+	case executor.EventTypeContainerStopped:
+		event := executor.ContainerStoppedEvent{}
+		err := json.Unmarshal(sseEvent.Data, &event)
+		return event, err
+	case executor.EventTypeContainerPreempted:
+		event := executor.ContainerPreemptedEvent{}
+		err := json.Unmarshal(sseEvent.Data, &event)
+		return event, err
+	case executor.EventTypeContainerComplete:
+		event := executor.ContainerCompleteEvent{}
+		err := json.Unmarshal(sseEvent.Data, &event)
+		return event, err
+	default:
+		return nil, fmt.Errorf("unknown event type: %q", sseEvent.Name)
+	}
+}
+
+// EventFilterQuery encodes filter as query parameters of the Events route.
+func EventFilterQuery(filter executor.EventFilter) url.Values {
+	query := url.Values{}
+	for _, eventType := range filter.Types {
+		query.Add(EventTypeQueryKey, string(eventType))
+	}
+	for key, value := range filter.Tags {
+		query.Add(EventTagQueryKey, key+":"+value)
+	}
+	return query
+}
+
+func EventFilterFromQuery(query url.Values) (executor.EventFilter, error) {
+	filter := executor.EventFilter{}
+	for _, eventType := range query[EventTypeQueryKey] {
+		filter.Types = append(filter.Types, executor.EventType(eventType))
+	}
+	for _, tag := range query[EventTagQueryKey] {
+		kv := strings.SplitN(tag, ":", 2)
+		if len(kv) != 2 {
+			return executor.EventFilter{}, fmt.Errorf("invalid tag %q: expected key:value", tag)
+		}
+		if filter.Tags == nil {
+			filter.Tags = executor.Tags{}
+		}
+		filter.Tags[kv[0]] = kv[1]
+	}
+	return filter, nil
+}
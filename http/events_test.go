@@ -0,0 +1,62 @@
+package http_test
+
+import (
+	"code.cloudfoundry.org/executor"
+	ehttp "code.cloudfoundry.org/executor/http"
+	"github.com/vito/go-sse/sse"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Events", func() {
+	Describe("NewEventFromSSE", func() {
+		It("decodes the events encoded by NewSSEEvent", func() {
+			event := executor.NewContainerCompleteEvent(executor.Container{Guid: "some-guid", State: executor.StateCompleted}).WithMetadata(executor.EventMetadata{
+				Seq:           42,
+				Timestamp:     1234,
+				PreviousState: executor.StateRunning,
+			})
+
+			sseEvent, err := ehttp.NewSSEEvent(event)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(sseEvent.ID).To(Equal("42"))
+			Expect(sseEvent.Name).To(Equal("container_complete"))
+
+			decoded, err := ehttp.NewEventFromSSE(sseEvent)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(decoded).To(BeAssignableToTypeOf(executor.ContainerCompleteEvent{}))
+			Expect(decoded.Metadata()).To(Equal(event.Metadata()))
+			Expect(decoded.(executor.ContainerCompleteEvent).Container().Guid).To(Equal("some-guid"))
+		})
+
+		It("fails on unknown event types", func() {
+			_, err := ehttp.NewEventFromSSE(sse.Event{Name: "bogus", Data: []byte("{}")})
+			Expect(err).To(MatchError(ContainSubstring("bogus")))
+		})
+	})
+
+	Describe("EventFilterQuery", func() {
+		It("round-trips through EventFilterFromQuery", func() {
+			filter := executor.EventFilter{
+				Types: []executor.EventType{executor.EventTypeContainerComplete, executor.EventTypeContainerOOM},
+				Tags:  executor.Tags{"domain": "cf-apps", "url": "http://example.com"},
+			}
+
+			decoded, err := ehttp.EventFilterFromQuery(ehttp.EventFilterQuery(filter))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(decoded).To(Equal(filter))
+		})
+
+		It("decodes an empty query as the empty filter", func() {
+			decoded, err := ehttp.EventFilterFromQuery(ehttp.EventFilterQuery(executor.EventFilter{}))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(decoded).To(Equal(executor.EventFilter{}))
+		})
+
+		It("rejects tags without a value", func() {
+			_, err := ehttp.EventFilterFromQuery(map[string][]string{ehttp.EventTagQueryKey: {"domain"}})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,34 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"code.cloudfoundry.org/executor"
+	"github.com/vito/go-sse/sse"
+)
+
+// LogLineSSEName is the name of the server-sent events of the logs stream.
+const LogLineSSEName = "log"
+
+func NewSSELogLine(line executor.LogLine) (sse.Event, error) {
+	payload, err := json.Marshal(line)
+	if err != nil {
+		return sse.Event{}, err
+	}
+
+	return sse.Event{
+		Name: LogLineSSEName,
+		Data: payload,
+	}, nil
+}
+
+func NewLogLineFromSSE(sseEvent sse.Event) (executor.LogLine, error) {
+	line := executor.LogLine{}
+	if sseEvent.Name != LogLineSSEName {
+		return line, fmt.Errorf("unknown log event: %q", sseEvent.Name)
+	}
+
+	err := json.Unmarshal(sseEvent.Data, &line)
+	return line, err
+}
@@ -0,0 +1,235 @@
+package http
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"code.cloudfoundry.org/executor"
+)
+
+// ProcessProtocol is the protocol a RunProcess request upgrades its
+// connection to. Once the executor switches protocols, the connection
+// carries frames in both directions until the process exits.
+const ProcessProtocol = "executor-process"
+
+// ProcessIDHeader carries the id of the process in the response switching
+// protocols.
+const ProcessIDHeader = "X-Executor-Process-Id"
+
+// FrameKind is the first byte of a frame. It is followed by the big endian
+// uint32 length of the payload and the payload itself.
+type FrameKind byte
+
+const (
+	// sent by the client
+	FrameStdin FrameKind = iota
+	FrameStdinClosed
+	FrameResize
+	FrameSignal
+
+	// sent by the executor
+	FrameStdout
+	FrameStderr
+	FrameExit
+)
+
+const frameHeaderSize = 5
+
+// MaxFramePayload is the largest payload ReadFrame accepts. Streams written
+// through FrameWriter are split into frames of at most this size.
+const MaxFramePayload = 64 * 1024
+
+var ErrFrameTooLarge = errors.New("frame payload too large")
+
+// ProcessExit is the payload of the FrameExit frame.
+type ProcessExit struct {
+	ExitStatus int    `json:"exit_status"`
+	Error      string `json:"error,omitempty"`
+}
+
+// FrameWriter writes frames to w. It is safe to use from several goroutines.
+type FrameWriter struct {
+	lock sync.Mutex
+	w    io.Writer
+}
+
+func NewFrameWriter(w io.Writer) *FrameWriter {
+	return &FrameWriter{w: w}
+}
+
+func (f *FrameWriter) Write(kind FrameKind, payload []byte) error {
+	if len(payload) > MaxFramePayload {
+		return ErrFrameTooLarge
+	}
+
+	frame := make([]byte, frameHeaderSize+len(payload))
+	frame[0] = byte(kind)
+	binary.BigEndian.PutUint32(frame[1:frameHeaderSize], uint32(len(payload)))
+	copy(frame[frameHeaderSize:], payload)
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	_, err := f.w.Write(frame)
+	return err
+}
+
+func (f *FrameWriter) WriteJSON(kind FrameKind, payload interface{}) error {
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	return f.Write(kind, encoded)
+}
+
+// Stream returns a writer sending everything written to it as frames of the
+// given kind.
+func (f *FrameWriter) Stream(kind FrameKind) io.Writer {
+	return &frameStream{frames: f, kind: kind}
+}
+
+type frameStream struct {
+	frames *FrameWriter
+	kind   FrameKind
+}
+
+func (s *frameStream) Write(p []byte) (int, error) {
+	written := 0
+	for written < len(p) {
+		end := written + MaxFramePayload
+		if end > len(p) {
+			end = len(p)
+		}
+
+		err := s.frames.Write(s.kind, p[written:end])
+		if err != nil {
+			return written, err
+		}
+		written = end
+	}
+	return written, nil
+}
+
+// ReadFrame reads the next frame from r.
+func ReadFrame(r io.Reader) (FrameKind, []byte, error) {
+	header := make([]byte, frameHeaderSize)
+	_, err := io.ReadFull(r, header)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	size := binary.BigEndian.Uint32(header[1:])
+	if size > MaxFramePayload {
+		return 0, nil, ErrFrameTooLarge
+	}
+
+	payload := make([]byte, size)
+	_, err = io.ReadFull(r, payload)
+	if err != nil {
+		return 0, nil, err
+	}
+	return FrameKind(header[0]), payload, nil
+}
+
+// remoteProcess is a process run by an executor behind the API, driven over
+// the connection the RunProcess request switched to.
+type remoteProcess struct {
+	id     string
+	conn   io.ReadWriteCloser
+	frames *FrameWriter
+
+	done       chan struct{}
+	exitStatus int
+	err        error
+}
+
+func newRemoteProcess(id string, conn io.ReadWriteCloser, processIO executor.ProcessIO) *remoteProcess {
+	p := &remoteProcess{
+		id:     id,
+		conn:   conn,
+		frames: NewFrameWriter(conn),
+		done:   make(chan struct{}),
+	}
+
+	go p.receive(processIO.Stdout, processIO.Stderr)
+	if processIO.Stdin != nil {
+		go p.send(processIO.Stdin)
+	} else {
+		p.frames.Write(FrameStdinClosed, nil)
+	}
+
+	return p
+}
+
+func (p *remoteProcess) ID() string {
+	return p.id
+}
+
+func (p *remoteProcess) Wait() (int, error) {
+	<-p.done
+	return p.exitStatus, p.err
+}
+
+func (p *remoteProcess) Resize(size executor.WindowSize) error {
+	return p.frames.WriteJSON(FrameResize, size)
+}
+
+func (p *remoteProcess) Signal(signal executor.ProcessSignal) error {
+	switch signal {
+	case executor.ProcessSignalTerminate, executor.ProcessSignalKill:
+		return p.frames.Write(FrameSignal, []byte(signal))
+	default:
+		return executor.ErrInvalidProcessSignal
+	}
+}
+
+func (p *remoteProcess) send(stdin io.Reader) {
+	_, err := io.Copy(p.frames.Stream(FrameStdin), stdin)
+	if err != nil {
+		return
+	}
+	p.frames.Write(FrameStdinClosed, nil)
+}
+
+func (p *remoteProcess) receive(stdout, stderr io.Writer) {
+	defer close(p.done)
+	defer p.conn.Close()
+
+	for {
+		kind, payload, err := ReadFrame(p.conn)
+		if err != nil {
+			p.exitStatus = -1
+			p.err = fmt.Errorf("lost connection to process %s: %s", p.id, err)
+			return
+		}
+
+		switch kind {
+		case FrameStdout:
+			if stdout != nil {
+				stdout.Write(payload)
+			}
+		case FrameStderr:
+			if stderr != nil {
+				stderr.Write(payload)
+			}
+		case FrameExit:
+			exit := ProcessExit{}
+			err := json.Unmarshal(payload, &exit)
+			if err != nil {
+				p.exitStatus = -1
+				p.err = err
+				return
+			}
+
+			p.exitStatus = exit.ExitStatus
+			if exit.Error != "" {
+				p.err = errors.New(exit.Error)
+			}
+			return
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package http
+
+import "github.com/tedsuo/rata"
+
+const (
+	Ping = "Ping"
+
+	AllocateContainers    = "AllocateContainers"
+	GetContainer          = "GetContainer"
+	RunContainer          = "RunContainer"
+	StopContainer         = "StopContainer"
+	UpdateContainerLimits = "UpdateContainerLimits"
+	PauseContainer        = "PauseContainer"
+	ResumeContainer       = "ResumeContainer"
+	DeleteContainer       = "DeleteContainer"
+	ListContainers        = "ListContainers"
+	GetFiles              = "GetFiles"
+	PutFiles              = "PutFiles"
+	ExportContainerData   = "ExportContainerData"
+	ImportContainerData   = "ImportContainerData"
+	RunProcess            = "RunProcess"
+	SubscribeToLogs       = "SubscribeToLogs"
+	GetRecentLogs         = "GetRecentLogs"
+
+	GetBulkMetrics     = "GetBulkMetrics"
+	RemainingResources = "RemainingResources"
+	TotalResources     = "TotalResources"
+	VolumeDrivers      = "VolumeDrivers"
+
+	Events = "Events"
+
+	Healthy    = "Healthy"
+	SetHealthy = "SetHealthy"
+)
+
+var Routes = rata.Routes{
+	{Path: "/ping", Method: "GET", Name: Ping},
+
+	{Path: "/containers", Method: "POST", Name: AllocateContainers},
+	{Path: "/containers", Method: "GET", Name: ListContainers},
+	{Path: "/containers/:guid", Method: "GET", Name: GetContainer},
+	{Path: "/containers/:guid", Method: "DELETE", Name: DeleteContainer},
+	{Path: "/containers/:guid/run", Method: "POST", Name: RunContainer},
+	{Path: "/containers/:guid/stop", Method: "POST", Name: StopContainer},
+	{Path: "/containers/:guid/limits", Method: "PUT", Name: UpdateContainerLimits},
+	{Path: "/containers/:guid/pause", Method: "POST", Name: PauseContainer},
+	{Path: "/containers/:guid/resume", Method: "POST", Name: ResumeContainer},
+	{Path: "/containers/:guid/files", Method: "GET", Name: GetFiles},
+	{Path: "/containers/:guid/files", Method: "PUT", Name: PutFiles},
+	{Path: "/containers/:guid/data", Method: "GET", Name: ExportContainerData},
+	{Path: "/containers/:guid/data", Method: "PUT", Name: ImportContainerData},
+	{Path: "/containers/:guid/processes", Method: "POST", Name: RunProcess},
+	{Path: "/containers/:guid/logs", Method: "GET", Name: SubscribeToLogs},
+	{Path: "/containers/:guid/logs/recent", Method: "GET", Name: GetRecentLogs},
+
+	{Path: "/metrics", Method: "GET", Name: GetBulkMetrics},
+	{Path: "/resources/remaining", Method: "GET", Name: RemainingResources},
+	{Path: "/resources/total", Method: "GET", Name: TotalResources},
+	{Path: "/volume_drivers", Method: "GET", Name: VolumeDrivers},
+
+	{Path: "/events", Method: "GET", Name: Events},
+
+	{Path: "/health", Method: "GET", Name: Healthy},
+	{Path: "/health", Method: "PUT", Name: SetHealthy},
+}
+
+// ErrorHeader carries the name of the executor.Error a request failed with, so
+// that the client can return the very same error.
+const ErrorHeader = "X-Executor-Error"
+
+// ErrorResponse is the body of a failed request.
+type ErrorResponse struct {
+	Name    string `json:"name,omitempty"`
+	Message string `json:"message"`
+}
+
+// HealthResponse is the body of the Healthy and SetHealthy requests.
+type HealthResponse struct {
+	Healthy bool `json:"healthy"`
+}
+
+// Query parameters of the Events and files routes. LastEventIDHeader takes
+// precedence over SinceQueryKey, so that a reconnecting server-sent events
+// client resumes where it left off.
+const (
+	EventTypeQueryKey = "type"
+	EventTagQueryKey  = "tag"
+	SinceQueryKey     = "since"
+	PathQueryKey      = "path"
+	UserQueryKey      = "user"
+
+	LastEventIDHeader = "Last-Event-ID"
+)
@@ -0,0 +1,477 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"code.cloudfoundry.org/executor"
+	ehttp "code.cloudfoundry.org/executor/http"
+	"code.cloudfoundry.org/lager"
+)
+
+type handler struct {
+	logger         lager.Logger
+	executorClient executor.Client
+}
+
+func (h *handler) ping(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger.Session("ping")
+
+	err := h.executorClient.Ping(logger)
+	if err != nil {
+		writeError(logger, w, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *handler) allocateContainers(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger.Session("allocate-containers")
+
+	requests := []executor.AllocationRequest{}
+	if !decodeRequest(logger, w, r, &requests) {
+		return
+	}
+
+	writeJSON(logger, w, http.StatusOK, h.executorClient.AllocateContainers(logger, requests))
+}
+
+func (h *handler) getContainer(w http.ResponseWriter, r *http.Request) {
+	guid := r.FormValue(":guid")
+	logger := h.logger.Session("get-container", lager.Data{"guid": guid})
+
+	container, err := h.executorClient.GetContainer(logger, guid)
+	if err != nil {
+		writeError(logger, w, err)
+		return
+	}
+	writeJSON(logger, w, http.StatusOK, container)
+}
+
+func (h *handler) runContainer(w http.ResponseWriter, r *http.Request) {
+	guid := r.FormValue(":guid")
+	logger := h.logger.Session("run-container", lager.Data{"guid": guid})
+
+	request := executor.RunRequest{}
+	if !decodeRequest(logger, w, r, &request) {
+		return
+	}
+	request.Guid = guid
+
+	writeResult(logger, w, h.executorClient.RunContainer(logger, &request))
+}
+
+func (h *handler) stopContainer(w http.ResponseWriter, r *http.Request) {
+	guid := r.FormValue(":guid")
+	logger := h.logger.Session("stop-container", lager.Data{"guid": guid})
+
+	writeResult(logger, w, h.executorClient.StopContainer(logger, guid))
+}
+
+func (h *handler) updateContainerLimits(w http.ResponseWriter, r *http.Request) {
+	guid := r.FormValue(":guid")
+	logger := h.logger.Session("update-container-limits", lager.Data{"guid": guid})
+
+	resource := executor.Resource{}
+	if !decodeRequest(logger, w, r, &resource) {
+		return
+	}
+
+	writeResult(logger, w, h.executorClient.UpdateContainerLimits(logger, guid, resource))
+}
+
+func (h *handler) pauseContainer(w http.ResponseWriter, r *http.Request) {
+	guid := r.FormValue(":guid")
+	logger := h.logger.Session("pause-container", lager.Data{"guid": guid})
+
+	writeResult(logger, w, h.executorClient.PauseContainer(logger, guid))
+}
+
+func (h *handler) resumeContainer(w http.ResponseWriter, r *http.Request) {
+	guid := r.FormValue(":guid")
+	logger := h.logger.Session("resume-container", lager.Data{"guid": guid})
+
+	writeResult(logger, w, h.executorClient.ResumeContainer(logger, guid))
+}
+
+func (h *handler) deleteContainer(w http.ResponseWriter, r *http.Request) {
+	guid := r.FormValue(":guid")
+	logger := h.logger.Session("delete-container", lager.Data{"guid": guid})
+
+	writeResult(logger, w, h.executorClient.DeleteContainer(logger, guid))
+}
+
+func (h *handler) listContainers(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger.Session("list-containers")
+
+	containers, err := h.executorClient.ListContainers(logger)
+	if err != nil {
+		writeError(logger, w, err)
+		return
+	}
+	writeJSON(logger, w, http.StatusOK, containers)
+}
+
+func (h *handler) getFiles(w http.ResponseWriter, r *http.Request) {
+	guid := r.FormValue(":guid")
+	path := r.FormValue(ehttp.PathQueryKey)
+	logger := h.logger.Session("get-files", lager.Data{"guid": guid, "path": path})
+
+	stream, err := h.executorClient.GetFiles(logger, guid, path)
+	if err != nil {
+		writeError(logger, w, err)
+		return
+	}
+	defer stream.Close()
+
+	w.Header().Set("Content-Type", "application/x-tar")
+	w.WriteHeader(http.StatusOK)
+
+	_, err = io.Copy(w, stream)
+	if err != nil {
+		logger.Error("failed-to-stream-files", err)
+	}
+}
+
+func (h *handler) putFiles(w http.ResponseWriter, r *http.Request) {
+	guid := r.FormValue(":guid")
+	path := r.FormValue(ehttp.PathQueryKey)
+	user := r.FormValue(ehttp.UserQueryKey)
+	logger := h.logger.Session("put-files", lager.Data{"guid": guid, "path": path, "user": user})
+
+	writeResult(logger, w, h.executorClient.PutFiles(logger, guid, path, r.Body, user))
+}
+
+func (h *handler) exportContainerData(w http.ResponseWriter, r *http.Request) {
+	guid := r.FormValue(":guid")
+	logger := h.logger.Session("export-container-data", lager.Data{"guid": guid})
+
+	dest := &lazyStreamWriter{w: w}
+	err := h.executorClient.ExportContainerData(logger, guid, dest)
+	if err == nil {
+		dest.start()
+		return
+	}
+
+	if !dest.started {
+		writeError(logger, w, err)
+		return
+	}
+
+	// the status is already sent, so the only way left to fail the request
+	// is to cut the export short
+	logger.Error("failed-to-stream-container-data", err)
+	panic(http.ErrAbortHandler)
+}
+
+func (h *handler) importContainerData(w http.ResponseWriter, r *http.Request) {
+	guid := r.FormValue(":guid")
+	logger := h.logger.Session("import-container-data", lager.Data{"guid": guid})
+
+	writeResult(logger, w, h.executorClient.ImportContainerData(logger, guid, r.Body))
+}
+
+// runProcess hijacks the connection before running the process, so that the
+// process never writes output nobody reads. A failure to run it is still sent
+// as a regular error response, success switches the connection to
+// ehttp.ProcessProtocol.
+func (h *handler) runProcess(w http.ResponseWriter, r *http.Request) {
+	guid := r.FormValue(":guid")
+	logger := h.logger.Session("run-process", lager.Data{"guid": guid})
+
+	spec := executor.ProcessSpec{}
+	if !decodeRequest(logger, w, r, &spec) {
+		return
+	}
+	// whatever is left of the body would otherwise be read as frames
+	io.Copy(ioutil.Discard, r.Body)
+
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		writeError(logger, w, errHijackingUnsupported)
+		return
+	}
+
+	conn, buffered, err := hijacker.Hijack()
+	if err != nil {
+		writeError(logger, w, err)
+		return
+	}
+	defer conn.Close()
+
+	stdinR, stdinW := io.Pipe()
+	stdoutR, stdoutW := io.Pipe()
+	stderrR, stderrW := io.Pipe()
+	defer stdinW.Close()
+
+	process, err := h.executorClient.RunProcess(logger, guid, spec, executor.ProcessIO{
+		Stdin:  stdinR,
+		Stdout: stdoutW,
+		Stderr: stderrW,
+	})
+	if err != nil {
+		writeHijackedError(logger, conn, err)
+		return
+	}
+
+	_, err = fmt.Fprintf(conn, "HTTP/1.1 101 Switching Protocols\r\nConnection: Upgrade\r\nUpgrade: %s\r\n%s: %s\r\n\r\n",
+		ehttp.ProcessProtocol, ehttp.ProcessIDHeader, process.ID())
+	if err != nil {
+		logger.Error("failed-to-switch-protocols", err)
+		process.Signal(executor.ProcessSignalKill)
+	}
+
+	frames := ehttp.NewFrameWriter(conn)
+	output := &sync.WaitGroup{}
+	output.Add(2)
+	go streamOutput(output, frames.Stream(ehttp.FrameStdout), stdoutR)
+	go streamOutput(output, frames.Stream(ehttp.FrameStderr), stderrR)
+
+	exited := make(chan struct{})
+	go h.receiveProcessFrames(logger, buffered.Reader, process, stdinW, exited)
+
+	exitStatus, err := process.Wait()
+	close(exited)
+	stdoutW.Close()
+	stderrW.Close()
+	output.Wait()
+
+	exit := ehttp.ProcessExit{ExitStatus: exitStatus}
+	if err != nil {
+		logger.Error("failed-to-wait-for-process", err)
+		exit.Error = err.Error()
+	}
+
+	err = frames.WriteJSON(ehttp.FrameExit, exit)
+	if err != nil {
+		logger.Error("failed-to-send-exit-status", err)
+	}
+}
+
+// receiveProcessFrames forwards the stdin, resizes and signals of the client
+// to the process. The process is killed when the client goes away before it
+// exited.
+func (h *handler) receiveProcessFrames(logger lager.Logger, r io.Reader, process executor.Process, stdin *io.PipeWriter, exited <-chan struct{}) {
+	for {
+		kind, payload, err := ehttp.ReadFrame(r)
+		if err != nil {
+			stdin.Close()
+
+			select {
+			case <-exited:
+			default:
+				logger.Error("lost-client", err)
+				process.Signal(executor.ProcessSignalKill)
+			}
+			return
+		}
+
+		switch kind {
+		case ehttp.FrameStdin:
+			stdin.Write(payload)
+		case ehttp.FrameStdinClosed:
+			stdin.Close()
+		case ehttp.FrameResize:
+			size := executor.WindowSize{}
+			err = json.Unmarshal(payload, &size)
+			if err == nil {
+				err = process.Resize(size)
+			}
+			if err != nil {
+				logger.Error("failed-to-resize", err)
+			}
+		case ehttp.FrameSignal:
+			err = process.Signal(executor.ProcessSignal(payload))
+			if err != nil {
+				logger.Error("failed-to-signal", err)
+			}
+		}
+	}
+}
+
+func (h *handler) getBulkMetrics(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger.Session("get-bulk-metrics")
+
+	metrics, err := h.executorClient.GetBulkMetrics(logger)
+	if err != nil {
+		writeError(logger, w, err)
+		return
+	}
+	writeJSON(logger, w, http.StatusOK, metrics)
+}
+
+func (h *handler) remainingResources(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger.Session("remaining-resources")
+
+	resources, err := h.executorClient.RemainingResources(logger)
+	if err != nil {
+		writeError(logger, w, err)
+		return
+	}
+	writeJSON(logger, w, http.StatusOK, resources)
+}
+
+func (h *handler) totalResources(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger.Session("total-resources")
+
+	resources, err := h.executorClient.TotalResources(logger)
+	if err != nil {
+		writeError(logger, w, err)
+		return
+	}
+	writeJSON(logger, w, http.StatusOK, resources)
+}
+
+func (h *handler) volumeDrivers(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger.Session("volume-drivers")
+
+	drivers, err := h.executorClient.VolumeDrivers(logger)
+	if err != nil {
+		writeError(logger, w, err)
+		return
+	}
+	writeJSON(logger, w, http.StatusOK, drivers)
+}
+
+func (h *handler) events(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger.Session("events")
+
+	filter, err := ehttp.EventFilterFromQuery(r.URL.Query())
+	if err != nil {
+		writeBadRequest(logger, w, err)
+		return
+	}
+
+	since := r.Header.Get(ehttp.LastEventIDHeader)
+	if since == "" {
+		since = r.URL.Query().Get(ehttp.SinceQueryKey)
+	}
+
+	var source executor.EventSource
+	if since == "" {
+		source, err = h.executorClient.SubscribeToEventsWithFilter(logger, filter)
+	} else {
+		seq, parseErr := strconv.ParseUint(since, 10, 64)
+		if parseErr != nil {
+			writeBadRequest(logger, w, parseErr)
+			return
+		}
+		source, err = h.executorClient.SubscribeFrom(logger, seq, filter)
+	}
+	if err != nil {
+		writeError(logger, w, err)
+		return
+	}
+	defer source.Close()
+
+	flusher, ok := startEventStream(logger, w)
+	if !ok {
+		return
+	}
+
+	// unblock Next once the client goes away
+	go func() {
+		<-r.Context().Done()
+		source.Close()
+	}()
+
+	for {
+		event, err := source.Next()
+		if err != nil {
+			logger.Debug("event-source-closed", lager.Data{"error": err.Error()})
+			return
+		}
+
+		sseEvent, err := ehttp.NewSSEEvent(event)
+		if err != nil {
+			logger.Error("failed-to-encode-event", err, lager.Data{"event-type": event.EventType()})
+			continue
+		}
+
+		err = sseEvent.Write(w)
+		if err != nil {
+			logger.Debug("failed-to-write-event", lager.Data{"error": err.Error()})
+			return
+		}
+		flusher.Flush()
+	}
+}
+
+func (h *handler) subscribeToLogs(w http.ResponseWriter, r *http.Request) {
+	guid := r.FormValue(":guid")
+	logger := h.logger.Session("subscribe-to-logs", lager.Data{"guid": guid})
+
+	source, err := h.executorClient.SubscribeToLogs(logger, guid)
+	if err != nil {
+		writeError(logger, w, err)
+		return
+	}
+	defer source.Close()
+
+	flusher, ok := startEventStream(logger, w)
+	if !ok {
+		return
+	}
+
+	// unblock Next once the client goes away
+	go func() {
+		<-r.Context().Done()
+		source.Close()
+	}()
+
+	for {
+		line, err := source.Next()
+		if err != nil {
+			logger.Debug("log-source-closed", lager.Data{"error": err.Error()})
+			return
+		}
+
+		sseEvent, err := ehttp.NewSSELogLine(line)
+		if err != nil {
+			logger.Error("failed-to-encode-log-line", err)
+			continue
+		}
+
+		err = sseEvent.Write(w)
+		if err != nil {
+			logger.Debug("failed-to-write-log-line", lager.Data{"error": err.Error()})
+			return
+		}
+		flusher.Flush()
+	}
+}
+
+func (h *handler) getRecentLogs(w http.ResponseWriter, r *http.Request) {
+	guid := r.FormValue(":guid")
+	logger := h.logger.Session("get-recent-logs", lager.Data{"guid": guid})
+
+	lines, err := h.executorClient.GetRecentLogs(logger, guid)
+	if err != nil {
+		writeError(logger, w, err)
+		return
+	}
+	writeJSON(logger, w, http.StatusOK, lines)
+}
+
+func (h *handler) healthy(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger.Session("healthy")
+
+	writeJSON(logger, w, http.StatusOK, ehttp.HealthResponse{Healthy: h.executorClient.Healthy(logger)})
+}
+
+func (h *handler) setHealthy(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger.Session("set-healthy")
+
+	health := ehttp.HealthResponse{}
+	if !decodeRequest(logger, w, r, &health) {
+		return
+	}
+
+	h.executorClient.SetHealthy(logger, health.Healthy)
+	writeJSON(logger, w, http.StatusOK, health)
+}
@@ -0,0 +1,174 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"sync"
+
+	"code.cloudfoundry.org/executor"
+	ehttp "code.cloudfoundry.org/executor/http"
+	"code.cloudfoundry.org/lager"
+)
+
+var (
+	errStreamingUnsupported = errors.New("streaming unsupported")
+	errHijackingUnsupported = errors.New("hijacking unsupported")
+)
+
+func decodeRequest(logger lager.Logger, w http.ResponseWriter, r *http.Request, request interface{}) bool {
+	err := json.NewDecoder(r.Body).Decode(request)
+	if err != nil {
+		writeBadRequest(logger, w, err)
+		return false
+	}
+	return true
+}
+
+func writeResult(logger lager.Logger, w http.ResponseWriter, err error) {
+	if err != nil {
+		writeError(logger, w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeJSON(logger lager.Logger, w http.ResponseWriter, status int, response interface{}) {
+	payload, err := json.Marshal(response)
+	if err != nil {
+		logger.Error("failed-to-marshal-response", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(payload)
+}
+
+func writeBadRequest(logger lager.Logger, w http.ResponseWriter, err error) {
+	logger.Error("invalid-request", err)
+	writeJSON(logger, w, http.StatusBadRequest, ehttp.ErrorResponse{Message: err.Error()})
+}
+
+// writeError names the registered executor.Error in the response, so that
+// the client returns that same error.
+func writeError(logger lager.Logger, w http.ResponseWriter, err error) {
+	logger.Error("request-failed", err)
+
+	response := ehttp.ErrorResponse{Message: err.Error()}
+	if executorErr, ok := err.(executor.Error); ok && executor.Errors[executorErr.Name()] == executorErr {
+		response.Name = executorErr.Name()
+		w.Header().Set(ehttp.ErrorHeader, response.Name)
+	}
+
+	writeJSON(logger, w, statusForError(err), response)
+}
+
+// writeHijackedError writes the response writeError would have written to a
+// hijacked connection.
+func writeHijackedError(logger lager.Logger, conn net.Conn, err error) {
+	recorded := &responseRecorder{header: http.Header{}, status: http.StatusOK}
+	writeError(logger, recorded, err)
+
+	response := &http.Response{
+		StatusCode:    recorded.status,
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        recorded.header,
+		ContentLength: int64(recorded.body.Len()),
+		Body:          ioutil.NopCloser(&recorded.body),
+		Close:         true,
+	}
+
+	writeErr := response.Write(conn)
+	if writeErr != nil {
+		logger.Error("failed-to-write-response", writeErr)
+	}
+}
+
+type responseRecorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (r *responseRecorder) Header() http.Header         { return r.header }
+func (r *responseRecorder) WriteHeader(status int)      { r.status = status }
+func (r *responseRecorder) Write(p []byte) (int, error) { return r.body.Write(p) }
+
+// streamOutput copies the output of a process until it ends. Once the copy
+// fails the output is discarded, so that the process is never blocked on it.
+func streamOutput(wg *sync.WaitGroup, dest io.Writer, src io.Reader) {
+	defer wg.Done()
+
+	_, err := io.Copy(dest, src)
+	if err != nil {
+		io.Copy(ioutil.Discard, src)
+	}
+}
+
+func statusForError(err error) int {
+	switch err {
+	case executor.ErrContainerNotFound:
+		return http.StatusNotFound
+	case executor.ErrContainerGuidNotAvailable,
+		executor.ErrContainerNotCompleted,
+		executor.ErrContainerNotRunning,
+		executor.ErrInvalidTransition:
+		return http.StatusConflict
+	case executor.ErrEventsEvicted:
+		return http.StatusGone
+	case executor.ErrPauseNotSupported,
+		executor.ErrLimitsUpdateNotSupported,
+		executor.ErrLogTapNotEnabled,
+		executor.ErrRecentLogsNotEnabled:
+		return http.StatusNotImplemented
+	}
+
+	if _, ok := err.(executor.Error); ok {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// startEventStream sends the headers of a server-sent events stream.
+func startEventStream(logger lager.Logger, w http.ResponseWriter) (http.Flusher, bool) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(logger, w, errStreamingUnsupported)
+		return nil, false
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	return flusher, true
+}
+
+// lazyStreamWriter only sends the status once there is something to stream,
+// so that a request failing early still gets an error response.
+type lazyStreamWriter struct {
+	w       http.ResponseWriter
+	started bool
+}
+
+func (l *lazyStreamWriter) start() {
+	if !l.started {
+		l.started = true
+		l.w.Header().Set("Content-Type", "application/octet-stream")
+		l.w.WriteHeader(http.StatusOK)
+	}
+}
+
+func (l *lazyStreamWriter) Write(p []byte) (int, error) {
+	l.start()
+	return l.w.Write(p)
+}
@@ -0,0 +1,148 @@
+package server
+
+import (
+	"crypto/tls"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"code.cloudfoundry.org/executor"
+	ehttp "code.cloudfoundry.org/executor/http"
+	"code.cloudfoundry.org/lager"
+	"github.com/tedsuo/ifrit"
+	"github.com/tedsuo/rata"
+)
+
+// New returns a handler serving the executor.Client API.
+func New(logger lager.Logger, executorClient executor.Client) (http.Handler, error) {
+	h := &handler{
+		logger:         logger.Session("executor-api"),
+		executorClient: executorClient,
+	}
+
+	return rata.NewRouter(ehttp.Routes, rata.Handlers{
+		ehttp.Ping: http.HandlerFunc(h.ping),
+
+		ehttp.AllocateContainers:    http.HandlerFunc(h.allocateContainers),
+		ehttp.GetContainer:          http.HandlerFunc(h.getContainer),
+		ehttp.RunContainer:          http.HandlerFunc(h.runContainer),
+		ehttp.StopContainer:         http.HandlerFunc(h.stopContainer),
+		ehttp.UpdateContainerLimits: http.HandlerFunc(h.updateContainerLimits),
+		ehttp.PauseContainer:        http.HandlerFunc(h.pauseContainer),
+		ehttp.ResumeContainer:       http.HandlerFunc(h.resumeContainer),
+		ehttp.DeleteContainer:       http.HandlerFunc(h.deleteContainer),
+		ehttp.ListContainers:        http.HandlerFunc(h.listContainers),
+		ehttp.GetFiles:              http.HandlerFunc(h.getFiles),
+		ehttp.PutFiles:              http.HandlerFunc(h.putFiles),
+		ehttp.ExportContainerData:   http.HandlerFunc(h.exportContainerData),
+		ehttp.ImportContainerData:   http.HandlerFunc(h.importContainerData),
+		ehttp.RunProcess:            http.HandlerFunc(h.runProcess),
+		ehttp.SubscribeToLogs:       http.HandlerFunc(h.subscribeToLogs),
+		ehttp.GetRecentLogs:         http.HandlerFunc(h.getRecentLogs),
+
+		ehttp.GetBulkMetrics:     http.HandlerFunc(h.getBulkMetrics),
+		ehttp.RemainingResources: http.HandlerFunc(h.remainingResources),
+		ehttp.TotalResources:     http.HandlerFunc(h.totalResources),
+		ehttp.VolumeDrivers:      http.HandlerFunc(h.volumeDrivers),
+
+		ehttp.Events: http.HandlerFunc(h.events),
+
+		ehttp.Healthy:    http.HandlerFunc(h.healthy),
+		ehttp.SetHealthy: http.HandlerFunc(h.setHealthy),
+	})
+}
+
+// NewRunner serves handler on the given network ("tcp" or "unix") and
+// address until it is signalled. A socket file left behind by a previous
+// run is removed first, and the socket is only accessible to its owner. When
+// tlsConfig is not nil the API is served over TLS.
+func NewRunner(logger lager.Logger, network, address string, tlsConfig *tls.Config, handler http.Handler) ifrit.Runner {
+	return ifrit.RunFunc(func(signals <-chan os.Signal, ready chan<- struct{}) error {
+		logger := logger.Session("executor-api-server", lager.Data{"network": network, "address": address})
+
+		var listener net.Listener
+		var err error
+		if network == "unix" {
+			listener, err = listenUnix(logger, address)
+			if err != nil {
+				return err
+			}
+			defer os.Remove(address)
+		} else {
+			listener, err = net.Listen(network, address)
+			if err != nil {
+				logger.Error("failed-to-listen", err)
+				return err
+			}
+		}
+
+		if tlsConfig != nil {
+			listener = tls.NewListener(listener, tlsConfig)
+		}
+
+		server := &http.Server{Handler: handler}
+		errCh := make(chan error, 1)
+		go func() {
+			errCh <- server.Serve(listener)
+		}()
+
+		logger.Info("started")
+		close(ready)
+
+		select {
+		case <-signals:
+			logger.Info("stopping")
+			// event streams are long lived, so the server is not drained
+			return server.Close()
+		case err := <-errCh:
+			logger.Error("failed-to-serve", err)
+			return err
+		}
+	})
+}
+
+// listenUnix listens on a socket at address that only its owner can connect
+// to. The socket is created and restricted inside a private directory and
+// only then moved into place, so it is never reachable by other users.
+func listenUnix(logger lager.Logger, address string) (net.Listener, error) {
+	err := os.Remove(address)
+	if err != nil && !os.IsNotExist(err) {
+		logger.Error("failed-to-remove-socket", err)
+		return nil, err
+	}
+
+	// TempDir creates the directory with mode 0700
+	dir, err := ioutil.TempDir(filepath.Dir(address), ".executor-api-")
+	if err != nil {
+		logger.Error("failed-to-create-socket-dir", err)
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, filepath.Base(address))
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		logger.Error("failed-to-listen", err)
+		return nil, err
+	}
+	// the socket is moved away from path, so it is removed by the runner
+	listener.(*net.UnixListener).SetUnlinkOnClose(false)
+
+	err = os.Chmod(path, 0600)
+	if err != nil {
+		logger.Error("failed-to-restrict-socket", err)
+		listener.Close()
+		return nil, err
+	}
+
+	err = os.Rename(path, address)
+	if err != nil {
+		logger.Error("failed-to-move-socket", err)
+		listener.Close()
+		return nil, err
+	}
+
+	return listener, nil
+}
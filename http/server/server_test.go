@@ -0,0 +1,434 @@
+package server_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+
+	"code.cloudfoundry.org/executor"
+	"code.cloudfoundry.org/executor/fakes"
+	ehttp "code.cloudfoundry.org/executor/http"
+	"code.cloudfoundry.org/executor/http/server"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/lager/lagertest"
+	"github.com/tedsuo/ifrit"
+	"github.com/tedsuo/ifrit/ginkgomon"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Server", func() {
+	var (
+		logger         *lagertest.TestLogger
+		executorClient *fakes.FakeClient
+		httpServer     *httptest.Server
+		client         executor.Client
+	)
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("test")
+		executorClient = new(fakes.FakeClient)
+
+		handler, err := server.New(logger, executorClient)
+		Expect(err).NotTo(HaveOccurred())
+
+		httpServer = httptest.NewServer(handler)
+		client = ehttp.NewClientWithHTTPClients(http.DefaultClient, http.DefaultClient, httpServer.URL)
+	})
+
+	AfterEach(func() {
+		httpServer.Close()
+	})
+
+	Describe("Ping", func() {
+		It("pings the executor", func() {
+			Expect(client.Ping(logger)).To(Succeed())
+			Expect(executorClient.PingCallCount()).To(Equal(1))
+		})
+
+		It("returns the failure", func() {
+			executorClient.PingReturns(errors.New("garden is down"))
+			Expect(client.Ping(logger)).To(MatchError(ContainSubstring("garden is down")))
+		})
+	})
+
+	Describe("GetContainer", func() {
+		It("returns the container", func() {
+			executorClient.GetContainerReturns(executor.Container{Guid: "some-guid", State: executor.StateRunning}, nil)
+
+			container, err := client.GetContainer(logger, "some-guid")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(container.Guid).To(Equal("some-guid"))
+			Expect(container.State).To(Equal(executor.StateRunning))
+
+			_, guid := executorClient.GetContainerArgsForCall(0)
+			Expect(guid).To(Equal("some-guid"))
+		})
+
+		It("returns registered executor errors as themselves", func() {
+			executorClient.GetContainerReturns(executor.Container{}, executor.ErrContainerNotFound)
+
+			_, err := client.GetContainer(logger, "some-guid")
+			Expect(err).To(Equal(executor.ErrContainerNotFound))
+		})
+	})
+
+	Describe("AllocateContainers", func() {
+		It("returns the allocation failures", func() {
+			requests := []executor.AllocationRequest{{Guid: "guid-1"}, {Guid: "guid-2"}}
+			executorClient.AllocateContainersReturns([]executor.AllocationFailure{
+				executor.NewAllocationFailureFromError(&requests[1], executor.ErrInsufficientResourcesAvailable),
+			})
+
+			failures := client.AllocateContainers(logger, requests)
+			Expect(failures).To(HaveLen(1))
+			Expect(failures[0].Guid).To(Equal("guid-2"))
+			Expect(failures[0].Reason).To(Equal(executor.AllocationFailureReasonInsufficientResources))
+
+			_, sent := executorClient.AllocateContainersArgsForCall(0)
+			Expect(sent).To(HaveLen(2))
+		})
+	})
+
+	Describe("RunContainer", func() {
+		It("runs the container", func() {
+			request := &executor.RunRequest{Guid: "some-guid", Tags: executor.Tags{"a": "b"}}
+			Expect(client.RunContainer(logger, request)).To(Succeed())
+
+			_, sent := executorClient.RunContainerArgsForCall(0)
+			Expect(sent.Guid).To(Equal("some-guid"))
+			Expect(sent.Tags).To(Equal(executor.Tags{"a": "b"}))
+		})
+
+		It("returns registered executor errors as themselves", func() {
+			executorClient.RunContainerReturns(executor.ErrInvalidTransition)
+			Expect(client.RunContainer(logger, &executor.RunRequest{Guid: "some-guid"})).To(Equal(executor.ErrInvalidTransition))
+		})
+	})
+
+	Describe("UpdateContainerLimits", func() {
+		It("sends the new limits", func() {
+			resource := executor.Resource{MemoryMB: 512, DiskMB: 1024}
+			Expect(client.UpdateContainerLimits(logger, "some-guid", resource)).To(Succeed())
+
+			_, guid, sent := executorClient.UpdateContainerLimitsArgsForCall(0)
+			Expect(guid).To(Equal("some-guid"))
+			Expect(sent).To(Equal(resource))
+		})
+	})
+
+	Describe("GetFiles", func() {
+		It("streams the files", func() {
+			executorClient.GetFilesReturns(ioutil.NopCloser(bytes.NewBufferString("some-tar")), nil)
+
+			stream, err := client.GetFiles(logger, "some-guid", "/some/path")
+			Expect(err).NotTo(HaveOccurred())
+			defer stream.Close()
+
+			content, err := ioutil.ReadAll(stream)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal("some-tar"))
+
+			_, guid, path := executorClient.GetFilesArgsForCall(0)
+			Expect(guid).To(Equal("some-guid"))
+			Expect(path).To(Equal("/some/path"))
+		})
+	})
+
+	Describe("PutFiles", func() {
+		It("sends the files", func() {
+			var received []byte
+			executorClient.PutFilesStub = func(_ lager.Logger, _, _ string, tarStream io.Reader, _ string) error {
+				var err error
+				received, err = ioutil.ReadAll(tarStream)
+				return err
+			}
+
+			Expect(client.PutFiles(logger, "some-guid", "/some/path", bytes.NewBufferString("some-tar"), "vcap")).To(Succeed())
+			Expect(string(received)).To(Equal("some-tar"))
+
+			_, guid, path, _, user := executorClient.PutFilesArgsForCall(0)
+			Expect(guid).To(Equal("some-guid"))
+			Expect(path).To(Equal("/some/path"))
+			Expect(user).To(Equal("vcap"))
+		})
+
+		It("returns registered executor errors as themselves", func() {
+			executorClient.PutFilesReturns(executor.ErrContainerNotRunning)
+			Expect(client.PutFiles(logger, "some-guid", "/some/path", &bytes.Buffer{}, "")).To(Equal(executor.ErrContainerNotRunning))
+		})
+	})
+
+	Describe("ExportContainerData", func() {
+		It("streams the exported data", func() {
+			executorClient.ExportContainerDataStub = func(_ lager.Logger, _ string, dest io.Writer) error {
+				_, err := dest.Write([]byte("some-export"))
+				return err
+			}
+
+			dest := &bytes.Buffer{}
+			Expect(client.ExportContainerData(logger, "some-guid", dest)).To(Succeed())
+			Expect(dest.String()).To(Equal("some-export"))
+		})
+
+		It("returns the failure when nothing was streamed", func() {
+			executorClient.ExportContainerDataReturns(executor.ErrContainerNotExportable)
+			Expect(client.ExportContainerData(logger, "some-guid", &bytes.Buffer{})).To(Equal(executor.ErrContainerNotExportable))
+		})
+	})
+
+	Describe("ImportContainerData", func() {
+		It("sends the exported data", func() {
+			var received []byte
+			executorClient.ImportContainerDataStub = func(_ lager.Logger, _ string, src io.Reader) error {
+				var err error
+				received, err = ioutil.ReadAll(src)
+				return err
+			}
+
+			Expect(client.ImportContainerData(logger, "some-guid", bytes.NewBufferString("some-export"))).To(Succeed())
+			Expect(string(received)).To(Equal("some-export"))
+		})
+	})
+
+	Describe("RunProcess", func() {
+		var process *fakes.FakeProcess
+
+		BeforeEach(func() {
+			process = new(fakes.FakeProcess)
+			process.IDReturns("some-process")
+
+			exited := make(chan struct{})
+			process.WaitStub = func() (int, error) {
+				<-exited
+				return 7, nil
+			}
+
+			executorClient.RunProcessStub = func(_ lager.Logger, _ string, _ executor.ProcessSpec, processIO executor.ProcessIO) (executor.Process, error) {
+				go func() {
+					defer close(exited)
+					input, _ := ioutil.ReadAll(processIO.Stdin)
+					processIO.Stdout.Write([]byte("out:" + string(input)))
+					processIO.Stderr.Write([]byte("err"))
+				}()
+				return process, nil
+			}
+		})
+
+		It("streams the stdio of the process and returns its exit status", func() {
+			stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+			spec := executor.ProcessSpec{Path: "cat", TTY: &executor.TTYSpec{}}
+
+			remote, err := client.RunProcess(logger, "some-guid", spec, executor.ProcessIO{
+				Stdin:  bytes.NewBufferString("some-input"),
+				Stdout: stdout,
+				Stderr: stderr,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(remote.ID()).To(Equal("some-process"))
+
+			Expect(remote.Wait()).To(Equal(7))
+			Expect(stdout.String()).To(Equal("out:some-input"))
+			Expect(stderr.String()).To(Equal("err"))
+
+			_, guid, sentSpec, _ := executorClient.RunProcessArgsForCall(0)
+			Expect(guid).To(Equal("some-guid"))
+			Expect(sentSpec).To(Equal(spec))
+		})
+
+		It("forwards resizes and signals", func() {
+			stdin, stdinWriter := io.Pipe()
+			remote, err := client.RunProcess(logger, "some-guid", executor.ProcessSpec{Path: "cat"}, executor.ProcessIO{
+				Stdin: stdin,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(remote.Resize(executor.WindowSize{Columns: 80, Rows: 24})).To(Succeed())
+			Eventually(process.ResizeCallCount).Should(Equal(1))
+			Expect(process.ResizeArgsForCall(0)).To(Equal(executor.WindowSize{Columns: 80, Rows: 24}))
+
+			Expect(remote.Signal(executor.ProcessSignalTerminate)).To(Succeed())
+			Eventually(process.SignalCallCount).Should(Equal(1))
+			Expect(process.SignalArgsForCall(0)).To(Equal(executor.ProcessSignalTerminate))
+
+			Expect(remote.Signal(executor.ProcessSignal("hup"))).To(Equal(executor.ErrInvalidProcessSignal))
+
+			stdinWriter.Close()
+			Expect(remote.Wait()).To(Equal(7))
+		})
+
+		It("returns registered executor errors as themselves", func() {
+			executorClient.RunProcessStub = nil
+			executorClient.RunProcessReturns(nil, executor.ErrContainerNotRunning)
+
+			_, err := client.RunProcess(logger, "some-guid", executor.ProcessSpec{Path: "cat"}, executor.ProcessIO{})
+			Expect(err).To(Equal(executor.ErrContainerNotRunning))
+		})
+	})
+
+	Describe("events", func() {
+		var eventSource *fakes.FakeEventSource
+
+		BeforeEach(func() {
+			eventSource = new(fakes.FakeEventSource)
+			eventSource.NextReturnsOnCall(0, executor.NewContainerRunningEvent(executor.Container{Guid: "some-guid"}).WithMetadata(executor.EventMetadata{Seq: 7}), nil)
+			eventSource.NextReturnsOnCall(1, nil, errors.New("closed"))
+		})
+
+		It("streams the events matching the filter", func() {
+			executorClient.SubscribeToEventsWithFilterReturns(eventSource, nil)
+
+			filter := executor.EventFilter{Types: []executor.EventType{executor.EventTypeContainerRunning}}
+			source, err := client.SubscribeToEventsWithFilter(logger, filter)
+			Expect(err).NotTo(HaveOccurred())
+			defer source.Close()
+
+			event, err := source.Next()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(event.EventType()).To(Equal(executor.EventTypeContainerRunning))
+			Expect(event.Sequence()).To(Equal(uint64(7)))
+			Expect(event.(executor.LifecycleEvent).Container().Guid).To(Equal("some-guid"))
+
+			_, err = source.Next()
+			Expect(err).To(HaveOccurred())
+
+			_, sentFilter := executorClient.SubscribeToEventsWithFilterArgsForCall(0)
+			Expect(sentFilter).To(Equal(filter))
+			Eventually(eventSource.CloseCallCount).Should(BeNumerically(">", 0))
+		})
+
+		It("resumes from a sequence number", func() {
+			executorClient.SubscribeFromReturns(eventSource, nil)
+
+			source, err := client.SubscribeFrom(logger, 6, executor.EventFilter{})
+			Expect(err).NotTo(HaveOccurred())
+			defer source.Close()
+
+			event, err := source.Next()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(event.Sequence()).To(Equal(uint64(7)))
+
+			_, seq, _ := executorClient.SubscribeFromArgsForCall(0)
+			Expect(seq).To(Equal(uint64(6)))
+		})
+
+		It("returns registered executor errors as themselves", func() {
+			executorClient.SubscribeFromReturns(nil, executor.ErrEventsEvicted)
+
+			_, err := client.SubscribeFrom(logger, 6, executor.EventFilter{})
+			Expect(err).To(Equal(executor.ErrEventsEvicted))
+		})
+	})
+
+	Describe("logs", func() {
+		It("streams the log lines of the container", func() {
+			logSource := new(fakes.FakeLogSource)
+			logSource.NextReturnsOnCall(0, executor.LogLine{SourceName: "APP", Stream: executor.LogStreamStderr, Message: "hello"}, nil)
+			logSource.NextReturnsOnCall(1, executor.LogLine{}, errors.New("closed"))
+			executorClient.SubscribeToLogsReturns(logSource, nil)
+
+			source, err := client.SubscribeToLogs(logger, "some-guid")
+			Expect(err).NotTo(HaveOccurred())
+			defer source.Close()
+
+			line, err := source.Next()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(line).To(Equal(executor.LogLine{SourceName: "APP", Stream: executor.LogStreamStderr, Message: "hello"}))
+
+			_, err = source.Next()
+			Expect(err).To(HaveOccurred())
+
+			_, guid := executorClient.SubscribeToLogsArgsForCall(0)
+			Expect(guid).To(Equal("some-guid"))
+			Eventually(logSource.CloseCallCount).Should(BeNumerically(">", 0))
+		})
+
+		It("returns the recent logs of the container", func() {
+			executorClient.GetRecentLogsReturns([]executor.LogLine{{SourceName: "APP", Message: "crashed"}}, nil)
+
+			lines, err := client.GetRecentLogs(logger, "some-guid")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(lines).To(Equal([]executor.LogLine{{SourceName: "APP", Message: "crashed"}}))
+
+			_, guid := executorClient.GetRecentLogsArgsForCall(0)
+			Expect(guid).To(Equal("some-guid"))
+		})
+
+		It("returns registered executor errors as themselves", func() {
+			executorClient.SubscribeToLogsReturns(nil, executor.ErrLogTapNotEnabled)
+
+			_, err := client.SubscribeToLogs(logger, "some-guid")
+			Expect(err).To(Equal(executor.ErrLogTapNotEnabled))
+		})
+	})
+
+	Describe("health", func() {
+		It("gets and sets the health of the executor", func() {
+			executorClient.HealthyReturns(true)
+			Expect(client.Healthy(logger)).To(BeTrue())
+
+			client.SetHealthy(logger, false)
+			Expect(executorClient.SetHealthyCallCount()).To(Equal(1))
+			_, healthy := executorClient.SetHealthyArgsForCall(0)
+			Expect(healthy).To(BeFalse())
+		})
+	})
+
+	Describe("NewRunner", func() {
+		var (
+			socketPath string
+			process    ifrit.Process
+		)
+
+		BeforeEach(func() {
+			tmpDir, err := ioutil.TempDir("", "executor-api")
+			Expect(err).NotTo(HaveOccurred())
+			socketPath = filepath.Join(tmpDir, "executor.sock")
+
+			// left behind by a previous run
+			Expect(ioutil.WriteFile(socketPath, nil, 0600)).To(Succeed())
+
+			handler, err := server.New(logger, executorClient)
+			Expect(err).NotTo(HaveOccurred())
+			process = ginkgomon.Invoke(server.NewRunner(logger, "unix", socketPath, nil, handler))
+		})
+
+		AfterEach(func() {
+			ginkgomon.Interrupt(process)
+			os.RemoveAll(filepath.Dir(socketPath))
+		})
+
+		It("serves the API on a unix socket", func() {
+			executorClient.ListContainersReturns([]executor.Container{{Guid: "some-guid"}}, nil)
+
+			containers, err := ehttp.NewClient("unix", socketPath).ListContainers(logger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(containers).To(HaveLen(1))
+			Expect(containers[0].Guid).To(Equal("some-guid"))
+		})
+
+		It("only lets its owner use the socket", func() {
+			info, err := os.Stat(socketPath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.Mode().Perm()).To(Equal(os.FileMode(0600)))
+		})
+
+		It("leaves only the socket in its directory", func() {
+			entries, err := ioutil.ReadDir(filepath.Dir(socketPath))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(entries).To(HaveLen(1))
+			Expect(entries[0].Name()).To(Equal("executor.sock"))
+		})
+
+		It("removes the socket when it stops", func() {
+			ginkgomon.Interrupt(process)
+			Expect(socketPath).NotTo(BeAnExistingFile())
+		})
+	})
+})
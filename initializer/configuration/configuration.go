@@ -3,6 +3,7 @@ package configuration
 import (
 	"fmt"
 	"net/url"
+	"runtime"
 	"strconv"
 
 	"code.cloudfoundry.org/executor"
@@ -15,18 +16,24 @@ import (
 
 const (
 	Automatic = "auto"
+
+	cpuSharesPerCore = 1024
 )
 
 var (
 	ErrMemoryFlagInvalid       = fmt.Errorf("memory limit must be a positive number or '%s'", Automatic)
 	ErrDiskFlagInvalid         = fmt.Errorf("disk limit must be a positive number or '%s'", Automatic)
 	ErrAutoDiskCapacityInvalid = fmt.Errorf("auto disk limit must result in a positive number")
+	ErrCPUSharesFlagInvalid    = fmt.Errorf("cpu shares limit must be a non-negative number or '%s'", Automatic)
+	ErrMaxPidsFlagInvalid      = fmt.Errorf("max pids limit must be a positive number or '%s'", Automatic)
 )
 
 func ConfigureCapacity(
 	gardenClient garden_client.Client,
 	memoryMBFlag string,
 	diskMBFlag string,
+	cpuSharesFlag string,
+	maxPidsFlag string,
 	maxCacheSizeInBytes uint64,
 	autoDiskMBOverhead int,
 	useSchedulableDiskSize bool,
@@ -46,13 +53,37 @@ func ConfigureCapacity(
 		return executor.ExecutorResources{}, err
 	}
 
+	cpuShares, err := cpuSharesInTotal(cpuSharesFlag)
+	if err != nil {
+		return executor.ExecutorResources{}, err
+	}
+
+	maxPids, err := maxPidsInTotal(maxPidsFlag)
+	if err != nil {
+		return executor.ExecutorResources{}, err
+	}
+
 	return executor.ExecutorResources{
 		MemoryMB:   memory,
 		DiskMB:     disk,
 		Containers: int(gardenCapacity.MaxContainers) - 1,
+		MaxPids:    maxPids,
+		CPUShares:  cpuShares,
 	}, nil
 }
 
+// ApplyOvercommit scales the advertised memory and disk capacity by the given
+// ratios. Ratios of zero or less leave the capacity untouched.
+func ApplyOvercommit(capacity executor.ExecutorResources, memoryRatio, diskRatio float64) executor.ExecutorResources {
+	if memoryRatio > 0 {
+		capacity.MemoryMB = int(float64(capacity.MemoryMB) * memoryRatio)
+	}
+	if diskRatio > 0 {
+		capacity.DiskMB = int(float64(capacity.DiskMB) * diskRatio)
+	}
+	return capacity
+}
+
 //go:generate counterfeiter -o configurationfakes/fake_rootfssizer.go . RootFSSizer
 type RootFSSizer interface {
 	RootFSSizeFromPath(path string) uint64
@@ -145,6 +176,37 @@ func diskInMB(capacity garden.Capacity, diskMBFlag string, useSchedulableDiskSiz
 	}
 }
 
+// an unset cpu shares limit is 0, which does not limit the cpu shares of the
+// cell, while an automatic limit gives each core 1024 shares
+func cpuSharesInTotal(cpuSharesFlag string) (int, error) {
+	if cpuSharesFlag == "" {
+		return 0, nil
+	}
+	if cpuSharesFlag == Automatic {
+		return runtime.NumCPU() * cpuSharesPerCore, nil
+	}
+
+	cpuShares, err := strconv.Atoi(cpuSharesFlag)
+	if err != nil || cpuShares < 0 {
+		return 0, ErrCPUSharesFlagInvalid
+	}
+	return cpuShares, nil
+}
+
+// an unset max pids limit is treated as automatic, whose limit depends on the
+// platform
+func maxPidsInTotal(maxPidsFlag string) (int, error) {
+	if maxPidsFlag == Automatic || maxPidsFlag == "" {
+		return autoMaxPids()
+	}
+
+	maxPids, err := strconv.Atoi(maxPidsFlag)
+	if err != nil || maxPids <= 0 {
+		return 0, ErrMaxPidsFlagInvalid
+	}
+	return maxPids, nil
+}
+
 type rootFSSizeMap struct {
 	rootFSSizes map[string]uint64
 }
@@ -2,6 +2,8 @@ package configuration_test
 
 import (
 	"errors"
+	"math"
+	"runtime"
 	"strings"
 
 	"code.cloudfoundry.org/executor"
@@ -29,6 +31,7 @@ var _ = Describe("configuration", func() {
 			capacity               executor.ExecutorResources
 			err                    error
 			memLimit, diskLimit    string
+			cpuShares, maxPids     string
 			maxCacheSizeInBytes    uint64
 			autoDiskMBOverhead     int
 			useSchedulableDiskSize bool
@@ -39,11 +42,13 @@ var _ = Describe("configuration", func() {
 			autoDiskMBOverhead = 0
 			memLimit = ""
 			diskLimit = ""
+			cpuShares = ""
+			maxPids = "32768"
 			useSchedulableDiskSize = false
 		})
 
 		JustBeforeEach(func() {
-			capacity, err = configuration.ConfigureCapacity(gardenClient, memLimit, diskLimit, maxCacheSizeInBytes, autoDiskMBOverhead, useSchedulableDiskSize)
+			capacity, err = configuration.ConfigureCapacity(gardenClient, memLimit, diskLimit, cpuShares, maxPids, maxCacheSizeInBytes, autoDiskMBOverhead, useSchedulableDiskSize)
 		})
 
 		Context("when getting the capacity fails", func() {
@@ -253,6 +258,119 @@ var _ = Describe("configuration", func() {
 				})
 			})
 
+			Describe("CPU Shares Limit", func() {
+				Context("when the cpu shares flag is 'auto'", func() {
+					BeforeEach(func() {
+						cpuShares = "auto"
+					})
+
+					It("gives each core 1024 shares", func() {
+						Expect(err).NotTo(HaveOccurred())
+						Expect(capacity.CPUShares).To(Equal(runtime.NumCPU() * 1024))
+					})
+				})
+
+				Context("when the cpu shares flag is not set", func() {
+					It("does not limit the cpu shares", func() {
+						Expect(err).NotTo(HaveOccurred())
+						Expect(capacity.CPUShares).To(Equal(0))
+					})
+				})
+
+				Context("when the cpu shares flag is 0", func() {
+					BeforeEach(func() {
+						cpuShares = "0"
+					})
+
+					It("does not limit the cpu shares", func() {
+						Expect(err).NotTo(HaveOccurred())
+						Expect(capacity.CPUShares).To(Equal(0))
+					})
+				})
+
+				Context("when the cpu shares flag is a positive number", func() {
+					BeforeEach(func() {
+						cpuShares = "4096"
+					})
+
+					It("uses that number", func() {
+						Expect(err).NotTo(HaveOccurred())
+						Expect(capacity.CPUShares).To(Equal(4096))
+					})
+				})
+
+				Context("when the cpu shares flag is negative", func() {
+					BeforeEach(func() {
+						cpuShares = "-1"
+					})
+
+					It("returns an error", func() {
+						Expect(err).To(Equal(configuration.ErrCPUSharesFlagInvalid))
+					})
+				})
+			})
+
+			Describe("Max Pids Limit", func() {
+				Context("when the max pids flag is 'auto'", func() {
+					BeforeEach(func() {
+						if runtime.GOOS != "linux" {
+							Skip("pid_max is only available on linux")
+						}
+						maxPids = "auto"
+					})
+
+					It("uses the kernel's pid_max", func() {
+						Expect(err).NotTo(HaveOccurred())
+						Expect(capacity.MaxPids).To(BeNumerically(">", 0))
+					})
+				})
+
+				Context("when the max pids flag is 'auto' on windows", func() {
+					BeforeEach(func() {
+						if runtime.GOOS != "windows" {
+							Skip("only windows has no pid_max")
+						}
+						maxPids = "auto"
+					})
+
+					It("does not limit the pids", func() {
+						Expect(err).NotTo(HaveOccurred())
+						Expect(capacity.MaxPids).To(Equal(math.MaxInt32))
+					})
+				})
+
+				Context("when the max pids flag is a positive number", func() {
+					BeforeEach(func() {
+						maxPids = "100000"
+					})
+
+					It("uses that number", func() {
+						Expect(err).NotTo(HaveOccurred())
+						Expect(capacity.MaxPids).To(Equal(100000))
+					})
+				})
+
+				Context("when the max pids flag is not a number", func() {
+					BeforeEach(func() {
+						maxPids = "stuff"
+					})
+
+					It("returns an error", func() {
+						Expect(err).To(Equal(configuration.ErrMaxPidsFlagInvalid))
+					})
+				})
+
+				Context("when the max pids flag is not positive", func() {
+					BeforeEach(func() {
+						maxPids = "0"
+					})
+
+					It("returns an error", func() {
+						Expect(err).To(Equal(configuration.ErrMaxPidsFlagInvalid))
+					})
+				})
+			})
+
 			Describe("Containers Limit", func() {
 				It("uses the garden server's max containers", func() {
 					Expect(capacity.Containers).To(Equal(4))
@@ -261,6 +379,34 @@ var _ = Describe("configuration", func() {
 		})
 	})
 
+	Describe("ApplyOvercommit", func() {
+		var capacity executor.ExecutorResources
+
+		BeforeEach(func() {
+			capacity = executor.ExecutorResources{
+				MemoryMB:   1024,
+				DiskMB:     2048,
+				Containers: 10,
+				MaxPids:    100,
+				CPUShares:  2048,
+			}
+		})
+
+		It("scales memory and disk by the given ratios", func() {
+			Expect(configuration.ApplyOvercommit(capacity, 1.5, 2)).To(Equal(executor.ExecutorResources{
+				MemoryMB:   1536,
+				DiskMB:     4096,
+				Containers: 10,
+				MaxPids:    100,
+				CPUShares:  2048,
+			}))
+		})
+
+		It("leaves the capacity untouched when the ratios are not set", func() {
+			Expect(configuration.ApplyOvercommit(capacity, 0, 0)).To(Equal(capacity))
+		})
+	})
+
 	Describe("GetRootFSSizes", func() {
 		var (
 			logger   lager.Logger
@@ -0,0 +1,20 @@
+// +build !windows
+
+package configuration
+
+import (
+	"io/ioutil"
+	"strconv"
+	"strings"
+)
+
+const pidMaxPath = "/proc/sys/kernel/pid_max"
+
+// the automatic max pids limit is the kernel's pid_max
+func autoMaxPids() (int, error) {
+	contents, err := ioutil.ReadFile(pidMaxPath)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(strings.TrimSpace(string(contents)))
+}
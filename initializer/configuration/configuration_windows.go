@@ -0,0 +1,9 @@
+package configuration
+
+import "math"
+
+// windows has no limit on the number of processes, so the automatic max pids
+// limit never runs out
+func autoMaxPids() (int, error) {
+	return math.MaxInt32, nil
+}
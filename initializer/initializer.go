@@ -2,6 +2,7 @@ package initializer
 
 import (
 	"bytes"
+	"crypto"
 	"crypto/rand"
 	"crypto/tls"
 	"crypto/x509"
@@ -10,8 +11,10 @@ import (
 	"fmt"
 	"io/ioutil"
 	"math"
+	"net/http"
 	"os"
 	"path/filepath"
+	"sort"
 	"sync/atomic"
 	"time"
 
@@ -25,11 +28,13 @@ import (
 	"code.cloudfoundry.org/executor/depot"
 	"code.cloudfoundry.org/executor/depot/containerstore"
 	"code.cloudfoundry.org/executor/depot/event"
+	"code.cloudfoundry.org/executor/depot/log_streamer"
 	"code.cloudfoundry.org/executor/depot/metrics"
 	"code.cloudfoundry.org/executor/depot/transformer"
 	"code.cloudfoundry.org/executor/depot/uploader"
 	"code.cloudfoundry.org/executor/gardenhealth"
 	"code.cloudfoundry.org/executor/guidgen"
+	"code.cloudfoundry.org/executor/http/server"
 	"code.cloudfoundry.org/executor/initializer/configuration"
 	"code.cloudfoundry.org/garden"
 	GardenClient "code.cloudfoundry.org/garden/client"
@@ -51,8 +56,12 @@ const (
 	maxConcurrentUploads           = 5
 	metricsReportInterval          = 1 * time.Minute
 	megabytesToBytes               = 1024 * 1024
+	defaultOTLPFlushInterval       = time.Second
+	otlpExportTimeout              = 10 * time.Second
 )
 
+var ErrAPIRequiresMutualTLS = errors.New("an executor API served over tcp requires a TLS certificate, key and CA")
+
 type executorContainers struct {
 	gardenClient garden.Client
 	owner        string
@@ -83,72 +92,120 @@ func (s systemcertsRetriever) SystemCerts() (*x509.CertPool, error) {
 }
 
 type ExecutorConfig struct {
-	AdvertisePreferenceForInstanceAddress bool                  `json:"advertise_preference_for_instance_address"`
-	AutoDiskOverheadMB                    int                   `json:"auto_disk_capacity_overhead_mb"`
-	CachePath                             string                `json:"cache_path,omitempty"`
-	ContainerInodeLimit                   uint64                `json:"container_inode_limit,omitempty"`
-	ContainerMaxCpuShares                 uint64                `json:"container_max_cpu_shares,omitempty"`
-	ContainerMetricsReportInterval        durationjson.Duration `json:"container_metrics_report_interval,omitempty"`
-	ContainerOwnerName                    string                `json:"container_owner_name,omitempty"`
-	ContainerProxyADSServers              []string              `json:"container_proxy_ads_addresses,omitempty"`
-	ContainerProxyConfigPath              string                `json:"container_proxy_config_path,omitempty"`
-	ContainerProxyPath                    string                `json:"container_proxy_path,omitempty"`
-	ContainerProxyRequireClientCerts      bool                  `json:"container_proxy_require_and_verify_client_certs"`
-	ContainerProxyTrustedCACerts          []string              `json:"container_proxy_trusted_ca_certs"`
-	ContainerProxyVerifySubjectAltName    []string              `json:"container_proxy_verify_subject_alt_name"`
-	ContainerReapInterval                 durationjson.Duration `json:"container_reap_interval,omitempty"`
-	CreateWorkPoolSize                    int                   `json:"create_work_pool_size,omitempty"`
-	DeclarativeHealthcheckPath            string                `json:"declarative_healthcheck_path,omitempty"`
-	DeleteWorkPoolSize                    int                   `json:"delete_work_pool_size,omitempty"`
-	DiskMB                                string                `json:"disk_mb,omitempty"`
-	EnableContainerProxy                  bool                  `json:"enable_container_proxy,omitempty"`
-	EnableDeclarativeHealthcheck          bool                  `json:"enable_declarative_healthcheck,omitempty"`
-	EnableUnproxiedPortMappings           bool                  `json:"enable_unproxied_port_mappings"`
-	EnvoyConfigRefreshDelay               durationjson.Duration `json:"envoy_config_refresh_delay"`
-	EnvoyConfigReloadDuration             durationjson.Duration `json:"envoy_config_reload_duration"`
-	EnvoyDrainTimeout                     durationjson.Duration `json:"envoy_drain_timeout,omitempty"`
-	ExportNetworkEnvVars                  bool                  `json:"export_network_env_vars,omitempty"` // DEPRECATED. Kept around for dusts compatability
-	GardenAddr                            string                `json:"garden_addr,omitempty"`
-	GardenHealthcheckCommandRetryPause    durationjson.Duration `json:"garden_healthcheck_command_retry_pause,omitempty"`
-	GardenHealthcheckEmissionInterval     durationjson.Duration `json:"garden_healthcheck_emission_interval,omitempty"`
-	GardenHealthcheckInterval             durationjson.Duration `json:"garden_healthcheck_interval,omitempty"`
-	GardenHealthcheckProcessArgs          []string              `json:"garden_healthcheck_process_args,omitempty"`
-	GardenHealthcheckProcessDir           string                `json:"garden_healthcheck_process_dir"`
-	GardenHealthcheckProcessEnv           []string              `json:"garden_healthcheck_process_env,omitempty"`
-	GardenHealthcheckProcessPath          string                `json:"garden_healthcheck_process_path"`
-	GardenHealthcheckProcessUser          string                `json:"garden_healthcheck_process_user"`
-	GardenHealthcheckTimeout              durationjson.Duration `json:"garden_healthcheck_timeout,omitempty"`
-	GardenNetwork                         string                `json:"garden_network,omitempty"`
-	GracefulShutdownInterval              durationjson.Duration `json:"graceful_shutdown_interval,omitempty"`
-	HealthCheckContainerOwnerName         string                `json:"healthcheck_container_owner_name,omitempty"`
-	HealthCheckWorkPoolSize               int                   `json:"healthcheck_work_pool_size,omitempty"`
-	HealthyMonitoringInterval             durationjson.Duration `json:"healthy_monitoring_interval,omitempty"`
-	InstanceIdentityCAPath                string                `json:"instance_identity_ca_path,omitempty"`
-	InstanceIdentityCredDir               string                `json:"instance_identity_cred_dir,omitempty"`
-	InstanceIdentityPrivateKeyPath        string                `json:"instance_identity_private_key_path,omitempty"`
-	InstanceIdentityValidityPeriod        durationjson.Duration `json:"instance_identity_validity_period,omitempty"`
-	LogRateLimitExceededReportInterval    durationjson.Duration `json:"log_rate_limit_exceeded_report_interval,omitempty"`
-	MaxCacheSizeInBytes                   uint64                `json:"max_cache_size_in_bytes,omitempty"`
-	MaxConcurrentDownloads                int                   `json:"max_concurrent_downloads,omitempty"`
-	MaxLogLinesPerSecond                  int                   `json:"max_log_lines_per_second"`
-	MemoryMB                              string                `json:"memory_mb,omitempty"`
-	MetricsWorkPoolSize                   int                   `json:"metrics_work_pool_size,omitempty"`
-	PathToCACertsForDownloads             string                `json:"path_to_ca_certs_for_downloads"`
-	PathToTLSCACert                       string                `json:"path_to_tls_ca_cert"`
-	PathToTLSCert                         string                `json:"path_to_tls_cert"`
-	PathToTLSKey                          string                `json:"path_to_tls_key"`
-	PostSetupHook                         string                `json:"post_setup_hook"`
-	PostSetupUser                         string                `json:"post_setup_user"`
-	ProxyMemoryAllocationMB               int                   `json:"proxy_memory_allocation_mb,omitempty"`
-	ReadWorkPoolSize                      int                   `json:"read_work_pool_size,omitempty"`
-	ReservedExpirationTime                durationjson.Duration `json:"reserved_expiration_time,omitempty"`
-	SetCPUWeight                          bool                  `json:"set_cpu_weight,omitempty"`
-	SkipCertVerify                        bool                  `json:"skip_cert_verify,omitempty"`
-	TempDir                               string                `json:"temp_dir,omitempty"`
-	TrustedSystemCertificatesPath         string                `json:"trusted_system_certificates_path"`
-	UnhealthyMonitoringInterval           durationjson.Duration `json:"unhealthy_monitoring_interval,omitempty"`
-	UseSchedulableDiskSize                bool                  `json:"use_schedulable_disk_size,omitempty"`
-	VolmanDriverPaths                     string                `json:"volman_driver_paths"`
+	AdmissionMaxConcurrentPerTag          map[string]int                          `json:"admission_max_concurrent_per_tag,omitempty"`
+	AdmissionTagQuotas                    map[string]map[string]executor.Resource `json:"admission_tag_quotas,omitempty"`
+	AdvertisePreferenceForInstanceAddress bool                                    `json:"advertise_preference_for_instance_address"`
+	APIListenAddress                      string                                  `json:"api_listen_address,omitempty"`
+	APIListenNetwork                      string                                  `json:"api_listen_network,omitempty"`
+	AutoDiskOverheadMB                    int                                     `json:"auto_disk_capacity_overhead_mb"`
+	CachePath                             string                                  `json:"cache_path,omitempty"`
+	CPUShares                             string                                  `json:"cpu_shares,omitempty"`
+	ContainerDataPath                     string                                  `json:"container_data_path,omitempty"`
+	ContainerFreezerCgroupRoot            string                                  `json:"container_freezer_cgroup_root,omitempty"`
+	ContainerInodeLimit                   uint64                                  `json:"container_inode_limit,omitempty"`
+	ContainerMaxCpuShares                 uint64                                  `json:"container_max_cpu_shares,omitempty"`
+	ContainerMemoryCgroupRoot             string                                  `json:"container_memory_cgroup_root,omitempty"`
+	ContainerMetricsReportInterval        durationjson.Duration                   `json:"container_metrics_report_interval,omitempty"`
+	ContainerOOMPollInterval              durationjson.Duration                   `json:"container_oom_poll_interval,omitempty"`
+	ContainerOwnerName                    string                                  `json:"container_owner_name,omitempty"`
+	ContainerProxyADSServers              []string                                `json:"container_proxy_ads_addresses,omitempty"`
+	ContainerProxyConfigPath              string                                  `json:"container_proxy_config_path,omitempty"`
+	ContainerProxyPath                    string                                  `json:"container_proxy_path,omitempty"`
+	ContainerProxyRequireClientCerts      bool                                    `json:"container_proxy_require_and_verify_client_certs"`
+	ContainerProxyTrustedCACerts          []string                                `json:"container_proxy_trusted_ca_certs"`
+	ContainerProxyVerifySubjectAltName    []string                                `json:"container_proxy_verify_subject_alt_name"`
+	ContainerReapInterval                 durationjson.Duration                   `json:"container_reap_interval,omitempty"`
+	ContainerStateFilePath                string                                  `json:"container_state_file_path,omitempty"`
+	CreateWorkPoolSize                    int                                     `json:"create_work_pool_size,omitempty"`
+	DeclarativeHealthcheckPath            string                                  `json:"declarative_healthcheck_path,omitempty"`
+	DeleteWorkPoolSize                    int                                     `json:"delete_work_pool_size,omitempty"`
+	DiskMB                                string                                  `json:"disk_mb,omitempty"`
+	DiskOvercommitRatio                   float64                                 `json:"disk_overcommit_ratio,omitempty"`
+	EnableContainerProxy                  bool                                    `json:"enable_container_proxy,omitempty"`
+	EnableDeclarativeHealthcheck          bool                                    `json:"enable_declarative_healthcheck,omitempty"`
+	EnableLogTap                          bool                                    `json:"enable_log_tap,omitempty"`
+	EnableUnproxiedPortMappings           bool                                    `json:"enable_unproxied_port_mappings"`
+	EnvoyConfigRefreshDelay               durationjson.Duration                   `json:"envoy_config_refresh_delay"`
+	EnvoyConfigReloadDuration             durationjson.Duration                   `json:"envoy_config_reload_duration"`
+	EnvoyDrainTimeout                     durationjson.Duration                   `json:"envoy_drain_timeout,omitempty"`
+	ExportNetworkEnvVars                  bool                                    `json:"export_network_env_vars,omitempty"` // DEPRECATED. Kept around for dusts compatability
+	GardenAddr                            string                                  `json:"garden_addr,omitempty"`
+	GardenHealthcheckCommandRetryPause    durationjson.Duration                   `json:"garden_healthcheck_command_retry_pause,omitempty"`
+	GardenHealthcheckEmissionInterval     durationjson.Duration                   `json:"garden_healthcheck_emission_interval,omitempty"`
+	GardenHealthcheckInterval             durationjson.Duration                   `json:"garden_healthcheck_interval,omitempty"`
+	GardenHealthcheckProcessArgs          []string                                `json:"garden_healthcheck_process_args,omitempty"`
+	GardenHealthcheckProcessDir           string                                  `json:"garden_healthcheck_process_dir"`
+	GardenHealthcheckProcessEnv           []string                                `json:"garden_healthcheck_process_env,omitempty"`
+	GardenHealthcheckProcessPath          string                                  `json:"garden_healthcheck_process_path"`
+	GardenHealthcheckProcessUser          string                                  `json:"garden_healthcheck_process_user"`
+	GardenHealthcheckTimeout              durationjson.Duration                   `json:"garden_healthcheck_timeout,omitempty"`
+	GardenNetwork                         string                                  `json:"garden_network,omitempty"`
+	GracefulShutdownInterval              durationjson.Duration                   `json:"graceful_shutdown_interval,omitempty"`
+	HealthCheckContainerOwnerName         string                                  `json:"healthcheck_container_owner_name,omitempty"`
+	HealthCheckWorkPoolSize               int                                     `json:"healthcheck_work_pool_size,omitempty"`
+	HealthyMonitoringInterval             durationjson.Duration                   `json:"healthy_monitoring_interval,omitempty"`
+	InstanceIdentityCAPath                string                                  `json:"instance_identity_ca_path,omitempty"`
+	InstanceIdentityCredDir               string                                  `json:"instance_identity_cred_dir,omitempty"`
+	InstanceIdentityKeyAlgorithm          string                                  `json:"instance_identity_key_algorithm,omitempty"`
+	InstanceIdentityPrivateKeyPath        string                                  `json:"instance_identity_private_key_path,omitempty"`
+	InstanceIdentitySignerSocketPath      string                                  `json:"instance_identity_signer_socket_path,omitempty"`
+	InstanceIdentityValidityPeriod        durationjson.Duration                   `json:"instance_identity_validity_period,omitempty"`
+	LogRateLimitExceededReportInterval    durationjson.Duration                   `json:"log_rate_limit_exceeded_report_interval,omitempty"`
+	LogSinks                              []LogSinkConfig                         `json:"log_sinks,omitempty"`
+	LogTapBacklogLines                    int                                     `json:"log_tap_backlog_lines,omitempty"`
+	MaxCacheSizeInBytes                   uint64                                  `json:"max_cache_size_in_bytes,omitempty"`
+	MaxConcurrentDownloads                int                                     `json:"max_concurrent_downloads,omitempty"`
+	MaxLogBytesPerSecond                  int64                                   `json:"max_log_bytes_per_second,omitempty"`
+	MaxLogBytesPerSecondCeiling           int64                                   `json:"max_log_bytes_per_second_ceiling,omitempty"`
+	MaxLogLinesPerSecond                  int                                     `json:"max_log_lines_per_second"`
+	MaxLogLinesPerSecondCeiling           int                                     `json:"max_log_lines_per_second_ceiling,omitempty"`
+	MaxPids                               string                                  `json:"max_pids,omitempty"`
+	MemoryMB                              string                                  `json:"memory_mb,omitempty"`
+	MemoryOvercommitRatio                 float64                                 `json:"memory_overcommit_ratio,omitempty"`
+	MetricsWorkPoolSize                   int                                     `json:"metrics_work_pool_size,omitempty"`
+	PathToCACertsForDownloads             string                                  `json:"path_to_ca_certs_for_downloads"`
+	PathToTLSCACert                       string                                  `json:"path_to_tls_ca_cert"`
+	PathToTLSCert                         string                                  `json:"path_to_tls_cert"`
+	PathToTLSKey                          string                                  `json:"path_to_tls_key"`
+	PostSetupHook                         string                                  `json:"post_setup_hook"`
+	PostSetupUser                         string                                  `json:"post_setup_user"`
+	ProxyMemoryAllocationMB               int                                     `json:"proxy_memory_allocation_mb,omitempty"`
+	ReadWorkPoolSize                      int                                     `json:"read_work_pool_size,omitempty"`
+	RecentLogsBufferKB                    int                                     `json:"recent_logs_buffer_kb,omitempty"`
+	ReservedExpirationTime                durationjson.Duration                   `json:"reserved_expiration_time,omitempty"`
+	SetCPUWeight                          bool                                    `json:"set_cpu_weight,omitempty"`
+	SkipCertVerify                        bool                                    `json:"skip_cert_verify,omitempty"`
+	TempDir                               string                                  `json:"temp_dir,omitempty"`
+	TrustedSystemCertificatesPath         string                                  `json:"trusted_system_certificates_path"`
+	UnhealthyMonitoringInterval           durationjson.Duration                   `json:"unhealthy_monitoring_interval,omitempty"`
+	UseSchedulableDiskSize                bool                                    `json:"use_schedulable_disk_size,omitempty"`
+	VolmanDriverPaths                     string                                  `json:"volman_driver_paths"`
+}
+
+const (
+	LogSinkTypeLoggregator = "loggregator"
+	LogSinkTypeSyslog      = "syslog"
+	LogSinkTypeFile        = "file"
+	LogSinkTypeOTLP        = "otlp"
+)
+
+// LogSinkConfig configures one of the sinks the log lines of the containers
+// are sent to. Without any, they are sent to Loggregator.
+type LogSinkConfig struct {
+	Type string `json:"type"`
+
+	// syslog
+	Network string `json:"network,omitempty"`
+	Address string `json:"address,omitempty"`
+
+	// file
+	Directory     string `json:"directory,omitempty"`
+	MaxFileSizeMB int    `json:"max_file_size_mb,omitempty"`
+	MaxFiles      int    `json:"max_files,omitempty"`
+
+	// otlp
+	Endpoint      string                `json:"endpoint,omitempty"`
+	FlushInterval durationjson.Duration `json:"flush_interval,omitempty"`
 }
 
 var (
@@ -200,11 +257,6 @@ func Initialize(logger lager.Logger, config ExecutorConfig, cellID, zone string,
 		return nil, nil, nil, err
 	}
 
-	err = destroyContainers(gardenClient, containersFetcher, logger)
-	if err != nil {
-		return nil, nil, nil, err
-	}
-
 	healthCheckWorkPool, err := workpool.NewWorkPool(config.HealthCheckWorkPoolSize)
 	if err != nil {
 		return nil, nil, grouper.Members{}, err
@@ -263,6 +315,22 @@ func Initialize(logger lager.Logger, config ExecutorConfig, cellID, zone string,
 		return nil, nil, grouper.Members{}, err
 	}
 
+	var logTap log_streamer.Tap
+	if config.EnableLogTap {
+		logTap = log_streamer.NewTap(config.LogTapBacklogLines)
+	}
+
+	var recentLogs log_streamer.RecentLogs
+	if config.RecentLogsBufferKB > 0 {
+		recentLogs = log_streamer.NewRecentLogs(config.RecentLogsBufferKB * 1024)
+	}
+
+	logSink, logSinkMembers, err := LogSinkFromConfig(logger, metronClient, config, cellID, clock)
+	if err != nil {
+		logger.Error("failed-to-create-log-sink", err)
+		return nil, nil, grouper.Members{}, err
+	}
+
 	containerConfig := containerstore.ContainerConfig{
 		OwnerName:                          config.ContainerOwnerName,
 		INodeLimit:                         config.ContainerInodeLimit,
@@ -271,7 +339,20 @@ func Initialize(logger lager.Logger, config ExecutorConfig, cellID, zone string,
 		ReservedExpirationTime:             time.Duration(config.ReservedExpirationTime),
 		ReapInterval:                       time.Duration(config.ContainerReapInterval),
 		MaxLogLinesPerSecond:               config.MaxLogLinesPerSecond,
+		MaxLogBytesPerSecond:               config.MaxLogBytesPerSecond,
+		MaxLogLinesPerSecondCeiling:        config.MaxLogLinesPerSecondCeiling,
+		MaxLogBytesPerSecondCeiling:        config.MaxLogBytesPerSecondCeiling,
 		LogRateLimitExceededReportInterval: time.Duration(config.LogRateLimitExceededReportInterval),
+		GracefulShutdownInterval:           time.Duration(config.GracefulShutdownInterval),
+		StateFilePath:                      config.ContainerStateFilePath,
+		DataPath:                           config.ContainerDataPath,
+		FreezerCgroupRoot:                  config.ContainerFreezerCgroupRoot,
+		MemoryCgroupRoot:                   config.ContainerMemoryCgroupRoot,
+		OOMPollInterval:                    time.Duration(config.ContainerOOMPollInterval),
+		DownloadRateLimiter:                downloadRateLimiter,
+		LogTap:                             logTap,
+		RecentLogs:                         recentLogs,
+		LogSink:                            logSink,
 	}
 
 	driverConfig := vollocal.NewDriverConfig()
@@ -324,8 +405,19 @@ func Initialize(logger lager.Logger, config ExecutorConfig, cellID, zone string,
 		cellID,
 		config.EnableUnproxiedPortMappings,
 		config.AdvertisePreferenceForInstanceAddress,
+		admissionPolicyFromConfig(config),
 	)
 
+	err = containerStore.Restore(logger)
+	if err != nil {
+		return nil, nil, grouper.Members{}, err
+	}
+
+	err = destroyContainers(gardenClient, containersFetcher, containerStore, logger)
+	if err != nil {
+		return nil, nil, grouper.Members{}, err
+	}
+
 	depotClient := depot.NewClient(
 		totalCapacity,
 		containerStore,
@@ -373,33 +465,61 @@ func Initialize(logger lager.Logger, config ExecutorConfig, cellID, zone string,
 		cpuSpikeReporter,
 	)
 
-	return depotClient, containerStatsReporter,
-		grouper.Members{
-			{"volman-driver-syncer", volmanDriverSyncer},
-			{"metrics-reporter", &metrics.Reporter{
-				ExecutorSource: depotClient,
-				Interval:       metricsReportInterval,
-				Clock:          clock,
-				Logger:         logger,
-				MetronClient:   metronClient,
-				Tags:           map[string]string{"zone": zone},
-			}},
-			{"hub-closer", closeHub(logger, hub)},
-			{"container-metrics-reporter", reportersRunner},
-			{"garden_health_checker", gardenhealth.NewRunner(
-				time.Duration(config.GardenHealthcheckInterval),
-				time.Duration(config.GardenHealthcheckEmissionInterval),
-				time.Duration(config.GardenHealthcheckTimeout),
-				logger,
-				gardenHealthcheck,
-				depotClient,
-				metronClient,
-				clock,
-			)},
-			{"registry-pruner", containerStore.NewRegistryPruner(logger)},
-			{"container-reaper", containerStore.NewContainerReaper(logger)},
-		},
-		nil
+	members := grouper.Members{
+		// listed first so that an ordered group stops it last, after the final
+		// state of the containers was journaled
+		{"state-journal-writer", containerStore.NewStateJournalWriter(logger)},
+		{"volman-driver-syncer", volmanDriverSyncer},
+		{"metrics-reporter", &metrics.Reporter{
+			ExecutorSource: depotClient,
+			Interval:       metricsReportInterval,
+			Clock:          clock,
+			Logger:         logger,
+			MetronClient:   metronClient,
+			Tags:           map[string]string{"zone": zone},
+
+			MemoryOvercommitRatio: config.MemoryOvercommitRatio,
+			DiskOvercommitRatio:   config.DiskOvercommitRatio,
+		}},
+		{"hub-closer", closeHub(logger, hub)},
+		{"container-metrics-reporter", reportersRunner},
+		{"garden_health_checker", gardenhealth.NewRunner(
+			time.Duration(config.GardenHealthcheckInterval),
+			time.Duration(config.GardenHealthcheckEmissionInterval),
+			time.Duration(config.GardenHealthcheckTimeout),
+			logger,
+			gardenHealthcheck,
+			depotClient,
+			metronClient,
+			clock,
+		)},
+		{"registry-pruner", containerStore.NewRegistryPruner(logger)},
+		{"container-reaper", containerStore.NewContainerReaper(logger)},
+	}
+	members = append(members, logSinkMembers...)
+
+	if config.APIListenAddress != "" {
+		apiHandler, err := server.New(logger, depotClient)
+		if err != nil {
+			logger.Error("failed-to-create-api-handler", err)
+			return nil, nil, grouper.Members{}, err
+		}
+		apiNetwork := config.apiListenNetwork()
+		var apiTLSConfig *tls.Config
+		if apiRequiresTLS(apiNetwork) {
+			apiTLSConfig, err = APITLSConfigFromConfig(logger, config)
+			if err != nil {
+				logger.Error("failed-to-configure-api-tls", err)
+				return nil, nil, grouper.Members{}, err
+			}
+		}
+		members = append(members, grouper.Member{
+			Name:   "executor-api",
+			Runner: server.NewRunner(logger, apiNetwork, config.APIListenAddress, apiTLSConfig, apiHandler),
+		})
+	}
+
+	return depotClient, containerStatsReporter, members, nil
 }
 
 // Until we get a successful response from garden,
@@ -451,27 +571,64 @@ func waitForGarden(logger lager.Logger, gardenClient GardenClient.Client, metron
 }
 
 func fetchCapacity(logger lager.Logger, gardenClient GardenClient.Client, config ExecutorConfig) (executor.ExecutorResources, error) {
-	capacity, err := configuration.ConfigureCapacity(gardenClient, config.MemoryMB, config.DiskMB, config.MaxCacheSizeInBytes, config.AutoDiskOverheadMB, config.UseSchedulableDiskSize)
+	capacity, err := configuration.ConfigureCapacity(gardenClient, config.MemoryMB, config.DiskMB, config.CPUShares, config.MaxPids, config.MaxCacheSizeInBytes, config.AutoDiskOverheadMB, config.UseSchedulableDiskSize)
 	if err != nil {
 		logger.Error("failed-to-configure-capacity", err)
 		return executor.ExecutorResources{}, err
 	}
 
+	capacity = configuration.ApplyOvercommit(capacity, config.MemoryOvercommitRatio, config.DiskOvercommitRatio)
+
 	logger.Info("initial-capacity", lager.Data{
-		"capacity": capacity,
+		"capacity":                capacity,
+		"memory-overcommit-ratio": config.MemoryOvercommitRatio,
+		"disk-overcommit-ratio":   config.DiskOvercommitRatio,
 	})
 
 	return capacity, nil
 }
 
-func destroyContainers(gardenClient garden.Client, containersFetcher *executorContainers, logger lager.Logger) error {
+func admissionPolicyFromConfig(config ExecutorConfig) containerstore.AdmissionPolicy {
+	policies := []containerstore.AdmissionPolicy{}
+
+	tags := make([]string, 0, len(config.AdmissionTagQuotas))
+	for tag := range config.AdmissionTagQuotas {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	for _, tag := range tags {
+		policies = append(policies, containerstore.NewQuotaByTagPolicy(tag, config.AdmissionTagQuotas[tag]))
+	}
+
+	tags = make([]string, 0, len(config.AdmissionMaxConcurrentPerTag))
+	for tag := range config.AdmissionMaxConcurrentPerTag {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	for _, tag := range tags {
+		policies = append(policies, containerstore.NewMaxConcurrentPerTagPolicy(tag, config.AdmissionMaxConcurrentPerTag[tag]))
+	}
+
+	return containerstore.NewCompositeAdmissionPolicy(policies...)
+}
+
+func destroyContainers(gardenClient garden.Client, containersFetcher *executorContainers, containerStore containerstore.ContainerStore, logger lager.Logger) error {
 	logger.Info("executor-fetching-containers-to-destroy")
-	containers, err := containersFetcher.Containers()
+	allContainers, err := containersFetcher.Containers()
 	if err != nil {
 		logger.Error("executor-failed-to-get-containers", err)
 		return err
 	}
 
+	containers := make([]garden.Container, 0, len(allContainers))
+	for _, container := range allContainers {
+		if _, err := containerStore.Get(logger, container.Handle()); err == nil {
+			logger.Info("executor-keeping-restored-container", lager.Data{"handle": container.Handle()})
+			continue
+		}
+		containers = append(containers, container)
+	}
+
 	logger.Info("executor-fetched-containers-to-destroy", lager.Data{"num-containers": len(containers)})
 
 	type containerDeletionResult struct {
@@ -638,20 +795,74 @@ func TLSConfigFromConfig(logger lager.Logger, certsRetriever CertPoolRetriever,
 	return tlsConfig, nil
 }
 
+// the system CAs are left out of the executor API's pool, so that only the
+// configured CA vouches for its clients
+type noSystemCertsRetriever struct{}
+
+func (noSystemCertsRetriever) SystemCerts() (*x509.CertPool, error) {
+	return x509.NewCertPool(), nil
+}
+
+// APITLSConfigFromConfig returns the mutual TLS config of an executor API
+// reachable from outside the cell. It uses the certificate, key and CA of
+// TLSConfigFromConfig, and only accepts clients with a certificate signed by
+// that CA.
+func APITLSConfigFromConfig(logger lager.Logger, config ExecutorConfig) (*tls.Config, error) {
+	if config.PathToTLSCert == "" || config.PathToTLSKey == "" || config.PathToTLSCACert == "" {
+		return nil, ErrAPIRequiresMutualTLS
+	}
+
+	config.PathToCACertsForDownloads = ""
+	config.SkipCertVerify = false
+	tlsConfig, err := TLSConfigFromConfig(logger, noSystemCertsRetriever{}, config)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig.ClientCAs = tlsConfig.RootCAs
+	tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	return tlsConfig, nil
+}
+
+func (config *ExecutorConfig) apiListenNetwork() string {
+	if config.APIListenNetwork == "" {
+		return "unix"
+	}
+	return config.APIListenNetwork
+}
+
+// apiRequiresTLS returns whether the executor API must be served with mutual
+// TLS. Only the unix socket, which is accessible to its owner alone, is served
+// without: a tcp port, even on a loopback address, is reachable by every user
+// of the cell and by the containers sharing its network namespace.
+func apiRequiresTLS(network string) bool {
+	return network != "unix"
+}
+
 func CredManagerFromConfig(logger lager.Logger, metronClient loggingclient.IngressClient, config ExecutorConfig, clock clock.Clock, handlers ...containerstore.CredentialHandler) (containerstore.CredManager, error) {
 	if config.InstanceIdentityCredDir != "" {
 		logger.Info("instance-identity-enabled")
-		keyData, err := ioutil.ReadFile(config.InstanceIdentityPrivateKeyPath)
+		keyAlgorithm := containerstore.KeyAlgorithm(config.InstanceIdentityKeyAlgorithm)
+		err := keyAlgorithm.Validate()
 		if err != nil {
 			return nil, err
 		}
-		keyBlock, _ := pem.Decode(keyData)
-		if keyBlock == nil {
-			return nil, errors.New("instance ID key is not PEM-encoded")
-		}
-		privateKey, err := x509.ParsePKCS1PrivateKey(keyBlock.Bytes)
-		if err != nil {
-			return nil, err
+
+		// with an external signer, the CA key is not on the cell
+		var privateKey crypto.Signer
+		if config.InstanceIdentitySignerSocketPath == "" {
+			keyData, err := ioutil.ReadFile(config.InstanceIdentityPrivateKeyPath)
+			if err != nil {
+				return nil, err
+			}
+			keyBlock, _ := pem.Decode(keyData)
+			if keyBlock == nil {
+				return nil, errors.New("instance ID key is not PEM-encoded")
+			}
+			privateKey, err = parsePrivateKey(keyBlock.Bytes)
+			if err != nil {
+				return nil, err
+			}
 		}
 
 		certData, err := ioutil.ReadFile(config.InstanceIdentityCAPath)
@@ -671,14 +882,23 @@ func CredManagerFromConfig(logger lager.Logger, metronClient loggingclient.Ingre
 			return nil, errors.New("instance ID validity period needs to be set and positive")
 		}
 
+		var signer containerstore.Signer
+		if config.InstanceIdentitySignerSocketPath != "" {
+			logger.Info("instance-identity-external-signer", lager.Data{"socket-path": config.InstanceIdentitySignerSocketPath})
+			signer = containerstore.NewSocketSigner(config.InstanceIdentitySignerSocketPath, certs[0])
+		} else {
+			signer = containerstore.NewLocalSigner(rand.Reader, certs[0], privateKey)
+		}
+
 		return containerstore.NewCredManager(
 			logger,
 			metronClient,
 			time.Duration(config.InstanceIdentityValidityPeriod),
 			rand.Reader,
 			clock,
+			keyAlgorithm,
 			certs[0],
-			privateKey,
+			signer,
 			handlers...,
 		), nil
 	}
@@ -687,6 +907,80 @@ func CredManagerFromConfig(logger lager.Logger, metronClient loggingclient.Ingre
 	return containerstore.NewNoopCredManager(), nil
 }
 
+// parsePrivateKey parses a PKCS #1, SEC 1 or PKCS #8 private key. When none
+// of them parses, the PKCS #1 error is returned.
+func parsePrivateKey(der []byte) (crypto.Signer, error) {
+	rsaKey, err := x509.ParsePKCS1PrivateKey(der)
+	if err == nil {
+		return rsaKey, nil
+	}
+
+	ecKey, ecErr := x509.ParseECPrivateKey(der)
+	if ecErr == nil {
+		return ecKey, nil
+	}
+
+	key, pkcs8Err := x509.ParsePKCS8PrivateKey(der)
+	if pkcs8Err == nil {
+		if signer, ok := key.(crypto.Signer); ok {
+			return signer, nil
+		}
+	}
+
+	return nil, err
+}
+
+// LogSinkFromConfig returns the sink for the log lines of the containers, along
+// with the members flushing it. Lines are sent to every configured sink, the
+// sink is nil when none is configured.
+func LogSinkFromConfig(logger lager.Logger, metronClient loggingclient.IngressClient, config ExecutorConfig, hostname string, clock clock.Clock) (log_streamer.LogSink, grouper.Members, error) {
+	sinks := log_streamer.LogSinks{}
+	members := grouper.Members{}
+
+	for i, sinkConfig := range config.LogSinks {
+		switch sinkConfig.Type {
+		case LogSinkTypeLoggregator:
+			sinks = append(sinks, metronClient)
+
+		case LogSinkTypeSyslog:
+			network := sinkConfig.Network
+			if network == "" {
+				network = "tcp"
+			}
+			sink, err := log_streamer.NewSyslogSink(logger, metronClient, network, sinkConfig.Address, hostname)
+			if err != nil {
+				return nil, nil, err
+			}
+			sinks = append(sinks, sink)
+			members = append(members, grouper.Member{Name: fmt.Sprintf("syslog-log-sink-%d", i), Runner: sink})
+
+		case LogSinkTypeFile:
+			sinks = append(sinks, log_streamer.NewFileSink(sinkConfig.Directory, int64(sinkConfig.MaxFileSizeMB)*megabytesToBytes, sinkConfig.MaxFiles))
+
+		case LogSinkTypeOTLP:
+			flushInterval := time.Duration(sinkConfig.FlushInterval)
+			if flushInterval <= 0 {
+				flushInterval = defaultOTLPFlushInterval
+			}
+			sink := log_streamer.NewOTLPSink(logger, &http.Client{Timeout: otlpExportTimeout}, sinkConfig.Endpoint, flushInterval, clock)
+			sinks = append(sinks, sink)
+			members = append(members, grouper.Member{Name: fmt.Sprintf("otlp-log-sink-%d", i), Runner: sink})
+
+		default:
+			return nil, nil, fmt.Errorf("unknown log sink type: %q", sinkConfig.Type)
+		}
+	}
+
+	switch len(sinks) {
+	case 0:
+		return nil, members, nil
+	case 1:
+		return sinks[0], members, nil
+	default:
+		return sinks, members, nil
+	}
+}
+
 func (config *ExecutorConfig) Validate(logger lager.Logger) bool {
 	valid := true
 
@@ -725,6 +1019,64 @@ func (config *ExecutorConfig) Validate(logger lager.Logger) bool {
 		valid = false
 	}
 
+	if config.MemoryOvercommitRatio != 0 && config.MemoryOvercommitRatio < 1 {
+		logger.Error("memory-overcommit-ratio-invalid", nil)
+		valid = false
+	}
+
+	if config.DiskOvercommitRatio != 0 && config.DiskOvercommitRatio < 1 {
+		logger.Error("disk-overcommit-ratio-invalid", nil)
+		valid = false
+	}
+
+	for tag, max := range config.AdmissionMaxConcurrentPerTag {
+		if max < 1 {
+			logger.Error("admission-max-concurrent-per-tag-invalid", nil, lager.Data{"tag": tag})
+			valid = false
+		}
+	}
+
+	if config.APIListenAddress != "" && apiRequiresTLS(config.apiListenNetwork()) &&
+		(config.PathToTLSCert == "" || config.PathToTLSKey == "" || config.PathToTLSCACert == "") {
+		logger.Error("api-listen-address-requires-mutual-tls", ErrAPIRequiresMutualTLS)
+		valid = false
+	}
+
+	err := containerstore.KeyAlgorithm(config.InstanceIdentityKeyAlgorithm).Validate()
+	if err != nil {
+		logger.Error("instance-identity-key-algorithm-invalid", err)
+		valid = false
+	}
+
+	for i, sinkConfig := range config.LogSinks {
+		data := lager.Data{"index": i, "type": sinkConfig.Type}
+		switch sinkConfig.Type {
+		case LogSinkTypeLoggregator:
+		case LogSinkTypeSyslog:
+			if sinkConfig.Network != "" && sinkConfig.Network != "tcp" && sinkConfig.Network != "udp" {
+				logger.Error("log-sink-network-invalid", nil, data)
+				valid = false
+			}
+			if sinkConfig.Address == "" {
+				logger.Error("log-sink-address-invalid", nil, data)
+				valid = false
+			}
+		case LogSinkTypeFile:
+			if sinkConfig.Directory == "" {
+				logger.Error("log-sink-directory-invalid", nil, data)
+				valid = false
+			}
+		case LogSinkTypeOTLP:
+			if sinkConfig.Endpoint == "" {
+				logger.Error("log-sink-endpoint-invalid", nil, data)
+				valid = false
+			}
+		default:
+			logger.Error("log-sink-type-invalid", nil, data)
+			valid = false
+		}
+	}
+
 	return valid
 }
 
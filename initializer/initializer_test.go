@@ -20,6 +20,7 @@ import (
 	"code.cloudfoundry.org/executor"
 	"code.cloudfoundry.org/executor/depot/containerstore"
 	"code.cloudfoundry.org/executor/depot/containerstore/containerstorefakes"
+	"code.cloudfoundry.org/executor/depot/log_streamer"
 	"code.cloudfoundry.org/executor/gardenhealth"
 	"code.cloudfoundry.org/executor/initializer"
 	"code.cloudfoundry.org/executor/initializer/configuration"
@@ -31,6 +32,7 @@ import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"github.com/onsi/gomega/ghttp"
+	"github.com/tedsuo/ifrit/grouper"
 )
 
 var _ = Describe("Initializer", func() {
@@ -570,6 +572,98 @@ var _ = Describe("Initializer", func() {
 		})
 	})
 
+	Describe("APITLSConfigFromConfig", func() {
+		var logger *lagertest.TestLogger
+
+		BeforeEach(func() {
+			logger = lagertest.NewTestLogger("executor")
+			config.PathToTLSCert = "fixtures/downloader/client.crt"
+			config.PathToTLSKey = "fixtures/downloader/client.key"
+			config.PathToTLSCACert = "fixtures/downloader/ca.crt"
+			config.PathToCACertsForDownloads = "fixtures/systemcerts/extra-ca.crt"
+		})
+
+		It("requires client certificates signed by the TLS CA alone", func() {
+			tlsConfig, err := initializer.APITLSConfigFromConfig(logger, config)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tlsConfig.ClientAuth).To(Equal(tls.RequireAndVerifyClientCert))
+
+			certBytes, err := ioutil.ReadFile(config.PathToTLSCACert)
+			Expect(err).NotTo(HaveOccurred())
+			block, _ := pem.Decode(certBytes)
+			caCert, err := x509.ParseCertificate(block.Bytes)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tlsConfig.ClientCAs.Subjects()).To(ConsistOf(caCert.RawSubject))
+		})
+
+		It("serves the configured certificate", func() {
+			tlsCert, err := tls.LoadX509KeyPair(config.PathToTLSCert, config.PathToTLSKey)
+			Expect(err).NotTo(HaveOccurred())
+
+			tlsConfig, err := initializer.APITLSConfigFromConfig(logger, config)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tlsConfig.Certificates).To(ContainElement(tlsCert))
+		})
+
+		Context("when the TLS CA is not configured", func() {
+			BeforeEach(func() {
+				config.PathToTLSCACert = ""
+			})
+
+			It("returns ErrAPIRequiresMutualTLS", func() {
+				_, err := initializer.APITLSConfigFromConfig(logger, config)
+				Expect(err).To(Equal(initializer.ErrAPIRequiresMutualTLS))
+			})
+		})
+	})
+
+	Describe("the executor API", func() {
+		BeforeEach(func() {
+			config.APIListenNetwork = "tcp"
+		})
+
+		Context("when it listens on a loopback address without mutual TLS", func() {
+			BeforeEach(func() {
+				config.APIListenAddress = "127.0.0.1:0"
+			})
+
+			It("fails", func() {
+				Eventually(errCh, 5*time.Second).Should(Receive(Equal(initializer.ErrAPIRequiresMutualTLS)))
+			})
+		})
+
+		Context("when it listens on other addresses without mutual TLS", func() {
+			BeforeEach(func() {
+				config.APIListenAddress = "0.0.0.0:0"
+			})
+
+			It("fails", func() {
+				Eventually(errCh, 5*time.Second).Should(Receive(Equal(initializer.ErrAPIRequiresMutualTLS)))
+			})
+		})
+
+		Context("when it listens on a unix socket", func() {
+			var socketDir string
+
+			BeforeEach(func() {
+				var err error
+				socketDir, err = ioutil.TempDir("", "executor-api")
+				Expect(err).NotTo(HaveOccurred())
+
+				config.APIListenNetwork = "unix"
+				config.APIListenAddress = filepath.Join(socketDir, "executor.sock")
+			})
+
+			AfterEach(func() {
+				os.RemoveAll(socketDir)
+			})
+
+			It("does not require mutual TLS", func() {
+				Consistently(errCh).ShouldNot(Receive(HaveOccurred()))
+			})
+		})
+	})
+
 	Describe("CredManagerFromConfig", func() {
 		var credManager containerstore.CredManager
 		var err error
@@ -690,6 +784,132 @@ var _ = Describe("Initializer", func() {
 					Eventually(err).Should(MatchError(ContainSubstring("instance ID validity period needs to be set and positive")))
 				})
 			})
+
+			Context("when the key algorithm is ECDSA P-256", func() {
+				BeforeEach(func() {
+					config.InstanceIdentityKeyAlgorithm = string(containerstore.KeyAlgorithmECDSAP256)
+				})
+
+				It("returns a credential manager", func() {
+					Expect(err).NotTo(HaveOccurred())
+					Expect(credManager).NotTo(BeNil())
+				})
+			})
+
+			Context("when the key algorithm is not supported", func() {
+				BeforeEach(func() {
+					config.InstanceIdentityKeyAlgorithm = "dsa-1024"
+				})
+
+				It("fails", func() {
+					Expect(err).To(Equal(containerstore.ErrUnsupportedKeyAlgorithm))
+				})
+			})
+
+			Context("when an external signer is set", func() {
+				BeforeEach(func() {
+					config.InstanceIdentitySignerSocketPath = "/var/vcap/data/instance-identity-signer/signer.sock"
+					config.InstanceIdentityPrivateKeyPath = "fixtures/instance-id/notexist.key"
+				})
+
+				It("does not need the CA key", func() {
+					Expect(err).NotTo(HaveOccurred())
+					Expect(credManager).NotTo(BeNil())
+				})
+			})
+		})
+	})
+
+	Describe("LogSinkFromConfig", func() {
+		var (
+			sink    log_streamer.LogSink
+			members grouper.Members
+			err     error
+		)
+
+		JustBeforeEach(func() {
+			sink, members, err = initializer.LogSinkFromConfig(logger, fakeMetronClient, config, "the-cell", fakeClock)
+		})
+
+		Context("when no log sinks are configured", func() {
+			It("returns no sink, so that the lines go to Loggregator", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(sink).To(BeNil())
+				Expect(members).To(BeEmpty())
+			})
+		})
+
+		Context("when the loggregator sink is configured", func() {
+			BeforeEach(func() {
+				config.LogSinks = []initializer.LogSinkConfig{{Type: initializer.LogSinkTypeLoggregator}}
+			})
+
+			It("returns the metron client", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(sink).To(Equal(fakeMetronClient))
+			})
+		})
+
+		Context("when several log sinks are configured", func() {
+			var logDir string
+
+			BeforeEach(func() {
+				logDir, err = ioutil.TempDir("", "log-sinks")
+				Expect(err).NotTo(HaveOccurred())
+
+				config.LogSinks = []initializer.LogSinkConfig{
+					{Type: initializer.LogSinkTypeLoggregator},
+					{Type: initializer.LogSinkTypeFile, Directory: logDir},
+					{Type: initializer.LogSinkTypeOTLP, Endpoint: "http://127.0.0.1:4318/v1/logs"},
+				}
+			})
+
+			AfterEach(func() {
+				os.RemoveAll(logDir)
+			})
+
+			It("sends the lines to all of them, and flushes the otlp sink with a member", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(members).To(HaveLen(1))
+				Expect(members[0].Name).To(Equal("otlp-log-sink-2"))
+
+				Expect(sink.SendAppLog("hello", "APP", map[string]string{"source_id": "app-guid", "instance_id": "0"})).To(Succeed())
+				Expect(fakeMetronClient.SendAppLogCallCount()).To(Equal(1))
+				Expect(filepath.Join(logDir, "app-guid", "0.log")).To(BeAnExistingFile())
+			})
+		})
+
+		Context("when a syslog sink is configured", func() {
+			BeforeEach(func() {
+				config.LogSinks = []initializer.LogSinkConfig{{Type: initializer.LogSinkTypeSyslog, Address: "127.0.0.1:514"}}
+			})
+
+			It("writes its queue with a member", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(sink).To(BeAssignableToTypeOf(&log_streamer.SyslogSink{}))
+				Expect(members).To(HaveLen(1))
+				Expect(members[0].Name).To(Equal("syslog-log-sink-0"))
+			})
+		})
+
+		Context("when a syslog sink has an unsupported network", func() {
+			BeforeEach(func() {
+				config.LogSinks = []initializer.LogSinkConfig{{Type: initializer.LogSinkTypeSyslog, Network: "unix", Address: "/dev/log"}}
+			})
+
+			It("fails", func() {
+				Expect(err).To(Equal(log_streamer.ErrUnsupportedSyslogNetwork))
+			})
+		})
+
+		Context("when a log sink has an unknown type", func() {
+			BeforeEach(func() {
+				config.LogSinks = []initializer.LogSinkConfig{{Type: "carrier-pigeon"}}
+			})
+
+			It("fails", func() {
+				Expect(err).To(MatchError(ContainSubstring("unknown log sink type")))
+			})
 		})
 	})
 })
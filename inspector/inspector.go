@@ -0,0 +1,357 @@
+package inspector
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/bytefmt"
+	"code.cloudfoundry.org/executor"
+	"code.cloudfoundry.org/lager"
+)
+
+const redacted = "[REDACTED]"
+
+// Inspector prints what an executor knows about its containers in a form
+// suited to a terminal.
+type Inspector struct {
+	logger lager.Logger
+	client executor.Client
+	out    io.Writer
+}
+
+func New(logger lager.Logger, client executor.Client, out io.Writer) *Inspector {
+	return &Inspector{
+		logger: logger,
+		client: client,
+		out:    out,
+	}
+}
+
+// ListContainers prints a line per container with its state, tags, ports and
+// resource usage, ordered by guid.
+func (i *Inspector) ListContainers() error {
+	containers, err := i.client.ListContainers(i.logger)
+	if err != nil {
+		return err
+	}
+
+	metrics, err := i.client.GetBulkMetrics(i.logger)
+	if err != nil {
+		return err
+	}
+
+	sort.Slice(containers, func(a, b int) bool {
+		return containers[a].Guid < containers[b].Guid
+	})
+
+	w := tabwriter.NewWriter(i.out, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "GUID\tSTATE\tMEMORY\tDISK\tCPU\tPORTS\tTAGS")
+	for _, container := range containers {
+		memory, disk, cpu := "-", "-", "-"
+		if m, ok := metrics[container.Guid]; ok {
+			memory = usage(m.MemoryUsageInBytes, m.MemoryLimitInBytes)
+			disk = usage(m.DiskUsageInBytes, m.DiskLimitInBytes)
+			cpu = m.TimeSpentInCPU.Round(time.Millisecond).String()
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			container.Guid,
+			container.State,
+			memory,
+			disk,
+			cpu,
+			formatPorts(container.Ports),
+			formatTags(container.Tags),
+		)
+	}
+	return w.Flush()
+}
+
+// ShowContainer prints the state of a container followed by its full run
+// info. The image password is redacted, and so are the values of the env vars
+// of the container and its run actions unless showEnv is set. Even then, the
+// env vars with a secret name stay redacted.
+func (i *Inspector) ShowContainer(guid string, showEnv bool) error {
+	container, err := i.client.GetContainer(i.logger, guid)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(i.out, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(w, "Guid:\t%s\n", container.Guid)
+	fmt.Fprintf(w, "State:\t%s\n", container.State)
+	fmt.Fprintf(w, "Allocated At:\t%s\n", formatTimestamp(container.AllocatedAt))
+	fmt.Fprintf(w, "Memory:\t%d MB\n", container.MemoryMB)
+	fmt.Fprintf(w, "Disk:\t%d MB\n", container.DiskMB)
+	fmt.Fprintf(w, "Priority:\t%d\n", container.Priority)
+	fmt.Fprintf(w, "External IP:\t%s\n", container.ExternalIP)
+	fmt.Fprintf(w, "Internal IP:\t%s\n", container.InternalIP)
+	fmt.Fprintf(w, "Ports:\t%s\n", formatPorts(container.Ports))
+	fmt.Fprintf(w, "Tags:\t%s\n", formatTags(container.Tags))
+	if container.OOMKillCount > 0 {
+		fmt.Fprintf(w, "OOM Kills:\t%d (last at %s)\n", container.OOMKillCount, formatTimestamp(container.LastOOMKillAt))
+	}
+	if container.State == executor.StateCompleted {
+		fmt.Fprintf(w, "Failed:\t%t\n", container.RunResult.Failed)
+		if container.RunResult.Failed {
+			fmt.Fprintf(w, "Failure Code:\t%s\n", container.RunResult.FailureCode)
+			fmt.Fprintf(w, "Failure Reason:\t%s\n", container.RunResult.FailureReason)
+		}
+	}
+	err = w.Flush()
+	if err != nil {
+		return err
+	}
+
+	runInfo := container.RunInfo
+	if runInfo.ImagePassword != "" {
+		runInfo.ImagePassword = redacted
+	}
+	redactEnv(&runInfo, func(name string) bool {
+		return !showEnv || executor.IsSecretEnvName(name)
+	})
+
+	payload, err := json.MarshalIndent(runInfo, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(i.out, "Run Info:\n%s\n", payload)
+	return err
+}
+
+// redactEnv redacts the values of the env vars of the run info and of its run
+// actions whose name is to be redacted.
+func redactEnv(runInfo *executor.RunInfo, redact func(name string) bool) {
+	env := make([]executor.EnvironmentVariable, len(runInfo.Env))
+	for i, envVar := range runInfo.Env {
+		if redact(envVar.Name) {
+			envVar.Value = redacted
+		}
+		env[i] = envVar
+	}
+	runInfo.Env = env
+
+	redactActionEnv(runInfo.Setup, redact)
+	redactActionEnv(runInfo.Action, redact)
+	redactActionEnv(runInfo.Monitor, redact)
+	for _, sidecar := range runInfo.Sidecars {
+		redactActionEnv(sidecar.Action, redact)
+	}
+}
+
+func redactActionEnv(action *models.Action, redact func(name string) bool) {
+	if action == nil {
+		return
+	}
+
+	switch a := action.GetValue().(type) {
+	case *models.RunAction:
+		env := make([]*models.EnvironmentVariable, len(a.Env))
+		for i, envVar := range a.Env {
+			envVar := *envVar
+			if redact(envVar.Name) {
+				envVar.Value = redacted
+			}
+			env[i] = &envVar
+		}
+		a.Env = env
+	case *models.EmitProgressAction:
+		redactActionEnv(a.Action, redact)
+	case *models.TimeoutAction:
+		redactActionEnv(a.Action, redact)
+	case *models.TryAction:
+		redactActionEnv(a.Action, redact)
+	case *models.ParallelAction:
+		for _, subAction := range a.Actions {
+			redactActionEnv(subAction, redact)
+		}
+	case *models.CodependentAction:
+		for _, subAction := range a.Actions {
+			redactActionEnv(subAction, redact)
+		}
+	case *models.SerialAction:
+		for _, subAction := range a.Actions {
+			redactActionEnv(subAction, redact)
+		}
+	}
+}
+
+// TailEvents prints the lifecycle events matching filter as they happen,
+// until the event source fails. An empty guid prints the events of every
+// container.
+func (i *Inspector) TailEvents(guid string, filter executor.EventFilter) error {
+	source, err := i.client.SubscribeToEventsWithFilter(i.logger, filter)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	for {
+		event, err := source.Next()
+		if err != nil {
+			return err
+		}
+
+		lifecycleEvent, ok := event.(executor.LifecycleEvent)
+		if !ok {
+			continue
+		}
+
+		container := lifecycleEvent.Container()
+		if guid != "" && container.Guid != guid {
+			continue
+		}
+
+		metadata := event.Metadata()
+		state := string(container.State)
+		if metadata.PreviousState != "" {
+			state = fmt.Sprintf("%s -> %s", metadata.PreviousState, container.State)
+		}
+
+		_, err = fmt.Fprintf(i.out, "%s  %-6d %-24s %s  %s\n",
+			formatTimestamp(metadata.Timestamp),
+			metadata.Seq,
+			event.EventType(),
+			container.Guid,
+			state,
+		)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// TailLogs prints the log lines of the container as they are logged, starting
+// with the lines the executor kept, until the log source fails.
+func (i *Inspector) TailLogs(guid string) error {
+	source, err := i.client.SubscribeToLogs(i.logger, guid)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	for {
+		line, err := source.Next()
+		if err != nil {
+			return err
+		}
+
+		err = i.printLogLine(line)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// RecentLogs prints the last lines the container logged, which the executor
+// keeps until the container is deleted.
+func (i *Inspector) RecentLogs(guid string) error {
+	lines, err := i.client.GetRecentLogs(i.logger, guid)
+	if err != nil {
+		return err
+	}
+
+	for _, line := range lines {
+		err = i.printLogLine(line)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (i *Inspector) printLogLine(line executor.LogLine) error {
+	stream := "OUT"
+	if line.Stream == executor.LogStreamStderr {
+		stream = "ERR"
+	}
+
+	_, err := fmt.Fprintf(i.out, "%s [%s] %s %s\n", formatTimestamp(line.Timestamp), line.SourceName, stream, line.Message)
+	return err
+}
+
+// GetFiles copies the tar stream of the files at path in the container to
+// dest.
+func (i *Inspector) GetFiles(guid, path string, dest io.Writer) error {
+	stream, err := i.client.GetFiles(i.logger, guid, path)
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+
+	_, err = io.Copy(dest, stream)
+	return err
+}
+
+// PutFiles streams the tar read from src into the container at path, owned by
+// user.
+func (i *Inspector) PutFiles(guid, path, user string, src io.Reader) error {
+	return i.client.PutFiles(i.logger, guid, path, src, user)
+}
+
+// Resources prints the remaining and total resources of the cell.
+func (i *Inspector) Resources() error {
+	remaining, err := i.client.RemainingResources(i.logger)
+	if err != nil {
+		return err
+	}
+
+	total, err := i.client.TotalResources(i.logger)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(i.out, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "RESOURCE\tREMAINING\tTOTAL")
+	fmt.Fprintf(w, "Memory (MB)\t%d\t%d\n", remaining.MemoryMB, total.MemoryMB)
+	fmt.Fprintf(w, "Disk (MB)\t%d\t%d\n", remaining.DiskMB, total.DiskMB)
+	fmt.Fprintf(w, "Containers\t%d\t%d\n", remaining.Containers, total.Containers)
+	fmt.Fprintf(w, "Max Pids\t%d\t%d\n", remaining.MaxPids, total.MaxPids)
+	fmt.Fprintf(w, "CPU Shares\t%d\t%d\n", remaining.CPUShares, total.CPUShares)
+	return w.Flush()
+}
+
+func usage(used, limit uint64) string {
+	if limit == 0 {
+		return bytefmt.ByteSize(used)
+	}
+	return fmt.Sprintf("%s/%s", bytefmt.ByteSize(used), bytefmt.ByteSize(limit))
+}
+
+func formatPorts(ports []executor.PortMapping) string {
+	if len(ports) == 0 {
+		return "-"
+	}
+
+	formatted := make([]string, 0, len(ports))
+	for _, port := range ports {
+		formatted = append(formatted, fmt.Sprintf("%d->%d", port.HostPort, port.ContainerPort))
+	}
+	return strings.Join(formatted, ",")
+}
+
+func formatTags(tags executor.Tags) string {
+	if len(tags) == 0 {
+		return "-"
+	}
+
+	formatted := make([]string, 0, len(tags))
+	for key, value := range tags {
+		formatted = append(formatted, key+"="+value)
+	}
+	sort.Strings(formatted)
+	return strings.Join(formatted, ",")
+}
+
+func formatTimestamp(nanos int64) string {
+	if nanos == 0 {
+		return "-"
+	}
+	return time.Unix(0, nanos).UTC().Format(time.RFC3339Nano)
+}
@@ -0,0 +1,245 @@
+package inspector_test
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"time"
+
+	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/executor"
+	"code.cloudfoundry.org/executor/fakes"
+	"code.cloudfoundry.org/executor/inspector"
+	"code.cloudfoundry.org/lager/lagertest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Inspector", func() {
+	var (
+		client *fakes.FakeClient
+		out    *bytes.Buffer
+		i      *inspector.Inspector
+	)
+
+	BeforeEach(func() {
+		client = new(fakes.FakeClient)
+		out = &bytes.Buffer{}
+		i = inspector.New(lagertest.NewTestLogger("test"), client, out)
+	})
+
+	Describe("ListContainers", func() {
+		BeforeEach(func() {
+			client.ListContainersReturns([]executor.Container{
+				{
+					Guid:  "guid-b",
+					State: executor.StateReserved,
+				},
+				{
+					Guid:    "guid-a",
+					State:   executor.StateRunning,
+					Tags:    executor.Tags{"lifecycle": "lrp", "domain": "cf-apps"},
+					RunInfo: executor.RunInfo{Ports: []executor.PortMapping{{ContainerPort: 8080, HostPort: 61001}}},
+				},
+			}, nil)
+
+			client.GetBulkMetricsReturns(map[string]executor.Metrics{
+				"guid-a": {ContainerMetrics: executor.ContainerMetrics{
+					MemoryUsageInBytes: 512 * 1024 * 1024,
+					MemoryLimitInBytes: 1024 * 1024 * 1024,
+					DiskUsageInBytes:   2 * 1024 * 1024,
+					DiskLimitInBytes:   1024 * 1024 * 1024,
+					TimeSpentInCPU:     1500 * time.Millisecond,
+				}},
+			}, nil)
+		})
+
+		It("prints the containers ordered by guid", func() {
+			Expect(i.ListContainers()).To(Succeed())
+
+			lines := bytes.Split(bytes.TrimSpace(out.Bytes()), []byte("\n"))
+			Expect(lines).To(HaveLen(3))
+			Expect(string(lines[0])).To(MatchRegexp(`^GUID\s+STATE\s+MEMORY\s+DISK\s+CPU\s+PORTS\s+TAGS$`))
+			Expect(string(lines[1])).To(MatchRegexp(`^guid-a\s+running\s+512M/1G\s+2M/1G\s+1.5s\s+61001->8080\s+domain=cf-apps,lifecycle=lrp$`))
+			Expect(string(lines[2])).To(MatchRegexp(`^guid-b\s+reserved\s+-\s+-\s+-\s+-\s+-$`))
+		})
+
+		It("returns the error when the metrics cannot be fetched", func() {
+			client.GetBulkMetricsReturns(nil, errors.New("boom"))
+			Expect(i.ListContainers()).To(MatchError("boom"))
+		})
+	})
+
+	Describe("ShowContainer", func() {
+		It("prints the container and its run info with the image password redacted", func() {
+			client.GetContainerReturns(executor.Container{
+				Guid:  "some-guid",
+				State: executor.StateCompleted,
+				RunInfo: executor.RunInfo{
+					RootFSPath:    "docker:///some/image",
+					ImageUsername: "user",
+					ImagePassword: "hunter2",
+				},
+				RunResult: executor.ContainerRunResult{
+					Failed:        true,
+					FailureCode:   executor.FailureCodeOOM,
+					FailureReason: "out of memory",
+				},
+			}, nil)
+
+			Expect(i.ShowContainer("some-guid", false)).To(Succeed())
+
+			_, guid := client.GetContainerArgsForCall(0)
+			Expect(guid).To(Equal("some-guid"))
+
+			Expect(out.String()).To(MatchRegexp(`Guid:\s+some-guid`))
+			Expect(out.String()).To(MatchRegexp(`Failure Code:\s+oom`))
+			Expect(out.String()).To(ContainSubstring(`"rootfs": "docker:///some/image"`))
+			Expect(out.String()).To(ContainSubstring(`"image_password": "[REDACTED]"`))
+			Expect(out.String()).NotTo(ContainSubstring("hunter2"))
+		})
+
+		Describe("env vars", func() {
+			BeforeEach(func() {
+				client.GetContainerReturns(executor.Container{
+					Guid: "some-guid",
+					RunInfo: executor.RunInfo{
+						Env: []executor.EnvironmentVariable{
+							{Name: "PORT", Value: "8080"},
+							{Name: "DB_PASSWORD", Value: "hunter2"},
+						},
+						Action: models.WrapAction(models.Timeout(&models.RunAction{
+							Path: "/action/path",
+							Env: []*models.EnvironmentVariable{
+								{Name: "VCAP_SERVICES", Value: "some-credentials"},
+								{Name: "API_TOKEN", Value: "some-token"},
+							},
+						}, time.Minute)),
+					},
+				}, nil)
+			})
+
+			It("redacts every value", func() {
+				Expect(i.ShowContainer("some-guid", false)).To(Succeed())
+
+				Expect(out.String()).To(ContainSubstring(`"name": "PORT"`))
+				for _, value := range []string{"8080", "hunter2", "some-credentials", "some-token"} {
+					Expect(out.String()).NotTo(ContainSubstring(value))
+				}
+			})
+
+			Context("when asked to show them", func() {
+				It("only redacts the values of the env vars with a secret name", func() {
+					Expect(i.ShowContainer("some-guid", true)).To(Succeed())
+
+					Expect(out.String()).To(ContainSubstring("8080"))
+					Expect(out.String()).To(ContainSubstring("some-credentials"))
+					Expect(out.String()).NotTo(ContainSubstring("hunter2"))
+					Expect(out.String()).NotTo(ContainSubstring("some-token"))
+				})
+			})
+		})
+
+		It("returns the error", func() {
+			client.GetContainerReturns(executor.Container{}, executor.ErrContainerNotFound)
+			Expect(i.ShowContainer("some-guid", false)).To(Equal(executor.ErrContainerNotFound))
+		})
+	})
+
+	Describe("TailEvents", func() {
+		It("prints the events of the container until the source fails", func() {
+			source := new(fakes.FakeEventSource)
+			source.NextReturnsOnCall(0, executor.NewContainerRunningEvent(executor.Container{Guid: "other-guid", State: executor.StateRunning}), nil)
+			source.NextReturnsOnCall(1, executor.NewContainerRunningEvent(executor.Container{Guid: "some-guid", State: executor.StateRunning}).WithMetadata(executor.EventMetadata{
+				Seq:           3,
+				PreviousState: executor.StateCreated,
+			}), nil)
+			source.NextReturnsOnCall(2, nil, errors.New("closed"))
+			client.SubscribeToEventsWithFilterReturns(source, nil)
+
+			filter := executor.EventFilter{Types: []executor.EventType{executor.EventTypeContainerRunning}}
+			Expect(i.TailEvents("some-guid", filter)).To(MatchError("closed"))
+
+			_, sentFilter := client.SubscribeToEventsWithFilterArgsForCall(0)
+			Expect(sentFilter).To(Equal(filter))
+			Expect(source.CloseCallCount()).To(Equal(1))
+
+			Expect(out.String()).NotTo(ContainSubstring("other-guid"))
+			Expect(out.String()).To(MatchRegexp(`3\s+container_running\s+some-guid\s+created -> running`))
+		})
+	})
+
+	Describe("TailLogs", func() {
+		It("prints the log lines until the source fails", func() {
+			source := new(fakes.FakeLogSource)
+			source.NextReturnsOnCall(0, executor.LogLine{SourceName: "APP/PROC/WEB/0", Stream: executor.LogStreamStdout, Message: "hello"}, nil)
+			source.NextReturnsOnCall(1, executor.LogLine{SourceName: "CELL", Stream: executor.LogStreamStderr, Message: "oops"}, nil)
+			source.NextReturnsOnCall(2, executor.LogLine{}, errors.New("closed"))
+			client.SubscribeToLogsReturns(source, nil)
+
+			Expect(i.TailLogs("some-guid")).To(MatchError("closed"))
+
+			_, guid := client.SubscribeToLogsArgsForCall(0)
+			Expect(guid).To(Equal("some-guid"))
+			Expect(source.CloseCallCount()).To(Equal(1))
+
+			Expect(out.String()).To(ContainSubstring("[APP/PROC/WEB/0] OUT hello\n"))
+			Expect(out.String()).To(ContainSubstring("[CELL] ERR oops\n"))
+		})
+	})
+
+	Describe("RecentLogs", func() {
+		It("prints the recent log lines", func() {
+			client.GetRecentLogsReturns([]executor.LogLine{
+				{SourceName: "CELL", Stream: executor.LogStreamStdout, Message: "creating container"},
+				{SourceName: "APP/PROC/WEB/0", Stream: executor.LogStreamStderr, Message: "config file missing"},
+			}, nil)
+
+			Expect(i.RecentLogs("some-guid")).To(Succeed())
+
+			_, guid := client.GetRecentLogsArgsForCall(0)
+			Expect(guid).To(Equal("some-guid"))
+			Expect(out.String()).To(MatchRegexp(`\[CELL\] OUT creating container\n.*\[APP/PROC/WEB/0\] ERR config file missing\n$`))
+		})
+	})
+
+	Describe("GetFiles", func() {
+		It("copies the tar stream", func() {
+			client.GetFilesReturns(ioutil.NopCloser(bytes.NewBufferString("some-tar")), nil)
+
+			dest := &bytes.Buffer{}
+			Expect(i.GetFiles("some-guid", "/some/path", dest)).To(Succeed())
+			Expect(dest.String()).To(Equal("some-tar"))
+
+			_, guid, path := client.GetFilesArgsForCall(0)
+			Expect(guid).To(Equal("some-guid"))
+			Expect(path).To(Equal("/some/path"))
+		})
+	})
+
+	Describe("PutFiles", func() {
+		It("sends the tar stream", func() {
+			src := bytes.NewBufferString("some-tar")
+			Expect(i.PutFiles("some-guid", "/some/path", "vcap", src)).To(Succeed())
+
+			_, guid, path, tarStream, user := client.PutFilesArgsForCall(0)
+			Expect(guid).To(Equal("some-guid"))
+			Expect(path).To(Equal("/some/path"))
+			Expect(tarStream).To(Equal(src))
+			Expect(user).To(Equal("vcap"))
+		})
+	})
+
+	Describe("Resources", func() {
+		It("prints the remaining and total resources", func() {
+			client.RemainingResourcesReturns(executor.ExecutorResources{MemoryMB: 1024, DiskMB: 2048, Containers: 10}, nil)
+			client.TotalResourcesReturns(executor.ExecutorResources{MemoryMB: 4096, DiskMB: 8192, Containers: 250}, nil)
+
+			Expect(i.Resources()).To(Succeed())
+			Expect(out.String()).To(MatchRegexp(`Memory \(MB\)\s+1024\s+4096`))
+			Expect(out.String()).To(MatchRegexp(`Disk \(MB\)\s+2048\s+8192`))
+			Expect(out.String()).To(MatchRegexp(`Containers\s+10\s+250`))
+		})
+	})
+})
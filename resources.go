@@ -2,6 +2,8 @@ package executor
 
 import (
 	"errors"
+	"regexp"
+	"strings"
 	"time"
 
 	"code.cloudfoundry.org/bbs/models"
@@ -17,6 +19,7 @@ const (
 	StateInitializing State = "initializing"
 	StateCreated      State = "created"
 	StateRunning      State = "running"
+	StatePaused       State = "paused"
 	StateCompleted    State = "completed"
 )
 
@@ -43,6 +46,8 @@ type Container struct {
 	MemoryLimit                           uint64             `json:"memory_limit"`
 	DiskLimit                             uint64             `json:"disk_limit"`
 	AdvertisePreferenceForInstanceAddress bool               `json:"advertise_preference_for_instance_address"`
+	OOMKillCount                          int                `json:"oom_kill_count,omitempty"`
+	LastOOMKillAt                         int64              `json:"last_oom_kill_at,omitempty"`
 }
 
 func NewContainerFromResource(guid string, resource *Resource, tags Tags) Container {
@@ -64,6 +69,10 @@ func (c *Container) ValidateTransitionTo(newState State) bool {
 		return newState == StateCreated
 	case StateCreated:
 		return newState == StateRunning
+	case StateRunning:
+		return newState == StatePaused
+	case StatePaused:
+		return newState == StateRunning
 	default:
 		return false
 	}
@@ -88,8 +97,9 @@ func (c *Container) TransitionToCreate() error {
 	return nil
 }
 
-func (c *Container) TransitionToComplete(failed bool, failureReason string, retryable bool) {
+func (c *Container) TransitionToComplete(failed bool, failureCode FailureCode, failureReason string, retryable bool) {
 	c.RunResult.Failed = failed
+	c.RunResult.FailureCode = failureCode
 	c.RunResult.FailureReason = failureReason
 	c.RunResult.Retryable = retryable
 	c.State = StateCompleted
@@ -125,6 +135,7 @@ func (c *Container) HasTags(tags Tags) bool {
 
 func NewReservedContainerFromAllocationRequest(req *AllocationRequest, allocatedAt int64) Container {
 	c := NewContainerFromResource(req.Guid, &req.Resource, req.Tags)
+	c.CPUWeight = req.CPUWeight
 	c.State = StateReserved
 	c.AllocatedAt = allocatedAt
 	return c
@@ -134,6 +145,14 @@ type Resource struct {
 	MemoryMB int `json:"memory_mb"`
 	DiskMB   int `json:"disk_mb"`
 	MaxPids  int `json:"max_pids"`
+
+	// CPUShares is set from the CPUWeight of the allocation when the
+	// container is reserved, as the shares of its Garden container are.
+	CPUShares int `json:"cpu_shares,omitempty"`
+
+	// Priority allows a reservation to preempt containers with a lower
+	// priority when the cell is out of resources.
+	Priority int `json:"priority,omitempty"`
 }
 
 func NewResource(memoryMB, diskMB, maxPids int) Resource {
@@ -241,6 +260,124 @@ type LogConfig struct {
 	Index      int               `json:"index"`
 	SourceName string            `json:"source_name"`
 	Tags       map[string]string `json:"tags"`
+
+	// override the cell's log rate limits, up to the operator's ceilings;
+	// zero keeps the cell's limit. The byte limit is also the largest burst,
+	// so a message larger than it is always dropped; messages are at most
+	// 60KiB.
+	MaxLogLinesPerSecond int   `json:"max_log_lines_per_second,omitempty"`
+	MaxLogBytesPerSecond int64 `json:"max_log_bytes_per_second,omitempty"`
+
+	// Multiline groups the lines of a record, such as a stack trace, into one
+	// message; nil sends every line on its own.
+	Multiline *MultilineConfig `json:"multiline,omitempty"`
+
+	// JSON reads the lines that are JSON objects, promoting some of their
+	// fields to tags; nil sends every line as it is.
+	JSON *JSONLogConfig `json:"json,omitempty"`
+
+	// Redaction masks the container's secrets in the lines before they are
+	// sent; nil sends them as they are.
+	Redaction *LogRedactionConfig `json:"redaction,omitempty"`
+}
+
+type MultilineConfig struct {
+	// StartPattern matches the first line of a record, the lines not matching
+	// it continue the record. When it is empty, the indented lines and the
+	// lines starting with "Caused by:" continue the record.
+	StartPattern string `json:"start_pattern,omitempty"`
+
+	// FlushTimeoutMs is how long a record waits for another line before it is
+	// sent; zero uses the default.
+	FlushTimeoutMs uint `json:"flush_timeout_ms,omitempty"`
+}
+
+type JSONLogConfig struct {
+	// PromotedFields are copied into the tags of the line, without overriding
+	// the container's tags. The default is level, trace_id and span_id.
+	PromotedFields []string `json:"promoted_fields,omitempty"`
+
+	// LevelField holds the level of the line; lines of error level or higher
+	// are sent as errors even when written to stdout. The default is "level".
+	LevelField string `json:"level_field,omitempty"`
+}
+
+func (c *MultilineConfig) Validate() error {
+	if c.StartPattern == "" {
+		return nil
+	}
+
+	_, err := regexp.Compile(c.StartPattern)
+	if err != nil {
+		return ErrLogConfigInvalid
+	}
+	return nil
+}
+
+type LogRedactionConfig struct {
+	// SecretEnvNames are the env vars whose values are masked, on top of the
+	// ones named like a secret (see IsSecretEnvName).
+	SecretEnvNames []string `json:"secret_env_names,omitempty"`
+
+	// Patterns are regular expressions whose matches are masked.
+	Patterns []string `json:"patterns,omitempty"`
+}
+
+var secretEnvNameSuffixes = []string{"_SECRET", "_PASSWORD", "_TOKEN", "_API_KEY", "_PRIVATE_KEY", "_CREDENTIALS"}
+
+// IsSecretEnvName tells whether the env var holds a secret by its name, which
+// ends in _SECRET, _PASSWORD, _TOKEN, _API_KEY, _PRIVATE_KEY or _CREDENTIALS,
+// in any case.
+func IsSecretEnvName(name string) bool {
+	name = strings.ToUpper(name)
+	for _, suffix := range secretEnvNameSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// SecretEnvValues returns the values of the secret env vars in env.
+func (c *LogRedactionConfig) SecretEnvValues(env []EnvironmentVariable) []string {
+	names := map[string]bool{}
+	for _, name := range c.SecretEnvNames {
+		names[name] = true
+	}
+
+	values := []string{}
+	for _, envVar := range env {
+		if names[envVar.Name] || IsSecretEnvName(envVar.Name) {
+			values = append(values, envVar.Value)
+		}
+	}
+	return values
+}
+
+func (c *LogRedactionConfig) Validate() error {
+	for _, pattern := range c.Patterns {
+		_, err := regexp.Compile(pattern)
+		if err != nil {
+			return ErrLogConfigInvalid
+		}
+	}
+	return nil
+}
+
+type LogStream string
+
+const (
+	LogStreamStdout LogStream = "stdout"
+	LogStreamStderr LogStream = "stderr"
+)
+
+// LogLine is a line logged by a container, as it was sent to Loggregator.
+type LogLine struct {
+	SourceName string            `json:"source_name"`
+	Stream     LogStream         `json:"stream"`
+	Message    string            `json:"message"`
+	Tags       map[string]string `json:"tags,omitempty"`
+	Timestamp  int64             `json:"timestamp"`
 }
 
 type PortMapping struct {
@@ -251,24 +388,54 @@ type PortMapping struct {
 }
 
 type ContainerRunResult struct {
-	Failed        bool   `json:"failed"`
-	FailureReason string `json:"failure_reason"`
+	Failed        bool        `json:"failed"`
+	FailureCode   FailureCode `json:"failure_code,omitempty"`
+	FailureReason string      `json:"failure_reason"`
 	Retryable     bool
 
 	Stopped bool `json:"stopped"`
 }
 
+// FailureCode classifies why a container failed. Unlike the failure reason,
+// which is meant for humans and may change, codes are stable.
+type FailureCode string
+
+const (
+	FailureCodeNone                    FailureCode = ""
+	FailureCodeUnknown                 FailureCode = "unknown"
+	FailureCodeOOM                     FailureCode = "oom"
+	FailureCodeProcessExited           FailureCode = "process_exited"
+	FailureCodeExitTimeout             FailureCode = "exit_timeout"
+	FailureCodeStepTimeout             FailureCode = "step_timeout"
+	FailureCodeDownloadFailed          FailureCode = "download_failed"
+	FailureCodeUploadFailed            FailureCode = "upload_failed"
+	FailureCodeVolumeMountFailed       FailureCode = "volume_mount_failed"
+	FailureCodeHealthCheckTimeout      FailureCode = "health_check_timeout"
+	FailureCodeHealthCheckFailed       FailureCode = "health_check_failed"
+	FailureCodeExpired                 FailureCode = "expired"
+	FailureCodeContainerMissing        FailureCode = "container_missing"
+	FailureCodeContainerCreationFailed FailureCode = "container_creation_failed"
+	FailureCodeSetupFailed             FailureCode = "setup_failed"
+	FailureCodeStoppedBeforeRunning    FailureCode = "stopped_before_running"
+	FailureCodeRestoreFailed           FailureCode = "restore_failed"
+	FailureCodePreempted               FailureCode = "preempted"
+)
+
 type ExecutorResources struct {
 	MemoryMB   int `json:"memory_mb"`
 	DiskMB     int `json:"disk_mb"`
 	Containers int `json:"containers"`
+	MaxPids    int `json:"max_pids"`
+	CPUShares  int `json:"cpu_shares"`
 }
 
-func NewExecutorResources(memoryMB, diskMB, containers int) ExecutorResources {
+func NewExecutorResources(memoryMB, diskMB, containers, maxPids, cpuShares int) ExecutorResources {
 	return ExecutorResources{
 		MemoryMB:   memoryMB,
 		DiskMB:     diskMB,
 		Containers: containers,
+		MaxPids:    maxPids,
+		CPUShares:  cpuShares,
 	}
 }
 
@@ -277,7 +444,11 @@ func (e ExecutorResources) Copy() ExecutorResources {
 }
 
 func (r *ExecutorResources) canSubtract(res *Resource) bool {
-	return r.MemoryMB >= res.MemoryMB && r.DiskMB >= res.DiskMB && r.Containers > 0
+	return r.MemoryMB >= res.MemoryMB &&
+		r.DiskMB >= res.DiskMB &&
+		r.MaxPids >= pidsToAccount(res) &&
+		r.CPUShares >= res.CPUShares &&
+		r.Containers > 0
 }
 
 func (r *ExecutorResources) Subtract(res *Resource) bool {
@@ -286,6 +457,8 @@ func (r *ExecutorResources) Subtract(res *Resource) bool {
 	}
 	r.MemoryMB -= res.MemoryMB
 	r.DiskMB -= res.DiskMB
+	r.MaxPids -= pidsToAccount(res)
+	r.CPUShares -= res.CPUShares
 	r.Containers -= 1
 	return true
 }
@@ -293,9 +466,20 @@ func (r *ExecutorResources) Subtract(res *Resource) bool {
 func (r *ExecutorResources) Add(res *Resource) {
 	r.MemoryMB += res.MemoryMB
 	r.DiskMB += res.DiskMB
+	r.MaxPids += pidsToAccount(res)
+	r.CPUShares += res.CPUShares
 	r.Containers += 1
 }
 
+// a non-positive MaxPids means the container has no pid limit, which does not
+// count against the cell's pid budget
+func pidsToAccount(res *Resource) int {
+	if res.MaxPids < 0 {
+		return 0
+	}
+	return res.MaxPids
+}
+
 type Tags map[string]string
 
 func (t Tags) Copy() Tags {
@@ -315,6 +499,61 @@ func (t Tags) Add(other Tags) {
 
 type Event interface {
 	EventType() EventType
+	Sequence() uint64
+	Metadata() EventMetadata
+	WithMetadata(EventMetadata) Event
+}
+
+// EventMetadata is embedded in every event.
+type EventMetadata struct {
+	// Seq numbers the events in the order the hub emits them, so that a
+	// subscriber can resume where it left off.
+	Seq uint64 `json:"sequence,omitempty"`
+
+	// Timestamp is when the event happened, in nanoseconds since the epoch.
+	Timestamp int64 `json:"timestamp,omitempty"`
+
+	// PreviousState is the state the container left, for events that are
+	// state transitions.
+	PreviousState State `json:"previous_state,omitempty"`
+}
+
+func (m EventMetadata) Sequence() uint64        { return m.Seq }
+func (m EventMetadata) Metadata() EventMetadata { return m }
+
+// EventFilter selects the events delivered to a subscriber. An event matches
+// if it has one of the types and its container has all of the tags; a filter
+// without types or tags does not restrict on them.
+type EventFilter struct {
+	Types []EventType `json:"types,omitempty"`
+	Tags  Tags        `json:"tags,omitempty"`
+}
+
+func (f EventFilter) Matches(event Event) bool {
+	if len(f.Types) > 0 {
+		matched := false
+		for _, eventType := range f.Types {
+			if event.EventType() == eventType {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	if len(f.Tags) == 0 {
+		return true
+	}
+
+	lifecycleEvent, ok := event.(LifecycleEvent)
+	if !ok {
+		return false
+	}
+
+	container := lifecycleEvent.Container()
+	return container.HasTags(f.Tags)
 }
 
 type EventType string
@@ -324,9 +563,19 @@ var ErrUnknownEventType = errors.New("unknown event type")
 const (
 	EventTypeInvalid EventType = ""
 
-	EventTypeContainerComplete EventType = "container_complete"
-	EventTypeContainerRunning  EventType = "container_running"
-	EventTypeContainerReserved EventType = "container_reserved"
+	EventTypeContainerComplete     EventType = "container_complete"
+	EventTypeContainerRunning      EventType = "container_running"
+	EventTypeContainerReserved     EventType = "container_reserved"
+	EventTypeContainerInitializing EventType = "container_initializing"
+	EventTypeContainerCreated      EventType = "container_created"
+	EventTypeContainerPaused       EventType = "container_paused"
+	EventTypeContainerStopped      EventType = "container_stopped"
+	EventTypeContainerHealthy      EventType = "container_healthy"
+	EventTypeContainerUnhealthy    EventType = "container_unhealthy"
+
+	EventTypeContainerLimitsUpdated EventType = "container_limits_updated"
+	EventTypeContainerPreempted     EventType = "container_preempted"
+	EventTypeContainerOOM           EventType = "container_oom"
 )
 
 type LifecycleEvent interface {
@@ -336,6 +585,11 @@ type LifecycleEvent interface {
 
 type ContainerCompleteEvent struct {
 	RawContainer Container `json:"container"`
+
+	// RecentLogs are the last lines the container logged, when it failed and
+	// the cell keeps them.
+	RecentLogs []LogLine `json:"recent_logs,omitempty"`
+	EventMetadata
 }
 
 func NewContainerCompleteEvent(container Container) ContainerCompleteEvent {
@@ -348,8 +602,14 @@ func (ContainerCompleteEvent) EventType() EventType   { return EventTypeContaine
 func (e ContainerCompleteEvent) Container() Container { return e.RawContainer }
 func (ContainerCompleteEvent) lifecycleEvent()        {}
 
+func (e ContainerCompleteEvent) WithMetadata(metadata EventMetadata) Event {
+	e.EventMetadata = metadata
+	return e
+}
+
 type ContainerRunningEvent struct {
 	RawContainer Container `json:"container"`
+	EventMetadata
 }
 
 func NewContainerRunningEvent(container Container) ContainerRunningEvent {
@@ -362,8 +622,14 @@ func (ContainerRunningEvent) EventType() EventType   { return EventTypeContainer
 func (e ContainerRunningEvent) Container() Container { return e.RawContainer }
 func (ContainerRunningEvent) lifecycleEvent()        {}
 
+func (e ContainerRunningEvent) WithMetadata(metadata EventMetadata) Event {
+	e.EventMetadata = metadata
+	return e
+}
+
 type ContainerReservedEvent struct {
 	RawContainer Container `json:"container"`
+	EventMetadata
 }
 
 func NewContainerReservedEvent(container Container) ContainerReservedEvent {
@@ -375,3 +641,188 @@ func NewContainerReservedEvent(container Container) ContainerReservedEvent {
 func (ContainerReservedEvent) EventType() EventType   { return EventTypeContainerReserved }
 func (e ContainerReservedEvent) Container() Container { return e.RawContainer }
 func (ContainerReservedEvent) lifecycleEvent()        {}
+
+func (e ContainerReservedEvent) WithMetadata(metadata EventMetadata) Event {
+	e.EventMetadata = metadata
+	return e
+}
+
+type ContainerInitializingEvent struct {
+	RawContainer Container `json:"container"`
+	EventMetadata
+}
+
+func NewContainerInitializingEvent(container Container) ContainerInitializingEvent {
+	return ContainerInitializingEvent{
+		RawContainer: container,
+	}
+}
+
+func (ContainerInitializingEvent) EventType() EventType   { return EventTypeContainerInitializing }
+func (e ContainerInitializingEvent) Container() Container { return e.RawContainer }
+func (ContainerInitializingEvent) lifecycleEvent()        {}
+
+func (e ContainerInitializingEvent) WithMetadata(metadata EventMetadata) Event {
+	e.EventMetadata = metadata
+	return e
+}
+
+type ContainerCreatedEvent struct {
+	RawContainer Container `json:"container"`
+	EventMetadata
+}
+
+func NewContainerCreatedEvent(container Container) ContainerCreatedEvent {
+	return ContainerCreatedEvent{
+		RawContainer: container,
+	}
+}
+
+func (ContainerCreatedEvent) EventType() EventType   { return EventTypeContainerCreated }
+func (e ContainerCreatedEvent) Container() Container { return e.RawContainer }
+func (ContainerCreatedEvent) lifecycleEvent()        {}
+
+func (e ContainerCreatedEvent) WithMetadata(metadata EventMetadata) Event {
+	e.EventMetadata = metadata
+	return e
+}
+
+type ContainerPausedEvent struct {
+	RawContainer Container `json:"container"`
+	EventMetadata
+}
+
+func NewContainerPausedEvent(container Container) ContainerPausedEvent {
+	return ContainerPausedEvent{
+		RawContainer: container,
+	}
+}
+
+func (ContainerPausedEvent) EventType() EventType   { return EventTypeContainerPaused }
+func (e ContainerPausedEvent) Container() Container { return e.RawContainer }
+func (ContainerPausedEvent) lifecycleEvent()        {}
+
+func (e ContainerPausedEvent) WithMetadata(metadata EventMetadata) Event {
+	e.EventMetadata = metadata
+	return e
+}
+
+type ContainerStoppedEvent struct {
+	RawContainer Container `json:"container"`
+	EventMetadata
+}
+
+func NewContainerStoppedEvent(container Container) ContainerStoppedEvent {
+	return ContainerStoppedEvent{
+		RawContainer: container,
+	}
+}
+
+func (ContainerStoppedEvent) EventType() EventType   { return EventTypeContainerStopped }
+func (e ContainerStoppedEvent) Container() Container { return e.RawContainer }
+func (ContainerStoppedEvent) lifecycleEvent()        {}
+
+func (e ContainerStoppedEvent) WithMetadata(metadata EventMetadata) Event {
+	e.EventMetadata = metadata
+	return e
+}
+
+type ContainerHealthyEvent struct {
+	RawContainer Container `json:"container"`
+	EventMetadata
+}
+
+func NewContainerHealthyEvent(container Container) ContainerHealthyEvent {
+	return ContainerHealthyEvent{
+		RawContainer: container,
+	}
+}
+
+func (ContainerHealthyEvent) EventType() EventType   { return EventTypeContainerHealthy }
+func (e ContainerHealthyEvent) Container() Container { return e.RawContainer }
+func (ContainerHealthyEvent) lifecycleEvent()        {}
+
+func (e ContainerHealthyEvent) WithMetadata(metadata EventMetadata) Event {
+	e.EventMetadata = metadata
+	return e
+}
+
+type ContainerUnhealthyEvent struct {
+	RawContainer Container `json:"container"`
+	EventMetadata
+}
+
+func NewContainerUnhealthyEvent(container Container) ContainerUnhealthyEvent {
+	return ContainerUnhealthyEvent{
+		RawContainer: container,
+	}
+}
+
+func (ContainerUnhealthyEvent) EventType() EventType   { return EventTypeContainerUnhealthy }
+func (e ContainerUnhealthyEvent) Container() Container { return e.RawContainer }
+func (ContainerUnhealthyEvent) lifecycleEvent()        {}
+
+func (e ContainerUnhealthyEvent) WithMetadata(metadata EventMetadata) Event {
+	e.EventMetadata = metadata
+	return e
+}
+
+type ContainerLimitsUpdatedEvent struct {
+	RawContainer Container `json:"container"`
+	EventMetadata
+}
+
+func NewContainerLimitsUpdatedEvent(container Container) ContainerLimitsUpdatedEvent {
+	return ContainerLimitsUpdatedEvent{
+		RawContainer: container,
+	}
+}
+
+func (ContainerLimitsUpdatedEvent) EventType() EventType   { return EventTypeContainerLimitsUpdated }
+func (e ContainerLimitsUpdatedEvent) Container() Container { return e.RawContainer }
+func (ContainerLimitsUpdatedEvent) lifecycleEvent()        {}
+
+func (e ContainerLimitsUpdatedEvent) WithMetadata(metadata EventMetadata) Event {
+	e.EventMetadata = metadata
+	return e
+}
+
+type ContainerPreemptedEvent struct {
+	RawContainer Container `json:"container"`
+	EventMetadata
+}
+
+func NewContainerPreemptedEvent(container Container) ContainerPreemptedEvent {
+	return ContainerPreemptedEvent{
+		RawContainer: container,
+	}
+}
+
+func (ContainerPreemptedEvent) EventType() EventType   { return EventTypeContainerPreempted }
+func (e ContainerPreemptedEvent) Container() Container { return e.RawContainer }
+func (ContainerPreemptedEvent) lifecycleEvent()        {}
+
+func (e ContainerPreemptedEvent) WithMetadata(metadata EventMetadata) Event {
+	e.EventMetadata = metadata
+	return e
+}
+
+type ContainerOOMEvent struct {
+	RawContainer Container `json:"container"`
+	EventMetadata
+}
+
+func NewContainerOOMEvent(container Container) ContainerOOMEvent {
+	return ContainerOOMEvent{
+		RawContainer: container,
+	}
+}
+
+func (ContainerOOMEvent) EventType() EventType   { return EventTypeContainerOOM }
+func (e ContainerOOMEvent) Container() Container { return e.RawContainer }
+func (ContainerOOMEvent) lifecycleEvent()        {}
+
+func (e ContainerOOMEvent) WithMetadata(metadata EventMetadata) Event {
+	e.EventMetadata = metadata
+	return e
+}
@@ -52,24 +52,91 @@ var _ = Describe("Container", func() {
 			defaultDiskMB     = 20
 			defaultMemoryMB   = 30
 			defaultContainers = 3
+			defaultMaxPids    = 100
+			defaultCPUShares  = 1024
 		)
 
 		It("returns false when the number of containers is less than 1", func() {
-			resources := executor.NewExecutorResources(defaultMemoryMB, defaultDiskMB, 0)
+			resources := executor.NewExecutorResources(defaultMemoryMB, defaultDiskMB, 0, defaultMaxPids, defaultCPUShares)
 			resourceToSubtract := executor.NewResource(defaultMemoryMB-1, defaultDiskMB-1, -1)
 			Expect(resources.Subtract(&resourceToSubtract)).To(BeFalse())
 		})
 
 		It("returns false when disk size exceeds total available disk size", func() {
-			resources := executor.NewExecutorResources(defaultMemoryMB, 10, defaultContainers)
+			resources := executor.NewExecutorResources(defaultMemoryMB, 10, defaultContainers, defaultMaxPids, defaultCPUShares)
 			resourceToSubtract := executor.NewResource(defaultMemoryMB-1, 20, -1)
 			Expect(resources.Subtract(&resourceToSubtract)).To(BeFalse())
 		})
 
 		It("returns false when memory exceeds total available memory", func() {
-			resources := executor.NewExecutorResources(10, defaultDiskMB, defaultContainers)
+			resources := executor.NewExecutorResources(10, defaultDiskMB, defaultContainers, defaultMaxPids, defaultCPUShares)
 			resourceToSubtract := executor.NewResource(20, defaultDiskMB-1, -1)
 			Expect(resources.Subtract(&resourceToSubtract)).To(BeFalse())
 		})
+
+		It("returns false when max pids exceed the total available pids", func() {
+			resources := executor.NewExecutorResources(defaultMemoryMB, defaultDiskMB, defaultContainers, 10, defaultCPUShares)
+			resourceToSubtract := executor.NewResource(defaultMemoryMB-1, defaultDiskMB-1, 20)
+			Expect(resources.Subtract(&resourceToSubtract)).To(BeFalse())
+		})
+
+		It("returns false when cpu shares exceed the total available cpu shares", func() {
+			resources := executor.NewExecutorResources(defaultMemoryMB, defaultDiskMB, defaultContainers, defaultMaxPids, 10)
+			resourceToSubtract := executor.NewResource(defaultMemoryMB-1, defaultDiskMB-1, -1)
+			resourceToSubtract.CPUShares = 20
+			Expect(resources.Subtract(&resourceToSubtract)).To(BeFalse())
+		})
+
+		It("subtracts every budget when the resource fits", func() {
+			resources := executor.NewExecutorResources(defaultMemoryMB, defaultDiskMB, defaultContainers, defaultMaxPids, defaultCPUShares)
+			resourceToSubtract := executor.NewResource(10, 5, 50)
+			resourceToSubtract.CPUShares = 256
+			Expect(resources.Subtract(&resourceToSubtract)).To(BeTrue())
+			Expect(resources).To(Equal(executor.NewExecutorResources(defaultMemoryMB-10, defaultDiskMB-5, defaultContainers-1, defaultMaxPids-50, defaultCPUShares-256)))
+
+			resources.Add(&resourceToSubtract)
+			Expect(resources).To(Equal(executor.NewExecutorResources(defaultMemoryMB, defaultDiskMB, defaultContainers, defaultMaxPids, defaultCPUShares)))
+		})
+
+		It("does not count containers without a pid limit against the pid budget", func() {
+			resources := executor.NewExecutorResources(defaultMemoryMB, defaultDiskMB, defaultContainers, 0, defaultCPUShares)
+			resourceToSubtract := executor.NewResource(10, 5, -1)
+			Expect(resources.Subtract(&resourceToSubtract)).To(BeTrue())
+			Expect(resources.MaxPids).To(Equal(0))
+		})
+	})
+})
+
+var _ = Describe("EventFilter", func() {
+	container := executor.Container{Guid: "some-guid", Tags: executor.Tags{"domain": "cf-apps", "app": "app-1"}}
+	runningEvent := executor.NewContainerRunningEvent(container)
+	completeEvent := executor.NewContainerCompleteEvent(container)
+
+	It("matches every event when empty", func() {
+		filter := executor.EventFilter{}
+		Expect(filter.Matches(runningEvent)).To(BeTrue())
+		Expect(filter.Matches(completeEvent)).To(BeTrue())
+	})
+
+	It("matches events of the given types", func() {
+		filter := executor.EventFilter{Types: []executor.EventType{executor.EventTypeContainerComplete, executor.EventTypeContainerOOM}}
+		Expect(filter.Matches(completeEvent)).To(BeTrue())
+		Expect(filter.Matches(runningEvent)).To(BeFalse())
+	})
+
+	It("matches events whose container has the given tags", func() {
+		Expect(executor.EventFilter{Tags: executor.Tags{"domain": "cf-apps"}}.Matches(runningEvent)).To(BeTrue())
+		Expect(executor.EventFilter{Tags: executor.Tags{"domain": "cf-tasks"}}.Matches(runningEvent)).To(BeFalse())
+		Expect(executor.EventFilter{Tags: executor.Tags{"other": "tag"}}.Matches(runningEvent)).To(BeFalse())
+	})
+
+	It("requires both the type and the tags to match", func() {
+		filter := executor.EventFilter{
+			Types: []executor.EventType{executor.EventTypeContainerComplete},
+			Tags:  executor.Tags{"app": "app-1"},
+		}
+		Expect(filter.Matches(completeEvent)).To(BeTrue())
+		Expect(filter.Matches(runningEvent)).To(BeFalse())
+		Expect(filter.Matches(executor.NewContainerCompleteEvent(executor.Container{Guid: "untagged"}))).To(BeFalse())
 	})
 })